@@ -0,0 +1,144 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const (
+	// fields of a logical acknowledgement
+	ackFieldDateTime = "177"
+	ackFieldResult   = "451"
+	ackFieldReason   = "405"
+
+	// possible values of the accept/reject result, field 451
+	ackResultAccepted = "0"
+	ackResultRejected = "1"
+
+	nakCodeLength = 3
+)
+
+// nakReasonDescriptions holds the descriptions of commonly encountered error codes a NAK can carry in field 405. Codes
+// starting with H concern the headers of the rejected message, those starting with T the text, i.e. the body.
+var nakReasonDescriptions = map[string]string{
+	"H21": "Invalid message type",
+	"H25": "Invalid destination address",
+	"H50": "Invalid message priority",
+	"T27": "Invalid BIC",
+	"T43": "Amount has too many decimal digits for the currency",
+	"T50": "Invalid date",
+	"T52": "Invalid currency code",
+}
+
+// NAKReason is the reason SWIFT rejected a message, field 405 of a NAK. It consists of an error code (3!c), optionally
+// followed by the number of the line of the message the error was found on (3!n), e.g. T27003. The description is taken
+// from a table of commonly encountered codes and is empty for codes not in it.
+type NAKReason struct {
+	Set         bool
+	Raw         string
+	Code        string
+	Description string
+	Line        int
+}
+
+func (nr *NAKReason) UnmarshalMT(input string) error {
+	// example:
+	// H50
+	// T27003
+
+	if len(input) < nakCodeLength {
+		return fmt.Errorf("invalid NAKReason: expected an error code of %d characters, got: %s", nakCodeLength, input)
+	}
+
+	line := 0
+	if len(input) > nakCodeLength {
+		l, err := strconv.Atoi(input[nakCodeLength:])
+		if err != nil {
+			return fmt.Errorf("invalid NAKReason: expected a line number after the error code, got: %s", input)
+		}
+		line = l
+	}
+
+	nr.Set = true
+	nr.Raw = input
+	nr.Code = input[:nakCodeLength]
+	nr.Description = nakReasonDescriptions[nr.Code]
+	nr.Line = line
+
+	return nil
+}
+
+func (nr NAKReason) RawString() string {
+	return nr.Raw
+}
+
+func (nr NAKReason) String() string {
+	if nr.Description == "" {
+		return nr.Code
+	}
+
+	return nr.Code + ": " + nr.Description
+}
+
+// Acknowledgement is a logical acknowledgement SWIFT sends in response to a message that was input, identified by
+// service identifier 21 in the basic header. A positive acknowledgement, ACK, signals the message was accepted, a
+// negative acknowledgement, NAK, signals it was rejected and holds the reason why.
+type Acknowledgement struct {
+	Base
+	Accepted bool
+	DateTime DateTime
+	Reason   NAKReason
+}
+
+// acknowledgementField returns the content of a field of an acknowledgement. The fields are sub blocks of the body,
+// e.g. {4:{177:2103151130}{451:1}{405:H50}}, though some interfaces place them among the trailers instead.
+func (m MTx) acknowledgementField(tag string) (string, bool) {
+	if vals := m.Field(tag); len(vals) > 0 {
+		return vals[0], true
+	}
+
+	val, ok := m.Trailers.AdditionalTrailers[tag]
+
+	return val, ok
+}
+
+// Acknowledgement returns the details of the message if it is a logical acknowledgement, see IsAcknowledgement. The
+// accept/reject result, field 451, is mandatory. The reason, field 405, is taken along for a NAK, if present.
+func (m MTx) Acknowledgement() (Acknowledgement, error) {
+	ack := Acknowledgement{Base: m.Base}
+
+	if !m.IsAcknowledgement() {
+		return ack, fmt.Errorf("invalid Acknowledgement: expected service identifier 21, got %s", m.BasicHeader.ServiceID)
+	}
+
+	result, _ := m.acknowledgementField(ackFieldResult)
+	switch result {
+	case ackResultAccepted:
+		ack.Accepted = true
+	case ackResultRejected:
+		ack.Accepted = false
+	default:
+		return ack, fmt.Errorf("invalid Acknowledgement: expected result 0 or 1 in field %s, got %q", ackFieldResult, result)
+	}
+
+	if dateTime, ok := m.acknowledgementField(ackFieldDateTime); ok {
+		err := ack.DateTime.UnmarshalMT(dateTime)
+		if err != nil {
+			return ack, fmt.Errorf("invalid Acknowledgement: field %s: %w", ackFieldDateTime, err)
+		}
+	}
+
+	if reason, ok := m.acknowledgementField(ackFieldReason); ok && !ack.Accepted {
+		err := ack.Reason.UnmarshalMT(reason)
+		if err != nil {
+			return ack, fmt.Errorf("invalid Acknowledgement: field %s: %w", ackFieldReason, err)
+		}
+	}
+
+	return ack, nil
+}
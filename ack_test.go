@@ -0,0 +1,123 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DennisVis/mt"
+	mttest "github.com/DennisVis/mt/testdata"
+)
+
+func TestAcknowledgement(t *testing.T) {
+	const original = "{1:F01BANKBEBBAXXX0000000000}{2:I940BANKDEFFXXXXN}{4:\n:20:REFERENCE\n-}"
+
+	for _, test := range []struct {
+		name           string
+		input          string
+		expectedErr    error
+		expectedAck    bool
+		expectedReason mt.NAKReason
+	}{
+		{
+			name:        "ACK",
+			input:       "{1:F21BANKBEBBAXXX0000000000}{4:{177:2103151130}{451:0}}",
+			expectedAck: true,
+		},
+		{
+			name:  "NAK",
+			input: "{1:F21BANKBEBBAXXX0000000000}{4:{177:2103151130}{451:1}{405:H50}}",
+			expectedReason: mt.NAKReason{
+				Set:         true,
+				Raw:         "H50",
+				Code:        "H50",
+				Description: "Invalid message priority",
+			},
+		},
+		{
+			name:  "NAKWithLine",
+			input: "{1:F21BANKBEBBAXXX0000000000}{4:{177:2103151130}{451:1}{405:T27003}}",
+			expectedReason: mt.NAKReason{
+				Set:         true,
+				Raw:         "T27003",
+				Code:        "T27",
+				Description: "Invalid BIC",
+				Line:        3,
+			},
+		},
+		{
+			name:  "NAKUnknownCode",
+			input: "{1:F21BANKBEBBAXXX0000000000}{4:{177:2103151130}{451:1}{405:Z99}}",
+			expectedReason: mt.NAKReason{
+				Set:  true,
+				Raw:  "Z99",
+				Code: "Z99",
+			},
+		},
+		{
+			name:  "NAKReasonInTrailers",
+			input: "{1:F21BANKBEBBAXXX0000000000}{4:{177:2103151130}{451:1}}{5:{405:H50}}",
+			expectedReason: mt.NAKReason{
+				Set:         true,
+				Raw:         "H50",
+				Code:        "H50",
+				Description: "Invalid message priority",
+			},
+		},
+		{
+			name:        "InvalidResult",
+			input:       "{1:F21BANKBEBBAXXX0000000000}{4:{177:2103151130}{451:2}}",
+			expectedErr: fmt.Errorf(`invalid Acknowledgement: expected result 0 or 1 in field 451, got "2"`),
+		},
+		{
+			name:        "InvalidReason",
+			input:       "{1:F21BANKBEBBAXXX0000000000}{4:{177:2103151130}{451:1}{405:T27ABC}}",
+			expectedErr: fmt.Errorf("invalid Acknowledgement: field 405: invalid NAKReason: expected a line number"),
+		},
+		{
+			name:        "NotAnAcknowledgement",
+			input:       original,
+			expectedErr: fmt.Errorf("invalid Acknowledgement: expected service identifier 21, got 01"),
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			msgs, err := mt.ParseAllMTx(ctx, strings.NewReader(test.input+original))
+			if err != nil {
+				t.Fatalf("expected nil error, got: %v", err)
+			}
+			if len(msgs) < 1 {
+				t.Fatalf("expected at least 1 message, got %d", len(msgs))
+			}
+
+			ack, err := msgs[0].Acknowledgement()
+			mttest.ValidateError(t, test.expectedErr, err)
+
+			if test.expectedErr != nil {
+				return
+			}
+
+			if !msgs[0].IsAcknowledgement() {
+				t.Errorf("expected IsAcknowledgement() to return true")
+			}
+			if ack.Accepted != test.expectedAck {
+				t.Errorf("expected Accepted to be %t, got %t", test.expectedAck, ack.Accepted)
+			}
+			if ack.DateTime.Raw != "2103151130" {
+				t.Errorf("expected DateTime to be 2103151130, got %s", ack.DateTime.Raw)
+			}
+			if ack.Reason != test.expectedReason {
+				t.Errorf("expected Reason to be %+v, got %+v", test.expectedReason, ack.Reason)
+			}
+		})
+	}
+}
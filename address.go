@@ -0,0 +1,88 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt
+
+import (
+	"fmt"
+	"unicode"
+)
+
+const logicalTerminalAddressLength = 12
+
+func isUpperLetter(r rune) bool {
+	return r >= 'A' && r <= 'Z'
+}
+
+func isUpperAlphaNumeric(r rune) bool {
+	return isUpperLetter(r) || unicode.IsDigit(r)
+}
+
+// logicalTerminalAddressSlots describes the structure of a logical terminal address, e.g. SCBLZAJJXXXX, being a BIC8
+// (4!a bank code, 2!a country code, 2!c location code), followed by a 1!a terminal code and a 3!c branch code.
+var logicalTerminalAddressSlots = []struct {
+	name        string
+	start       int
+	end         int
+	valid       func(r rune) bool
+	description string
+}{
+	{"bank code", 0, 4, isUpperLetter, "letters"},
+	{"country code", 4, 6, isUpperLetter, "letters"},
+	{"location code", 6, 8, isUpperAlphaNumeric, "letters or digits"},
+	{"terminal code", 8, 9, isUpperLetter, "a letter"},
+	{"branch code", 9, 12, isUpperAlphaNumeric, "letters or digits"},
+}
+
+// validateLogicalTerminalAddress verifies the given address adheres to the 12 character logical terminal address
+// structure. The returned error describes which part of the address is invalid.
+func validateLogicalTerminalAddress(address string) error {
+	if len(address) != logicalTerminalAddressLength {
+		return fmt.Errorf(
+			"logical terminal address %q: expected %d characters, got %d",
+			address,
+			logicalTerminalAddressLength,
+			len(address),
+		)
+	}
+
+	for _, slot := range logicalTerminalAddressSlots {
+		for _, r := range address[slot.start:slot.end] {
+			if !slot.valid(r) {
+				return fmt.Errorf(
+					"logical terminal address %q: %s %q must consist of %s",
+					address,
+					slot.name,
+					address[slot.start:slot.end],
+					slot.description,
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateAddresses verifies the logical terminal addresses in the headers of the message. These are the address in
+// the basic header and, depending on the direction of the message, the receiver address of the input app header or
+// the address in the message input reference of the output app header.
+func validateAddresses(b Base) error {
+	err := validateLogicalTerminalAddress(b.BasicHeader.LogicalTerminalAddress)
+	if err != nil {
+		return fmt.Errorf("invalid basic header: %w", err)
+	}
+
+	switch {
+	case b.IsInput():
+		err = validateLogicalTerminalAddress(b.AppHeaderInput.ReceiverAddress)
+	case b.IsOutput():
+		err = validateLogicalTerminalAddress(b.AppHeaderOutput.MessageInputReference.LogicalTerminalAddress)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid app header: %w", err)
+	}
+
+	return nil
+}
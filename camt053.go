@@ -0,0 +1,367 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	camt053Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.02"
+
+	camt053DateFormat     = "2006-01-02"
+	camt053DateTimeFormat = "2006-01-02T15:04:05"
+
+	// camt053MaxInfoLength is the maximum length of the additional information of a statement or an entry.
+	camt053MaxInfoLength = 500
+)
+
+type camt053Document struct {
+	XMLName xml.Name             `xml:"Document"`
+	Xmlns   string               `xml:"xmlns,attr"`
+	Stmt    camt053BkToCstmrStmt `xml:"BkToCstmrStmt"`
+}
+
+type camt053BkToCstmrStmt struct {
+	GrpHdr camt053GrpHdr `xml:"GrpHdr"`
+	Stmt   camt053Stmt   `xml:"Stmt"`
+}
+
+type camt053GrpHdr struct {
+	MsgID   string `xml:"MsgId"`
+	CreDtTm string `xml:"CreDtTm"`
+}
+
+type camt053Stmt struct {
+	ID           string        `xml:"Id"`
+	ElctrncSeqNb string        `xml:"ElctrncSeqNb,omitempty"`
+	CreDtTm      string        `xml:"CreDtTm"`
+	Acct         camt053Acct   `xml:"Acct"`
+	Bal          []camt053Bal  `xml:"Bal"`
+	Ntry         []camt053Ntry `xml:"Ntry"`
+	AddtlStmtInf string        `xml:"AddtlStmtInf,omitempty"`
+}
+
+type camt053Acct struct {
+	ID  camt053AcctID `xml:"Id"`
+	Ccy string        `xml:"Ccy,omitempty"`
+}
+
+type camt053AcctID struct {
+	IBAN string             `xml:"IBAN,omitempty"`
+	Othr *camt053OthrAcctID `xml:"Othr,omitempty"`
+}
+
+type camt053OthrAcctID struct {
+	ID string `xml:"Id"`
+}
+
+type camt053Amt struct {
+	Ccy   string `xml:"Ccy,attr"`
+	Value string `xml:",chardata"`
+}
+
+type camt053Dt struct {
+	Dt string `xml:"Dt"`
+}
+
+type camt053Bal struct {
+	Tp        camt053BalTp `xml:"Tp"`
+	Amt       camt053Amt   `xml:"Amt"`
+	CdtDbtInd string       `xml:"CdtDbtInd"`
+	Dt        camt053Dt    `xml:"Dt"`
+}
+
+type camt053BalTp struct {
+	CdOrPrtry camt053Cd `xml:"CdOrPrtry"`
+}
+
+type camt053Cd struct {
+	Cd string `xml:"Cd"`
+}
+
+type camt053Ntry struct {
+	Amt          camt053Amt       `xml:"Amt"`
+	CdtDbtInd    string           `xml:"CdtDbtInd"`
+	RvslInd      bool             `xml:"RvslInd,omitempty"`
+	Sts          string           `xml:"Sts"`
+	BookgDt      camt053Dt        `xml:"BookgDt"`
+	ValDt        camt053Dt        `xml:"ValDt"`
+	AcctSvcrRef  string           `xml:"AcctSvcrRef,omitempty"`
+	BkTxCd       camt053BkTxCd    `xml:"BkTxCd"`
+	NtryDtls     *camt053NtryDtls `xml:"NtryDtls,omitempty"`
+	AddtlNtryInf string           `xml:"AddtlNtryInf,omitempty"`
+}
+
+type camt053BkTxCd struct {
+	Prtry camt053Prtry `xml:"Prtry"`
+}
+
+type camt053Prtry struct {
+	Cd   string `xml:"Cd"`
+	Issr string `xml:"Issr"`
+}
+
+type camt053NtryDtls struct {
+	TxDtls camt053TxDtls `xml:"TxDtls"`
+}
+
+type camt053TxDtls struct {
+	Refs camt053Refs `xml:"Refs"`
+}
+
+type camt053Refs struct {
+	EndToEndID string `xml:"EndToEndId"`
+}
+
+// camt053Amount formats an amount as an ISO 20022 decimal, with a dot as the decimal separator.
+func camt053Amount(a Amount) string {
+	value := strconv.FormatInt(a.Value, 10)
+	if a.Decimals <= 0 {
+		return value
+	}
+
+	if len(value) <= a.Decimals {
+		value = strings.Repeat("0", a.Decimals-len(value)+1) + value
+	}
+
+	return value[:len(value)-a.Decimals] + "." + value[len(value)-a.Decimals:]
+}
+
+func camt053CreditDebit(cd CreditDebit) string {
+	if cd == Debit {
+		return "DBIT"
+	}
+	return "CRDT"
+}
+
+// camt053Info joins the lines of additional information with spaces and truncates the result to the maximum length of
+// the information elements.
+func camt053Info(lines ...string) string {
+	parts := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.Join(strings.Fields(line), " ")
+		if line != "" {
+			parts = append(parts, line)
+		}
+	}
+
+	info := []rune(strings.Join(parts, " "))
+	if len(info) > camt053MaxInfoLength {
+		info = info[:camt053MaxInfoLength]
+	}
+
+	return string(info)
+}
+
+// isIBAN tells whether an account identification has the form of an IBAN: a country code, two check digits and up to
+// 30 alphanumeric characters.
+func isIBAN(account string) bool {
+	if len(account) < 5 || len(account) > 34 {
+		return false
+	}
+
+	for i, r := range account {
+		switch {
+		case i < 2 && !unicode.IsUpper(r):
+			return false
+		case i >= 2 && i < 4 && !unicode.IsDigit(r):
+			return false
+		case !unicode.IsUpper(r) && !unicode.IsDigit(r):
+			return false
+		}
+	}
+
+	return true
+}
+
+func (msg MT940) camt053Account(currency string) camt053Acct {
+	acct := camt053Acct{Ccy: currency}
+
+	if isIBAN(msg.AccountIdentification) {
+		acct.ID.IBAN = msg.AccountIdentification
+	} else {
+		acct.ID.Othr = &camt053OthrAcctID{ID: msg.AccountIdentification}
+	}
+
+	return acct
+}
+
+// camt053CreationTime returns the time the statement was created. For output messages that's the output date and
+// time, otherwise the date of the closing balance is used.
+func (msg MT940) camt053CreationTime() string {
+	if msg.IsOutput() && msg.AppHeaderOutput.OutputDate.Set {
+		date := msg.AppHeaderOutput.OutputDate.Time
+		clock := msg.AppHeaderOutput.OutputTime.Time
+
+		return time.Date(
+			date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), 0, 0, time.UTC,
+		).Format(camt053DateTimeFormat)
+	}
+
+	return msg.ClosingBalance.Date.Time.Format(camt053DateTimeFormat)
+}
+
+// camt053BalanceCode returns the balance type of an opening or closing balance, the given code for a final balance or
+// ITBD for an intermediate one, option M.
+func camt053BalanceCode(final string, balance Balance) string {
+	if balance.Option == "M" {
+		return "ITBD"
+	}
+
+	return final
+}
+
+func (msg MT940) camt053Balances() []camt053Bal {
+	balances := []struct {
+		code    string
+		balance Balance
+	}{
+		{camt053BalanceCode("OPBD", msg.OpeningBalance), msg.OpeningBalance},
+		{camt053BalanceCode("CLBD", msg.ClosingBalance), msg.ClosingBalance},
+		{"CLAV", msg.ClosingAvailableBalance},
+	}
+	for _, balance := range msg.ForwardAvailableBalances {
+		balances = append(balances, struct {
+			code    string
+			balance Balance
+		}{"FWAV", balance})
+	}
+
+	bals := make([]camt053Bal, 0, len(balances))
+	for _, b := range balances {
+		if !b.balance.Set {
+			continue
+		}
+
+		bals = append(bals, camt053Bal{
+			Tp:        camt053BalTp{CdOrPrtry: camt053Cd{Cd: b.code}},
+			Amt:       camt053Amt{Ccy: b.balance.Currency, Value: camt053Amount(b.balance.Amount)},
+			CdtDbtInd: camt053CreditDebit(b.balance.CreditDebit),
+			Dt:        camt053Dt{Dt: b.balance.Date.Time.Format(camt053DateFormat)},
+		})
+	}
+
+	return bals
+}
+
+func camt053Entry(line StatementLine, currency string) camt053Ntry {
+	ntry := camt053Ntry{
+		Amt:     camt053Amt{Ccy: currency, Value: camt053Amount(line.Amount)},
+		Sts:     "BOOK",
+		BookgDt: camt053Dt{Dt: line.Date.Time.Format(camt053DateFormat)},
+		ValDt:   camt053Dt{Dt: line.Date.Time.Format(camt053DateFormat)},
+		BkTxCd: camt053BkTxCd{
+			Prtry: camt053Prtry{Cd: line.SwiftCode, Issr: "SWIFT"},
+		},
+		AddtlNtryInf: camt053Info(line.Description, line.Information),
+	}
+
+	// a reversal of a credit is booked as a debit and vice versa
+	switch line.FundsCode {
+	case FundsCodeDebit:
+		ntry.CdtDbtInd = "DBIT"
+	case FundsCodeCreditReversal:
+		ntry.CdtDbtInd = "DBIT"
+		ntry.RvslInd = true
+	case FundsCodeDebitReversal:
+		ntry.CdtDbtInd = "CRDT"
+		ntry.RvslInd = true
+	default:
+		ntry.CdtDbtInd = "CRDT"
+	}
+
+	if entryDate := line.EntryDateFull(); !entryDate.IsZero() {
+		ntry.BookgDt.Dt = entryDate.Format(camt053DateFormat)
+	}
+
+	ntry.AcctSvcrRef = strings.TrimPrefix(line.BankReference, bankReferencePrefix)
+
+	if line.AccountOwnerReference != "" && line.AccountOwnerReference != "NONREF" {
+		ntry.NtryDtls = &camt053NtryDtls{
+			TxDtls: camt053TxDtls{Refs: camt053Refs{EndToEndID: line.AccountOwnerReference}},
+		}
+	}
+
+	return ntry
+}
+
+// ToCamt053 converts the statement into an ISO 20022 bank to customer statement, a camt.053.001.02 XML document. Only
+// the mandatory elements, and the optional ones that directly correspond to fields of the MT940, are generated:
+//
+//   - The group header and statement are identified by the transaction reference number (20). Their creation time is
+//     the output date and time of output messages, otherwise the date of the closing balance.
+//   - The statement number (28C) is the electronic sequence number.
+//   - The account (25) is identified by its IBAN if it has the form of one, otherwise as another identification. Its
+//     currency is that of the opening balance.
+//   - The balances (60F, 60M, 62F, 62M, 64 and 65) are mapped to the balance types OPBD, ITBD, CLBD, ITBD, CLAV and
+//     FWAV respectively, the option of the opening and closing balances (60a and 62a) telling final from intermediate.
+//   - Every statement line (61) becomes a booked entry in the currency of the account. The value date is the value
+//     date, the booking date the entry date if present, otherwise the value date. Reversals are mapped to the opposite
+//     credit/debit indicator with the reversal indicator set. The transaction type identification code becomes a
+//     proprietary bank transaction code issued by SWIFT, the reference for the account owner, unless NONREF, the end
+//     to end identification and the reference of the account servicing institution the entry's reference of the same.
+//     The supplementary details and the information to the account owner (86) of the line are joined into the
+//     additional entry information.
+//   - The remaining information to the account owner (86) is joined into the additional statement information.
+//
+// Not mapped are the headers, other than for the creation time, the sequence number within the statement number and
+// any structure within the information to the account owner. Additional information is truncated to the maximum length
+// of 500 characters.
+func (msg MT940) ToCamt053() ([]byte, error) {
+	opening := msg.OpeningBalance
+	if !opening.Set || opening.Currency == "" {
+		return nil, fmt.Errorf("camt.053: statement has no opening balance to take the currency from")
+	}
+	currency := opening.Currency
+
+	statementNumber := msg.StatementNumber.Number
+	if statementNumber != "" {
+		number, err := strconv.Atoi(statementNumber)
+		if err != nil {
+			return nil, fmt.Errorf("camt.053: invalid statement number %q: %w", statementNumber, err)
+		}
+		statementNumber = strconv.Itoa(number)
+	}
+
+	creationTime := msg.camt053CreationTime()
+
+	entries := make([]camt053Ntry, len(msg.StatementLines))
+	for i, line := range msg.StatementLines {
+		entries[i] = camt053Entry(line, currency)
+	}
+
+	doc := camt053Document{
+		Xmlns: camt053Namespace,
+		Stmt: camt053BkToCstmrStmt{
+			GrpHdr: camt053GrpHdr{
+				MsgID:   msg.Reference,
+				CreDtTm: creationTime,
+			},
+			Stmt: camt053Stmt{
+				ID:           msg.Reference,
+				ElctrncSeqNb: statementNumber,
+				CreDtTm:      creationTime,
+				Acct:         msg.camt053Account(currency),
+				Bal:          msg.camt053Balances(),
+				Ntry:         entries,
+				AddtlStmtInf: camt053Info(msg.AccountOwnerInformation...),
+			},
+		},
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("camt.053: %w", err)
+	}
+
+	return append([]byte(xml.Header), out...), nil
+}
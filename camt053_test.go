@@ -0,0 +1,123 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/DennisVis/mt"
+	mttest "github.com/DennisVis/mt/testdata"
+)
+
+func TestMT940ToCamt053Golden(t *testing.T) {
+	// the sample file does not fully adhere to the specification, see TestParseMT940
+	msgs, _ := mt.ParseAllMT940(ctx, mttest.MustOpenFile("testdata/sample-file-mt940.txt"), mt.Lax(true))
+	if len(msgs) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(msgs))
+	}
+
+	for i, msg := range msgs {
+		// rebind to make sure we can run in parallel
+		msg := msg
+		golden := fmt.Sprintf("testdata/sample-file-mt940-camt053-%d.xml", i+1)
+
+		t.Run(golden, func(t *testing.T) {
+			t.Parallel()
+
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("could not read golden file: %v", err)
+			}
+
+			actual, err := msg.ToCamt053()
+			mttest.ValidateError(t, nil, err)
+
+			if string(actual) != string(expected) {
+				t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+			}
+		})
+	}
+}
+
+func TestMT940ToCamt053(t *testing.T) {
+	for _, test := range []struct {
+		name             string
+		input            string
+		expectedErr      error
+		expectedContains []string
+	}{
+		{
+			name: "Reversals",
+			input: ":20:REFERENCE\n" +
+				":25:PL61109010140000071219812874\n" +
+				":28C:00001\n" +
+				":60F:C031002EUR100,\n" +
+				":61:0310021003RC10,00NTRFNONREF\n" +
+				":61:0310021002RD5,5NTRFCUSTREF//BANKREF\n" +
+				":62F:C031002EUR95,50\n",
+			expectedContains: []string{
+				"<IBAN>PL61109010140000071219812874</IBAN>",
+				"<ElctrncSeqNb>1</ElctrncSeqNb>",
+				"<Amt Ccy=\"EUR\">100</Amt>",
+				"<Amt Ccy=\"EUR\">10.00</Amt>\n        <CdtDbtInd>DBIT</CdtDbtInd>\n        <RvslInd>true</RvslInd>",
+				"<BookgDt>\n          <Dt>2003-10-03</Dt>",
+				"<Amt Ccy=\"EUR\">5.5</Amt>\n        <CdtDbtInd>CRDT</CdtDbtInd>\n        <RvslInd>true</RvslInd>",
+				"<AcctSvcrRef>BANKREF</AcctSvcrRef>",
+				"<EndToEndId>CUSTREF</EndToEndId>",
+			},
+		},
+		{
+			name: "IntermediateBalances",
+			input: ":20:REFERENCE\n" +
+				":25:BPHKPLPK/320000546101\n" +
+				":28C:00084/002\n" +
+				":60M:D031002PLN40000,00\n" +
+				":62M:D031002PLN40000,00\n" +
+				":86:STATEMENT\nINFORMATION\n",
+			expectedContains: []string{
+				"<Othr>\n            <Id>BPHKPLPK/320000546101</Id>",
+				"<Cd>ITBD</Cd>",
+				"<CdtDbtInd>DBIT</CdtDbtInd>",
+				"<AddtlStmtInf>STATEMENT INFORMATION</AddtlStmtInf>",
+			},
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			input := "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:\n" + test.input + "-}"
+
+			msgs, err := mt.ParseAllMT940(ctx, strings.NewReader(input))
+			mttest.ValidateError(t, nil, err)
+
+			if len(msgs) != 1 {
+				t.Fatalf("expected 1 message, got %d", len(msgs))
+			}
+
+			actual, err := msgs[0].ToCamt053()
+			mttest.ValidateError(t, test.expectedErr, err)
+
+			for _, expected := range test.expectedContains {
+				if !strings.Contains(string(actual), expected) {
+					t.Errorf("expected output to contain:\n%s\ngot:\n%s", expected, actual)
+				}
+			}
+		})
+	}
+}
+
+func TestMT940ToCamt053WithoutOpeningBalance(t *testing.T) {
+	t.Parallel()
+
+	_, err := mt.MT940{Reference: "REFERENCE"}.ToCamt053()
+	mttest.ValidateError(t, fmt.Errorf("camt.053: statement has no opening balance"), err)
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+)
+
+// canonicalFieldValue normalizes the value of a body field. Line breaks are normalized to \n and every line is trimmed
+// of surrounding whitespace, as are leading and trailing empty lines.
+func canonicalFieldValue(value string) string {
+	lines := strings.Split(strings.ReplaceAll(value, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// canonicalBasicHeader generates block 1 without the session and sequence numbers, which change every time a message
+// is sent, e.g. when it is sent again as a possible duplicate.
+func canonicalBasicHeader(bh BasicHeader) string {
+	if bh.Raw == "" {
+		return ""
+	}
+
+	return strings.TrimSuffix(bh.Raw, bh.SessionNumber+bh.SequenceNumber+"}") + "}"
+}
+
+// canonicalAppHeaderOutput generates block 2 of an output message without the output date and time, which change
+// every time a message is delivered, e.g. when it is delivered again as a possible duplicate. The message input
+// reference identifying the message is kept.
+func canonicalAppHeaderOutput(ah AppHeaderOutput) string {
+	content := strings.TrimSuffix(strings.TrimPrefix(ah.Raw, "{2:"), "}")
+	if len(content) < 46 {
+		return ah.Raw
+	}
+
+	// direction, message type, input time and message input reference, followed by the output date and time
+	return "{2:" + content[:36] + content[46:] + "}"
+}
+
+// canonicalTrailers generates block 5 with the trailers ordered by label, leaving out the possible duplicate trailers
+// PDE and PDM. Without any remaining trailers an empty string is returned.
+func canonicalTrailers(t Trailers) string {
+	trailers := make(map[string]string, len(t.AdditionalTrailers)+5)
+	for label, content := range t.AdditionalTrailers {
+		trailers[label] = content
+	}
+
+	if t.Checksum != "" {
+		trailers["CHK"] = t.Checksum
+	}
+	if t.TestAndTrainingMessage {
+		trailers["TNG"] = ""
+	}
+	if t.DelayedMessage {
+		trailers["DLM"] = ""
+	}
+	if t.MessageReference.Raw != "" {
+		trailers["MRF"] = t.MessageReference.Raw
+	}
+	if t.SystemOriginatedMessage.Raw != "" {
+		trailers["SYS"] = t.SystemOriginatedMessage.Raw
+	}
+	delete(trailers, "PDE")
+	delete(trailers, "PDM")
+
+	if len(trailers) == 0 {
+		return ""
+	}
+
+	labels := make([]string, 0, len(trailers))
+	for label := range trailers {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	sb := &strings.Builder{}
+	sb.WriteString("{5:")
+	for _, label := range labels {
+		sb.WriteString("{" + label + ":" + trailers[label] + "}")
+	}
+	sb.WriteString("}")
+
+	return sb.String()
+}
+
+// Canonical returns the message in a normalized MT format, which is the same for all representations of the same
+// message. It is meant for comparing messages, e.g. to detect duplicates, rather than for sending them. The following
+// rules apply:
+//
+//   - The blocks are written in the order 1 to 5, regardless of the order they were found in.
+//   - The headers, including the user header, are written as they were parsed, except for the parts that change every
+//     time the same message is sent or delivered. The session and sequence numbers are left out of the basic header,
+//     the output date and time out of the app header of output messages.
+//   - The body is written with one field per line in the original order. Line breaks within field values are
+//     normalized to \n and every line is trimmed of surrounding whitespace, as are leading and trailing empty lines.
+//   - The trailers are written ordered by label. The possible duplicate trailers, PDE and PDM, are left out, so a
+//     message sent again as a possible duplicate has the same canonical form as the original. The trailers block is
+//     left out altogether when no other trailers remain.
+func (m MTx) Canonical() string {
+	m = m.loaded()
+
+	sb := &strings.Builder{}
+
+	sb.WriteString(canonicalBasicHeader(m.BasicHeader))
+	if m.IsOutput() {
+		sb.WriteString(canonicalAppHeaderOutput(m.AppHeaderOutput))
+	} else {
+		sb.WriteString(m.AppHeaderInput.Raw)
+	}
+
+	if m.HasUserHeader() {
+		sb.WriteString(m.UsrHeader.Raw)
+	}
+
+	sb.WriteString("{4:\n")
+	eachOrderedField(m.bodyTags(), m.Body, func(tag string, _ int, value string) {
+		sb.WriteString(":" + tag + ":" + canonicalFieldValue(value) + "\n")
+	})
+	sb.WriteString("-}")
+
+	sb.WriteString(canonicalTrailers(m.Trailers))
+
+	return sb.String()
+}
+
+// Hash returns the hex encoded SHA-256 hash of the canonical form of the message, see Canonical. Two messages have the
+// same hash if, and only if, their canonical forms are the same.
+func (m MTx) Hash() string {
+	sum := sha256.Sum256([]byte(m.Canonical()))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/DennisVis/mt"
+)
+
+const canonicalMTx = "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{3:{108:MUR12345}}{4:\n" +
+	":20:REFERENCE\n" +
+	":86:LINE ONE\nLINE TWO\n" +
+	"-}{5:{MRF:1806271539180626BANKFRPPAXXX2222123456}{CHK:ABCDEF123456}}"
+
+func TestMTxCanonical(t *testing.T) {
+	expectedCanonical := "{1:F01BPHKPLPKXXXX}{2:I940BOFAUS6BXBAMN}{3:{108:MUR12345}}{4:\n" +
+		":20:REFERENCE\n" +
+		":86:LINE ONE\nLINE TWO\n" +
+		"-}{5:{CHK:ABCDEF123456}{MRF:1806271539180626BANKFRPPAXXX2222123456}}"
+
+	for _, test := range []struct {
+		name          string
+		input         string
+		preserve      bool
+		expectedEqual bool
+	}{
+		{
+			name:          "Same",
+			input:         canonicalMTx,
+			expectedEqual: true,
+		},
+		{
+			name: "Whitespace",
+			input: "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{3:{108:MUR12345}}{4:\r\n" +
+				":20:REFERENCE  \r\n" +
+				":86: LINE ONE \r\nLINE TWO\r\n\r\n" +
+				"-}{5:{MRF:1806271539180626BANKFRPPAXXX2222123456}{CHK:ABCDEF123456}}",
+			preserve:      true,
+			expectedEqual: true,
+		},
+		{
+			name: "BlockOrder",
+			input: "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{3:{108:MUR12345}}" +
+				"{5:{MRF:1806271539180626BANKFRPPAXXX2222123456}{CHK:ABCDEF123456}}{4:\n" +
+				":20:REFERENCE\n" +
+				":86:LINE ONE\nLINE TWO\n" +
+				"-}",
+			expectedEqual: true,
+		},
+		{
+			name: "TrailerOrderAndPossibleDuplicate",
+			input: "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{3:{108:MUR12345}}{4:\n" +
+				":20:REFERENCE\n" +
+				":86:LINE ONE\nLINE TWO\n" +
+				"-}{5:{CHK:ABCDEF123456}{PDE:1348120811BANKFRPPAXXX2222123456}" +
+				"{MRF:1806271539180626BANKFRPPAXXX2222123456}}",
+			expectedEqual: true,
+		},
+		{
+			// sent again in another session, as a possible duplicate
+			name: "PossibleDuplicateEmission",
+			input: "{1:F01BPHKPLPKXXXX1348000042}{2:I940BOFAUS6BXBAMN}{3:{108:MUR12345}}{4:\n" +
+				":20:REFERENCE\n" +
+				":86:LINE ONE\nLINE TWO\n" +
+				"-}{5:{MRF:1806271539180626BANKFRPPAXXX2222123456}{CHK:ABCDEF123456}" +
+				"{PDE:1348120811BPHKPLPKXXXX0000000000}}",
+			expectedEqual: true,
+		},
+		{
+			name: "DifferentField",
+			input: "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{3:{108:MUR12345}}{4:\n" +
+				":20:REFERENCE\n" +
+				":86:LINE ONE\nLINE THREE\n" +
+				"-}{5:{MRF:1806271539180626BANKFRPPAXXX2222123456}{CHK:ABCDEF123456}}",
+		},
+		{
+			name: "DifferentFieldOrder",
+			input: "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{3:{108:MUR12345}}{4:\n" +
+				":86:LINE ONE\nLINE TWO\n" +
+				":20:REFERENCE\n" +
+				"-}{5:{MRF:1806271539180626BANKFRPPAXXX2222123456}{CHK:ABCDEF123456}}",
+		},
+		{
+			name: "DifferentTrailer",
+			input: "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{3:{108:MUR12345}}{4:\n" +
+				":20:REFERENCE\n" +
+				":86:LINE ONE\nLINE TWO\n" +
+				"-}{5:{MRF:1806271539180626BANKFRPPAXXX2222123456}{CHK:ABCDEF654321}}",
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			msgs, err := mt.ParseAllMTx(ctx, strings.NewReader(test.input), mt.PreserveWhitespace(test.preserve))
+			if err != nil {
+				t.Fatalf("expected nil error, got: %v", err)
+			}
+			if len(msgs) != 1 {
+				t.Fatalf("expected 1 message, got %d", len(msgs))
+			}
+
+			canonical := msgs[0].Canonical()
+			if test.expectedEqual && canonical != expectedCanonical {
+				t.Errorf("expected canonical form:\n%s\ngot:\n%s", expectedCanonical, canonical)
+			}
+			if !test.expectedEqual && canonical == expectedCanonical {
+				t.Errorf("expected canonical form to differ from:\n%s", expectedCanonical)
+			}
+		})
+	}
+}
+
+func TestMTxCanonicalPossibleDuplicateMessage(t *testing.T) {
+	t.Parallel()
+
+	const body = "{4:\n:20:REFERENCE\n:86:LINE ONE\n-}"
+
+	// the same message delivered twice, the second time in another session and at a later time, as a possible duplicate
+	// referring to the first delivery, followed by another message
+	input := "{1:F01BANKBEBBAXXX2222123456}{2:O9401200180626BANKFRPPAXXX22221234561806271539N}" + body +
+		"{5:{CHK:ABCDEF123456}}\n" +
+		"{1:F01BANKBEBBAXXX2223000017}{2:O9401200180626BANKFRPPAXXX22221234561806281010N}" + body +
+		"{5:{CHK:ABCDEF123456}{PDM:1010180628BANKBEBBAXXX2222123456}}\n" +
+		"{1:F01BANKBEBBAXXX2223000018}{2:O9401201180626BANKFRPPAXXX22221234571806281011N}" + body +
+		"{5:{CHK:ABCDEF123456}}"
+
+	msgs, err := mt.ParseAllMTx(ctx, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("expected nil error, got: %v", err)
+	}
+	if len(msgs) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(msgs))
+	}
+
+	expectedCanonical := "{1:F01BANKBEBBAXXX}{2:O9401200180626BANKFRPPAXXX2222123456N}" + body + "{5:{CHK:ABCDEF123456}}"
+	for i, msg := range msgs[:2] {
+		if canonical := msg.Canonical(); canonical != expectedCanonical {
+			t.Errorf("expected canonical form of message %d:\n%s\ngot:\n%s", i, expectedCanonical, canonical)
+		}
+	}
+
+	if msgs[0].Hash() != msgs[1].Hash() {
+		t.Errorf("expected the possible duplicate to have the original hash, got %s and %s", msgs[0].Hash(), msgs[1].Hash())
+	}
+	if msgs[0].Hash() == msgs[2].Hash() {
+		t.Errorf("expected the hash of another message to differ, got %s for both", msgs[0].Hash())
+	}
+}
+
+func TestMTxHash(t *testing.T) {
+	t.Parallel()
+
+	// the second message is a test and training message, otherwise the same
+	input := canonicalMTx + "\n" + strings.Replace(canonicalMTx, "{CHK:", "{TNG:}{CHK:", 1)
+
+	msgs, err := mt.ParseAllMTx(ctx, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("expected nil error, got: %v", err)
+	}
+	if len(msgs) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(msgs))
+	}
+
+	hash := msgs[0].Hash()
+	if len(hash) != 64 {
+		t.Errorf("expected a hex encoded SHA-256 hash of 64 characters, got %d: %s", len(hash), hash)
+	}
+	if hash != msgs[0].Hash() {
+		t.Errorf("expected hash to be stable, got %s and %s", hash, msgs[0].Hash())
+	}
+	if hash == msgs[1].Hash() {
+		t.Errorf("expected hashes of different messages to differ, got %s for both", hash)
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt
+
+// categoryNames holds the names of the message categories, keyed by the first digit of the message type.
+var categoryNames = map[int]string{
+	0: "System Messages",
+	1: "Customer Payments & Cheques",
+	2: "Financial Institution Transfers",
+	3: "Treasury Markets - Foreign Exchange, Money Markets & Derivatives",
+	4: "Collection & Cash Letters",
+	5: "Securities Markets",
+	6: "Treasury Markets - Precious Metals & Syndications",
+	7: "Documentary Credits & Guarantees",
+	8: "Travellers Cheques",
+	9: "Cash Management & Customer Status",
+}
+
+// Category returns the category of the message, the first digit of its type, e.g. 9 for an MT940. It returns -1 if the
+// message has no type, or a type that does not start with a digit.
+func (b Base) Category() int {
+	messageType := b.Type()
+	if messageType == "" || isNotDigit(rune(messageType[0])) {
+		return -1
+	}
+
+	return int(messageType[0] - '0')
+}
+
+// CategoryName returns the name of the category of the message, e.g. Cash Management & Customer Status for an MT940,
+// see Category. It returns an empty string if the category is not known.
+func (b Base) CategoryName() string {
+	return categoryNames[b.Category()]
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt_test
+
+import (
+	"testing"
+
+	"github.com/DennisVis/mt"
+)
+
+func TestCategory(t *testing.T) {
+	for _, test := range []struct {
+		name             string
+		base             mt.Base
+		expectedCategory int
+		expectedName     string
+	}{
+		{
+			name:             "CustomerPayment",
+			base:             mt.Base{AppHeaderInput: mt.AppHeaderInput{Set: true, MessageType: "103"}},
+			expectedCategory: 1,
+			expectedName:     "Customer Payments & Cheques",
+		},
+		{
+			name:             "FinancialInstitutionTransfer",
+			base:             mt.Base{AppHeaderOutput: mt.AppHeaderOutput{Set: true, MessageType: "202"}},
+			expectedCategory: 2,
+			expectedName:     "Financial Institution Transfers",
+		},
+		{
+			name:             "SecuritiesMarkets",
+			base:             mt.Base{AppHeaderInput: mt.AppHeaderInput{Set: true, MessageType: "535"}},
+			expectedCategory: 5,
+			expectedName:     "Securities Markets",
+		},
+		{
+			name:             "CashManagement",
+			base:             mt.Base{AppHeaderInput: mt.AppHeaderInput{Set: true, MessageType: "940"}},
+			expectedCategory: 9,
+			expectedName:     "Cash Management & Customer Status",
+		},
+		{
+			name:             "System",
+			base:             mt.Base{AppHeaderInput: mt.AppHeaderInput{Set: true, MessageType: "021"}},
+			expectedCategory: 0,
+			expectedName:     "System Messages",
+		},
+		{
+			name:             "NoType",
+			expectedCategory: -1,
+		},
+		{
+			name:             "InvalidType",
+			base:             mt.Base{AppHeaderInput: mt.AppHeaderInput{Set: true, MessageType: "X40"}},
+			expectedCategory: -1,
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if category := test.base.Category(); category != test.expectedCategory {
+				t.Errorf("expected category %d, got %d", test.expectedCategory, category)
+			}
+			if name := test.base.CategoryName(); name != test.expectedName {
+				t.Errorf("expected category name %q, got %q", test.expectedName, name)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// finSpecialCharacters are the characters, besides letters and digits, permitted by the FIN X character set.
+const finSpecialCharacters = "/-?:().,'+ \r\n"
+
+// isFINCharacter reports whether the rune is part of the FIN X character set.
+func isFINCharacter(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return true
+	default:
+		return strings.ContainsRune(finSpecialCharacters, r)
+	}
+}
+
+// validateFINCharSet verifies the values of all body fields only consist of characters in the FIN X character set.
+// For every value that does not, an error is returned reporting the first offending character and its byte offset
+// within the value. The fields are checked in the order they were found in.
+func validateFINCharSet(body map[string][]string, order []string) []error {
+	errs := make([]error, 0)
+
+	eachOrderedField(order, body, func(tag string, _ int, value string) {
+		for offset, r := range value {
+			if !isFINCharacter(r) {
+				errs = append(errs, fmt.Errorf(
+					"invalid character %q in field %s at byte offset %d: not in the FIN character set",
+					r,
+					tag,
+					offset,
+				))
+				break
+			}
+		}
+	})
+
+	return errs
+}
+
+// detectNonASCII scans the values of all body fields for bytes outside of the ASCII range. For every value containing
+// any an error is returned reporting each offending character together with its byte offset within the value. Bytes
+// that are not valid UTF-8 have already been replaced by the Unicode replacement character during lexing, and are
+// reported as such. The fields are checked in the order they were found in.
+func detectNonASCII(body map[string][]string, order []string) []error {
+	errs := make([]error, 0)
+
+	eachOrderedField(order, body, func(tag string, _ int, value string) {
+		found := make([]string, 0)
+		for offset := 0; offset < len(value); {
+			if value[offset] < utf8.RuneSelf {
+				offset++
+				continue
+			}
+
+			r, size := utf8.DecodeRuneInString(value[offset:])
+			found = append(found, fmt.Sprintf("%q at byte offset %d", r, offset))
+			offset += size
+		}
+
+		if len(found) > 0 {
+			errs = append(errs, fmt.Errorf("non-ASCII characters in field %s: %s", tag, strings.Join(found, ", ")))
+		}
+	})
+
+	return errs
+}
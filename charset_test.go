@@ -0,0 +1,141 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DennisVis/mt"
+	mttest "github.com/DennisVis/mt/testdata"
+)
+
+const finCharSetMTx = `{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:
+:20:REFERENCE
+:25:BPHKPLPK/320000546101
+:86:%s
+-}`
+
+func TestFINCharSet(t *testing.T) {
+	for _, test := range []struct {
+		name          string
+		value         string
+		finCharSet    bool
+		lax           bool
+		expectedErrs  mt.Errors
+		expectedCount int
+	}{
+		{
+			name:          "Valid",
+			value:         "PAYMENT (REF/1-2?) 'A'+B, C.D:E",
+			finCharSet:    true,
+			expectedCount: 1,
+		},
+		{
+			name:          "InvalidNotValidated",
+			value:         "MAIL@EXAMPLE",
+			expectedCount: 1,
+		},
+		{
+			name:       "Invalid",
+			value:      "MAIL@EXAMPLE #1",
+			finCharSet: true,
+			expectedErrs: mt.Errors{
+				mt.NewError(fmt.Errorf("invalid character '@' in field 86 at byte offset 4"), 1),
+			},
+		},
+		{
+			name:       "InvalidMultiByte",
+			value:      "ZAŻÓŁĆ",
+			finCharSet: true,
+			expectedErrs: mt.Errors{
+				mt.NewError(fmt.Errorf("invalid character 'Ż' in field 86 at byte offset 2"), 1),
+			},
+		},
+		{
+			name:       "InvalidLax",
+			value:      "A#B",
+			finCharSet: true,
+			lax:        true,
+			expectedErrs: mt.Errors{
+				mt.NewError(fmt.Errorf("invalid character '#' in field 86 at byte offset 1"), 1),
+			},
+			expectedCount: 1,
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			input := strings.NewReader(fmt.Sprintf(finCharSetMTx, test.value))
+
+			msgs, err := mt.ParseAllMTx(ctx, input, mt.FINCharSet(test.finCharSet), mt.Lax(test.lax))
+			mttest.ValidateErrors(t, test.expectedErrs, err)
+
+			if len(msgs) != test.expectedCount {
+				t.Errorf("expected %d messages, got %d", test.expectedCount, len(msgs))
+			}
+		})
+	}
+}
+
+func TestDetectNonASCII(t *testing.T) {
+	for _, test := range []struct {
+		name         string
+		value        string
+		detect       bool
+		expectedErrs mt.Errors
+	}{
+		{
+			name:   "ASCII",
+			value:  "PLATNOSC ZA FAKTURE",
+			detect: true,
+		},
+		{
+			name:  "NonASCIINotDetected",
+			value: "kwotą",
+		},
+		{
+			name:   "NonASCII",
+			value:  "kwotą złotych",
+			detect: true,
+			expectedErrs: mt.Errors{
+				mt.NewError(fmt.Errorf(
+					"non-ASCII characters in field 86: 'ą' at byte offset 4, 'ł' at byte offset 8",
+				), 1),
+			},
+		},
+		{
+			// invalid UTF-8 is replaced by the replacement character while lexing
+			name:   "InvalidUTF8",
+			value:  "AB\xffC",
+			detect: true,
+			expectedErrs: mt.Errors{
+				mt.NewError(fmt.Errorf("non-ASCII characters in field 86: '\uFFFD' at byte offset 2"), 1),
+			},
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			input := strings.NewReader(fmt.Sprintf(finCharSetMTx, test.value))
+
+			msgs, err := mt.ParseAllMTx(ctx, input, mt.DetectNonASCII(test.detect))
+			mttest.ValidateErrors(t, test.expectedErrs, err)
+
+			// the messages are kept regardless of non-ASCII characters being detected
+			if len(msgs) != 1 {
+				t.Fatalf("expected 1 message, got %d", len(msgs))
+			}
+		})
+	}
+}
@@ -19,7 +19,7 @@ const (
 	generatedComment                  = "// Code generated by cmd/generate/generate.go, DO NOT EDIT\n\n"
 )
 
-var targetFileNamePattern = regexp.MustCompile(`mt([0-9]{3})(` + templateFileNameMessageTypeSuffix + `|)\.go`)
+var targetFileNamePattern = regexp.MustCompile(`^mt([0-9]{3})(` + templateFileNameMessageTypeSuffix + `|)\.go`)
 
 type target struct {
 	sourceFileName string
@@ -0,0 +1,155 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/DennisVis/mt"
+)
+
+// concurrentParses is the number of parses run at the same time by the concurrency tests. Run them with -race to have
+// any unsynchronized access to shared state reported.
+const concurrentParses = 32
+
+// runConcurrently calls fn concurrentParses times at once and waits for all calls to return.
+func runConcurrently(fn func(i int)) {
+	start := make(chan struct{})
+	wg := &sync.WaitGroup{}
+
+	for i := 0; i < concurrentParses; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			fn(i)
+		}(i)
+	}
+
+	close(start)
+	wg.Wait()
+}
+
+func TestConcurrentParsesWithSharedOptions(t *testing.T) {
+	sample, err := os.ReadFile("testdata/sample-file-mt940.txt")
+	if err != nil {
+		t.Fatalf("could not read sample file: %v", err)
+	}
+
+	// the same option values, including the callbacks, are shared by all parses
+	relaxFields := mt.RelaxFields("20", "86")
+	strict := mt.Strict(true)
+	reportSkipped := mt.ReportSkipped(true)
+	skippedContent := mt.SkippedContent(func(string, int) {})
+	logger := mt.WithLogger(func(context.Context, string, string) {})
+
+	parseAllMTx := func(synchronous bool) (interface{}, error) {
+		return mt.ParseAllMTx(
+			ctx,
+			bytes.NewReader(sample),
+			relaxFields,
+			strict,
+			reportSkipped,
+			skippedContent,
+			logger,
+			mt.Synchronous(synchronous),
+		)
+	}
+	parseAllMT940 := func() (interface{}, error) {
+		return mt.ParseAllMT940(ctx, bytes.NewReader(sample), relaxFields, strict, reportSkipped, skippedContent, logger)
+	}
+
+	expectedMsgs, expectedErr := parseAllMTx(true)
+	expectedMT940s, expectedMT940Err := parseAllMT940()
+
+	for _, test := range []struct {
+		name  string
+		parse func() (interface{}, error)
+		want  interface{}
+		err   error
+	}{
+		{
+			name: "ParseAllMTx",
+			parse: func() (interface{}, error) {
+				return parseAllMTx(false)
+			},
+			want: expectedMsgs,
+			err:  expectedErr,
+		},
+		{
+			name: "ParseAllMTxSynchronous",
+			parse: func() (interface{}, error) {
+				return parseAllMTx(true)
+			},
+			want: expectedMsgs,
+			err:  expectedErr,
+		},
+		{
+			name: "ParseAllMT940",
+			parse: func() (interface{}, error) {
+				return parseAllMT940()
+			},
+			want: expectedMT940s,
+			err:  expectedMT940Err,
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			runConcurrently(func(i int) {
+				actual, err := test.parse()
+				if !reflect.DeepEqual(actual, test.want) {
+					t.Errorf("parse %d: expected the same messages as a sequential parse", i)
+				}
+				if errorStrings(err) != errorStrings(test.err) {
+					t.Errorf("parse %d: expected error %v, got %v", i, test.err, err)
+				}
+			})
+		})
+	}
+}
+
+// errorStrings returns the sorted descriptions of the parse errors, the errors of concurrent parses are not necessarily
+// published in the same order.
+func errorStrings(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	strs := make([]string, 0)
+	for _, e := range mt.ErrorToErrors(err) {
+		strs = append(strs, e.String())
+	}
+	sort.Strings(strs)
+
+	return strings.Join(strs, "\n")
+}
+
+func TestRelaxFieldsCopiesTags(t *testing.T) {
+	input := "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:\n:20:REFERENCE-TOO-LONG-FOR-16X\n" +
+		":25:BPHKPLPK/320000546101\n:28C:00084/002\n:60F:C031002PLN40000,00\n:62F:C031020PLN40000,00\n-}"
+
+	tags := []string{"20"}
+	relaxFields := mt.RelaxFields(tags...)
+
+	// changing the slice after creating the option must not affect parses using it
+	tags[0] = "25"
+
+	msgs, _ := mt.ParseAllMT940(ctx, strings.NewReader(input), relaxFields)
+	if len(msgs) != 1 {
+		t.Errorf("expected field 20 to remain relaxed, got %d messages", len(msgs))
+	}
+}
@@ -5,18 +5,61 @@
 
 package mt
 
+import (
+	"context"
+	"time"
+)
+
 type config struct {
-	SkipValidation bool
-	Lax            bool
-	StopOnError    bool
+	SkipValidation      bool
+	Lax                 bool
+	StopOnError         bool
+	Envelope            EnvelopeKind
+	DecimalSeparator    rune
+	Limit               int
+	FINCharSet          bool
+	DetectNonASCII      bool
+	PreserveWhitespace  bool
+	StatementPageNumber bool
+	DeprecationWarnings bool
+	StandardsRelease    int
+	MaxFieldsPerMessage int
+	ImpliedDecimals     int
+	Synchronous         bool
+	RelaxedFields       []string
+	ReportSkipped       bool
+	Strict              bool
+	SkippedContent      func(content string, line int)
+	LazyBody            bool
+	Logger              func(ctx context.Context, level, msg string)
+	Location            *time.Location
 }
 
 type option = func(cfg config) config
 
 var defaultConfig = config{
-	SkipValidation: false,
-	Lax:            false,
-	StopOnError:    false,
+	SkipValidation:      false,
+	Lax:                 false,
+	StopOnError:         false,
+	Envelope:            EnvelopePlain,
+	DecimalSeparator:    decimalSeparatorSpec,
+	Limit:               0,
+	FINCharSet:          false,
+	DetectNonASCII:      false,
+	PreserveWhitespace:  false,
+	StatementPageNumber: false,
+	DeprecationWarnings: false,
+	StandardsRelease:    0,
+	MaxFieldsPerMessage: 0,
+	ImpliedDecimals:     0,
+	Synchronous:         false,
+	RelaxedFields:       nil,
+	ReportSkipped:       false,
+	Strict:              false,
+	SkippedContent:      nil,
+	LazyBody:            false,
+	Logger:              nil,
+	Location:            nil,
 }
 
 // SkipValidation will skip message validation and return messages as-is. The difference with Lax is that with this
@@ -53,6 +96,278 @@ func StopOnError(stop bool) option {
 	}
 }
 
+// Envelope sets the kind of envelope the messages in the input are wrapped in. The envelope is stripped before
+// parsing, taking care to keep the line numbers reported in errors and on messages in line with the original input.
+//
+// Default: EnvelopePlain
+func Envelope(kind EnvelopeKind) option {
+	return func(cfg config) config {
+		cfg.Envelope = kind
+		return cfg
+	}
+}
+
+// DecimalSeparator sets the decimal separator used in the amounts of the input, for feeds that do not adhere to the
+// specification's comma, e.g. by using a period. The amounts of the balance (60a, 62a, 64, 65), statement line (61),
+// floor limit (34F), number and sum (90D, 90C), currency and amount (32A, 32B, 33B, 71F, 71G) and sum of amounts (19)
+// fields are normalized to the comma before decoding. This keeps the typed amounts, and validation by the pattern
+// engine's d character set, which only recognizes the comma, consistent with spec-compliant input.
+//
+// Default: ','
+func DecimalSeparator(r rune) option {
+	return func(cfg config) config {
+		cfg.DecimalSeparator = r
+		return cfg
+	}
+}
+
+// Limit will make the parsing process stop after the given number of messages has been read, leaving the rest of the
+// input unread. A limit of 0 or less means all messages are read.
+//
+// Default: 0
+func Limit(n int) option {
+	return func(cfg config) config {
+		cfg.Limit = n
+		return cfg
+	}
+}
+
+// FINCharSet will make the parsing process validate the values of all body fields against the FIN X character set,
+// letters, digits and / - ? : ( ) . , ' + space, as permitted by SWIFT. Characters outside of it, such as @ or #, often
+// indicate encoding corruption. They are reported together with their byte offset in the value of the field. Like
+// any other validation this is skipped when SkipValidation is passed.
+//
+// Default: false
+func FINCharSet(validate bool) option {
+	return func(cfg config) config {
+		cfg.FINCharSet = validate
+		return cfg
+	}
+}
+
+// DetectNonASCII will make the parsing process report any characters outside of the ASCII range in the values of body
+// fields, together with their byte offset in the value of the field. SWIFT messages are restricted to ASCII but feeds
+// sometimes contain UTF-8 encoded characters, e.g. accented letters in names. The messages are kept, the errors merely
+// signal non-ASCII content made it in, leaving it up to the caller to decide whether to reject them.
+//
+// Default: false
+func DetectNonASCII(detect bool) option {
+	return func(cfg config) config {
+		cfg.DetectNonASCII = detect
+		return cfg
+	}
+}
+
+// PreserveWhitespace will make the parsing process store the values of body fields verbatim, including any leading
+// and trailing whitespace, e.g. trailing spaces in fixed width narrative fields. Only the line break separating a
+// field from the next is removed. This makes the values true to the input, as the Raw message always is. Be aware
+// that patterns do not allow for surrounding whitespace, so the values might fail validation.
+//
+// Default: false
+func PreserveWhitespace(preserve bool) option {
+	return func(cfg config) config {
+		cfg.PreserveWhitespace = preserve
+		return cfg
+	}
+}
+
+// StatementPageNumber will make the parsing process accept a page number appended to the statement number and sequence
+// number in field 28C, e.g. 00084/001/02, as found in the exports of certain core banking systems. This is not part
+// of the specification, which only allows for 5!n(/3!n), hence strict parsing rejects such values. The typed parsers,
+// e.g. ParseMT940, split the page number off into the Page of the StatementNumber, leaving a value that validates as
+// usual. The body of the message keeps the field as it was received.
+//
+// Default: false
+func StatementPageNumber(allow bool) option {
+	return func(cfg config) config {
+		cfg.StatementPageNumber = allow
+		return cfg
+	}
+}
+
+// DeprecationWarnings will make the parsing process report the use of fields that are deprecated in the current
+// standards release, e.g. an ordering customer (50) without option letter. Fields that were only superseded in some
+// message types, e.g. the statement number (28) replaced by 28C in the MT940 but still current in the MT941, are only
+// reported for those types. These are reported as errors with warning severity, see Error.IsWarning and
+// Errors.Warnings, and never cause messages to be discarded.
+//
+// Default: false
+func DeprecationWarnings(warn bool) option {
+	return func(cfg config) config {
+		cfg.DeprecationWarnings = warn
+		return cfg
+	}
+}
+
+// StandardsRelease selects the yearly SWIFT standards release, e.g. 2021 for SR2021, messages are validated against.
+// Rules apply from the release they were introduced in until a later release changes them, so validating against a
+// release uses the rules in effect at that time. Messages of types whose rules have not been parameterized by release
+// are validated against the current rules. Releases predating the earliest one known for a message type cannot be
+// validated against and cause its messages to fail. A release of 0 selects the current rules.
+//
+// Default: 0
+func StandardsRelease(year int) option {
+	return func(cfg config) config {
+		cfg.StandardsRelease = year
+		return cfg
+	}
+}
+
+// MaxFieldsPerMessage will make the parsing process discard messages holding more than the given number of fields,
+// reporting an error for each. This protects against malformed or malicious inputs consisting of a single message with
+// an excessive number of fields, which would otherwise be held in memory in full. A value of zero or less means the
+// number of fields is unbounded.
+//
+// Default: 0
+func MaxFieldsPerMessage(n int) option {
+	return func(cfg config) config {
+		cfg.MaxFieldsPerMessage = n
+		return cfg
+	}
+}
+
+// ImpliedDecimals will make the parsing process interpret amounts without a decimal separator as having the given
+// number of implied decimal places, e.g. 12345 as 123,45 for 2 decimals, as sent by certain legacy feeds. This is not
+// part of the specification, which requires the separator, and only applies to the same amount fields as
+// DecimalSeparator. Amounts that do hold a separator are left as they are. A value of zero or less disables it.
+//
+// Default: 0
+func ImpliedDecimals(n int) option {
+	return func(cfg config) config {
+		cfg.ImpliedDecimals = n
+		return cfg
+	}
+}
+
+// Synchronous will make ParseMTx, and the functions built on it, read the input and turn the messages into MTx within a
+// single goroutine, rather than handing each message off from the reading goroutine to a converting one. As the
+// conversion is cheap this saves the overhead of the hand-off, which dominates when parsing many small inputs
+// concurrently, e.g. within a server. Messages and errors are published in the order they are encountered.
+//
+// Default: false
+func Synchronous(sync bool) option {
+	return func(cfg config) config {
+		cfg.Synchronous = sync
+		return cfg
+	}
+}
+
+// RelaxFields will make the typed parsers, e.g. ParseMT940, report pattern validation failures of the body fields with
+// the given tags as warnings rather than errors, see Error.IsWarning and Errors.Warnings. A message is therefore not
+// discarded because of, e.g., a field 20 exceeding 16x if "20" is passed. Other validation failures of these fields,
+// such as a missing mandatory field, remain errors. As opposed to Lax this only affects the given fields.
+//
+// Default: none
+func RelaxFields(tags ...string) option {
+	// copy the tags so changes the caller makes to its slice don't affect parses sharing the option
+	relaxed := make([]string, len(tags))
+	copy(relaxed, tags)
+
+	return func(cfg config) config {
+		cfg.RelaxedFields = relaxed
+		return cfg
+	}
+}
+
+// ReportSkipped will report any text outside of the blocks of the messages that is skipped, other than whitespace, as
+// a warning, see Error.IsWarning and Errors.Warnings. This makes it possible to detect inputs holding content that is
+// not an MT message, e.g. a file that is entirely made up of other text, which would otherwise simply result in no
+// messages and no errors.
+//
+// Default: false
+func ReportSkipped(report bool) option {
+	return func(cfg config) config {
+		cfg.ReportSkipped = report
+		return cfg
+	}
+}
+
+// Strict will make the parsing process enforce rules beyond the formats of the individual fields, that valid messages
+// are nonetheless expected to adhere to. The logical terminal addresses in the headers must consist of a BIC8, a
+// terminal code and a branch code. The service type identifier (111) in the user header must consist of 3 digits, the
+// unique end-to-end transaction reference (121) must be a version 4 UUID and the sanctions screening information (433)
+// must consist of a known code and optional additional information. The session and sequence numbers in the basic
+// header and in the message input and output references must consist of digits only. The obsolescence period in the app
+// header of input messages may not exceed the one defined for the priority, 003 for urgent messages and 020 for others.
+// With the typed parsers, e.g. ParseMT940, the rules of the message type are enforced as well. For MT940 messages the
+// closing balance may not be dated before the opening balance, and the value dates of the statement lines must fall
+// within those of the balances. This catches e.g. statements assembled out of order. For MT942 messages with two floor
+// limits the debit floor limit must precede the credit floor limit. Violations are treated like any other validation
+// failure, see Lax.
+//
+// Default: false
+func Strict(strict bool) option {
+	return func(cfg config) config {
+		cfg.Strict = strict
+		return cfg
+	}
+}
+
+// SkippedContent will make the parsing process call the given function with any text outside of the blocks of the
+// messages that is skipped, e.g. stray data between two messages or after the last one, together with the line it
+// starts on. The text is passed verbatim, text that is only whitespace is not passed. Whereas ReportSkipped merely
+// reports the number of bytes skipped this makes it possible to inspect the content itself. The function is called
+// from the goroutine reading the input, one call at a time and in the order the text is encountered. When the option is
+// shared by concurrent parses the function is called by each of them, it must then be safe for concurrent use.
+//
+// Default: none
+func SkippedContent(fn func(content string, line int)) option {
+	return func(cfg config) config {
+		cfg.SkippedContent = fn
+		return cfg
+	}
+}
+
+// LazyBody will make the parsing process leave the fields of the body unparsed, the body is retained verbatim in
+// RawBody instead. A field is parsed from it on first access through MTx.Field, only the field accessed, which saves
+// splitting the entire body into fields for messages of which only a few fields are needed. Body, FieldOrder and
+// PresentFields are populated once the body is loaded, see MTx.LoadBody, which the typed parsers and the methods of MTx
+// that need all fields, e.g. MarshalMT, do by themselves. Until then they are empty, use MTx.IsPresent and MTx.Fields
+// rather than PresentFields and Body to access the fields regardless.
+//
+// The options concerning the fields of the body apply to the fields as they are parsed, e.g. DecimalSeparator. The
+// body is not validated as it is parsed though, so FINCharSet, DetectNonASCII and DeprecationWarnings have no effect.
+// MaxFieldsPerMessage does apply, the fields of the verbatim body are counted without being parsed.
+//
+// Default: false
+func LazyBody(lazy bool) option {
+	return func(cfg config) config {
+		cfg.LazyBody = lazy
+		return cfg
+	}
+}
+
+// WithLogger will make the parsing process call the given function at key decisions it takes, e.g. when it skips
+// content outside of a message, reports an error and carries on, or discards a message. The function is passed the
+// context given to the parse function, so request-scoped values it carries, e.g. a logger or trace ID, can be used to
+// correlate the entries. The level is one of info, warn or error, the message describes the decision. The function is
+// called from the goroutines doing the parsing, it must be safe for concurrent use.
+//
+// Default: none
+func WithLogger(fn func(ctx context.Context, level, msg string)) option {
+	return func(cfg config) config {
+		cfg.Logger = fn
+		return cfg
+	}
+}
+
+// Location will make the parsing process construct the times of the dates and times without a zone, e.g. Date,
+// DateTime and Time in the headers and bodies of the messages, in the given location rather than in UTC. The date and
+// time of day are kept as given, e.g. 2103151130 becomes 11:30 on March 15th 2021 in the location. Use it when the
+// times are known to be local to a bank's zone, to avoid being off by hours when comparing them to other times. Times
+// with an explicit offset, e.g. DateTimeOffset and DateTimeIndication, keep their offset.
+//
+// Default: UTC
+func Location(loc *time.Location) option {
+	return func(cfg config) config {
+		cfg.Location = loc
+		return cfg
+	}
+}
+
+// optionsToConfig applies the options to a copy of the default configuration. The options only ever return a modified
+// copy of the configuration they are passed and never hold on to it, which makes them safe to share between concurrent
+// parses.
 func optionsToConfig(option []option) config {
 	cfg := defaultConfig
 
@@ -0,0 +1,161 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt
+
+import (
+	"strings"
+	"unicode"
+)
+
+// decimalSeparatorSpec is the decimal separator mandated by the specification.
+const decimalSeparatorSpec = ','
+
+const (
+	// balanceAmountOffset is the offset of the amount in balance fields, after the credit/debit mark, date and currency.
+	balanceAmountOffset = 10
+	// dateCurrencyAmountOffset is the offset of the amount in date, currency and amount fields, e.g. 32A.
+	dateCurrencyAmountOffset = 9
+	// currencyAmountOffset is the offset of the amount in currency and amount fields, e.g. 32B.
+	currencyAmountOffset = 3
+)
+
+func isNotDigit(r rune) bool {
+	return !unicode.IsDigit(r)
+}
+
+// amountStart returns the offset of the amount within the value of a field with the given tag. If the field does not
+// hold an amount false is returned.
+func amountStart(tag, value string) (int, bool) {
+	switch tag {
+	case "60F", "60M", "62F", "62M", "64", "65":
+		return balanceAmountOffset, len(value) > balanceAmountOffset
+	case "32A":
+		return dateCurrencyAmountOffset, len(value) > dateCurrencyAmountOffset
+	case "32B", "33B", "71F", "71G":
+		return currencyAmountOffset, len(value) > currencyAmountOffset
+	case "19":
+		return 0, len(value) > 0
+	case "90D", "90C":
+		// number of entries, followed by the currency
+		start := strings.IndexFunc(value, isNotDigit)
+		if start < 0 {
+			return 0, false
+		}
+		start += currencyAmountOffset
+		return start, len(value) > start
+	case "34F":
+		// currency, followed by an optional indicator
+		start := 3
+		if len(value) > start && (value[start] == 'C' || value[start] == 'D') {
+			start++
+		}
+		return start, len(value) > start
+	case "61":
+		// value date, optional entry date, funds code and the optional third character of the currency code, i.e.
+		// 6!n[4!n]2a[1!a]
+		start := 6
+		if len(value) <= start {
+			return 0, false
+		}
+		if len(value) >= start+4 && strings.IndexFunc(value[start:start+4], isNotDigit) < 0 {
+			start += 4
+		}
+		if strings.HasPrefix(value[start:], "RC") || strings.HasPrefix(value[start:], "RD") {
+			start++
+		}
+		start++
+		if len(value) > start && unicode.IsUpper(rune(value[start])) {
+			start++
+		}
+		return start, len(value) > start
+	default:
+		return 0, false
+	}
+}
+
+// normalizeDecimalSeparator replaces the given decimal separator by the one mandated by the specification in the
+// amounts of all known amount holding fields. Other fields, and any other occurrences of the separator, are left alone.
+func normalizeDecimalSeparator(body map[string][]string, separator rune) map[string][]string {
+	if separator == decimalSeparatorSpec {
+		return body
+	}
+
+	normalized := make(map[string][]string, len(body))
+
+	for tag, values := range body {
+		normalizedValues := make([]string, len(values))
+
+		for i, value := range values {
+			normalizedValues[i] = value
+
+			start, ok := amountStart(tag, value)
+			if !ok {
+				continue
+			}
+
+			end := len(value)
+			if idx := strings.IndexFunc(value[start:], func(r rune) bool {
+				return isNotDigit(r) && r != separator
+			}); idx >= 0 {
+				end = start + idx
+			}
+
+			amount := strings.Replace(value[start:end], string(separator), string(decimalSeparatorSpec), 1)
+			normalizedValues[i] = value[:start] + amount + value[end:]
+		}
+
+		normalized[tag] = normalizedValues
+	}
+
+	return normalized
+}
+
+// applyImpliedDecimals inserts the decimal separator mandated by the specification in the amounts of all known amount
+// holding fields that lack one, treating the last given number of digits as decimals. Amounts with fewer digits than
+// that are padded with leading zeros. Amounts that do hold a separator, and all other fields, are left alone.
+func applyImpliedDecimals(body map[string][]string, decimals int) map[string][]string {
+	if decimals <= 0 {
+		return body
+	}
+
+	implied := make(map[string][]string, len(body))
+
+	for tag, values := range body {
+		impliedValues := make([]string, len(values))
+
+		for i, value := range values {
+			impliedValues[i] = value
+
+			start, ok := amountStart(tag, value)
+			if !ok {
+				continue
+			}
+
+			end := len(value)
+			if idx := strings.IndexFunc(value[start:], func(r rune) bool {
+				return isNotDigit(r) && r != decimalSeparatorSpec
+			}); idx >= 0 {
+				end = start + idx
+			}
+
+			amount := value[start:end]
+			if amount == "" || strings.ContainsRune(amount, decimalSeparatorSpec) {
+				continue
+			}
+
+			if len(amount) <= decimals {
+				amount = strings.Repeat("0", decimals-len(amount)+1) + amount
+			}
+
+			amount = amount[:len(amount)-decimals] + string(decimalSeparatorSpec) + amount[len(amount)-decimals:]
+			impliedValues[i] = value[:start] + amount + value[end:]
+		}
+
+		implied[tag] = impliedValues
+	}
+
+	return implied
+}
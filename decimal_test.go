@@ -0,0 +1,359 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DennisVis/mt"
+	mttest "github.com/DennisVis/mt/testdata"
+)
+
+const periodSeparatedMT940 = `{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:
+:20:REFERENCE
+:25:BPHKPLPK/320000546101
+:28C:00084/001
+:60F:C031002PLN40000.00
+:61:0310201020C20000.50FMSCREF.1//8327000090031789
+Card transaction
+:61:031020D100.FTRFNONREF
+:62F:C031020PLN59900.50
+:64:C031020PLN59900.50
+-}`
+
+func TestDecimalSeparator(t *testing.T) {
+	for _, test := range []struct {
+		name             string
+		separator        rune
+		expectedErr      error
+		expectedAmounts  []mt.Amount
+		expectedOwnerRef string
+	}{
+		{
+			name:        "Default",
+			separator:   ',',
+			expectedErr: fmt.Errorf("balance: invalid amount"),
+		},
+		{
+			name:      "Period",
+			separator: '.',
+			expectedAmounts: []mt.Amount{
+				mttest.MustParseAmount("40000,00"),
+				mttest.MustParseAmount("20000,50"),
+				mttest.MustParseAmount("100,"),
+				mttest.MustParseAmount("59900,50"),
+				mttest.MustParseAmount("59900,50"),
+			},
+			expectedOwnerRef: "REF.1",
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			msgs, err := mt.ParseAllMT940(ctx, strings.NewReader(periodSeparatedMT940), mt.DecimalSeparator(test.separator))
+			mttest.ValidateError(t, test.expectedErr, err)
+
+			if test.expectedAmounts == nil {
+				return
+			}
+
+			if len(msgs) != 1 {
+				t.Fatalf("expected 1 message, got %d", len(msgs))
+			}
+
+			msg := msgs[0]
+			amounts := []mt.Amount{
+				msg.OpeningBalance.Amount,
+				msg.StatementLines[0].Amount,
+				msg.StatementLines[1].Amount,
+				msg.ClosingBalance.Amount,
+				msg.ClosingAvailableBalance.Amount,
+			}
+
+			for i, expected := range test.expectedAmounts {
+				if amounts[i].Value != expected.Value || amounts[i].Decimals != expected.Decimals {
+					t.Errorf("Amount[%d] expected %s, got %s", i, expected, amounts[i])
+				}
+			}
+
+			if msg.StatementLines[0].AccountOwnerReference != test.expectedOwnerRef {
+				t.Errorf(
+					"AccountOwnerReference expected %q, got %q",
+					test.expectedOwnerRef,
+					msg.StatementLines[0].AccountOwnerReference,
+				)
+			}
+		})
+	}
+}
+
+const impliedDecimalsMT940 = `{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:
+:20:REFERENCE
+:25:BPHKPLPK/320000546101
+:28C:00084/001
+:60F:C031002PLN4000000
+:61:0310201020C2000050FMSCNONREF//8327000090031789
+:61:031020D5FTRFNONREF
+:62F:C031020PLN5999945
+:64:C031020PLN59999,45
+-}`
+
+func TestImpliedDecimals(t *testing.T) {
+	for _, test := range []struct {
+		name            string
+		input           string
+		decimals        int
+		expectedErr     error
+		expectedAmounts []mt.Amount
+	}{
+		{
+			name:        "Default",
+			input:       impliedDecimalsMT940,
+			expectedErr: fmt.Errorf("balance: invalid amount"),
+		},
+		{
+			name:     "Implied",
+			input:    impliedDecimalsMT940,
+			decimals: 2,
+			expectedAmounts: []mt.Amount{
+				mttest.MustParseAmount("40000,00"),
+				mttest.MustParseAmount("20000,50"),
+				mttest.MustParseAmount("0,05"),
+				mttest.MustParseAmount("59999,45"),
+				mttest.MustParseAmount("59999,45"),
+			},
+		},
+		{
+			name:     "Standard",
+			input:    strings.ReplaceAll(periodSeparatedMT940, ".", ","),
+			decimals: 2,
+			expectedAmounts: []mt.Amount{
+				mttest.MustParseAmount("40000,00"),
+				mttest.MustParseAmount("20000,50"),
+				mttest.MustParseAmount("100,"),
+				mttest.MustParseAmount("59900,50"),
+				mttest.MustParseAmount("59900,50"),
+			},
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			msgs, err := mt.ParseAllMT940(ctx, strings.NewReader(test.input), mt.ImpliedDecimals(test.decimals))
+			mttest.ValidateError(t, test.expectedErr, err)
+
+			if test.expectedAmounts == nil {
+				return
+			}
+
+			if len(msgs) != 1 {
+				t.Fatalf("expected 1 message, got %d", len(msgs))
+			}
+
+			msg := msgs[0]
+			amounts := []mt.Amount{
+				msg.OpeningBalance.Amount,
+				msg.StatementLines[0].Amount,
+				msg.StatementLines[1].Amount,
+				msg.ClosingBalance.Amount,
+				msg.ClosingAvailableBalance.Amount,
+			}
+
+			for i, expected := range test.expectedAmounts {
+				if amounts[i].Value != expected.Value || amounts[i].Decimals != expected.Decimals {
+					t.Errorf("Amount[%d] expected %s, got %s", i, expected, amounts[i])
+				}
+			}
+		})
+	}
+}
+
+func TestStatementLineAmount(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		line      string
+		separator rune
+		decimals  int
+		expected  string
+	}{
+		{
+			name:      "Separator",
+			line:      "2101010101D1000.00NTRFNONREF",
+			separator: '.',
+			expected:  "2101010101D1000,00NTRFNONREF",
+		},
+		{
+			name:      "SeparatorFundsCode",
+			line:      "2101010101DR1000.00NTRFNONREF",
+			separator: '.',
+			expected:  "2101010101DR1000,00NTRFNONREF",
+		},
+		{
+			name:      "SeparatorReversalFundsCode",
+			line:      "210101RCR1000.00NTRFNONREF",
+			separator: '.',
+			expected:  "210101RCR1000,00NTRFNONREF",
+		},
+		{
+			name:      "ImpliedDecimals",
+			line:      "2101010101D100000NTRFNONREF",
+			separator: ',',
+			decimals:  2,
+			expected:  "2101010101D1000,00NTRFNONREF",
+		},
+		{
+			name:      "ImpliedDecimalsFundsCode",
+			line:      "2101010101DR100000NTRFNONREF",
+			separator: ',',
+			decimals:  2,
+			expected:  "2101010101DR1000,00NTRFNONREF",
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			input := "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:\n:61:" + test.line + "\n-}"
+
+			msgs, err := mt.ParseAllMTx(
+				ctx,
+				strings.NewReader(input),
+				mt.DecimalSeparator(test.separator),
+				mt.ImpliedDecimals(test.decimals),
+			)
+			mttest.ValidateError(t, nil, err)
+
+			if len(msgs) != 1 {
+				t.Fatalf("expected 1 message, got %d", len(msgs))
+			}
+
+			if line := msgs[0].Body["61"][0]; line != test.expected {
+				t.Errorf("expected statement line %q, got %q", test.expected, line)
+			}
+		})
+	}
+}
+
+func TestDecimalSeparatorMessageTypes(t *testing.T) {
+	for _, test := range []struct {
+		name            string
+		parse           func(input string) ([]mt.Amount, error)
+		input           string
+		expectedAmounts []mt.Amount
+	}{
+		{
+			name: "MT900",
+			parse: func(input string) ([]mt.Amount, error) {
+				msgs, err := mt.ParseAllMT900(ctx, strings.NewReader(input), mt.DecimalSeparator('.'))
+				if len(msgs) != 1 {
+					return nil, err
+				}
+
+				return []mt.Amount{msgs[0].ValueDateCurrencyAmount.Amount}, err
+			},
+			input: `{1:F01AAAABEBBAXXX0000000000}{2:I900CHASUS33AXXXN}{4:
+:20:C11126A1378
+:21:5482ABC
+:25:9-9876543
+:32A:210315USD233530.25
+-}`,
+			expectedAmounts: []mt.Amount{mttest.MustParseAmount("233530,25")},
+		},
+		{
+			name: "MT941",
+			parse: func(input string) ([]mt.Amount, error) {
+				msgs, err := mt.ParseAllMT941(ctx, strings.NewReader(input), mt.DecimalSeparator('.'))
+				if len(msgs) != 1 {
+					return nil, err
+				}
+
+				return []mt.Amount{msgs[0].DebitEntries.Amount, msgs[0].CreditEntries.Amount}, err
+			},
+			input: fmt.Sprintf(strings.ReplaceAll(mt941Input, ",", "."), ":90D:3PLN100.00\n:90C:12PLN20000.50"),
+			expectedAmounts: []mt.Amount{
+				mttest.MustParseAmount("100,00"),
+				mttest.MustParseAmount("20000,50"),
+			},
+		},
+		{
+			name: "MT104",
+			parse: func(input string) ([]mt.Amount, error) {
+				msgs, err := mt.ParseAllMT104(ctx, strings.NewReader(input), mt.DecimalSeparator('.'))
+				if len(msgs) != 1 {
+					return nil, err
+				}
+
+				msg := msgs[0]
+
+				return []mt.Amount{
+					msg.Transactions[0].CurrencyAmount.Amount,
+					msg.Transactions[0].OriginalOrderedAmount.Amount,
+					msg.Transactions[0].ReceiversCharges.Amount,
+					msg.Transactions[1].CurrencyAmount.Amount,
+					msg.Transactions[1].SendersCharges.Amount,
+					msg.Settlement.SettlementAmount.Amount,
+					msg.Settlement.SumOfAmounts,
+					msg.Settlement.SumOfSendersCharges.Amount,
+				}, err
+			},
+			input: mt104Header + `:21:TRX1
+:32B:EUR1000.00
+:59:/BE62510007547061
+DEBTOR ONE
+:33B:EUR1000.00
+:71G:EUR2.50
+:21:TRX2
+:32B:EUR500.50
+:59:/BE68539007547034
+DEBTOR TWO
+:71F:EUR10.
+:32B:EUR1510.50
+:19:1500.50
+:71F:EUR10.
+-}`,
+			expectedAmounts: []mt.Amount{
+				mttest.MustParseAmount("1000,00"),
+				mttest.MustParseAmount("1000,00"),
+				mttest.MustParseAmount("2,50"),
+				mttest.MustParseAmount("500,50"),
+				mttest.MustParseAmount("10,"),
+				mttest.MustParseAmount("1510,50"),
+				mttest.MustParseAmount("1500,50"),
+				mttest.MustParseAmount("10,"),
+			},
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			amounts, err := test.parse(test.input)
+			mttest.ValidateError(t, nil, err)
+
+			if len(amounts) != len(test.expectedAmounts) {
+				t.Fatalf("expected %d amounts, got %d", len(test.expectedAmounts), len(amounts))
+			}
+
+			for i, expected := range test.expectedAmounts {
+				if amounts[i].Value != expected.Value || amounts[i].Decimals != expected.Decimals {
+					t.Errorf("Amount[%d] expected %s, got %s", i, expected, amounts[i])
+				}
+			}
+		})
+	}
+}
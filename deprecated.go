@@ -0,0 +1,56 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt
+
+import (
+	"fmt"
+)
+
+// deprecatedField is a field, or field option, that is no longer part of the current standards release. Types holds
+// the message types it was superseded in, it is deprecated in all message types if empty.
+type deprecatedField struct {
+	replacement string
+	types       []string
+}
+
+// deprecatedFields maps the tags of deprecated fields to the fields superseding them.
+var deprecatedFields = map[string]deprecatedField{
+	// statement number without sequence number, field 28 remains current in e.g. MT941
+	"28": {replacement: "28C", types: []string{"940", "942", "950", "970", "972"}},
+	// ordering customer without option letter
+	"50": {replacement: "50A, 50F or 50K"},
+}
+
+// detectDeprecatedFields reports the deprecated fields in the body of a message of the given type, in the order they
+// were found in, naming the fields to use instead. Every occurrence of a deprecated field is reported.
+func detectDeprecatedFields(msgType string, order []string) []error {
+	errs := make([]error, 0)
+
+	for _, tag := range order {
+		deprecated, ok := deprecatedFields[tag]
+		if !ok || !deprecated.appliesTo(msgType) {
+			continue
+		}
+
+		errs = append(errs, fmt.Errorf("field %s is deprecated, use %s instead", tag, deprecated.replacement))
+	}
+
+	return errs
+}
+
+func (df deprecatedField) appliesTo(msgType string) bool {
+	if len(df.types) == 0 {
+		return true
+	}
+
+	for _, typ := range df.types {
+		if typ == msgType {
+			return true
+		}
+	}
+
+	return false
+}
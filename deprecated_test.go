@@ -0,0 +1,87 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DennisVis/mt"
+	mttest "github.com/DennisVis/mt/testdata"
+)
+
+const deprecatedFieldsMTx = `{1:F01BPHKPLPKXXXX0000000000}{2:I910BOFAUS6BXBAMN}{4:
+:20:REFERENCE
+:21:RELATED
+:25:BPHKPLPK/320000546101
+:32A:031002PLN40000,00
+:50:ORDERING CUSTOMER
+-}`
+
+func TestDeprecationWarnings(t *testing.T) {
+	for _, test := range []struct {
+		name         string
+		input        string
+		warn         bool
+		expectedErrs mt.Errors
+	}{
+		{
+			name:  "NotWarned",
+			input: deprecatedFieldsMTx,
+		},
+		{
+			name:  "Deprecated",
+			input: deprecatedFieldsMTx,
+			warn:  true,
+			expectedErrs: mt.Errors{
+				mt.NewWarning(fmt.Errorf("field 50 is deprecated, use 50A, 50F or 50K instead"), 1),
+			},
+		},
+		{
+			name:  "Current",
+			input: strings.Replace(deprecatedFieldsMTx, ":50:", ":50K:", 1),
+			warn:  true,
+		},
+		{
+			// field 28 was only superseded by 28C in some message types, it is mandatory in the MT941
+			name:  "CurrentInMessageType",
+			input: fmt.Sprintf(mt941Input, ":90D:3PLN100,00"),
+			warn:  true,
+		},
+		{
+			name:  "DeprecatedInMessageType",
+			input: strings.Replace(fmt.Sprintf(mt941Input, ":90D:3PLN100,00"), "{2:I941", "{2:I940", 1),
+			warn:  true,
+			expectedErrs: mt.Errors{
+				mt.NewWarning(fmt.Errorf("field 28 is deprecated, use 28C instead"), 1),
+			},
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			msgs, err := mt.ParseAllMTx(ctx, strings.NewReader(test.input), mt.DeprecationWarnings(test.warn))
+			mttest.ValidateErrors(t, test.expectedErrs, err)
+
+			// warnings never cause messages to be discarded
+			if len(msgs) != 1 {
+				t.Fatalf("expected 1 message, got %d", len(msgs))
+			}
+
+			es := mt.ErrorToErrors(err)
+			if len(es.Warnings()) != len(test.expectedErrs) {
+				t.Errorf("expected %d warnings, got %d", len(test.expectedErrs), len(es.Warnings()))
+			}
+			if len(es.WithoutWarnings()) != 0 {
+				t.Errorf("expected no errors other than warnings, got %s", es.WithoutWarnings())
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/DennisVis/mt/internal/validate"
+)
+
+// FieldSpec describes a field of a message type defined by a FieldDictionary. The Pattern is the format of the field
+// in the notation of the specification, e.g. 16x or 3!a15d, the same notation the typed messages use in their mt struct
+// tags. A Mandatory field must be present and only a Repeatable field may occur more than once.
+type FieldSpec struct {
+	Pattern    string
+	Mandatory  bool
+	Repeatable bool
+}
+
+// FieldDictionary defines the body of a message type through data rather than a Go struct, it maps the tags of the
+// fields to their specs. Fields absent from the dictionary are not allowed in the body.
+//
+// Example:
+//
+//	dict := FieldDictionary{
+//		"20":  {Pattern: "16x", Mandatory: true},
+//		"32A": {Pattern: "6!n3!a15d", Mandatory: true},
+//		"72":  {Pattern: "6*35x"},
+//	}
+type FieldDictionary map[string]FieldSpec
+
+func (dict FieldDictionary) validator() (validate.Validator, error) {
+	specs := make(map[string]validate.FieldSpec, len(dict))
+	for tag, spec := range dict {
+		specs[tag] = validate.FieldSpec{
+			Pattern:    spec.Pattern,
+			Mandatory:  spec.Mandatory,
+			Repeatable: spec.Repeatable,
+		}
+	}
+
+	validator, err := validate.CreateValidatorForFields(specs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid field dictionary: %w", err)
+	}
+
+	return validator, nil
+}
+
+// validateDictionary validates the body of the message against the dictionary validator. The pattern validation
+// failures of the fields with the relaxed tags are returned separately, to be reported as warnings.
+func validateDictionary(mtx MTx, validator validate.Validator, relaxedTags []string) ([]error, error) {
+	err, relaxed := validate.Relax(validator.Validate(mtx.loaded().Body), relaxedTags)
+
+	warnings := make([]error, len(relaxed))
+	for i, r := range relaxed {
+		warnings[i] = fmt.Errorf("relaxed validation failure against the field dictionary:\n%w", r)
+	}
+
+	if err != nil {
+		return warnings, fmt.Errorf("validation failed against the field dictionary:\n%w", err)
+	}
+
+	return warnings, nil
+}
+
+// ParseWithDictionary parses all MT messages in the input, like ParseAllMTx, and validates their bodies against the
+// given dictionary. This makes it possible to handle message types this package has no typed message for, with their
+// fields defined at runtime. The messages are returned as generic MTx.
+//
+// Invalid messages are discarded unless the option Lax is passed, the options SkipValidation and RelaxFields apply as
+// they do for the typed messages. An error is returned straight away, without parsing, if the dictionary holds an
+// invalid pattern.
+func ParseWithDictionary(ctx context.Context, rd io.Reader, dict FieldDictionary, options ...option) ([]MTx, error) {
+	cfg := optionsToConfig(options)
+
+	validator, err := dict.validator()
+	if err != nil {
+		return nil, err
+	}
+
+	genericMessages, pes := ParseAllMTx(ctx, rd, options...)
+
+	mtxs := make([]MTx, 0, len(genericMessages))
+
+	parseErrors := errorToErrors(pes)
+
+	for _, mtx := range genericMessages {
+		if cfg.SkipValidation {
+			mtxs = append(mtxs, mtx)
+			continue
+		}
+
+		warnings, err := validateDictionary(mtx, validator, cfg.RelaxedFields)
+		for _, warning := range warnings {
+			parseErrors = append(parseErrors, NewWarning(warning, mtx.Line))
+		}
+		if err != nil {
+			parseErrors = append(parseErrors, NewError(err, mtx.Line))
+
+			if !cfg.Lax {
+				continue
+			}
+		}
+
+		mtxs = append(mtxs, mtx)
+	}
+
+	if len(parseErrors) > 0 {
+		return mtxs, parseErrors
+	}
+
+	return mtxs, nil
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DennisVis/mt"
+	mttest "github.com/DennisVis/mt/testdata"
+)
+
+func TestParseWithDictionary(t *testing.T) {
+	dict := mt.FieldDictionary{
+		"20":  {Pattern: "16x", Mandatory: true},
+		"32A": {Pattern: "6!n3!a15d", Mandatory: true},
+		"72":  {Pattern: "6*35x", Repeatable: true},
+	}
+
+	message := func(body string) string {
+		return "{1:F01BPHKPLPKXXXX0000000000}{2:I999BOFAUS6BXBAMN}{4:\n" + body + "\n-}"
+	}
+
+	for _, test := range []struct {
+		name          string
+		input         string
+		dict          mt.FieldDictionary
+		lax           bool
+		relaxed       []string
+		expectedErrs  mt.Errors
+		expectedErr   error
+		expectedCount int
+	}{
+		{
+			name:          "Valid",
+			input:         message(":20:REFERENCE\n:32A:210315EUR100,00\n:72:/INS/ONE\n:72:/INS/TWO"),
+			expectedCount: 1,
+		},
+		{
+			name:  "MissingMandatory",
+			input: message(":20:REFERENCE"),
+			expectedErrs: mt.Errors{
+				mt.NewError(fmt.Errorf("validation failed against the field dictionary:\n\t|32A|: missing mandatory field 32A"), 1),
+			},
+		},
+		{
+			name:  "InvalidPattern",
+			input: message(":20:REFERENCE\n:32A:210315EUR100"),
+			expectedErrs: mt.Errors{
+				mt.NewError(fmt.Errorf("validation failed against the field dictionary:\n\t|32A|: pattern validation failed"), 1),
+			},
+		},
+		{
+			name:  "InvalidPatternLax",
+			input: message(":20:REFERENCE\n:32A:210315EUR100"),
+			lax:   true,
+			expectedErrs: mt.Errors{
+				mt.NewError(fmt.Errorf("validation failed against the field dictionary:\n\t|32A|: pattern validation failed"), 1),
+			},
+			expectedCount: 1,
+		},
+		{
+			name:    "InvalidPatternRelaxed",
+			input:   message(":20:REFERENCE\n:32A:210315EUR100"),
+			relaxed: []string{"32A"},
+			expectedErrs: mt.Errors{
+				mt.NewWarning(fmt.Errorf("relaxed validation failure against the field dictionary:\n\t|32A|: pattern"), 1),
+			},
+			expectedCount: 1,
+		},
+		{
+			name:  "UnknownField",
+			input: message(":20:REFERENCE\n:32A:210315EUR100,00\n:21:RELATED"),
+			expectedErrs: mt.Errors{
+				mt.NewError(fmt.Errorf("validation failed against the field dictionary:\n\t|21|: field 21 is not in"), 1),
+			},
+		},
+		{
+			name:        "InvalidDictionary",
+			input:       message(":20:REFERENCE"),
+			dict:        mt.FieldDictionary{"20": {Pattern: "1**"}},
+			expectedErr: fmt.Errorf(`invalid field dictionary: field spec for tag 20 contained invalid pattern "1**"`),
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			dict := dict
+			if test.dict != nil {
+				dict = test.dict
+			}
+
+			msgs, err := mt.ParseWithDictionary(
+				ctx,
+				strings.NewReader(test.input),
+				dict,
+				mt.Lax(test.lax),
+				mt.RelaxFields(test.relaxed...),
+			)
+			if test.expectedErr != nil {
+				mttest.ValidateError(t, test.expectedErr, err)
+			} else {
+				mttest.ValidateErrors(t, test.expectedErrs, err)
+			}
+
+			if len(msgs) != test.expectedCount {
+				t.Errorf("expected %d messages, got %d", test.expectedCount, len(msgs))
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// EnvelopeKind identifies the kind of envelope the messages in an input are wrapped in.
+type EnvelopeKind int
+
+const (
+	// EnvelopePlain means the messages are not wrapped in an envelope. Any text before the first block of a message is
+	// skipped.
+	EnvelopePlain EnvelopeKind = iota
+	// EnvelopeRJE means the messages are wrapped in the RJE (Remote Job Entry) format, where messages are separated by
+	// lines only containing a $ and may be preceded by proprietary header lines.
+	EnvelopeRJE
+)
+
+const (
+	rjeMessageSeparator = "$"
+	rjeMessageStart     = "{1:"
+)
+
+// rjeReader strips an RJE envelope from the underlying reader. Everything between a message separator and the start of
+// the next basic header block is dropped, except for line breaks, so line numbers in the stripped output still match
+// those of the original input.
+type rjeReader struct {
+	rd        *bufio.Reader
+	buff      string
+	err       error
+	inMessage bool
+}
+
+func newRJEReader(rd io.Reader) *rjeReader {
+	return &rjeReader{
+		rd: bufio.NewReader(rd),
+	}
+}
+
+func (r *rjeReader) processLine(line string) string {
+	lineBreak := ""
+	if strings.HasSuffix(line, "\n") {
+		lineBreak = "\n"
+	}
+
+	if strings.TrimSpace(line) == rjeMessageSeparator {
+		r.inMessage = false
+		return lineBreak
+	}
+
+	if r.inMessage {
+		return line
+	}
+
+	start := strings.Index(line, rjeMessageStart)
+	if start < 0 {
+		return lineBreak
+	}
+
+	r.inMessage = true
+
+	return line[start:]
+}
+
+// Read implements the io.Reader interface.
+func (r *rjeReader) Read(p []byte) (int, error) {
+	for r.buff == "" {
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		line, err := r.rd.ReadString('\n')
+		r.err = err
+		r.buff = r.processLine(line)
+	}
+
+	n := copy(p, r.buff)
+	r.buff = r.buff[n:]
+
+	return n, nil
+}
+
+func envelopeReader(rd io.Reader, kind EnvelopeKind) io.Reader {
+	switch kind {
+	case EnvelopeRJE:
+		return newRJEReader(rd)
+	// EnvelopePlain
+	default:
+		return rd
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DennisVis/mt"
+	mttest "github.com/DennisVis/mt/testdata"
+)
+
+const envelopeMessage = "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:\n:20:REFERENCE\n-}"
+
+func TestEnvelope(t *testing.T) {
+	for _, test := range []struct {
+		name          string
+		input         string
+		envelope      mt.EnvelopeKind
+		expectedLines []int
+		expectedErr   error
+	}{
+		{
+			name:          "PlainLeadingText",
+			input:         "some header line\nanother header line\n" + envelopeMessage,
+			envelope:      mt.EnvelopePlain,
+			expectedLines: []int{3},
+		},
+		{
+			name:          "PlainMultipleMessages",
+			input:         "header\n" + envelopeMessage + "\n" + envelopeMessage,
+			envelope:      mt.EnvelopePlain,
+			expectedLines: []int{2, 5},
+		},
+		{
+			name:          "RJESingleMessage",
+			input:         envelopeMessage,
+			envelope:      mt.EnvelopeRJE,
+			expectedLines: []int{1},
+		},
+		{
+			name:          "RJEMultipleMessages",
+			input:         envelopeMessage + "\n$\n" + envelopeMessage + "\n$\n" + envelopeMessage,
+			envelope:      mt.EnvelopeRJE,
+			expectedLines: []int{1, 5, 9},
+		},
+		{
+			name:          "RJEHeaderWithBraces",
+			input:         "HDR{garbage}{more:garbage}\n" + envelopeMessage + "\n$\nHDR{garbage}\n" + envelopeMessage,
+			envelope:      mt.EnvelopeRJE,
+			expectedLines: []int{2, 7},
+		},
+		{
+			name:          "RJEHeaderOnSameLine",
+			input:         "HDR{garbage}" + envelopeMessage + "\n$\r\nHDR{garbage}" + envelopeMessage,
+			envelope:      mt.EnvelopeRJE,
+			expectedLines: []int{1, 5},
+		},
+		{
+			name:          "RJEReadError",
+			envelope:      mt.EnvelopeRJE,
+			expectedErr:   mttest.ErrReadInvalid,
+			expectedLines: []int{},
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var msgs []mt.MTx
+			var err error
+			if test.expectedErr != nil {
+				msgs, err = mt.ParseAllMTx(ctx, &mttest.TestReaderInvalid{}, mt.Envelope(test.envelope))
+			} else {
+				msgs, err = mt.ParseAllMTx(ctx, strings.NewReader(test.input), mt.Envelope(test.envelope))
+			}
+			mttest.ValidateError(t, test.expectedErr, err)
+
+			if len(msgs) != len(test.expectedLines) {
+				t.Fatalf("expected %d messages, got %d", len(test.expectedLines), len(msgs))
+			}
+
+			for i, line := range test.expectedLines {
+				t.Run(fmt.Sprintf("MTx[%d]", i), func(t *testing.T) {
+					if msgs[i].Line != line {
+						t.Errorf("expected line %d, got %d", line, msgs[i].Line)
+					}
+					if msgs[i].Body["20"][0] != "REFERENCE" {
+						t.Errorf("expected reference REFERENCE, got %s", msgs[i].Body["20"][0])
+					}
+				})
+			}
+		})
+	}
+}
@@ -5,7 +5,24 @@
 
 package mt
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/DennisVis/mt/internal/validate"
+)
+
+// Severity indicates how serious a problem encountered during parsing is.
+type Severity int
+
+const (
+	// SeverityError is used for problems that make a message invalid.
+	SeverityError Severity = iota
+	// SeverityWarning is used for problems that are merely reported, e.g. the use of deprecated fields. Messages are
+	// never discarded because of warnings.
+	SeverityWarning
+)
 
 // Error is used when parsing of an input encounters a problem.
 //
@@ -13,8 +30,13 @@ import "fmt"
 //
 // Any and all parse errors per input should be aggregated by this library and returned to the caller.
 type Error struct {
-	line  int
-	cause error
+	line     int
+	cause    error
+	severity Severity
+	raw      string
+	// messageIndex is the position of the message the error belongs to within the input, if indexed is set
+	messageIndex int
+	indexed      bool
 }
 
 // NewError creates a new parse error.
@@ -22,6 +44,22 @@ func NewError(cause error, line int) Error {
 	return Error{line: line, cause: cause}
 }
 
+// NewWarning creates a new parse error with warning severity.
+func NewWarning(cause error, line int) Error {
+	return Error{line: line, cause: cause, severity: SeverityWarning}
+}
+
+// newBlockError creates a new parse error for a problem in a block of a message, carrying the raw content of the
+// block. If the cause stems from a sub block the raw content of just that sub block is carried instead.
+func newBlockError(cause error, line int, raw string) Error {
+	var re rawError
+	if errors.As(cause, &re) {
+		raw = re.raw
+	}
+
+	return Error{line: line, cause: cause, raw: raw}
+}
+
 // Cause returns the underlying error.
 func (e Error) Cause() error {
 	return e.cause
@@ -32,8 +70,43 @@ func (e Error) Line() int {
 	return e.line
 }
 
+// Raw returns the raw input that triggered the error, e.g. the content of the offending block including its braces.
+// It is empty when the offending input is not known.
+func (e Error) Raw() string {
+	return e.raw
+}
+
+// MessageIndex returns the position of the message the error belongs to among the messages parsed from the input,
+// starting at 0. Ok is false if the error is not tied to a message by index, e.g. when it arose before the message
+// could be delimited.
+func (e Error) MessageIndex() (index int, ok bool) {
+	return e.messageIndex, e.indexed
+}
+
+// forMessage ties the error to the message at the given position among the messages parsed from the input.
+func (e Error) forMessage(index int) Error {
+	e.messageIndex = index
+	e.indexed = true
+
+	return e
+}
+
+// Severity returns the severity of the error.
+func (e Error) Severity() Severity {
+	return e.severity
+}
+
+// IsWarning returns whether the error is merely a warning.
+func (e Error) IsWarning() bool {
+	return e.severity == SeverityWarning
+}
+
 // String returns the string representation of the parse error.
 func (e Error) String() string {
+	if e.IsWarning() {
+		return fmt.Sprintf("#%d: warning: %s", e.line, e.Cause())
+	}
+
 	return fmt.Sprintf("#%d: %s", e.line, e.Cause())
 }
 
@@ -42,6 +115,105 @@ func (e Error) Error() string {
 	return e.String()
 }
 
+// Unwrap returns the underlying error, allowing errors.Is and errors.As to look through a parse error.
+func (e Error) Unwrap() error {
+	return e.cause
+}
+
+// rawError annotates an error with the raw input that triggered it, to be picked up by newBlockError.
+type rawError struct {
+	err error
+	raw string
+}
+
+// withRaw annotates the error with the raw input that triggered it.
+func withRaw(err error, raw string) error {
+	return rawError{err: err, raw: raw}
+}
+
+// Error implements the Error interface.
+func (e rawError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the annotated error.
+func (e rawError) Unwrap() error {
+	return e.err
+}
+
+// ErrWrongMessageType is returned by the typed parsers when a message is of a different type than the parser handles.
+// The message can be routed to the parser for its actual type instead, e.g.:
+//
+//	var wrongType ErrWrongMessageType
+//	if errors.As(err, &wrongType) && wrongType.Actual == MessageTypeMT942 {
+//		mt942, err := MTxToMT942(mtx)
+//		// ...
+//	}
+type ErrWrongMessageType struct {
+	Expected string
+	Actual   string
+}
+
+// Error implements the Error interface.
+func (e ErrWrongMessageType) Error() string {
+	return fmt.Sprintf("expected message type %s, got %s", e.Expected, e.Actual)
+}
+
+// ValidationError is the cause of a parse error for a typed message that failed validation. Besides the complete
+// description of all failures, as returned by Error, it holds the failure of each field separately. This allows for
+// presenting the failures of a message individually, e.g.:
+//
+//	var validationErr ValidationError
+//	if errors.As(err, &validationErr) {
+//		for _, failure := range validationErr.Failures {
+//			fmt.Printf("field %s (%s): %s\n", failure.Tag, failure.Field, failure)
+//		}
+//	}
+type ValidationError struct {
+	MessageType string
+	Failures    []FieldFailure
+	err         error
+}
+
+// newValidationError creates a validation error for a message of the given type from the failures of its validator.
+func newValidationError(messageType string, err validate.ValidationError) ValidationError {
+	failures := validate.Failures(err)
+
+	fieldFailures := make([]FieldFailure, len(failures))
+	for i, failure := range failures {
+		fieldFailures[i] = FieldFailure{
+			Field: failure.Field,
+			Tag:   failure.Label,
+			err:   failure.Err,
+		}
+	}
+
+	return ValidationError{
+		MessageType: messageType,
+		Failures:    fieldFailures,
+		err:         fmt.Errorf("validation failed for MT%s message:\n%w", messageType, err),
+	}
+}
+
+// Error implements the Error interface.
+func (e ValidationError) Error() string {
+	return e.err.Error()
+}
+
+// FieldFailure is the validation failure of a single field of a message. Field is the name of the member of the typed
+// message, e.g. StatementLines[0], Tag the tag of the field, e.g. 61. The failures of a group of fields, of which only
+// one may be present, are reported as a single failure with the fields and tags joined by a slash.
+type FieldFailure struct {
+	Field string
+	Tag   string
+	err   error
+}
+
+// Error implements the Error interface.
+func (ff FieldFailure) Error() string {
+	return strings.TrimPrefix(ff.err.Error(), "\t")
+}
+
 // Errors is a custom error type that is used for aggregating Error's into one error.
 type Errors []Error
 
@@ -64,3 +236,71 @@ func (es Errors) String() string {
 func (es Errors) Error() string {
 	return es.String()
 }
+
+// ByLine groups the errors by the line of the message they belong to. Within each line the original order of the
+// errors is kept.
+func (es Errors) ByLine() map[int][]Error {
+	byLine := make(map[int][]Error)
+
+	for _, e := range es {
+		byLine[e.line] = append(byLine[e.line], e)
+	}
+
+	return byLine
+}
+
+// ByMessage groups the errors tied to a message by the index of that message, see Error.MessageIndex. Errors not tied
+// to a message are left out. Within each message the original order of the errors is kept.
+func (es Errors) ByMessage() map[int][]Error {
+	byMessage := make(map[int][]Error)
+
+	for _, e := range es {
+		if index, ok := e.MessageIndex(); ok {
+			byMessage[index] = append(byMessage[index], e)
+		}
+	}
+
+	return byMessage
+}
+
+// Warnings returns only the errors with warning severity, in their original order.
+func (es Errors) Warnings() Errors {
+	return es.filter(func(e Error) bool { return e.IsWarning() })
+}
+
+// WithoutWarnings returns all errors except those with warning severity, in their original order.
+func (es Errors) WithoutWarnings() Errors {
+	return es.filter(func(e Error) bool { return !e.IsWarning() })
+}
+
+func (es Errors) filter(keep func(Error) bool) Errors {
+	var filtered Errors
+
+	for _, e := range es {
+		if keep(e) {
+			filtered = append(filtered, e)
+		}
+	}
+
+	return filtered
+}
+
+// errorToErrors turns any error into Errors. If the error already is of type Errors it is returned as-is, otherwise it
+// is wrapped as a single Error without line information.
+func errorToErrors(err error) Errors {
+	if err == nil {
+		return nil
+	}
+
+	var es Errors
+	if errors.As(err, &es) {
+		return es
+	}
+
+	var e Error
+	if errors.As(err, &e) {
+		return Errors{e}
+	}
+
+	return Errors{NewError(err, 0)}
+}
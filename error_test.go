@@ -6,7 +6,10 @@
 package mt_test
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/DennisVis/mt"
@@ -23,6 +26,11 @@ func TestErrorString(t *testing.T) {
 			parseErr:    mt.NewError(fmt.Errorf("simple error"), 1),
 			expectedStr: "#1: simple error",
 		},
+		{
+			name:        "WarningLine2",
+			parseErr:    mt.NewWarning(fmt.Errorf("simple warning"), 2),
+			expectedStr: "#2: warning: simple warning",
+		},
 	} {
 		// rebind to make sure we can run in parallel
 		test := test
@@ -69,3 +77,256 @@ func TestErrorsString(t *testing.T) {
 		})
 	}
 }
+
+func TestErrorToErrors(t *testing.T) {
+	simpleErr := fmt.Errorf("simple error")
+	parseErr := mt.NewError(simpleErr, 2)
+
+	for _, test := range []struct {
+		name        string
+		err         error
+		expectedStr string
+	}{
+		{
+			name:        "Nil",
+			err:         nil,
+			expectedStr: "",
+		},
+		{
+			name:        "Errors",
+			err:         mt.Errors{parseErr},
+			expectedStr: "mt: Parse errors per message line:\n#2: simple error",
+		},
+		{
+			name:        "Error",
+			err:         parseErr,
+			expectedStr: "mt: Parse errors per message line:\n#2: simple error",
+		},
+		{
+			name:        "WrappedErrors",
+			err:         fmt.Errorf("wrapped: %w", mt.Errors{parseErr}),
+			expectedStr: "mt: Parse errors per message line:\n#2: simple error",
+		},
+		{
+			name:        "OtherError",
+			err:         simpleErr,
+			expectedStr: "mt: Parse errors per message line:\n#0: simple error",
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			es := mt.ErrorToErrors(test.err)
+			if es.Error() != test.expectedStr {
+				t.Errorf("expected %s, got %s", test.expectedStr, es.Error())
+			}
+		})
+	}
+}
+
+func TestErrorsByLine(t *testing.T) {
+	errLine1a := mt.NewError(fmt.Errorf("first error"), 1)
+	errLine1b := mt.NewError(fmt.Errorf("second error"), 1)
+	errLine28 := mt.NewError(fmt.Errorf("third error"), 28)
+
+	for _, test := range []struct {
+		name     string
+		errs     mt.Errors
+		expected map[int][]mt.Error
+	}{
+		{
+			name:     "Empty",
+			errs:     mt.Errors{},
+			expected: map[int][]mt.Error{},
+		},
+		{
+			name: "MultipleLines",
+			errs: mt.Errors{errLine1a, errLine28, errLine1b},
+			expected: map[int][]mt.Error{
+				1:  {errLine1a, errLine1b},
+				28: {errLine28},
+			},
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			actual := test.errs.ByLine()
+
+			if len(actual) != len(test.expected) {
+				t.Fatalf("expected %d lines, got %d", len(test.expected), len(actual))
+			}
+
+			for line, expectedErrs := range test.expected {
+				actualErrs := actual[line]
+				if len(actualErrs) != len(expectedErrs) {
+					t.Errorf("line %d: expected %d errors, got %d", line, len(expectedErrs), len(actualErrs))
+					continue
+				}
+
+				for i, expectedErr := range expectedErrs {
+					if actualErrs[i] != expectedErr {
+						t.Errorf("line %d: expected error %d to be %s, got %s", line, i, expectedErr, actualErrs[i])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestErrorsWarnings(t *testing.T) {
+	errLine1 := mt.NewError(fmt.Errorf("first error"), 1)
+	warnLine1 := mt.NewWarning(fmt.Errorf("first warning"), 1)
+	errLine28 := mt.NewError(fmt.Errorf("second error"), 28)
+	warnLine28 := mt.NewWarning(fmt.Errorf("second warning"), 28)
+
+	for _, test := range []struct {
+		name                    string
+		errs                    mt.Errors
+		expectedWarnings        mt.Errors
+		expectedWithoutWarnings mt.Errors
+	}{
+		{
+			name: "Empty",
+			errs: mt.Errors{},
+		},
+		{
+			name:                    "OnlyErrors",
+			errs:                    mt.Errors{errLine1, errLine28},
+			expectedWithoutWarnings: mt.Errors{errLine1, errLine28},
+		},
+		{
+			name:             "OnlyWarnings",
+			errs:             mt.Errors{warnLine1, warnLine28},
+			expectedWarnings: mt.Errors{warnLine1, warnLine28},
+		},
+		{
+			name:                    "Mixed",
+			errs:                    mt.Errors{errLine1, warnLine1, warnLine28, errLine28},
+			expectedWarnings:        mt.Errors{warnLine1, warnLine28},
+			expectedWithoutWarnings: mt.Errors{errLine1, errLine28},
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, result := range []struct {
+				name     string
+				expected mt.Errors
+				actual   mt.Errors
+			}{
+				{name: "Warnings", expected: test.expectedWarnings, actual: test.errs.Warnings()},
+				{name: "WithoutWarnings", expected: test.expectedWithoutWarnings, actual: test.errs.WithoutWarnings()},
+			} {
+				if len(result.actual) != len(result.expected) {
+					t.Errorf("%s: expected %d errors, got %d", result.name, len(result.expected), len(result.actual))
+					continue
+				}
+
+				for i, expectedErr := range result.expected {
+					if result.actual[i] != expectedErr {
+						t.Errorf("%s: expected error %d to be %s, got %s", result.name, i, expectedErr, result.actual[i])
+					}
+				}
+			}
+
+			for _, warning := range test.errs.Warnings() {
+				if warning.Severity() != mt.SeverityWarning {
+					t.Errorf("expected warning severity, got %d", warning.Severity())
+				}
+			}
+		})
+	}
+}
+
+func TestErrWrongMessageType(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.Open("testdata/sample-file-mt942.txt")
+	if err != nil {
+		t.Fatalf("could not open sample file: %v", err)
+	}
+	defer f.Close()
+
+	msgs, err := mt.ParseAllMTx(ctx, f)
+	if err != nil {
+		t.Fatalf("expected nil error, got: %v", err)
+	}
+	if len(msgs) == 0 {
+		t.Fatal("expected messages, got none")
+	}
+
+	_, err = mt.MTxToMT940(msgs[0])
+
+	expectedStr := "expected message type 940, got 942"
+	if err == nil || err.Error() != expectedStr {
+		t.Fatalf("expected error %s, got %v", expectedStr, err)
+	}
+
+	// the error can be told apart from other errors, also when wrapped in a parse error
+	for _, wrapped := range []error{err, mt.NewError(err, msgs[0].Line)} {
+		var wrongType mt.ErrWrongMessageType
+		if !errors.As(wrapped, &wrongType) {
+			t.Fatalf("expected error to be an ErrWrongMessageType, got %T", wrapped)
+		}
+
+		if wrongType.Expected != mt.MessageTypeMT940 {
+			t.Errorf("expected expected type %s, got %s", mt.MessageTypeMT940, wrongType.Expected)
+		}
+		if wrongType.Actual != mt.MessageTypeMT942 {
+			t.Errorf("expected actual type %s, got %s", mt.MessageTypeMT942, wrongType.Actual)
+		}
+	}
+}
+
+func TestErrorRaw(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		input       string
+		expectedRaw string
+	}{
+		{
+			name:        "BasicHeader",
+			input:       "{1:X01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:\n:20:REFERENCE\n-}",
+			expectedRaw: "{1:X01BPHKPLPKXXXX0000000000}",
+		},
+		{
+			name:        "AppHeader",
+			input:       "{1:F01BPHKPLPKXXXX0000000000}{2:X940BOFAUS6BXBAMN}{4:\n:20:REFERENCE\n-}",
+			expectedRaw: "{2:X940BOFAUS6BXBAMN}",
+		},
+		{
+			name: "TrailersSubBlock",
+			input: "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:\n:20:REFERENCE\n-}" +
+				"{5:{CHK:123456789ABC}{PDE:INVALID}}",
+			expectedRaw: "{PDE:INVALID}",
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := mt.ParseAllMTx(ctx, strings.NewReader(test.input))
+
+			var errs mt.Errors
+			if !errors.As(err, &errs) || len(errs) != 1 {
+				t.Fatalf("expected a single parse error, got: %v", err)
+			}
+
+			if errs[0].Raw() != test.expectedRaw {
+				t.Errorf("expected raw %q, got %q", test.expectedRaw, errs[0].Raw())
+			}
+		})
+	}
+}
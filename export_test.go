@@ -0,0 +1,21 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt
+
+import "github.com/DennisVis/mt/internal/pattern"
+
+// ErrorToErrors exposes errorToErrors to the tests in the mt_test package.
+var ErrorToErrors = errorToErrors
+
+// ValidateAppHeader exposes validateAppHeader to the tests in the mt_test package.
+var ValidateAppHeader = validateAppHeader
+
+// ValidateStrict exposes validateStrict to the tests in the mt_test package.
+var ValidateStrict = validateStrict
+
+// UnregisterCharSet exposes the removal of a character set registered through RegisterCharSet to the tests in the
+// mt_test package.
+var UnregisterCharSet = pattern.UnregisterCharSet
@@ -0,0 +1,89 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// parseFileMT940 opens the file at the given path and parses all MT940 messages in it.
+func parseFileMT940(ctx context.Context, path string, options ...option) ([]MT940, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file: %w", err)
+	}
+	defer f.Close()
+
+	return ParseAllMT940(ctx, f, options...)
+}
+
+// ParseFilesMT940 parses the MT940 messages in each of the files at the given paths using ParseAllMT940. Files are
+// parsed concurrently, by at most the given number of goroutines at a time. A concurrency of less than 1 is treated as
+// 1.
+//
+// The messages and errors are returned keyed by path. Paths for which parsing failed are present in the error map,
+// also when messages were returned regardless, e.g. when Lax is passed. Once the context is done no further files are
+// opened, the paths of the files left unparsed map to the error of the context.
+func ParseFilesMT940(
+	ctx context.Context,
+	paths []string,
+	concurrency int,
+	options ...option,
+) (map[string][]MT940, map[string]error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make(map[string][]MT940, len(paths))
+	errs := make(map[string]error)
+
+	mu := &sync.Mutex{}
+	wg := &sync.WaitGroup{}
+	sem := make(chan struct{}, concurrency)
+
+	store := func(path string, msgs []MT940, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if msgs != nil {
+			results[path] = msgs
+		}
+		if err != nil {
+			errs[path] = err
+		}
+	}
+
+	for _, path := range paths {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			store(path, nil, ctx.Err())
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(path string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := ctx.Err(); err != nil {
+				store(path, nil, err)
+				return
+			}
+
+			msgs, err := parseFileMT940(ctx, path, options...)
+			store(path, msgs, err)
+		}(path)
+	}
+
+	wg.Wait()
+
+	return results, errs
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DennisVis/mt"
+	mttest "github.com/DennisVis/mt/testdata"
+)
+
+func writeTestFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600)
+		if err != nil {
+			t.Fatalf("could not write test file %s: %v", name, err)
+		}
+	}
+
+	return dir
+}
+
+func TestParseFilesMT940(t *testing.T) {
+	sample, err := os.ReadFile("testdata/sample-file-mt940.txt")
+	if err != nil {
+		t.Fatalf("could not read sample file: %v", err)
+	}
+
+	dir := writeTestFiles(t, map[string]string{
+		"sample-1.txt": string(sample),
+		"sample-2.txt": string(sample),
+		"single.txt":   strings.Replace(pagedMT940, "00084/001/02", "00084/001", 1),
+		"empty.txt":    "",
+		"invalid.txt":  pagedMT940,
+	})
+
+	for _, test := range []struct {
+		name                 string
+		files                []string
+		concurrency          int
+		expectedMessageCount map[string]int
+		expectedErrs         map[string]error
+	}{
+		{
+			name:        "Sequential",
+			files:       []string{"sample-1.txt", "single.txt"},
+			concurrency: 1,
+			expectedMessageCount: map[string]int{
+				"sample-1.txt": 3,
+				"single.txt":   1,
+			},
+			expectedErrs: map[string]error{
+				"sample-1.txt": fmt.Errorf("validation failed for MT940 message"),
+			},
+		},
+		{
+			name:        "Concurrent",
+			files:       []string{"sample-1.txt", "sample-2.txt", "single.txt", "empty.txt", "invalid.txt"},
+			concurrency: 3,
+			expectedMessageCount: map[string]int{
+				"sample-1.txt": 3,
+				"sample-2.txt": 3,
+				"single.txt":   1,
+				"empty.txt":    0,
+				"invalid.txt":  1,
+			},
+			expectedErrs: map[string]error{
+				"sample-1.txt": fmt.Errorf("validation failed for MT940 message"),
+				"sample-2.txt": fmt.Errorf("validation failed for MT940 message"),
+				"invalid.txt":  fmt.Errorf("validation failed for MT940 message"),
+			},
+		},
+		{
+			name:        "ZeroConcurrency",
+			files:       []string{"single.txt"},
+			concurrency: 0,
+			expectedMessageCount: map[string]int{
+				"single.txt": 1,
+			},
+		},
+		{
+			name:                 "MissingFile",
+			files:                []string{"missing.txt"},
+			concurrency:          2,
+			expectedMessageCount: map[string]int{},
+			expectedErrs: map[string]error{
+				"missing.txt": fmt.Errorf("could not open file"),
+			},
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			paths := make([]string, len(test.files))
+			for i, file := range test.files {
+				paths[i] = filepath.Join(dir, file)
+			}
+
+			results, errs := mt.ParseFilesMT940(ctx, paths, test.concurrency, mt.Lax(true))
+
+			for _, file := range test.files {
+				path := filepath.Join(dir, file)
+
+				if expected, got := test.expectedMessageCount[file], len(results[path]); expected != got {
+					t.Errorf("%s: expected %d messages, got %d", file, expected, got)
+				}
+
+				mttest.ValidateError(t, test.expectedErrs[file], errs[path])
+			}
+
+			if len(errs) != len(test.expectedErrs) {
+				t.Errorf("expected %d errors, got %d: %v", len(test.expectedErrs), len(errs), errs)
+			}
+		})
+	}
+}
+
+func TestParseFilesMT940Cancelled(t *testing.T) {
+	t.Parallel()
+
+	dir := writeTestFiles(t, map[string]string{
+		"single.txt": strings.Replace(pagedMT940, "00084/001/02", "00084/001", 1),
+	})
+
+	cancelledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	paths := []string{filepath.Join(dir, "single.txt"), filepath.Join(dir, "missing.txt")}
+
+	results, errs := mt.ParseFilesMT940(cancelledCtx, paths, 1)
+
+	if len(results) != 0 {
+		t.Errorf("expected no results, got %d", len(results))
+	}
+
+	for _, path := range paths {
+		mttest.ValidateError(t, context.Canceled, errs[path])
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// gzipMagic holds the bytes every gzip compressed input starts with.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// gzipReader transparently decompresses the underlying reader if it holds gzip compressed data, which is detected by
+// the magic bytes at its start. Any other input is read as-is. Detection is deferred until the first read, so a reader
+// that fails to read reports its error the same way regardless.
+type gzipReader struct {
+	rd *bufio.Reader
+	r  io.Reader
+}
+
+func newGzipReader(rd io.Reader) *gzipReader {
+	return &gzipReader{
+		rd: bufio.NewReader(rd),
+	}
+}
+
+// Read implements the io.Reader interface.
+func (r *gzipReader) Read(p []byte) (int, error) {
+	if r.r == nil {
+		// an input shorter than the magic bytes can't be gzip compressed, any read error is left to the reads to come
+		magic, _ := r.rd.Peek(len(gzipMagic))
+		if !bytes.Equal(magic, gzipMagic) {
+			r.r = r.rd
+			return r.r.Read(p)
+		}
+
+		gz, err := gzip.NewReader(r.rd)
+		if err != nil {
+			return 0, fmt.Errorf("could not decompress gzip input: %w", err)
+		}
+
+		r.r = gz
+	}
+
+	return r.r.Read(p)
+}
+
+// inputReader returns a reader for the messages in rd, decompressing it if it's gzip compressed and stripping its
+// envelope according to the configuration. Reading fails if rd turns out to be exhausted already.
+func inputReader(rd io.Reader, cfg config) io.Reader {
+	return envelopeReader(newGzipReader(&exhaustionGuard{rd: rd}), cfg.Envelope)
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/DennisVis/mt"
+	mttest "github.com/DennisVis/mt/testdata"
+)
+
+func gzipBytes(t *testing.T, b []byte) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+
+	gz := gzip.NewWriter(buf)
+	if _, err := gz.Write(b); err != nil {
+		t.Fatalf("could not compress input: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("could not compress input: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestGzip(t *testing.T) {
+	sample, err := os.ReadFile("testdata/sample-file-mt940.txt")
+	if err != nil {
+		t.Fatalf("could not read sample file: %v", err)
+	}
+	compressed := gzipBytes(t, sample)
+
+	expected, err := mt.ParseAllMTx(ctx, bytes.NewReader(sample))
+	if err != nil {
+		t.Fatalf("could not parse sample file: %v", err)
+	}
+
+	for name, parse := range map[string]func(input []byte) ([]mt.MTx, error){
+		"ParseAllMTx": func(input []byte) ([]mt.MTx, error) {
+			return mt.ParseAllMTx(ctx, bytes.NewReader(input))
+		},
+		"Synchronous": func(input []byte) ([]mt.MTx, error) {
+			return mt.ParseAllMTx(ctx, bytes.NewReader(input), mt.Synchronous(true))
+		},
+		"ParseBytes": func(input []byte) ([]mt.MTx, error) {
+			return mt.ParseBytes(input)
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		parse := parse
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			msgs, err := parse(compressed)
+			mttest.ValidateError(t, nil, err)
+
+			if len(msgs) != len(expected) {
+				t.Fatalf("expected %d messages, got %d", len(expected), len(msgs))
+			}
+			for i := range msgs {
+				if msgs[i].Raw != expected[i].Raw {
+					t.Errorf("message %d: expected Raw %s, got %s", i, expected[i].Raw, msgs[i].Raw)
+				}
+			}
+		})
+	}
+
+	t.Run("SplitMessages", func(t *testing.T) {
+		t.Parallel()
+
+		expectedSplit, err := mt.SplitMessages(bytes.NewReader(sample))
+		mttest.ValidateError(t, nil, err)
+
+		split, err := mt.SplitMessages(bytes.NewReader(compressed))
+		mttest.ValidateError(t, nil, err)
+
+		if !reflect.DeepEqual(split, expectedSplit) {
+			t.Errorf("expected messages %v, got %v", expectedSplit, split)
+		}
+	})
+
+	t.Run("Corrupt", func(t *testing.T) {
+		t.Parallel()
+
+		// the magic bytes followed by an invalid gzip header
+		corrupt := append([]byte{0x1f, 0x8b}, sample[:16]...)
+
+		msgs, err := mt.ParseAllMTx(ctx, bytes.NewReader(corrupt))
+		mttest.ValidateError(t, fmt.Errorf("could not decompress gzip input"), err)
+
+		if len(msgs) != 0 {
+			t.Errorf("expected no messages, got %d", len(msgs))
+		}
+
+		_, err = mt.ParseBytes(corrupt)
+		mttest.ValidateError(t, fmt.Errorf("could not decompress gzip input"), err)
+	})
+}
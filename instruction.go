@@ -0,0 +1,217 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	instructionCodeLength                  = 4
+	instructionAdditionalInformationMaxLen = 30
+)
+
+// bankOperationCodes holds the codes allowed in the bank operation code of an MT103, field 23B, with their
+// descriptions.
+var bankOperationCodes = map[string]string{
+	"CRED": "Normal credit transfer",
+	"CRTS": "Test message",
+	"SPAY": "SWIFTPay service level",
+	"SPRI": "Priority service level",
+	"SSTD": "Standard service level",
+}
+
+// instructionCodes holds the codes allowed in the instruction code of an MT103, field 23E, with their descriptions and
+// whether they may be followed by additional information.
+var instructionCodes = map[string]struct {
+	description       string
+	additionalAllowed bool
+}{
+	"CHQB": {"Pay beneficiary customer by cheque only", false},
+	"HOLD": {"Beneficiary customer will call, pay upon identification", true},
+	"INTC": {"Intra-company payment", false},
+	"PHOB": {"Contact the beneficiary's bank by phone", true},
+	"PHOI": {"Contact the intermediary by phone", true},
+	"PHON": {"Contact the account with institution by phone", true},
+	"REPA": {"Related to a reimbursement or payment arrangement", true},
+	"SDVA": {"Payment must be executed with same day value", false},
+	"TELB": {"Contact the beneficiary's bank by the most efficient means of telecommunication", true},
+	"TELE": {"Contact the account with institution by the most efficient means of telecommunication", true},
+	"TELI": {"Contact the intermediary by the most efficient means of telecommunication", true},
+}
+
+// validateInstructionCodeFormat verifies the code consists of 4 upper case letters or digits, 4!c.
+func validateInstructionCodeFormat(code string) error {
+	if len(code) != instructionCodeLength {
+		return fmt.Errorf("expected a code of %d characters, got: %s", instructionCodeLength, code)
+	}
+
+	for _, r := range code {
+		if !isUpperAlphaNumeric(r) {
+			return fmt.Errorf("code %q must consist of upper case letters or digits", code)
+		}
+	}
+
+	return nil
+}
+
+// BankOperationCode is the bank operation code of an MT103, field 23B, e.g. CRED. The code must be of the format 4!c,
+// whether it is one of the codes the specification allows is only verified in strict mode, see the Strict option. The
+// description is empty for codes that aren't allowed.
+type BankOperationCode struct {
+	Set         bool
+	Raw         string
+	Code        string
+	Description string
+}
+
+func (boc *BankOperationCode) UnmarshalMT(input string) error {
+	// example:
+	// CRED
+
+	err := validateInstructionCodeFormat(input)
+	if err != nil {
+		return fmt.Errorf("invalid BankOperationCode: %w", err)
+	}
+
+	boc.Set = true
+	boc.Raw = input
+	boc.Code = input
+	boc.Description = bankOperationCodes[input]
+
+	return nil
+}
+
+// Known tells whether the code is one of the codes the specification allows.
+func (boc BankOperationCode) Known() bool {
+	_, ok := bankOperationCodes[boc.Code]
+
+	return ok
+}
+
+// validateStrict verifies the code is one of the codes the specification allows.
+func (boc BankOperationCode) validateStrict() error {
+	if boc.Set && !boc.Known() {
+		return fmt.Errorf("invalid BankOperationCode: unknown code %s", boc.Code)
+	}
+
+	return nil
+}
+
+func (boc BankOperationCode) RawString() string {
+	return boc.Raw
+}
+
+func (boc BankOperationCode) String() string {
+	if boc.Description == "" {
+		return boc.Code
+	}
+
+	return boc.Code + ": " + boc.Description
+}
+
+// MarshalMT generates the value of the field from the code.
+func (boc BankOperationCode) MarshalMT() (string, error) {
+	return boc.Code, nil
+}
+
+// InstructionCode is an instruction code of an MT103, field 23E, e.g. PHON/0031201234567. The code, of the format 4!c,
+// is optionally followed by a slash and additional information of up to 30 characters. Whether the code is one of the
+// codes the specification allows, and whether it may be followed by additional information, is only verified in strict
+// mode, see the Strict option. The description is empty for codes that aren't allowed.
+type InstructionCode struct {
+	Set                   bool
+	Raw                   string
+	Code                  string
+	AdditionalInformation string
+	Description           string
+}
+
+func (ic *InstructionCode) UnmarshalMT(input string) error {
+	// example:
+	// SDVA
+	// PHON/0031201234567
+
+	code, additional := input, ""
+	if idx := strings.Index(input, "/"); idx >= 0 {
+		code, additional = input[:idx], input[idx+1:]
+
+		if additional == "" || len(additional) > instructionAdditionalInformationMaxLen {
+			return fmt.Errorf(
+				"invalid InstructionCode: expected additional information of 1 to %d characters after the slash, got: %s",
+				instructionAdditionalInformationMaxLen,
+				input,
+			)
+		}
+
+		for _, r := range additional {
+			if r == '\n' || r == '\r' || !isFINCharacter(r) {
+				return fmt.Errorf("invalid InstructionCode: invalid character %q in additional information", r)
+			}
+		}
+	}
+
+	err := validateInstructionCodeFormat(code)
+	if err != nil {
+		return fmt.Errorf("invalid InstructionCode: %w", err)
+	}
+
+	ic.Set = true
+	ic.Raw = input
+	ic.Code = code
+	ic.AdditionalInformation = additional
+	ic.Description = instructionCodes[code].description
+
+	return nil
+}
+
+// Known tells whether the code is one of the codes the specification allows.
+func (ic InstructionCode) Known() bool {
+	_, ok := instructionCodes[ic.Code]
+
+	return ok
+}
+
+// validateStrict verifies the code is one of the codes the specification allows and is only followed by additional
+// information if the code allows for it.
+func (ic InstructionCode) validateStrict() error {
+	if !ic.Set {
+		return nil
+	}
+
+	spec, ok := instructionCodes[ic.Code]
+	if !ok {
+		return fmt.Errorf("invalid InstructionCode: unknown code %s", ic.Code)
+	}
+
+	if ic.AdditionalInformation != "" && !spec.additionalAllowed {
+		return fmt.Errorf("invalid InstructionCode: code %s may not be followed by additional information", ic.Code)
+	}
+
+	return nil
+}
+
+func (ic InstructionCode) RawString() string {
+	return ic.Raw
+}
+
+func (ic InstructionCode) String() string {
+	if ic.Description == "" {
+		return ic.RawString()
+	}
+
+	return ic.RawString() + ": " + ic.Description
+}
+
+// MarshalMT generates the value of the field from the code and the additional information, if any.
+func (ic InstructionCode) MarshalMT() (string, error) {
+	if ic.AdditionalInformation == "" {
+		return ic.Code, nil
+	}
+
+	return ic.Code + "/" + ic.AdditionalInformation, nil
+}
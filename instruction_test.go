@@ -0,0 +1,174 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/DennisVis/mt"
+	mttest "github.com/DennisVis/mt/testdata"
+)
+
+func TestBankOperationCode(t *testing.T) {
+	for _, test := range []struct {
+		name              string
+		input             string
+		expectedErr       error
+		expectedStrictErr error
+		expected          mt.BankOperationCode
+	}{
+		{
+			name:  "CRED",
+			input: "CRED",
+			expected: mt.BankOperationCode{
+				Set:         true,
+				Raw:         "CRED",
+				Code:        "CRED",
+				Description: "Normal credit transfer",
+			},
+		},
+		{
+			name:              "UnknownCode",
+			input:             "ABCD",
+			expectedStrictErr: fmt.Errorf("invalid BankOperationCode: unknown code ABCD"),
+			expected: mt.BankOperationCode{
+				Set:  true,
+				Raw:  "ABCD",
+				Code: "ABCD",
+			},
+		},
+		{
+			name:        "TooLong",
+			input:       "CREDIT",
+			expectedErr: fmt.Errorf("invalid BankOperationCode: expected a code of 4 characters, got: CREDIT"),
+		},
+		{
+			name:        "LowerCase",
+			input:       "cred",
+			expectedErr: fmt.Errorf(`invalid BankOperationCode: code "cred" must consist of upper case letters or digits`),
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var actual mt.BankOperationCode
+			err := actual.UnmarshalMT(test.input)
+			mttest.ValidateError(t, test.expectedErr, err)
+			if err != nil {
+				return
+			}
+
+			if actual != test.expected {
+				t.Errorf("expected %+v, got %+v", test.expected, actual)
+			}
+
+			mttest.ValidateError(t, test.expectedStrictErr, mt.ValidateStrict(actual))
+
+			str, _ := actual.MarshalMT()
+			if str != test.input {
+				t.Errorf("expected marshalled value %s, got %s", test.input, str)
+			}
+		})
+	}
+}
+
+func TestInstructionCode(t *testing.T) {
+	for _, test := range []struct {
+		name              string
+		input             string
+		expectedErr       error
+		expectedStrictErr error
+		expected          mt.InstructionCode
+	}{
+		{
+			name:  "CodeOnly",
+			input: "SDVA",
+			expected: mt.InstructionCode{
+				Set:         true,
+				Raw:         "SDVA",
+				Code:        "SDVA",
+				Description: "Payment must be executed with same day value",
+			},
+		},
+		{
+			name:  "AdditionalInformation",
+			input: "PHON/0031201234567",
+			expected: mt.InstructionCode{
+				Set:                   true,
+				Raw:                   "PHON/0031201234567",
+				Code:                  "PHON",
+				AdditionalInformation: "0031201234567",
+				Description:           "Contact the account with institution by phone",
+			},
+		},
+		{
+			name:              "AdditionalInformationNotAllowed",
+			input:             "SDVA/TODAY",
+			expectedStrictErr: fmt.Errorf("invalid InstructionCode: code SDVA may not be followed by additional information"),
+			expected: mt.InstructionCode{
+				Set:                   true,
+				Raw:                   "SDVA/TODAY",
+				Code:                  "SDVA",
+				AdditionalInformation: "TODAY",
+				Description:           "Payment must be executed with same day value",
+			},
+		},
+		{
+			name:              "UnknownCode",
+			input:             "ABCD",
+			expectedStrictErr: fmt.Errorf("invalid InstructionCode: unknown code ABCD"),
+			expected: mt.InstructionCode{
+				Set:  true,
+				Raw:  "ABCD",
+				Code: "ABCD",
+			},
+		},
+		{
+			name:        "EmptyAdditionalInformation",
+			input:       "PHON/",
+			expectedErr: fmt.Errorf("invalid InstructionCode: expected additional information of 1 to 30 characters"),
+		},
+		{
+			name:        "AdditionalInformationTooLong",
+			input:       "PHON/1234567890123456789012345678901",
+			expectedErr: fmt.Errorf("invalid InstructionCode: expected additional information of 1 to 30 characters"),
+		},
+		{
+			name:        "InvalidCode",
+			input:       "PH/0031201234567",
+			expectedErr: fmt.Errorf("invalid InstructionCode: expected a code of 4 characters, got: PH"),
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var actual mt.InstructionCode
+			err := actual.UnmarshalMT(test.input)
+			mttest.ValidateError(t, test.expectedErr, err)
+			if err != nil {
+				return
+			}
+
+			if actual != test.expected {
+				t.Errorf("expected %+v, got %+v", test.expected, actual)
+			}
+
+			mttest.ValidateError(t, test.expectedStrictErr, mt.ValidateStrict(actual))
+
+			str, _ := actual.MarshalMT()
+			if str != test.input {
+				t.Errorf("expected marshalled value %s, got %s", test.input, str)
+			}
+		})
+	}
+}
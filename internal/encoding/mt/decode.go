@@ -8,6 +8,7 @@ package mt
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -16,6 +17,72 @@ type MTUnmarshaler interface {
 	UnmarshalMT(input string) error
 }
 
+// MTTagUnmarshaler is implemented by types that need the tag a value was found under to decode it, e.g. a party in an
+// optioned field such as 50A, 50F or 50K, the format of which depends on the option letter. For the fields of a struct
+// it takes precedence over MTUnmarshaler. For a tag with the option letter left open, e.g. 50a, the tag passed is the
+// one of the option present, e.g. 50K.
+type MTTagUnmarshaler interface {
+	UnmarshalMTTag(tag, input string) error
+}
+
+// optionWildcard is the letter that, at the end of a tag, stands for any option letter, e.g. 62a, as the specification
+// denotes fields that come in several options.
+const optionWildcard = 'a'
+
+// isOptionWildcard tells whether the tag leaves the option letter open, e.g. 62a.
+func isOptionWildcard(tag string) bool {
+	return len(tag) > 1 && tag[len(tag)-1] == optionWildcard
+}
+
+// matchesOptionWildcard tells whether the key of a field matches the tag with the option letter left open, i.e. whether
+// the key consists of the same number followed by a single upper case letter, e.g. 62F or 62M for 62a.
+func matchesOptionWildcard(tag, key string) bool {
+	number := tag[:len(tag)-1]
+	if len(key) != len(tag) || !strings.HasPrefix(key, number) {
+		return false
+	}
+
+	option := key[len(key)-1]
+
+	return option >= 'A' && option <= 'Z'
+}
+
+// lookupTag returns the values of the field with the given tag, along with the key they were found under. For a tag
+// with the option letter left open, e.g. 62a, that is the key of the option present, e.g. 62M. At most one of the
+// options may be present.
+func lookupTag(fields map[string][]string, tag string) (string, []string, error) {
+	if !isOptionWildcard(tag) {
+		return tag, fields[tag], nil
+	}
+
+	keys := make([]string, 0, 1)
+	for key := range fields {
+		if matchesOptionWildcard(tag, key) {
+			keys = append(keys, key)
+		}
+	}
+
+	switch len(keys) {
+	case 0:
+		return tag, nil, nil
+	case 1:
+		return keys[0], fields[keys[0]], nil
+	default:
+		sort.Strings(keys)
+		return tag, nil, fmt.Errorf("multiple options present for tag %s: %s", tag, strings.Join(keys, ", "))
+	}
+}
+
+func toTagUnmarshaler(rval reflect.Value) (MTTagUnmarshaler, bool) {
+	if !rval.CanAddr() || !rval.CanInterface() || rval.Kind() != reflect.Struct {
+		return nil, false
+	}
+
+	um, ok := rval.Addr().Interface().(MTTagUnmarshaler)
+
+	return um, ok
+}
+
 func toUnmarshaler(rval reflect.Value) (MTUnmarshaler, bool) {
 	switch {
 	case !rval.CanAddr() || !rval.CanInterface():
@@ -98,18 +165,64 @@ func unmarshalFloat(val string, rval reflect.Value, bitSize int) error {
 	return nil
 }
 
+// unmarshalInterfaceSlice decodes the values into a slice of an interface type. The concrete type of its elements can't
+// be derived from the interface, so the slice has to hold prototype elements, pointers to types implementing
+// MTUnmarshaler. Every value is decoded into a copy of the prototype at the same index, or of the last prototype for
+// values beyond those, and the prototypes are replaced by the decoded elements.
+func unmarshalInterfaceSlice(vals []string, itemName string, rval reflect.Value) error {
+	if rval.Len() == 0 {
+		return fmt.Errorf("no prototype elements to decode into for slice of interface type %v", rval.Type().Elem())
+	}
+
+	decoded := reflect.MakeSlice(rval.Type(), 0, len(vals))
+
+	for i, v := range vals {
+		protoIdx := i
+		if protoIdx >= rval.Len() {
+			protoIdx = rval.Len() - 1
+		}
+
+		proto := rval.Index(protoIdx).Elem()
+		if !proto.IsValid() || proto.Kind() != reflect.Ptr || proto.IsNil() {
+			return fmt.Errorf("prototype element %s[%d] is not a non-nil pointer", itemName, protoIdx)
+		}
+
+		ins := reflect.New(proto.Type().Elem())
+		ins.Elem().Set(proto.Elem())
+
+		um, ok := ins.Interface().(MTUnmarshaler)
+		if !ok {
+			return fmt.Errorf("prototype element %s[%d] does not implement MTUnmarshaler", itemName, protoIdx)
+		}
+
+		err := um.UnmarshalMT(v)
+		if err != nil {
+			return fmt.Errorf("decoding failed for %s[%d]: decoding failed: %w", itemName, i, err)
+		}
+
+		decoded = reflect.Append(decoded, ins)
+	}
+
+	rval.Set(decoded)
+
+	return nil
+}
+
 func unmarshalSlice(vals []string, itemName string, rval reflect.Value) error {
 	elType := rval.Type().Elem()
+	if elType.Kind() == reflect.Interface {
+		return unmarshalInterfaceSlice(vals, itemName, rval)
+	}
 
-	for _, v := range vals {
+	for i, v := range vals {
 		ins := reflect.New(elType).Elem()
 
 		err := unmarshalItem([]string{v}, itemName, ins)
 		if err != nil {
-			return fmt.Errorf("decoding failed for slice item: %w", err)
+			return fmt.Errorf("decoding failed for %s[%d]: %w", itemName, i, err)
 		}
 
-		reflect.Append(rval, ins)
+		rval.Set(reflect.Append(rval, ins))
 	}
 
 	return nil
@@ -120,9 +233,27 @@ func unmarshalString(val string, rval reflect.Value) error {
 	return nil
 }
 
+// unmarshalPtr decodes the values into the value the pointer points to, allocating it if the pointer is nil.
+func unmarshalPtr(vals []string, itemName string, rval reflect.Value) error {
+	if rval.IsNil() {
+		rval.Set(reflect.New(rval.Type().Elem()))
+	}
+
+	return unmarshalItem(vals, itemName, rval.Elem())
+}
+
+// errNotRepeatable is the error for a field that occurs multiple times, while it's decoded into a single value rather
+// than a slice, e.g. a duplicate reference, field 20.
+func errNotRepeatable(occurrences int) error {
+	return fmt.Errorf(
+		"multiple values but field is not a slice: got %d occurrences of a non-repeatable field",
+		occurrences,
+	)
+}
+
 func unmarshalItem(vals []string, itemName string, rval reflect.Value) error {
 	if len(vals) > 1 && rval.Kind() != reflect.Slice {
-		return fmt.Errorf("multiple values but field is not a slice")
+		return errNotRepeatable(len(vals))
 	}
 
 	var err error
@@ -159,6 +290,8 @@ func unmarshalItem(vals []string, itemName string, rval reflect.Value) error {
 		err = unmarshalSlice(vals, itemName, rval)
 	case rval.Kind() == reflect.String:
 		err = unmarshalString(vals[0], rval)
+	case rval.Kind() == reflect.Ptr:
+		err = unmarshalPtr(vals, itemName, rval)
 	default:
 		err = fmt.Errorf("unsupported type: %v", rval.Type())
 	}
@@ -169,6 +302,11 @@ func unmarshalItem(vals []string, itemName string, rval reflect.Value) error {
 	return nil
 }
 
+// UnmarshalMT decodes the fields of a body into the struct v points to. The fields are matched to the members of the
+// struct by the tags in their mt struct tags, e.g. mt:"20,M,16x". A tag ending in a lower case a, e.g. mt:"62a,O,dive",
+// leaves the option letter open and matches any one of the options, e.g. 62F or 62M. Members implementing
+// MTTagUnmarshaler are passed the tag the value was actually found under, recording which option matched. Only slice
+// members can hold a field occurring multiple times, duplicate occurrences of any other field are an error.
 func UnmarshalMT(fields map[string][]string, v interface{}) error {
 	rv := reflect.ValueOf(v)
 	if rv.Kind() != reflect.Ptr {
@@ -194,14 +332,29 @@ func UnmarshalMT(fields map[string][]string, v interface{}) error {
 		}
 
 		tagSplit := strings.Split(structTag, ",")
-		tag := tagSplit[0]
 
-		vals, ok := fields[tag]
-		if !ok || len(vals) < 1 {
+		tag, vals, err := lookupTag(fields, tagSplit[0])
+		if err != nil {
+			return fmt.Errorf("decoding failed for field %s: %w", sf.Name, err)
+		}
+		if len(vals) < 1 {
+			continue
+		}
+
+		if um, ok := toTagUnmarshaler(fv); ok {
+			if len(vals) > 1 {
+				return fmt.Errorf("decoding failed for tag %s, field %s: %w", tag, sf.Name, errNotRepeatable(len(vals)))
+			}
+
+			err := um.UnmarshalMTTag(tag, vals[0])
+			if err != nil {
+				return fmt.Errorf("decoding failed for tag %s, field %s: decoding failed: %w", tag, sf.Name, err)
+			}
+
 			continue
 		}
 
-		err := unmarshalItem(vals, sf.Name, fv)
+		err = unmarshalItem(vals, sf.Name, fv)
 		if err != nil {
 			return fmt.Errorf("decoding failed for tag %s, field %s: %w", tag, sf.Name, err)
 		}
@@ -128,6 +128,19 @@ func TestUnmarshalMT(t *testing.T) {
 			},
 			expectedError: fmt.Errorf("multiple values but field is not a slice"),
 		},
+		{
+			name: "InvalidSliceItem",
+			factory: func() interface{} {
+				strct := struct {
+					Field []int `mt:"1"`
+				}{}
+				return &strct
+			},
+			input: map[string][]string{
+				"1": {"1", "2", "x"},
+			},
+			expectedError: fmt.Errorf("decoding failed for Field[2]: decoding failed: invalid int value"),
+		},
 		{
 			name: "InvalidBool",
 			factory: func() interface{} {
@@ -207,6 +220,11 @@ func TestUnmarshalMT(t *testing.T) {
 					SubField: &testSubStruct{
 						set: true,
 					},
+					SliceSubField: []MTUnmarshaler{
+						&testSubStruct{
+							set: true,
+						},
+					},
 				}
 			},
 			expectedStruct: testStruct{
@@ -254,22 +272,58 @@ func TestUnmarshalMT(t *testing.T) {
 			},
 			expectedError: errUnmarshalFail,
 		},
-		// {
-		// 	name: "SliceSubField",
-		// 	input: map[string][]string{
-		// 		"16": {"test"},
-		// 	},
-		// 	factory: func() interface{} {
-		// 		return &testStruct{
-		// 			SliceSubField: []MTUnmarshaler{
-		// 				&testSubStruct{
-		// 					set: true,
-		// 				},
-		// 			},
-		// 		}
-		// 	},
-		// 	expectedError: errUnmarshalFail,
-		// },
+		{
+			name: "SliceSubField",
+			input: map[string][]string{
+				"16": {"test"},
+			},
+			factory: func() interface{} {
+				return &testStruct{
+					SliceSubField: []MTUnmarshaler{
+						&testSubStruct{
+							set: true,
+						},
+					},
+				}
+			},
+		},
+		{
+			name: "SliceSubFieldInvalid",
+			input: map[string][]string{
+				"16": {"test1", "test2"},
+			},
+			factory: func() interface{} {
+				return &testStruct{
+					SliceSubField: []MTUnmarshaler{
+						&testSubStruct{},
+						&testSubStructInvalid{},
+					},
+				}
+			},
+			expectedError: fmt.Errorf("decoding failed for SliceSubField[1]: decoding failed: unmarshal fail"),
+		},
+		{
+			name: "SliceSubFieldWithoutPrototype",
+			input: map[string][]string{
+				"16": {"test"},
+			},
+			factory: func() interface{} {
+				return &testStruct{}
+			},
+			expectedError: fmt.Errorf("no prototype elements to decode into for slice of interface type"),
+		},
+		{
+			name: "SliceSubFieldNilPrototype",
+			input: map[string][]string{
+				"16": {"test"},
+			},
+			factory: func() interface{} {
+				return &testStruct{
+					SliceSubField: []MTUnmarshaler{nil},
+				}
+			},
+			expectedError: fmt.Errorf("prototype element SliceSubField[0] is not a non-nil pointer"),
+		},
 	} {
 		// rebind to make sure we can run in parallel
 		test := test
@@ -289,3 +343,178 @@ func TestUnmarshalMT(t *testing.T) {
 		})
 	}
 }
+
+func TestUnmarshalMTInterfaceSlice(t *testing.T) {
+	t.Parallel()
+
+	proto := &testSubStruct{set: true}
+	ts := &testStruct{
+		SliceSubField: []MTUnmarshaler{proto},
+	}
+
+	err := mt.UnmarshalMT(map[string][]string{"16": {"test1", "test2", "test3"}}, ts)
+	if err != nil {
+		t.Fatalf("expected nil error, got: %v", err)
+	}
+
+	if len(ts.SliceSubField) != 3 {
+		t.Fatalf("expected 3 elements, got %d", len(ts.SliceSubField))
+	}
+
+	for i, el := range ts.SliceSubField {
+		sub, ok := el.(*testSubStruct)
+		if !ok {
+			t.Fatalf("expected element %d to be a *testSubStruct, got %T", i, el)
+		}
+		if sub == proto {
+			t.Errorf("expected element %d to be a copy of the prototype, got the prototype itself", i)
+		}
+		if !sub.set || !sub.processed {
+			t.Errorf("expected element %d to be set and processed, got %+v", i, *sub)
+		}
+	}
+
+	if proto.processed {
+		t.Error("expected the prototype to be left untouched")
+	}
+}
+
+type testTagStruct struct {
+	tag   string
+	value string
+}
+
+func (tts *testTagStruct) UnmarshalMT(input string) error {
+	return errUnmarshalFail
+}
+
+func (tts *testTagStruct) UnmarshalMTTag(tag, input string) error {
+	if input == "invalid" {
+		return errUnmarshalFail
+	}
+
+	tts.tag = tag
+	tts.value = input
+
+	return nil
+}
+
+func TestUnmarshalMTTag(t *testing.T) {
+	type tagged struct {
+		OptionA testTagStruct `mt:"50A"`
+		OptionK testTagStruct `mt:"50K"`
+	}
+
+	for _, test := range []struct {
+		name          string
+		fields        map[string][]string
+		expected      tagged
+		expectedError error
+	}{
+		{
+			name:   "TagPassed",
+			fields: map[string][]string{"50K": {"JOHN DOE"}},
+			expected: tagged{
+				OptionK: testTagStruct{tag: "50K", value: "JOHN DOE"},
+			},
+		},
+		{
+			name:          "Invalid",
+			fields:        map[string][]string{"50A": {"invalid"}},
+			expectedError: fmt.Errorf("decoding failed for tag 50A, field OptionA: decoding failed: unmarshal fail"),
+		},
+		{
+			name:          "MultipleValues",
+			fields:        map[string][]string{"50A": {"A", "B"}},
+			expectedError: fmt.Errorf("multiple values but field is not a slice"),
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var actual tagged
+			err := mt.UnmarshalMT(test.fields, &actual)
+			mttest.ValidateError(t, test.expectedError, err)
+
+			if test.expectedError == nil && !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestUnmarshalMTOptionWildcard(t *testing.T) {
+	type optioned struct {
+		Balance     string        `mt:"62a,M,dive"`
+		Tagged      testTagStruct `mt:"50a,O,dive"`
+		NotOptioned string        `mt:"20,M,16x"`
+		Information []string      `mt:"86a,O,6*65x"`
+	}
+
+	for _, test := range []struct {
+		name          string
+		fields        map[string][]string
+		expected      optioned
+		expectedError error
+	}{
+		{
+			name:     "OptionF",
+			fields:   map[string][]string{"62F": {"C031002PLN40000,00"}},
+			expected: optioned{Balance: "C031002PLN40000,00"},
+		},
+		{
+			name:     "OptionM",
+			fields:   map[string][]string{"62M": {"C031002PLN40000,00"}},
+			expected: optioned{Balance: "C031002PLN40000,00"},
+		},
+		{
+			name:   "MatchedTagRecorded",
+			fields: map[string][]string{"50K": {"JOHN DOE"}},
+			expected: optioned{
+				Tagged: testTagStruct{tag: "50K", value: "JOHN DOE"},
+			},
+		},
+		{
+			name:     "RepeatedValues",
+			fields:   map[string][]string{"86B": {"INFO 1", "INFO 2"}},
+			expected: optioned{Information: []string{"INFO 1", "INFO 2"}},
+		},
+		{
+			name: "NoMatch",
+			fields: map[string][]string{
+				"62":   {"no option"},
+				"62FF": {"two letters"},
+				"62f":  {"lower case"},
+				"620F": {"other number"},
+				"20":   {"REFERENCE"},
+			},
+			expected: optioned{NotOptioned: "REFERENCE"},
+		},
+		{
+			name: "MultipleOptions",
+			fields: map[string][]string{
+				"62M": {"C031002PLN40000,00"},
+				"62F": {"C031002PLN40000,00"},
+			},
+			expectedError: fmt.Errorf("decoding failed for field Balance: multiple options present for tag 62a: 62F, 62M"),
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var actual optioned
+			err := mt.UnmarshalMT(test.fields, &actual)
+			mttest.ValidateError(t, test.expectedError, err)
+
+			if test.expectedError == nil && !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, actual)
+			}
+		})
+	}
+}
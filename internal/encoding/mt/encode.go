@@ -0,0 +1,194 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type MTMarshaler interface {
+	MarshalMT() (string, error)
+}
+
+// MTOptioner is implemented by types that know the option letter they are to be encoded under, e.g. the one recorded
+// when decoding them through MTTagUnmarshaler. For a tag with the option letter left open, e.g. 62a, it completes the
+// tag, e.g. to 62M.
+type MTOptioner interface {
+	MTOption() string
+}
+
+func toOptioner(rval reflect.Value) (MTOptioner, bool) {
+	if !rval.CanInterface() {
+		return nil, false
+	}
+
+	o, ok := rval.Interface().(MTOptioner)
+
+	return o, ok
+}
+
+// optionTag completes the tag with the option letter left open, e.g. 62a, with the option letter of the value, e.g. to
+// 62M.
+func optionTag(tag string, rval reflect.Value) (string, error) {
+	o, ok := toOptioner(rval)
+	if !ok || o.MTOption() == "" {
+		return "", fmt.Errorf("option letter is not known")
+	}
+
+	return tag[:len(tag)-1] + o.MTOption(), nil
+}
+
+func toMarshaler(rval reflect.Value) (MTMarshaler, bool) {
+	switch {
+	case !rval.CanInterface():
+		return nil, false
+	case (rval.Kind() == reflect.Ptr || rval.Kind() == reflect.Interface) && rval.IsNil():
+		return nil, false
+	default:
+		m, ok := rval.Interface().(MTMarshaler)
+		if ok {
+			return m, true
+		}
+
+		if !rval.CanAddr() {
+			return nil, false
+		}
+
+		m, ok = rval.Addr().Interface().(MTMarshaler)
+
+		return m, ok
+	}
+}
+
+func useMarshaler(rval reflect.Value) (string, error) {
+	m, _ := toMarshaler(rval)
+
+	val, err := m.MarshalMT()
+	if err != nil {
+		return "", fmt.Errorf("encoding failed: %w", err)
+	}
+
+	return val, nil
+}
+
+func marshalSlice(itemName string, rval reflect.Value) ([]string, error) {
+	vals := make([]string, 0, rval.Len())
+
+	for i := 0; i < rval.Len(); i++ {
+		val, err := marshalItem(itemName, rval.Index(i))
+		if err != nil {
+			return nil, fmt.Errorf("encoding failed for %s[%d]: %w", itemName, i, err)
+		}
+
+		vals = append(vals, val...)
+	}
+
+	return vals, nil
+}
+
+func marshalItem(itemName string, rval reflect.Value) ([]string, error) {
+	if _, ok := toMarshaler(rval); ok {
+		val, err := useMarshaler(rval)
+		if err != nil {
+			return nil, err
+		}
+
+		return []string{val}, nil
+	}
+
+	switch rval.Kind() {
+	case reflect.Bool:
+		return []string{strconv.FormatBool(rval.Bool())}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return []string{strconv.FormatInt(rval.Int(), 10)}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return []string{strconv.FormatUint(rval.Uint(), 10)}, nil
+	case reflect.Float32:
+		return []string{strconv.FormatFloat(rval.Float(), 'f', -1, 32)}, nil
+	case reflect.Float64:
+		return []string{strconv.FormatFloat(rval.Float(), 'f', -1, 64)}, nil
+	case reflect.Slice:
+		return marshalSlice(itemName, rval)
+	case reflect.String:
+		return []string{rval.String()}, nil
+	case reflect.Ptr, reflect.Interface:
+		if rval.IsNil() {
+			return nil, nil
+		}
+
+		return marshalItem(itemName, rval.Elem())
+	default:
+		return nil, fmt.Errorf("encoding failed: unsupported type: %v", rval.Type())
+	}
+}
+
+// MarshalMT is the inverse of UnmarshalMT, it builds the fields of a body from the struct, or pointer to a struct, v.
+// The fields are keyed by the tags in the mt struct tags. Every element of a slice results in a separate value for the
+// tag, in the order of the slice, allowing for repeated tags. Fields holding their zero value are left out, as are nil
+// pointers, as these are indistinguishable from absent optional fields. Tags with the option letter left open, e.g.
+// 62a, are completed with the option letter of the value, which must implement MTOptioner.
+func MarshalMT(v interface{}) (map[string][]string, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("not a non-nil pointer: %s", reflect.TypeOf(v))
+		}
+
+		rv = rv.Elem()
+	}
+
+	rt := rv.Type()
+	if rt.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("not a struct or pointer to a struct: %s", reflect.TypeOf(v))
+	}
+
+	// work on an addressable copy, so marshalers with a pointer receiver are found as well
+	addressable := reflect.New(rt).Elem()
+	addressable.Set(rv)
+	rv = addressable
+
+	fields := make(map[string][]string)
+
+	for i := 0; i < rv.NumField(); i++ {
+		fv := rv.Field(i)
+		sf := rt.Field(i)
+
+		structTag, ok := sf.Tag.Lookup("mt")
+		if !ok || structTag == "" {
+			continue
+		}
+
+		if fv.IsZero() {
+			continue
+		}
+
+		tagSplit := strings.Split(structTag, ",")
+		tag := tagSplit[0]
+
+		if isOptionWildcard(tag) {
+			var err error
+			tag, err = optionTag(tag, fv)
+			if err != nil {
+				return nil, fmt.Errorf("encoding failed for tag %s, field %s: %w", tagSplit[0], sf.Name, err)
+			}
+		}
+
+		vals, err := marshalItem(sf.Name, fv)
+		if err != nil {
+			return nil, fmt.Errorf("encoding failed for tag %s, field %s: %w", tag, sf.Name, err)
+		}
+		if len(vals) == 0 {
+			continue
+		}
+
+		fields[tag] = append(fields[tag], vals...)
+	}
+
+	return fields, nil
+}
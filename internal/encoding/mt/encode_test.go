@@ -0,0 +1,269 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/DennisVis/mt/internal/encoding/mt"
+	mttest "github.com/DennisVis/mt/testdata"
+)
+
+type MTMarshaler interface {
+	MarshalMT() (string, error)
+}
+
+var errMarshalFail = fmt.Errorf("marshal fail")
+
+type testMarshalSubStruct struct {
+	value string
+}
+
+func (tss testMarshalSubStruct) MarshalMT() (string, error) {
+	return "sub:" + tss.value, nil
+}
+
+type testMarshalPtrSubStruct struct {
+	value string
+}
+
+func (tss *testMarshalPtrSubStruct) MarshalMT() (string, error) {
+	return "ptr:" + tss.value, nil
+}
+
+type testMarshalSubStructInvalid struct{}
+
+func (tss testMarshalSubStructInvalid) MarshalMT() (string, error) {
+	return "", errMarshalFail
+}
+
+type testMarshalOptioned struct {
+	option string
+	value  string
+}
+
+func (tmo testMarshalOptioned) MarshalMT() (string, error) {
+	return tmo.value, nil
+}
+
+func (tmo testMarshalOptioned) MTOption() string {
+	return tmo.option
+}
+
+type testMarshalStruct struct {
+	SubField       MTMarshaler             `mt:"1"`
+	BoolField      bool                    `mt:"2"`
+	IntField       int                     `mt:"3"`
+	Int8Field      int8                    `mt:"4"`
+	Int16Field     int16                   `mt:"5"`
+	Int32Field     int32                   `mt:"6"`
+	Int64Field     int64                   `mt:"7"`
+	UintField      uint                    `mt:"8"`
+	Uint8Field     uint8                   `mt:"9"`
+	Uint16Field    uint16                  `mt:"10"`
+	Uint32Field    uint32                  `mt:"11"`
+	Uint64Field    uint64                  `mt:"12"`
+	Float32Field   float32                 `mt:"13"`
+	Float64Field   float64                 `mt:"14"`
+	SliceField     []string                `mt:"15"`
+	SliceSubField  []testMarshalSubStruct  `mt:"16"`
+	StringField    string                  `mt:"17"`
+	StringPtrField *string                 `mt:"18"`
+	PtrSubField    testMarshalPtrSubStruct `mt:"19"`
+	NoTagField     string
+}
+
+func TestMarshalMT(t *testing.T) {
+	str := "1"
+
+	for _, test := range []struct {
+		name           string
+		input          interface{}
+		expectedFields map[string][]string
+		expectedError  error
+	}{
+		{
+			name:          "NotAStruct",
+			input:         "1",
+			expectedError: fmt.Errorf("not a struct or pointer to a struct"),
+		},
+		{
+			name:          "NotANonNilPointer",
+			input:         (*testMarshalStruct)(nil),
+			expectedError: fmt.Errorf("not a non-nil pointer"),
+		},
+		{
+			name:           "ZeroValuesLeftOut",
+			input:          testMarshalStruct{NoTagField: "ignored"},
+			expectedFields: map[string][]string{},
+		},
+		{
+			name: "UnsupportedType",
+			input: struct {
+				Field map[string]string `mt:"1"`
+			}{
+				Field: map[string]string{"a": "b"},
+			},
+			expectedError: fmt.Errorf("encoding failed for tag 1, field Field: encoding failed: unsupported type"),
+		},
+		{
+			name: "OptionWildcard",
+			input: struct {
+				Balance string `mt:"62a,M,dive"`
+			}{
+				Balance: "C031002PLN40000,00",
+			},
+			expectedError: fmt.Errorf("encoding failed for tag 62a, field Balance: option letter is not known"),
+		},
+		{
+			name: "OptionWildcardEmptyOption",
+			input: struct {
+				Balance testMarshalOptioned `mt:"62a,M,dive"`
+			}{
+				Balance: testMarshalOptioned{value: "C031002PLN40000,00"},
+			},
+			expectedError: fmt.Errorf("encoding failed for tag 62a, field Balance: option letter is not known"),
+		},
+		{
+			name: "OptionWildcardOptioned",
+			input: struct {
+				Opening testMarshalOptioned `mt:"60a,M,dive"`
+				Closing testMarshalOptioned `mt:"62a,M,dive"`
+			}{
+				Opening: testMarshalOptioned{option: "F", value: "C031002PLN40000,00"},
+				Closing: testMarshalOptioned{option: "M", value: "C031020PLN50000,00"},
+			},
+			expectedFields: map[string][]string{
+				"60F": {"C031002PLN40000,00"},
+				"62M": {"C031020PLN50000,00"},
+			},
+		},
+		{
+			name: "SubFieldInvalid",
+			input: testMarshalStruct{
+				SubField: testMarshalSubStructInvalid{},
+			},
+			expectedError: errMarshalFail,
+		},
+		{
+			name: "SliceSubFieldInvalid",
+			input: struct {
+				Field []MTMarshaler `mt:"1"`
+			}{
+				Field: []MTMarshaler{testMarshalSubStruct{value: "a"}, testMarshalSubStructInvalid{}},
+			},
+			expectedError: fmt.Errorf("encoding failed for Field[1]: encoding failed: marshal fail"),
+		},
+		{
+			name: "RepeatedTags",
+			input: struct {
+				Lines       []testMarshalSubStruct `mt:"61,O,dive"`
+				Information []string               `mt:"86,O,6*65x"`
+				Closing     string                 `mt:"86,O,6*65x"`
+			}{
+				Lines:       []testMarshalSubStruct{{value: "a"}, {value: "b"}},
+				Information: []string{"info1", "info2"},
+				Closing:     "info3",
+			},
+			expectedFields: map[string][]string{
+				"61": {"sub:a", "sub:b"},
+				"86": {"info1", "info2", "info3"},
+			},
+		},
+		{
+			name: "AllFieldsValid",
+			input: &testMarshalStruct{
+				SubField:       testMarshalSubStruct{value: "test"},
+				BoolField:      true,
+				IntField:       1,
+				Int8Field:      1,
+				Int16Field:     1,
+				Int32Field:     1,
+				Int64Field:     1,
+				UintField:      1,
+				Uint8Field:     1,
+				Uint16Field:    1,
+				Uint32Field:    1,
+				Uint64Field:    1,
+				Float32Field:   1.1,
+				Float64Field:   2.2,
+				SliceField:     []string{"test1", "test2"},
+				SliceSubField:  []testMarshalSubStruct{{value: "test1"}, {value: "test2"}},
+				StringField:    "1",
+				StringPtrField: &str,
+				PtrSubField:    testMarshalPtrSubStruct{value: "test"},
+			},
+			expectedFields: map[string][]string{
+				"1":  {"sub:test"},
+				"2":  {"true"},
+				"3":  {"1"},
+				"4":  {"1"},
+				"5":  {"1"},
+				"6":  {"1"},
+				"7":  {"1"},
+				"8":  {"1"},
+				"9":  {"1"},
+				"10": {"1"},
+				"11": {"1"},
+				"12": {"1"},
+				"13": {"1.1"},
+				"14": {"2.2"},
+				"15": {"test1", "test2"},
+				"16": {"sub:test1", "sub:test2"},
+				"17": {"1"},
+				"18": {"1"},
+				"19": {"ptr:test"},
+			},
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			fields, err := mt.MarshalMT(test.input)
+			mttest.ValidateError(t, test.expectedError, err)
+
+			if test.expectedError == nil && !reflect.DeepEqual(fields, test.expectedFields) {
+				t.Errorf("expected fields %v, got %v", test.expectedFields, fields)
+			}
+		})
+	}
+}
+
+func TestMarshalUnmarshalMT(t *testing.T) {
+	t.Parallel()
+
+	type roundTrip struct {
+		Reference string   `mt:"20,M,16x"`
+		Number    int      `mt:"28C"`
+		Lines     []string `mt:"86,O,6*65x"`
+	}
+
+	expected := roundTrip{
+		Reference: "REFERENCE",
+		Number:    84,
+		Lines:     []string{"LINE 1", "LINE 2"},
+	}
+
+	fields, err := mt.MarshalMT(expected)
+	if err != nil {
+		t.Fatalf("expected nil error, got: %v", err)
+	}
+
+	var actual roundTrip
+	err = mt.UnmarshalMT(fields, &actual)
+	if err != nil {
+		t.Fatalf("expected nil error, got: %v", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
@@ -6,12 +6,12 @@
 package message
 
 import (
-	"bufio"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
-	"strings"
+	"unicode/utf8"
 )
 
 // itemType identifies the type of items the message lexer can produce.
@@ -38,15 +38,21 @@ const (
 )
 
 var (
-	blockLeftMeta     = "{"
-	blockLabelMeta    = ":"
-	blockRightMeta    = "}"
-	subBlockLeftMeta  = "{"
-	subBlockLabelMeta = ":"
-	subBlockRightMeta = "}"
-	tagLeftMeta       = ":"
-	tagRightMeta      = ":"
-	fieldsRightMeta   = "-}"
+	blockLeftMeta      = "{"
+	blockLabelMeta     = ":"
+	blockRightMeta     = "}"
+	subBlockLeftMeta   = "{"
+	subBlockLabelMeta  = ":"
+	subBlockRightMeta  = "}"
+	tagLeftMeta        = ":"
+	tagRightMeta       = ":"
+	fieldTagLeftMeta   = "\n:"
+	fieldTagLeftMetaCR = "\r:"
+	fieldsRightMeta    = "-}"
+	// fieldsContinuedRightMeta ends a body that is continued in a subsequent transmission, rather than the hyphen
+	// the continuation indicator precedes the closing brace
+	continuationIndicator    = "+"
+	fieldsContinuedRightMeta = continuationIndicator + blockRightMeta
 )
 
 const eof = -1
@@ -55,54 +61,92 @@ type item struct {
 	typ  itemType // The type of this item.
 	val  string   // The value of this item.
 	line int      // The line number at the start of this item.
+	pos  int      // The byte offset of the start of this item within the input.
 }
 
 // lexer holds the state of the scanner.
 type lexer struct {
-	ctx   context.Context
-	input *bufio.Reader // the bytes being scanned
-	buff  string        // the buffer used for storing read bytes from input
-	items chan item     // channel of scanned items
-	line  int           // start line of the current item
+	ctx     context.Context
+	input   io.RuneReader // the runes being scanned
+	buff    []byte        // the buffer used for storing read bytes from input
+	items   chan item     // channel of scanned items, only used when lexing concurrently
+	deliver func(item)    // passes scanned items to the client
+	line    int           // start line of the current item
+	start   int           // byte offset of the start of the current item within the input
+	depth   int           // number of sub blocks the current item is nested in
+	label   string        // label of the block being lexed
+	// skipBody has the content of the body, block 4, lexed as a whole rather than as fields or sub blocks
+	skipBody bool
 }
 
 // stateFn represents the state of the scanner as a function that returns the next state.
 type stateFn func() stateFn
 
-func newLexer(ctx context.Context, input *bufio.Reader) *lexer {
+// newLexer creates a lexer that runs in its own goroutine and delivers the scanned items on its items channel.
+func newLexer(ctx context.Context, input io.RuneReader, skipBody bool) *lexer {
 	l := &lexer{
-		ctx:   ctx,
-		input: input,
-		items: make(chan item),
-		line:  1,
+		ctx:      ctx,
+		input:    input,
+		items:    make(chan item),
+		line:     1,
+		skipBody: skipBody,
 	}
 
-	go l.run()
+	l.deliver = func(i item) {
+		// stop delivering items once the context is done, the receiving end might have stopped listening
+		select {
+		case l.items <- i:
+		case <-l.ctx.Done():
+		}
+	}
+
+	go func() {
+		l.run()
+		close(l.items) // No more tokens will be delivered.
+	}()
 
 	return l
 }
 
+// newSyncLexer creates a lexer that delivers the scanned items by calling deliver from within run, which is to be
+// called by the client. Lexing stops early once the context is done.
+func newSyncLexer(ctx context.Context, input io.RuneReader, skipBody bool, deliver func(item)) *lexer {
+	return &lexer{
+		ctx:      ctx,
+		input:    input,
+		deliver:  deliver,
+		line:     1,
+		skipBody: skipBody,
+	}
+}
+
 // emit passes an item back to the client.
 func (l *lexer) emit(t itemType) {
-	i := item{
-		typ:  t,
-		val:  l.buff,
-		line: l.line,
+	if t == itemBlockLabel {
+		l.label = string(l.buff)
 	}
 
-	l.items <- i
+	l.deliver(item{
+		typ:  t,
+		val:  string(l.buff),
+		line: l.line,
+		pos:  l.start,
+	})
 
-	l.buff = ""
+	// the backing array is reused, the emitted value is a copy
+	l.start += len(l.buff)
+	l.buff = l.buff[:0]
 }
 
 // errorf returns an error token and terminates the scan by passing back a nil pointer that will be the next state,
 // terminating l.nextItem.
 func (l *lexer) errorf(format string, args ...interface{}) stateFn {
-	l.items <- item{
+	l.deliver(item{
 		typ:  itemError,
 		val:  fmt.Sprintf(format, args...),
 		line: l.line,
-	}
+		pos:  l.start,
+	})
 	return nil
 }
 
@@ -117,7 +161,8 @@ func (l *lexer) next() rune {
 		return eof
 	}
 
-	l.buff += string(r)
+	var encoded [utf8.UTFMax]byte
+	l.buff = append(l.buff, encoded[:utf8.EncodeRune(encoded[:], r)]...)
 
 	if r == '\n' {
 		l.line++
@@ -129,10 +174,10 @@ func (l *lexer) next() rune {
 func (l *lexer) lexText(typ itemType, next map[string]stateFn) stateFn {
 	for {
 		for suffix, nextStateFn := range next {
-			if strings.HasSuffix(l.buff, suffix) {
+			if bytes.HasSuffix(l.buff, []byte(suffix)) {
 				l.buff = l.buff[:len(l.buff)-len(suffix)]
 				l.emit(typ)
-				l.buff = suffix
+				l.buff = append(l.buff[:0], suffix...)
 				return nextStateFn
 			}
 		}
@@ -161,10 +206,13 @@ func (l *lexer) lexMeta(
 
 func (l *lexer) lexFieldContent() stateFn {
 	return l.lexText(itemFieldContent, map[string]stateFn{
-		// stop when we find a new tag and start parsing that
-		tagLeftMeta: l.lexTagLeftMeta,
+		// stop when we find a new tag and start parsing that, tags only start at the beginning of a line so colons
+		// within the content of a field are left alone, lines may be terminated by a lone carriage return as well
+		fieldTagLeftMeta:   l.lexTagLeftMeta,
+		fieldTagLeftMetaCR: l.lexTagLeftMeta,
 		// also stop when we find the end of the fields, we'll finish parsing of the block in that case
-		fieldsRightMeta: l.lexBlockContent,
+		fieldsRightMeta:          l.lexBlockContent,
+		fieldsContinuedRightMeta: l.lexBlockContent,
 	})
 }
 
@@ -191,10 +239,19 @@ func (l *lexer) lexTagLeftMeta() stateFn {
 }
 
 func (l *lexer) lexSubBlockRightMeta() stateFn {
+	l.depth--
+
+	// we've reached the end of the sub block, we can now return to lexing the sub block it's nested in, if any, or the
+	// block
+	next := l.lexBlockContent
+	if l.depth > 0 {
+		next = l.lexSubBlockContent
+	}
+
 	return l.lexMeta(
 		itemSubBlockRightMeta,
 		subBlockRightMeta,
-		l.lexBlockContent, // we've reached the end of the sub block, we can now return to lexing the block
+		next,
 	)
 }
 
@@ -210,6 +267,8 @@ func (l *lexer) lexSubBlockContent() stateFn {
 	return l.lexText(itemSubBlockContent, map[string]stateFn{
 		// we've reached the end of the sub block, we can now return to lexing the block
 		subBlockRightMeta: l.lexSubBlockRightMeta,
+		// a sub block can contain further sub blocks, if it does we start parsing the nested one
+		subBlockLeftMeta: l.lexSubBlockLeftMeta,
 	})
 }
 
@@ -228,6 +287,8 @@ func (l *lexer) lexSubBlockLabel() stateFn {
 }
 
 func (l *lexer) lexSubBlockLeftMeta() stateFn {
+	l.depth++
+
 	return l.lexMeta(
 		itemSubBlockLeftMeta,
 		subBlockLeftMeta,
@@ -240,19 +301,70 @@ func (l *lexer) lexBlockContent() stateFn {
 		blockRightMeta: l.lexBlockRightMeta,
 		// a block can contain a sub-block, if it does we start parsing it
 		subBlockLeftMeta: l.lexSubBlockLeftMeta,
-		// a block can contain a tag, if it does we start parsing it
-		tagLeftMeta: l.lexTagLeftMeta,
+		// a block can contain a tag, if it does we start parsing it, like within fields tags only start at the beginning
+		// of a line
+		fieldTagLeftMeta:   l.lexTagLeftMeta,
+		fieldTagLeftMetaCR: l.lexTagLeftMeta,
 	})
 }
 
+// lexBlockStart lexes the start of the content of a block, which is also the beginning of a line to a tag directly
+// following the block label, e.g. {4::20:REFERENCE.
+func (l *lexer) lexBlockStart() stateFn {
+	if l.next() != ':' {
+		return l.lexBlockContent
+	}
+
+	l.buff = l.buff[:0]
+	l.emit(itemBlockContent)
+	l.buff = append(l.buff, tagLeftMeta...)
+
+	return l.lexTagLeftMeta
+}
+
 func (l *lexer) lexBlockLabelMeta() stateFn {
+	next := l.lexBlockStart
+	if l.skipBody && l.label == blockLabelBody {
+		next = l.lexSkippedBlockContent
+	}
+
 	return l.lexMeta(
 		itemBlockLabelMeta,
 		blockLabelMeta,
-		l.lexBlockContent,
+		next,
 	)
 }
 
+// lexSkippedBlockContent lexes the content of a block as a whole, without looking for fields or sub blocks, up to the
+// closing brace of the block. Braces of any sub blocks are balanced, so the closing brace of a sub block isn't taken
+// for that of the block.
+func (l *lexer) lexSkippedBlockContent() stateFn {
+	depth := 0
+
+	for {
+		switch l.next() {
+		case eof:
+			l.emit(itemBlockContent)
+			l.emit(itemEOF)
+
+			return nil
+		case '{':
+			depth++
+		case '}':
+			if depth > 0 {
+				depth--
+				continue
+			}
+
+			l.buff = l.buff[:len(l.buff)-len(blockRightMeta)]
+			l.emit(itemBlockContent)
+			l.buff = append(l.buff[:0], blockRightMeta...)
+
+			return l.lexBlockRightMeta
+		}
+	}
+}
+
 func (l *lexer) lexBlockLabel() stateFn {
 	return l.lexText(itemBlockLabel, map[string]stateFn{
 		blockLabelMeta: l.lexBlockLabelMeta,
@@ -281,6 +393,7 @@ Loop:
 	for {
 		select {
 		case <-l.ctx.Done():
+			break Loop
 		default:
 			state = state()
 			if state == nil {
@@ -288,6 +401,4 @@ Loop:
 			}
 		}
 	}
-
-	close(l.items) // No more tokens will be delivered.
 }
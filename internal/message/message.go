@@ -7,27 +7,51 @@ package message
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"fmt"
 	"io"
+	"strings"
 )
 
 type Config struct {
 	StopOnError bool
+	// PreserveWhitespace stores the content of fields verbatim, only the line break separating a field from the next
+	// is removed. By default any surrounding whitespace is trimmed.
+	PreserveWhitespace bool
+	// MaxFieldsPerMessage limits the number of fields a message may hold, a message exceeding it is discarded. Zero
+	// means unbounded.
+	MaxFieldsPerMessage int
+	// ReportSkipped reports any text outside of blocks that is skipped, other than whitespace, as a warning.
+	ReportSkipped bool
+	// OnSkipped is called with the verbatim text outside of blocks that is skipped, unless it is only whitespace, and
+	// the line it starts on.
+	OnSkipped func(content string, line int)
+	// SkipBody leaves the fields of the body, block 4, unparsed. Its content is retained verbatim only. The fields are
+	// still counted, for MaxFieldsPerMessage.
+	SkipBody bool
 }
 
 type Message struct {
 	Line        int
 	Raw         string
+	RawBody     string
 	BasicHeader Block
 	AppHeader   Block
 	UsrHeader   Block
 	Body        map[string][]string
+	BodyOrder   []string
 	Trailers    Block
+	// Continued is set when the body ends with the continuation indicator, +}, rather than -}, i.e. the remainder of
+	// the body follows in a subsequent transmission.
+	Continued bool
 }
 
 type Error struct {
 	Line int
 	Err  error
+	// Warning is set for errors that are merely reported, they never stop the parsing process.
+	Warning bool
 }
 
 func (err Error) String() string {
@@ -39,7 +63,214 @@ func (err Error) Error() string {
 }
 
 func Parse(ctx context.Context, rd io.Reader, cfg Config) (chan Message, chan Error) {
-	lexer := newLexer(ctx, bufio.NewReader(rd))
-	parser := newParser(cfg, lexer)
+	lexer := newLexer(ctx, bufio.NewReader(rd), cfg.SkipBody)
+	parser := newParser(ctx, cfg, lexer)
 	return parser.messages, parser.errors
 }
+
+// ParseBytes parses the messages in b synchronously, within the calling goroutine. Each message is passed to
+// onMessage as soon as it is complete, parsing stops early when onMessage returns false. Each error is passed to
+// onError.
+//
+// As opposed to Parse no goroutines and channels are involved, which makes this the faster option for inputs that are
+// already held in memory.
+func ParseBytes(b []byte, cfg Config, onMessage func(Message) bool, onError func(Error)) {
+	parseSync(context.Background(), bytes.NewReader(b), cfg, onMessage, onError)
+}
+
+// ParseReader parses the messages read from rd synchronously, within the calling goroutine, like ParseBytes does for
+// inputs held in memory. Parsing also stops once the context is done.
+func ParseReader(ctx context.Context, rd io.Reader, cfg Config, onMessage func(Message) bool, onError func(Error)) {
+	parseSync(ctx, bufio.NewReader(rd), cfg, onMessage, onError)
+}
+
+func parseSync(
+	ctx context.Context,
+	rd io.RuneReader,
+	cfg Config,
+	onMessage func(Message) bool,
+	onError func(Error),
+) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := false
+
+	bldr := newBuilder(
+		cfg,
+		func(msg Message) {
+			if !done && !onMessage(msg) {
+				done = true
+			}
+		},
+		func(err Error) {
+			if !done {
+				onError(err)
+			}
+		},
+	)
+
+	lexer := newSyncLexer(ctx, rd, cfg.SkipBody, func(i item) {
+		if !done && bldr.handle(i) {
+			done = true
+		}
+		if done {
+			cancel()
+		}
+	})
+
+	lexer.run()
+}
+
+// ParseBody parses the fields of a body from its verbatim content, as retained in RawBody, e.g. of a message parsed
+// with SkipBody. The fields and their order are the ones Parse produces for the body.
+func ParseBody(rawBody string, preserveWhitespace bool) (map[string][]string, []string) {
+	var msg Message
+
+	ParseBytes(
+		[]byte(blockLeftMeta+blockLabelBody+blockLabelMeta+rawBody+fieldsRightMeta),
+		Config{PreserveWhitespace: preserveWhitespace},
+		func(m Message) bool {
+			msg = m
+			return false
+		},
+		func(Error) {},
+	)
+
+	return msg.Body, msg.BodyOrder
+}
+
+// BodyField returns the values of the field with the given tag from the verbatim content of a body, as retained in
+// RawBody, without parsing any of the other fields. The values are the ones Parse produces for the field. Ok is false
+// for a body consisting of sub blocks rather than fields, e.g. that of a system message, ParseBody handles those.
+func BodyField(rawBody, tag string, preserveWhitespace bool) (values []string, ok bool) {
+	if strings.HasPrefix(strings.TrimSpace(rawBody), subBlockLeftMeta) {
+		return nil, false
+	}
+
+	// a field starts with a tag at the beginning of a line, or right at the start of the body
+	start := nextFieldStart(rawBody, 0)
+	if strings.HasPrefix(rawBody, tagLeftMeta) {
+		start = 0
+	}
+
+	for start >= 0 {
+		tagEnd := strings.Index(rawBody[start+len(tagLeftMeta):], tagRightMeta)
+		if tagEnd < 0 {
+			break
+		}
+
+		contentStart := start + len(tagLeftMeta) + tagEnd + len(tagRightMeta)
+		next := nextFieldStart(rawBody, contentStart)
+
+		if rawBody[start+len(tagLeftMeta):contentStart-len(tagRightMeta)] == tag {
+			contentEnd := len(rawBody)
+			if next >= 0 {
+				// the line break preceding the next tag is not part of the content
+				contentEnd = next - 1
+			}
+
+			values = append(values, fieldValue(rawBody[contentStart:contentEnd], preserveWhitespace))
+		}
+
+		start = next
+	}
+
+	return values, true
+}
+
+// countBodyFields returns the number of fields in the verbatim content of a body, the fields being found the way
+// BodyField finds them. A body consisting of sub blocks holds no fields.
+func countBodyFields(rawBody string) int {
+	if strings.HasPrefix(strings.TrimSpace(rawBody), subBlockLeftMeta) {
+		return 0
+	}
+
+	count := 0
+
+	start := nextFieldStart(rawBody, 0)
+	if strings.HasPrefix(rawBody, tagLeftMeta) {
+		start = 0
+	}
+
+	for start >= 0 {
+		count++
+		start = nextFieldStart(rawBody, start+len(tagLeftMeta))
+	}
+
+	return count
+}
+
+// nextFieldStart returns the offset of the colon starting the first tag in s at or after offset from, -1 if there is
+// none. Like the lexer does, lines may be terminated by a lone carriage return as well.
+func nextFieldStart(s string, from int) int {
+	idx := strings.Index(s[from:], fieldTagLeftMeta)
+	if idxCR := strings.Index(s[from:], fieldTagLeftMetaCR); idxCR >= 0 && (idx < 0 || idxCR < idx) {
+		idx = idxCR
+	}
+
+	if idx < 0 {
+		return -1
+	}
+
+	return from + idx + 1
+}
+
+// Split splits the input into the raw text of the messages it contains, without parsing their structure. Messages are
+// delimited the same way Parse delimits them: a message starts at its first block, a basic header block starts a new
+// message and a message ends with its last complete block. Any text outside of the messages is left out.
+func Split(rd io.Reader) ([]string, []Error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msgs := make([]string, 0)
+	errs := make([]Error, 0)
+
+	buff := &strings.Builder{}
+	msgStart := -1 // offset of the start of the current message in buff, -1 while outside of a message
+	msgEnd := -1   // offset of the end of the last complete block of the current message in buff
+	blockStart := 0
+	line := 1
+
+	flush := func() {
+		if msgStart >= 0 && msgEnd > msgStart {
+			msgs = append(msgs, buff.String()[msgStart:msgEnd])
+		}
+	}
+
+	lexer := newSyncLexer(ctx, bufio.NewReader(rd), false, func(i item) {
+		switch i.typ {
+		case itemBlockLeftMeta:
+			blockStart = buff.Len()
+		case itemBlockLabel:
+			if msgStart < 0 || i.val == blockLabelBasicHeader {
+				flush()
+
+				// only keep what's needed for the new message
+				rest := buff.String()[blockStart:]
+				buff.Reset()
+				buff.WriteString(rest)
+
+				msgStart = 0
+				msgEnd = -1
+				blockStart = 0
+				line = i.line
+			}
+		case itemError:
+			errs = append(errs, Error{Err: fmt.Errorf(i.val), Line: line})
+			return
+		}
+
+		buff.WriteString(i.val)
+
+		if i.typ == itemBlockRightMeta {
+			msgEnd = buff.Len()
+		}
+	})
+
+	lexer.run()
+
+	flush()
+
+	return msgs, errs
+}
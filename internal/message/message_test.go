@@ -9,6 +9,8 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"reflect"
+	"runtime"
 	"strings"
 	"sync"
 	"testing"
@@ -85,6 +87,7 @@ func validateSubBlock(t *testing.T, name string, expected message.SubBlock, actu
 		if expected.Content != "" && expected.Content != actual.Content {
 			t.Errorf("expected content %q, got %q", expected.Content, actual.Content)
 		}
+		validateSubBlocks(t, "Blocks", expected.Blocks, actual.Blocks)
 	})
 }
 
@@ -134,6 +137,7 @@ func TestParse(t *testing.T) {
 		expectedAppHeader   *message.Block
 		expectedUsrHeader   *message.Block
 		expectedBody        *map[string][]string
+		expectedBodyOrder   []string
 		expectedTrailers    *message.Block
 	}{
 		{
@@ -155,6 +159,48 @@ func TestParse(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:  "TruncatedBody",
+			input: strings.NewReader("{1:F01SCBLZAJJXXXX5712100002}{4:\n:20:Test1\n:21:Te"),
+			expectedErrors: []message.Error{
+				{
+					Err:  fmt.Errorf("incomplete message: block 4 is not terminated"),
+					Line: 1,
+				},
+			},
+		},
+		{
+			name:  "TruncatedHeader",
+			input: strings.NewReader("{1:F01SCBLZAJJXXXX5712100002}{2:O9401157"),
+			expectedErrors: []message.Error{
+				{
+					Err:  fmt.Errorf("incomplete message: block 2 is not terminated"),
+					Line: 1,
+				},
+			},
+		},
+		{
+			name:  "TooManyFields",
+			cfg:   message.Config{MaxFieldsPerMessage: 1},
+			input: strings.NewReader("{1:F01SCBLZAJJXXXX5712100002}{4:\n:20:Test1\n:21:Test2\n:25:Test3\n-}"),
+			expectedErrors: []message.Error{
+				{
+					Err:  fmt.Errorf("message exceeds the maximum of 1 fields"),
+					Line: 1,
+				},
+			},
+		},
+		{
+			name:  "TooManyFieldsSkipBody",
+			cfg:   message.Config{MaxFieldsPerMessage: 2, SkipBody: true},
+			input: strings.NewReader("{1:F01SCBLZAJJXXXX5712100002}{4:\n:20:Test1\n:21:Test2\n:25:Test3\n-}"),
+			expectedErrors: []message.Error{
+				{
+					Err:  fmt.Errorf("message exceeds the maximum of 2 fields"),
+					Line: 1,
+				},
+			},
+		},
 		{
 			name:          "BasicHeader",
 			input:         strings.NewReader(`{1:F01SCBLZAJJXXXX5712100002}`),
@@ -182,6 +228,37 @@ func TestParse(t *testing.T) {
 				Content: `O9401157091028SCBLZAJJXXXX57121000020910281157N`,
 			},
 		},
+		{
+			name:          "UsrHeaderNestedSubBlocks",
+			input:         strings.NewReader(`{3:{108:MYREF}{ABC:pre{DEF:{GHI:value}post}}}`),
+			expectMessage: true,
+			expectedUsrHeader: &message.Block{
+				Label:   "3",
+				Content: "",
+				Blocks: []message.SubBlock{
+					{
+						Label:   "108",
+						Content: "MYREF",
+					},
+					{
+						Label:   "ABC",
+						Content: "pre{DEF:{GHI:value}post}",
+						Blocks: []message.SubBlock{
+							{
+								Label:   "DEF",
+								Content: "{GHI:value}post",
+								Blocks: []message.SubBlock{
+									{
+										Label:   "GHI",
+										Content: "value",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name:          "TrailersAllCorrect",
 			input:         strings.NewReader(`{5:{CHK:my checksum}{TNG:}{PDE:1348120811BANKFRPPAXXX2222123456}{DLM:}{MRF:1806271539180626BANKFRPPAXXX2222123456}{PDM:1213120811BANKFRPPAXXX2222123456}{SYS:1454120811BANKFRPPAXXX2222123456}}`),
@@ -235,6 +312,73 @@ func TestParse(t *testing.T) {
 				"20a": {"Test2"},
 				"21":  {"Test3", "Test4"},
 			},
+			expectedBodyOrder: []string{"20", "20a", "21", "21"},
+		},
+		{
+			name: "BodyColonWithinField",
+			input: strings.NewReader(`{4:
+:61:0312091209D4000,FTRFREF:BPHPBK/081203/0001//59512092914002
+:86:082?00Wplata:wlasna
+-}`),
+			expectMessage: true,
+			expectedBody: &map[string][]string{
+				"61": {"0312091209D4000,FTRFREF:BPHPBK/081203/0001//59512092914002"},
+				"86": {"082?00Wplata:wlasna"},
+			},
+			expectedBodyOrder: []string{"61", "86"},
+		},
+		{
+			name:          "BodyColonsInNarrative",
+			input:         strings.NewReader("{4:\n:20:REF\n:86:MEETING AT 12:30:00\nROOM:B12 :NOTE:\n-}"),
+			expectMessage: true,
+			expectedBody: &map[string][]string{
+				"20": {"REF"},
+				"86": {"MEETING AT 12:30:00\nROOM:B12 :NOTE:"},
+			},
+			expectedBodyOrder: []string{"20", "86"},
+		},
+		{
+			name:          "BodyCarriageReturnOnly",
+			input:         strings.NewReader("{4:\r:20:REF\r:86:MEETING AT 12:30\rROOM:B12\r-}"),
+			expectMessage: true,
+			expectedBody: &map[string][]string{
+				"20": {"REF"},
+				"86": {"MEETING AT 12:30\rROOM:B12"},
+			},
+			expectedBodyOrder: []string{"20", "86"},
+		},
+		{
+			name:          "BodyTagAtBlockStart",
+			input:         strings.NewReader("{4::20:REF\n:86:MEETING AT 12:30\n-}"),
+			expectMessage: true,
+			expectedBody: &map[string][]string{
+				"20": {"REF"},
+				"86": {"MEETING AT 12:30"},
+			},
+			expectedBodyOrder: []string{"20", "86"},
+		},
+		{
+			name:          "BodyPreserveWhitespace",
+			cfg:           message.Config{PreserveWhitespace: true},
+			input:         strings.NewReader("{4:\r\n:20: REFERENCE  \r\n:86:NARRATIVE   \n-}"),
+			expectMessage: true,
+			expectedBody: &map[string][]string{
+				"20": {" REFERENCE  "},
+				"86": {"NARRATIVE   "},
+			},
+		},
+		{
+			name: "BodyMultiByteRunes",
+			input: strings.NewReader(`{4:
+:20:ZAŻÓŁĆ GĘŚLĄ JAŹŃ
+:86:€100
+-}`),
+			expectMessage: true,
+			expectedBody: &map[string][]string{
+				"20": {"ZAŻÓŁĆ GĘŚLĄ JAŹŃ"},
+				"86": {"€100"},
+			},
+			expectedBodyOrder: []string{"20", "86"},
 		},
 	} {
 		// rebind to make sure we can run in parallel
@@ -247,6 +391,10 @@ func TestParse(t *testing.T) {
 			msgs, errs := collectAllMessagesAndErrors(msgch, errch)
 			validateErrors(t, test.expectedErrors, errs)
 
+			if !test.expectMessage && len(msgs) > 0 {
+				t.Fatalf("expected no messages, got %d", len(msgs))
+			}
+
 			if test.expectMessage && len(msgs) < 1 {
 				t.Fatalf("expected at least 1 message, got 0")
 			}
@@ -264,6 +412,9 @@ func TestParse(t *testing.T) {
 				if test.expectedBody != nil {
 					validateBody(t, *test.expectedBody, msgs[0].Body)
 				}
+				if test.expectedBodyOrder != nil {
+					mttest.ValidateStringSlice(t, "BodyOrder", test.expectedBodyOrder, msgs[0].BodyOrder)
+				}
 				if test.expectedTrailers != nil {
 					validateBlock(t, "Trailers", *test.expectedTrailers, msgs[0].Trailers)
 				}
@@ -271,3 +422,315 @@ func TestParse(t *testing.T) {
 		})
 	}
 }
+
+func TestParseBytes(t *testing.T) {
+	input := []byte(strings.Repeat("{1:F01BPHKPLPKXXXX0000000000}{4:\n:20:REFERENCE\n-}\n", 5))
+
+	for _, test := range []struct {
+		name          string
+		stopAfter     int
+		expectedCount int
+	}{
+		{
+			name:          "All",
+			expectedCount: 5,
+		},
+		{
+			name:          "StopEarly",
+			stopAfter:     2,
+			expectedCount: 2,
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			msgs := make([]message.Message, 0)
+			errs := make([]message.Error, 0)
+
+			message.ParseBytes(
+				input,
+				message.Config{},
+				func(msg message.Message) bool {
+					msgs = append(msgs, msg)
+					return test.stopAfter == 0 || len(msgs) < test.stopAfter
+				},
+				func(err message.Error) {
+					errs = append(errs, err)
+				},
+			)
+
+			validateErrors(t, nil, errs)
+
+			if len(msgs) != test.expectedCount {
+				t.Fatalf("expected %d messages, got %d", test.expectedCount, len(msgs))
+			}
+
+			for i, msg := range msgs {
+				if expectedLine := i*3 + 1; msg.Line != expectedLine {
+					t.Errorf("message %d: expected line %d, got %d", i, expectedLine, msg.Line)
+				}
+				validateBody(t, map[string][]string{"20": {"REFERENCE"}}, msg.Body)
+			}
+		})
+	}
+}
+
+func TestParseFieldsNotShared(t *testing.T) {
+	t.Parallel()
+
+	input := strings.NewReader(`{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:
+:20:FIRST
+-}{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:
+-}{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:
+:20:THIRD
+-}`)
+
+	msgch, errch := message.Parse(ctx, input, message.Config{})
+	msgs, errs := collectAllMessagesAndErrors(msgch, errch)
+	validateErrors(t, nil, errs)
+
+	if len(msgs) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(msgs))
+	}
+
+	mttest.ValidateStringSliceMap(t, "Body[0]", map[string][]string{"20": {"FIRST"}}, msgs[0].Body)
+	mttest.ValidateStringSliceMap(t, "Body[2]", map[string][]string{"20": {"THIRD"}}, msgs[2].Body)
+
+	// an empty body is still handed out as a map of its own, ready for use by the caller
+	if msgs[1].Body == nil || len(msgs[1].Body) > 0 {
+		t.Errorf("expected empty body to be an empty map, got %#v", msgs[1].Body)
+	}
+
+	for i, msg := range msgs {
+		if len(msg.BasicHeader.Fields) > 0 || len(msg.AppHeader.Fields) > 0 {
+			t.Errorf(
+				"message %d: expected headers without fields, got %v and %v",
+				i,
+				msg.BasicHeader.Fields,
+				msg.AppHeader.Fields,
+			)
+		}
+	}
+}
+
+// repeatReader reads its value count times, so large inputs can be parsed without holding them in memory.
+type repeatReader struct {
+	value string
+	count int
+	rest  string
+}
+
+func (r *repeatReader) Read(p []byte) (int, error) {
+	if r.rest == "" {
+		if r.count == 0 {
+			return 0, io.EOF
+		}
+
+		r.rest = r.value
+		r.count--
+	}
+
+	n := copy(p, r.rest)
+	r.rest = r.rest[n:]
+
+	return n, nil
+}
+
+func TestParseTooManyFieldsBoundedMemory(t *testing.T) {
+	const (
+		fieldCount = 100000
+		maxHeap    = 4 << 20
+	)
+
+	field := ":86:" + strings.Repeat("X", 65) + "\n"
+	input := io.MultiReader(
+		strings.NewReader("{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:\n"),
+		&repeatReader{value: field, count: fieldCount},
+		strings.NewReader("-}{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:\n:20:NEXT\n-}"),
+	)
+
+	// the discarded message is well over the maximum heap size, none of its input is to be retained once its fields
+	// are discarded
+	var heapAlloc uint64
+	msgs := make([]message.Message, 0)
+	errs := make([]message.Error, 0)
+
+	message.ParseReader(
+		ctx,
+		input,
+		message.Config{MaxFieldsPerMessage: 10},
+		func(msg message.Message) bool {
+			var stats runtime.MemStats
+			runtime.GC()
+			runtime.ReadMemStats(&stats)
+			heapAlloc = stats.HeapAlloc
+
+			msgs = append(msgs, msg)
+			return true
+		},
+		func(err message.Error) {
+			errs = append(errs, err)
+		},
+	)
+
+	validateErrors(t, []message.Error{{Err: fmt.Errorf("message exceeds the maximum of 10 fields"), Line: 1}}, errs)
+
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+
+	validateBody(t, map[string][]string{"20": {"NEXT"}}, msgs[0].Body)
+
+	if heapAlloc > maxHeap {
+		t.Errorf("expected at most %d bytes to be allocated on the heap, got %d", maxHeap, heapAlloc)
+	}
+}
+
+func TestParseFieldsOwnedByMessage(t *testing.T) {
+	t.Parallel()
+
+	input := []byte(strings.Repeat(
+		"{1:F01BPHKPLPKXXXX0000000000}{4:\n:20:REFERENCE\n:86:FIRST\n:25:ACCOUNT\n:86:SECOND\n-}",
+		3,
+	))
+
+	msgs := make([]message.Message, 0)
+	message.ParseBytes(
+		input,
+		message.Config{},
+		func(msg message.Message) bool {
+			msgs = append(msgs, msg)
+			return true
+		},
+		func(err message.Error) {
+			t.Errorf("expected no errors, got: %v", err)
+		},
+	)
+
+	if len(msgs) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(msgs))
+	}
+
+	// the fields of a message are its own, changing them affects neither its other fields nor other messages
+	msgs[0].Body["20"] = append(msgs[0].Body["20"], "CHANGED")
+	msgs[0].Body["86"][0] = "CHANGED"
+	msgs[0].BodyOrder[0] = "CHANGED"
+
+	if !reflect.DeepEqual(msgs[0].Body["25"], []string{"ACCOUNT"}) {
+		t.Errorf("message 0: expected field 25 to be left alone, got %q", msgs[0].Body["25"])
+	}
+
+	expected := map[string][]string{"20": {"REFERENCE"}, "25": {"ACCOUNT"}, "86": {"FIRST", "SECOND"}}
+	for i, msg := range msgs[1:] {
+		if !reflect.DeepEqual(msg.Body, expected) {
+			t.Errorf("message %d: expected body %v, got %v", i+1, expected, msg.Body)
+		}
+		if msg.BodyOrder[0] != "20" {
+			t.Errorf("message %d: expected order to start with 20, got %v", i+1, msg.BodyOrder)
+		}
+	}
+}
+
+func TestBodyField(t *testing.T) {
+	for _, test := range []struct {
+		name               string
+		rawBody            string
+		preserveWhitespace bool
+	}{
+		{
+			name:    "Fields",
+			rawBody: "\n:20:REFERENCE\n:86:LINE 1\nLINE 2\n:61:0310201020C20000,00FMSCNONREF\n:86: PADDED \n",
+		},
+		{
+			name:               "PreserveWhitespace",
+			rawBody:            "\n:20:REFERENCE\n:86: PADDED \n:86:LINE 1\r\nLINE 2\r\n",
+			preserveWhitespace: true,
+		},
+		{
+			name:    "TagAtStartOfBody",
+			rawBody: ":20:REFERENCE\n:21:NONREF",
+		},
+		{
+			name:    "CarriageReturns",
+			rawBody: "\r:20:REFERENCE\r:86:INFO\r",
+		},
+		{
+			name:    "ColonsInContent",
+			rawBody: "\n:20:REFERENCE\n:86:KEY:VALUE\nNEXT LINE: MORE\n",
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			body, order := message.ParseBody(test.rawBody, test.preserveWhitespace)
+			if len(order) == 0 {
+				t.Fatal("expected fields, got none")
+			}
+
+			// every field parsed on its own should equal the same field parsed as part of the whole body
+			for _, tag := range append(order, "99") {
+				values, ok := message.BodyField(test.rawBody, tag, test.preserveWhitespace)
+				if !ok {
+					t.Fatalf("expected body of fields for tag %s", tag)
+				}
+				if fmt.Sprintf("%q", values) != fmt.Sprintf("%q", body[tag]) {
+					t.Errorf("expected values %q for tag %s, got %q", body[tag], tag, values)
+				}
+			}
+		})
+	}
+}
+
+func TestBodyFieldSubBlocks(t *testing.T) {
+	t.Parallel()
+
+	rawBody := "{177:2103151130}{451:0}"
+
+	if _, ok := message.BodyField(rawBody, "451", false); ok {
+		t.Error("expected a body of sub blocks not to be handled")
+	}
+
+	body, order := message.ParseBody(rawBody, false)
+	validateBody(t, map[string][]string{"177": {"2103151130"}, "451": {"0"}}, body)
+
+	if strings.Join(order, ",") != "177,451" {
+		t.Errorf("expected order 177,451, got %s", strings.Join(order, ","))
+	}
+}
+
+func BenchmarkParseBytes(b *testing.B) {
+	input := []byte(strings.Repeat(
+		"{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:\n:20:REFERENCE\n:25:BPHKPLPK/320000546101\n"+
+			":28C:00084/001\n:60F:C031002PLN40000,00\n:61:0310201020C20000,00FMSCNONREF\n:86:DETAILS\n"+
+			":62F:C031020PLN60000,00\n-}\n",
+		1000,
+	))
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		count := 0
+
+		message.ParseBytes(
+			input,
+			message.Config{},
+			func(msg message.Message) bool {
+				count++
+				return true
+			},
+			func(err message.Error) {
+				b.Fatalf("expected no errors, got: %v", err)
+			},
+		)
+
+		if count != 1000 {
+			b.Fatalf("expected 1000 messages, got %d", count)
+		}
+	}
+}
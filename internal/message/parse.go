@@ -6,8 +6,10 @@
 package message
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"sync"
 )
 
 const (
@@ -18,9 +20,13 @@ const (
 	blockLabelTrailers    = "5"
 )
 
+// SubBlock is a block nested in another block, or in another sub block. Its content is the verbatim input between the
+// colon following its label and its closing brace, including any sub blocks nested in it. Those are available as
+// Blocks as well.
 type SubBlock struct {
 	Label   string
 	Content string
+	Blocks  []SubBlock
 }
 
 func newMessageSubBlock() SubBlock {
@@ -31,142 +37,488 @@ type Block struct {
 	Label   string
 	Content string
 	Fields  map[string][]string
+	Order   []string
 	Blocks  []SubBlock
+	// Continued is set for a body terminated by the continuation indicator, +}, rather than by -}
+	Continued bool
+}
+
+// fieldBuffer collects the fields of a body while it is being parsed. It's reused for the bodies of the messages of a
+// single parse run, see builder.fieldsPool, the fields are copied out of it once the body is complete so none of it is
+// shared with the messages handed out.
+type fieldBuffer struct {
+	// fields holds the values per tag, tags of previous bodies are kept with their values truncated so their slices
+	// can be reused
+	fields map[string][]string
+	order  []string
+	values int
+}
+
+func newFieldBuffer() interface{} {
+	return &fieldBuffer{fields: make(map[string][]string)}
 }
 
-func newBlock() Block {
-	return Block{
-		Fields: make(map[string][]string),
-		Blocks: make([]SubBlock, 0),
+func (fb *fieldBuffer) reset() {
+	for tag, values := range fb.fields {
+		fb.fields[tag] = values[:0]
 	}
+
+	fb.order = fb.order[:0]
+	fb.values = 0
 }
 
-type parser struct {
-	cfg        Config
-	lexerItems chan item
-	messages   chan Message
-	errors     chan Error
+func (fb *fieldBuffer) add(tag, value string) {
+	fb.fields[tag] = append(fb.fields[tag], value)
+	fb.order = append(fb.order, tag)
+	fb.values++
 }
 
-func newParser(cfg Config, lexer *lexer) *parser {
-	p := &parser{
-		cfg:        cfg,
-		lexerItems: lexer.items,
-		messages:   make(chan Message),
-		errors:     make(chan Error),
+// copyOut returns the fields and their order as collected, in a map and slices of their own. The values of all tags
+// share a single backing array, the slice of each tag being capped so appending to it does not affect the others.
+func (fb *fieldBuffer) copyOut() (map[string][]string, []string) {
+	fields := make(map[string][]string)
+	values := make([]string, 0, fb.values)
+
+	for _, tag := range fb.order {
+		if _, ok := fields[tag]; ok {
+			continue
+		}
+
+		start := len(values)
+		values = append(values, fb.fields[tag]...)
+		fields[tag] = values[start:len(values):len(values)]
 	}
 
-	go p.run()
+	var order []string
+	if len(fb.order) > 0 {
+		order = append(make([]string, 0, len(fb.order)), fb.order...)
+	}
 
-	return p
+	return fields, order
+}
+
+// builder assembles messages from the items produced by the lexer. It holds the state in between items so it can be
+// driven by the concurrent parser as well as by the synchronous ParseBytes.
+type builder struct {
+	cfg       Config
+	onMessage func(Message)
+	onError   func(Error)
+
+	blocks     []Block
+	blockOpen  bool
+	currLine   int
+	currBlock  Block
+	subBlocks  []SubBlock // sub blocks currently open, the innermost last
+	fields     *fieldBuffer
+	currTag    string
+	fieldCount int
+	discarding bool
+
+	// raw holds the verbatim input since the start of the current message, rawOffset being the offset of its first
+	// byte within the input. The current message spans the input from msgStart up to msgEnd, msgStart is -1 while
+	// outside of a message. The content of the current block starts at contentStart.
+	raw          []byte
+	rawOffset    int
+	blockStart   int
+	contentStart int
+	msgStart     int
+	msgEnd       int
+
+	// fieldsPool holds the field buffers of the bodies, reused across the messages of the parse run
+	fieldsPool sync.Pool
+}
+
+func newBuilder(cfg Config, onMessage func(Message), onError func(Error)) *builder {
+	return &builder{
+		cfg:       cfg,
+		onMessage: onMessage,
+		onError:   onError,
+		blocks:    make([]Block, 0),
+		currLine:  1,
+		msgStart:  -1,
+		fieldsPool: sync.Pool{
+			New: newFieldBuffer,
+		},
+	}
+}
+
+// newBlock creates a block with the given label. Only the body holds fields, which are collected in a field buffer
+// taken from the pool until the body is complete. The order and sub blocks are allocated on first use.
+func (b *builder) newBlock(label string) Block {
+	if label == blockLabelBody {
+		if b.fields == nil {
+			b.fields = b.fieldsPool.Get().(*fieldBuffer)
+		}
+		b.fields.reset()
+	}
+
+	return Block{Label: label}
+}
+
+// releaseFields sets the fields collected for the body on the current block and returns the field buffer to the pool.
+func (b *builder) releaseFields() {
+	if b.fields == nil {
+		b.currBlock.Fields = make(map[string][]string)
+		return
+	}
+
+	b.currBlock.Fields, b.currBlock.Order = b.fields.copyOut()
+
+	b.fieldsPool.Put(b.fields)
+	b.fields = nil
 }
 
 // blocksToMessage takes a slice of blocks, that should form a complete message, and parses them into a message struct.
-// It delegates parsing of each type of blog to its respective function.
-func (p *parser) blocksToMessage(blocks []Block, line int) Message {
+// It delegates parsing of each type of blog to its respective function. The raw message is the verbatim input the
+// blocks were lexed from.
+func blocksToMessage(blocks []Block, line int, raw string) Message {
 	m := Message{
 		Line: line,
+		Raw:  raw,
 	}
 
-	var rawHeader string
-	var rawAppHeader string
-	var rawUsrHeader string
-	var rawBody string
-	var rawTrailers string
-
 	for _, block := range blocks {
 		switch block.Label {
 		case blockLabelBasicHeader:
 			m.BasicHeader = block
-			rawHeader = fmt.Sprintf("{%s:%s}", blockLabelBasicHeader, block.Content)
 		case blockLabelAppHeader:
 			m.AppHeader = block
-			rawAppHeader = fmt.Sprintf("{%s:%s}", blockLabelAppHeader, block.Content)
 		case blockLabelUsrHeader:
 			m.UsrHeader = block
-			rawUsrHeader = fmt.Sprintf("{%s:%s}", blockLabelUsrHeader, block.Content)
 		case blockLabelBody:
 			m.Body = block.Fields
-			rawBody = fmt.Sprintf("{%s:%s}", blockLabelBody, block.Content)
+			m.BodyOrder = block.Order
+			m.RawBody = block.Content
+			m.Continued = block.Continued
+
+			// the body of a system message, e.g. {4:{202:0001}}, consists of sub blocks rather than fields, which are
+			// made available as fields so they are not lost
+			if len(block.Order) == 0 && len(block.Blocks) > 0 {
+				m.Body, m.BodyOrder = subBlocksToFields(block.Blocks)
+			}
 		case blockLabelTrailers:
 			m.Trailers = block
-			rawTrailers = fmt.Sprintf("{%s:%s}", blockLabelTrailers, block.Content)
 		}
 	}
 
-	m.Raw = rawHeader + rawAppHeader + rawUsrHeader + rawBody + rawTrailers
-
 	return m
 }
 
-// run runs the parser. This means it will read the items it receives from the lexer and parses them into complete
-// messages.
-func (p *parser) run() {
-	blocks := make([]Block, 0)
+// subBlocksToFields converts sub blocks into fields, keyed by their labels, and the order the labels were found in.
+func subBlocksToFields(subBlocks []SubBlock) (map[string][]string, []string) {
+	fields := make(map[string][]string, len(subBlocks))
+	order := make([]string, 0, len(subBlocks))
 
-	currLine := 1
-	currBlock := newBlock()
+	for _, subBlock := range subBlocks {
+		fields[subBlock.Label] = append(fields[subBlock.Label], subBlock.Content)
+		order = append(order, subBlock.Label)
+	}
 
-	var currSubBlock SubBlock
-	var currTag string
+	return fields, order
+}
 
-	sendMessage := func() {
-		if len(blocks) > 0 {
-			p.messages <- p.blocksToMessage(blocks, currLine)
-		}
+// fieldValue returns the value of a field from its lexed content. Unless whitespace is to be preserved the content is
+// trimmed. Otherwise only the line break that separates the field from the next field, or the end of the body, is
+// removed.
+func fieldValue(content string, preserveWhitespace bool) string {
+	if !preserveWhitespace {
+		return strings.TrimSpace(content)
 	}
 
-Loop:
-	for item := range p.lexerItems {
-		switch item.typ {
-		case itemBlockLabel:
-			// if we receive a new basic header block it means a new message
-			if item.val == blockLabelBasicHeader {
-				// if we had blocks before this new message we process them before starting on the new message
-				sendMessage()
-
-				currLine = item.line
-				blocks = make([]Block, 0)
+	content = strings.TrimSuffix(content, "\n")
+	content = strings.TrimSuffix(content, "\r")
+
+	return content
+}
+
+// exceedsMaxFields returns true if the current message holds more fields than the configured maximum, if any.
+func (b *builder) exceedsMaxFields() bool {
+	return b.cfg.MaxFieldsPerMessage > 0 && b.fieldCount > b.cfg.MaxFieldsPerMessage
+}
+
+// discardMessage reports the current message exceeding the maximum number of fields and drops what was retained of it.
+// The message is discarded up to its end.
+func (b *builder) discardMessage() {
+	b.onError(Error{
+		Err:  fmt.Errorf("message exceeds the maximum of %d fields", b.cfg.MaxFieldsPerMessage),
+		Line: b.currLine,
+	})
+
+	b.discarding = true
+	b.blocks = make([]Block, 0)
+	b.fields.reset()
+	b.currTag = ""
+}
+
+func (b *builder) sendMessage() {
+	if len(b.blocks) > 0 && !b.discarding {
+		b.onMessage(blocksToMessage(b.blocks, b.currLine, b.rawMessage()))
+	}
+}
+
+// startMessage marks the block that was opened last as the start of a new message. Any input before it is dropped.
+func (b *builder) startMessage() {
+	n := copy(b.raw, b.raw[b.blockStart-b.rawOffset:])
+	b.raw = b.raw[:n]
+	b.rawOffset = b.blockStart
+	b.msgStart = b.blockStart
+	b.msgEnd = b.blockStart
+}
+
+// rawMessage returns the verbatim input of the current message, from its first block up to and including its last
+// complete block.
+func (b *builder) rawMessage() string {
+	if b.msgStart < 0 {
+		return ""
+	}
+
+	return string(b.raw[b.msgStart-b.rawOffset : b.msgEnd-b.rawOffset])
+}
+
+// appendToOuterSubBlocks appends the value of an item belonging to the innermost open sub block to the content of all
+// sub blocks it is nested in.
+func (b *builder) appendToOuterSubBlocks(val string) {
+	for i := 0; i < len(b.subBlocks)-1; i++ {
+		b.subBlocks[i].Content += val
+	}
+}
+
+// handle processes a single item from the lexer. It returns true when no more items are to be processed, either
+// because the end of the input was reached or because an error occurred and StopOnError is set.
+func (b *builder) handle(item item) bool {
+	// while discarding a message its input is dropped, only the start of each block is retained as it might be the
+	// start of the next message
+	if b.discarding && item.typ == itemBlockLeftMeta {
+		b.raw = b.raw[:0]
+		b.rawOffset = item.pos
+	}
+
+	// the values of all items, except for errors, together form the input
+	if item.typ != itemError && (!b.discarding || item.typ == itemBlockLeftMeta || item.typ == itemBlockLabel) {
+		b.raw = append(b.raw, item.val...)
+	}
+
+	switch item.typ {
+	case itemIgnore:
+		content := strings.TrimSpace(item.val)
+		if content == "" {
+			break
+		}
+
+		// the line of an item is the one it ends on, the line reported is the one the skipped content starts on
+		trailing := item.val[strings.Index(item.val, content):]
+		line := item.line - strings.Count(trailing, "\n")
+
+		if b.cfg.ReportSkipped {
+			b.onError(Error{
+				Err:     fmt.Errorf("skipped %d bytes of content outside of a message", len(item.val)),
+				Line:    line,
+				Warning: true,
+			})
+		}
+		if b.cfg.OnSkipped != nil {
+			b.cfg.OnSkipped(item.val, line)
+		}
+	case itemBlockLeftMeta:
+		b.blockStart = item.pos
+	case itemBlockLabel:
+		// if we receive a new basic header block it means a new message
+		if item.val == blockLabelBasicHeader {
+			// if we had blocks before this new message we process them before starting on the new message
+			b.sendMessage()
+
+			b.currLine = item.line
+			b.blocks = make([]Block, 0)
+			b.fieldCount = 0
+			b.discarding = false
+			b.startMessage()
+		} else if b.msgStart < 0 {
+			// a message lacking a basic header starts with its first block
+			b.startMessage()
+		}
+
+		b.currBlock = b.newBlock(item.val)
+		b.subBlocks = b.subBlocks[:0]
+		b.blockOpen = true
+	case itemBlockLabelMeta:
+		b.contentStart = item.pos + len(item.val)
+	case itemBlockContent:
+		b.currBlock.Content = item.val
+
+		// the fields of a skipped body are lexed as a whole, they are counted here instead so the maximum applies all
+		// the same
+		if b.cfg.SkipBody && b.currBlock.Label == blockLabelBody && !b.discarding {
+			b.fieldCount += countBodyFields(item.val)
+			if b.exceedsMaxFields() {
+				b.discardMessage()
+
+				if b.cfg.StopOnError {
+					return true
+				}
+			}
+		}
+	case itemSubBlockLeftMeta:
+		b.subBlocks = append(b.subBlocks, newMessageSubBlock())
+		b.appendToOuterSubBlocks(item.val)
+	case itemSubBlockLabel:
+		b.subBlocks[len(b.subBlocks)-1].Label = item.val
+		b.appendToOuterSubBlocks(item.val)
+	case itemSubBlockLabelMeta:
+		b.appendToOuterSubBlocks(item.val)
+	case itemSubBlockContent:
+		b.subBlocks[len(b.subBlocks)-1].Content += item.val
+		b.appendToOuterSubBlocks(item.val)
+	case itemSubBlockRightMeta:
+		subBlock := b.subBlocks[len(b.subBlocks)-1]
+		b.subBlocks = b.subBlocks[:len(b.subBlocks)-1]
+
+		if len(b.subBlocks) == 0 {
+			b.currBlock.Blocks = append(b.currBlock.Blocks, subBlock)
+			break
+		}
+
+		parent := &b.subBlocks[len(b.subBlocks)-1]
+		parent.Blocks = append(parent.Blocks, subBlock)
+
+		for i := range b.subBlocks {
+			b.subBlocks[i].Content += item.val
+		}
+	case itemTagContent:
+		b.currTag = item.val
+	case itemFieldContent:
+		// the remaining fields of a message exceeding the maximum are not stored, only the boundaries of the message
+		// are still tracked so parsing can resume with the next message
+		if b.discarding {
+			b.currTag = ""
+			break
+		}
+
+		b.fieldCount++
+		if b.exceedsMaxFields() {
+			b.discardMessage()
+
+			if b.cfg.StopOnError {
+				return true
 			}
 
-			currBlock = newBlock()
-			currBlock.Label = item.val
-		case itemBlockContent:
-			currBlock.Content = item.val
-		case itemSubBlockLeftMeta:
-			currSubBlock = newMessageSubBlock()
-		case itemSubBlockLabel:
-			currSubBlock.Label = item.val
-		case itemSubBlockContent:
-			currSubBlock.Content = item.val
-		case itemSubBlockRightMeta:
-			currBlock.Blocks = append(currBlock.Blocks, currSubBlock)
-		case itemTagContent:
-			currTag = item.val
-		case itemFieldContent:
-			_, ok := currBlock.Fields[currTag]
-			if !ok {
-				currBlock.Fields[currTag] = make([]string, 0)
+			break
+		}
+
+		b.fields.add(b.currTag, fieldValue(item.val, b.cfg.PreserveWhitespace))
+		b.currTag = ""
+	case itemBlockRightMeta:
+		b.msgEnd = item.pos + len(item.val)
+
+		// the blocks of a message being discarded are not retained
+		if b.discarding {
+			b.blockOpen = false
+			break
+		}
+
+		// the content of the body is retained verbatim, up to the hyphen terminating the fields if any, rather than only
+		// the fields extracted from it
+		if b.currBlock.Label == blockLabelBody {
+			b.releaseFields()
+
+			content := string(b.raw[b.contentStart-b.rawOffset : item.pos-b.rawOffset])
+
+			// a body split across transmissions ends with the continuation indicator, it's not truncated but is to be
+			// reassembled with the remainder of the body
+			if strings.HasSuffix(content, continuationIndicator) {
+				content = strings.TrimSuffix(content, continuationIndicator)
+				b.currBlock.Continued = true
+
+				b.onError(Error{
+					Err: fmt.Errorf(
+						"message body is continued in a subsequent transmission: terminated by %s rather than %s",
+						fieldsContinuedRightMeta,
+						fieldsRightMeta,
+					),
+					Line:    b.currLine,
+					Warning: true,
+				})
 			}
 
-			currBlock.Fields[currTag] = append(currBlock.Fields[currTag], strings.TrimSpace(item.val))
-			currTag = ""
-		case itemBlockRightMeta:
-			blocks = append(blocks, currBlock)
-		case itemError:
-			p.errors <- Error{
-				Err:  fmt.Errorf(item.val),
-				Line: currLine,
+			b.currBlock.Content = strings.TrimSuffix(content, "-")
+		}
+
+		b.blocks = append(b.blocks, b.currBlock)
+		b.blockOpen = false
+	case itemError:
+		b.onError(Error{
+			Err:  fmt.Errorf(item.val),
+			Line: b.currLine,
+		})
+		if b.cfg.StopOnError {
+			return true
+		}
+	case itemEOF:
+		// a block that was never terminated means the input was truncated, rather than passing on what's left of the
+		// last message it's reported as incomplete
+		if b.blockOpen {
+			b.onError(Error{
+				Err:  fmt.Errorf("incomplete message: block %s is not terminated", b.currBlock.Label),
+				Line: b.currLine,
+			})
+
+			return true
+		}
+
+		// If we've reached the end of the file and still have unprocessed blocks left these are processed as the
+		// last message
+		b.sendMessage()
+
+		return true
+	}
+
+	return false
+}
+
+type parser struct {
+	ctx        context.Context
+	cfg        Config
+	lexerItems chan item
+	messages   chan Message
+	errors     chan Error
+}
+
+func newParser(ctx context.Context, cfg Config, lexer *lexer) *parser {
+	p := &parser{
+		ctx:        ctx,
+		cfg:        cfg,
+		lexerItems: lexer.items,
+		messages:   make(chan Message),
+		errors:     make(chan Error),
+	}
+
+	go p.run()
+
+	return p
+}
+
+// run runs the parser. This means it will read the items it receives from the lexer and parses them into complete
+// messages.
+func (p *parser) run() {
+	// sending stops once the context is done, the receiving end might have stopped listening
+	b := newBuilder(
+		p.cfg,
+		func(msg Message) {
+			select {
+			case p.messages <- msg:
+			case <-p.ctx.Done():
 			}
-			if p.cfg.StopOnError {
-				break Loop
+		},
+		func(err Error) {
+			select {
+			case p.errors <- err:
+			case <-p.ctx.Done():
 			}
-		case itemEOF:
-			// If we've reached the end of the file and still have unprocessed blocks left these are processed as the
-			// last message
-			sendMessage()
+		},
+	)
 
-			break Loop
+	for item := range p.lexerItems {
+		if b.handle(item) {
+			break
 		}
 	}
 
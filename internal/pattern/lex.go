@@ -64,7 +64,7 @@ func lex(input string) chan token {
 type stateFn func() stateFn
 
 func isCharSetSpecifier(r rune) bool {
-	return charSetsKeys.contains(r)
+	return isCharSetKey(r)
 }
 
 // next returns the next rune in the input.
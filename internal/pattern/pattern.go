@@ -8,6 +8,9 @@ package pattern
 import (
 	"fmt"
 	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
 )
 
 type CharSet func(r rune) bool
@@ -50,10 +53,162 @@ var (
 		"c": alphaNumericUpper,
 		"x": any,
 		"d": floats,
+		"z": extended,
 	}
 	charSetsKeys runeSet = charsetsKeysAsRunes(charSets)
+	// charSetsMu guards charSets and charSetsKeys, which can be extended through RegisterCharSet
+	charSetsMu      = &sync.RWMutex{}
+	builtInCharSets = runeSet(charSetsKeys)
+	// extended is the character set used by fields carrying large envelopes, such as 77T, it holds the characters of
+	// any along with a number of additional special characters and the carriage return
+	extended CharSet = func(r rune) bool { return any(r) || strings.ContainsRune("=!\"%&*<>;@#_\r", r) }
 )
 
+func lookupCharSet(key string) CharSet {
+	charSetsMu.RLock()
+	defer charSetsMu.RUnlock()
+
+	return charSets[key]
+}
+
+func isCharSetKey(r rune) bool {
+	charSetsMu.RLock()
+	defer charSetsMu.RUnlock()
+
+	return charSetsKeys.contains(r)
+}
+
+// RegisterCharSet registers a custom character set under the given key, making it usable in patterns, e.g. 1!h after
+// registering a hexadecimal character set under h. The key must be a single letter and can not be one of the built-in
+// keys n, a, c, x, d and z. Registering a key again replaces the character set registered before.
+func RegisterCharSet(key string, fn func(r rune) bool) error {
+	if utf8.RuneCountInString(key) != 1 {
+		return fmt.Errorf("invalid charset key %q: expected a single character", key)
+	}
+
+	r, _ := utf8.DecodeRuneInString(key)
+	switch {
+	case !unicode.IsLetter(r):
+		return fmt.Errorf("invalid charset key %q: expected a letter", key)
+	case builtInCharSets.contains(r):
+		return fmt.Errorf("invalid charset key %q: can not redefine built-in charset", key)
+	case fn == nil:
+		return fmt.Errorf("invalid charset %q: nil function", key)
+	}
+
+	charSetsMu.Lock()
+	charSets[key] = fn
+	charSetsKeys = charsetsKeysAsRunes(charSets)
+	charSetsMu.Unlock()
+
+	// patterns cached before registering might have interpreted the key as a literal
+	clearCache()
+
+	return nil
+}
+
+// UnregisterCharSet removes the custom character set registered under the given key, e.g. to undo a registration made
+// by a test. Built-in character sets are left alone.
+func UnregisterCharSet(key string) {
+	r, _ := utf8.DecodeRuneInString(key)
+	if builtInCharSets.contains(r) {
+		return
+	}
+
+	charSetsMu.Lock()
+	delete(charSets, key)
+	charSetsKeys = charsetsKeysAsRunes(charSets)
+	charSetsMu.Unlock()
+
+	// patterns cached before unregistering might have interpreted the key as a character set
+	clearCache()
+}
+
+// clearCache removes all patterns from the cache, see ParseCached.
+func clearCache() {
+	cache.Range(func(k, _ interface{}) bool {
+		cache.Delete(k)
+		return true
+	})
+}
+
+// PositionError is returned by Validate when the input does not match the pattern. It holds the position in the input
+// where the match failed. Both line and column are 1-based, the column counts characters rather than bytes.
+type PositionError struct {
+	Line   int
+	Column int
+	Err    error
+}
+
+// Error implements the Error interface.
+func (pe PositionError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", pe.Line, pe.Column, pe.Err)
+}
+
+// Unwrap returns the underlying error.
+func (pe PositionError) Unwrap() error {
+	return pe.Err
+}
+
+// offsetError is the error returned by the parts of a pattern. It holds the byte offset, relative to the input passed
+// to the part, at which the match failed. Every composite part shifts the offset by that of the input passed to its
+// sub parts, so the offset returned by the pattern itself is relative to the entire input.
+type offsetError struct {
+	offset int
+	err    error
+}
+
+func (oe offsetError) Error() string {
+	return oe.err.Error()
+}
+
+func (oe offsetError) Unwrap() error {
+	return oe.err
+}
+
+// shiftOffset adds the given number of bytes to the offset of the error.
+func shiftOffset(err error, offset int) error {
+	oe, ok := err.(offsetError)
+	if !ok {
+		return offsetError{offset: offset, err: err}
+	}
+
+	oe.offset += offset
+
+	return oe
+}
+
+// errorOffset returns the offset of the error, zero if it has none.
+func errorOffset(err error) int {
+	oe, ok := err.(offsetError)
+	if !ok {
+		return 0
+	}
+
+	return oe.offset
+}
+
+// positionError turns an error into a PositionError by converting its offset into a line and column within the input.
+func positionError(input string, err error) PositionError {
+	offset := errorOffset(err)
+	if offset > len(input) {
+		offset = len(input)
+	}
+
+	consumed := input[:offset]
+	lineStart := strings.LastIndex(consumed, "\n") + 1
+
+	if oe, ok := err.(offsetError); ok {
+		err = oe.err
+	}
+
+	return PositionError{
+		Line:   strings.Count(consumed, "\n") + 1,
+		Column: utf8.RuneCountInString(consumed[lineStart:]) + 1,
+		Err:    err,
+	}
+}
+
 type ValidatesPartially interface {
 	ValidatePartial(input string, currLine int) (string, error)
 }
@@ -71,7 +226,7 @@ func (l Literal) ValidatePartial(input string, currLine int) (string, error) {
 		return "", nil
 	}
 
-	return input, fmt.Errorf("expected input to have literal %q", l.Chars)
+	return input, offsetError{err: fmt.Errorf("expected input to have literal %q", l.Chars)}
 }
 
 type Optional struct {
@@ -96,6 +251,7 @@ type CharGroup struct {
 
 func (cg CharGroup) countAndStripFloats(input string) (int, string) {
 	charCount := 0
+	commaFound := false
 
 	countBeforeDecimal := 0
 BeforeDecimalLoop:
@@ -107,6 +263,7 @@ BeforeDecimalLoop:
 		switch {
 		case r == ',':
 			charCount++
+			commaFound = true
 			break BeforeDecimalLoop
 		case numbers(r):
 			charCount++
@@ -128,15 +285,15 @@ AfterDecimalLoop:
 			charCount++
 			countAfterDecimal++
 		default:
-			charCount++
 			break AfterDecimalLoop
 		}
 	}
 
 	finalCount := countBeforeDecimal + countAfterDecimal
 
-	// no decimals meaning invalid float
-	if countAfterDecimal == 0 {
+	// no decimal comma, or no digits before it, meaning invalid float
+	// the decimals themselves are optional, e.g. 100, is valid
+	if !commaFound || countBeforeDecimal == 0 {
 		return 0, input
 	}
 
@@ -170,7 +327,10 @@ func (cg CharGroup) ValidatePartial(input string, currLine int) (string, error)
 
 	switch {
 	case count < cg.Count && cg.CountStrict:
-		return newInput, fmt.Errorf("expected %d characters within '%s' group, got %d", cg.Count, cg.charSetKey, count)
+		return newInput, offsetError{
+			offset: len(input) - len(newInput),
+			err:    fmt.Errorf("expected %d characters within '%s' group, got %d", cg.Count, cg.charSetKey, count),
+		}
 	default:
 		return newInput, nil
 	}
@@ -179,30 +339,32 @@ func (cg CharGroup) ValidatePartial(input string, currLine int) (string, error)
 type Pattern []ValidatesPartially
 
 func (p Pattern) ValidatePartial(input string, currLine int) (string, error) {
-	var err error
+	orig := input
 
 	for _, v := range p {
-		input, err = v.ValidatePartial(input, currLine)
+		offset := len(orig) - len(input)
+
+		rest, err := v.ValidatePartial(input, currLine)
 		if err != nil {
-			return input, err
+			return rest, shiftOffset(err, offset)
 		}
+
+		input = rest
 	}
 
 	return input, nil
 }
 
+// Validate validates the entire input against the pattern. When the input does not match the error returned wraps a
+// PositionError pointing to where the match failed.
 func (p Pattern) Validate(input string) error {
-	var err error
-
-	for _, pv := range p {
-		input, err = pv.ValidatePartial(input, 1)
-		if err != nil {
-			return fmt.Errorf("input invalid: %w", err)
-		}
+	rest, err := p.ValidatePartial(input, 1)
+	if err != nil {
+		return fmt.Errorf("input invalid: %w", positionError(input, err))
 	}
 
-	if input != "" {
-		return fmt.Errorf("incomplete match")
+	if rest != "" {
+		return positionError(input, offsetError{offset: len(input) - len(rest), err: fmt.Errorf("incomplete match")})
 	}
 
 	return nil
@@ -214,17 +376,19 @@ type LinePattern struct {
 }
 
 func (lp LinePattern) ValidatePartial(input string, currLine int) (string, error) {
+	orig := input
 	lines := strings.Split(input, "\n")
 
 	for i := 0; i < len(lines) && lp.InRange(currLine); i++ {
 		line := lines[i]
+		lineOffset := len(orig) - len(input)
 
 		rest, err := lp.Pattern.ValidatePartial(line, currLine)
 		if err != nil {
-			return input, fmt.Errorf("line %d: %w", currLine, err)
+			return input, shiftOffset(err, lineOffset)
 		}
 		if rest != "" {
-			return input, fmt.Errorf("line %d: incomplete match", currLine)
+			return input, offsetError{offset: lineOffset + len(line) - len(rest), err: fmt.Errorf("incomplete match")}
 		}
 
 		newLineIdx := len(line + "\n")
@@ -266,8 +430,14 @@ func (op OrPattern) ValidatePartial(input string, currLine int) (string, error)
 	case errLeft == nil && errRight == nil && len(restLeft) > len(restRight):
 		return restRight, nil
 	default:
+		// the side that got furthest is the most likely to have been intended
+		offset := errorOffset(errLeft)
+		if errorOffset(errRight) > offset {
+			offset = errorOffset(errRight)
+		}
+
 		errStr = fmt.Sprintf("left: %s, right: %s", errLeft, errRight)
-		return input, fmt.Errorf("input invalid for or: %s", errStr)
+		return input, offsetError{offset: offset, err: fmt.Errorf("input invalid for or: %s", errStr)}
 	}
 }
 
@@ -282,3 +452,24 @@ func Parse(input string) (Pattern, error) {
 
 	return process(astPattern), nil
 }
+
+// cache holds the patterns compiled by ParseCached, keyed by their pattern string.
+var cache = &sync.Map{}
+
+// ParseCached behaves like Parse but keeps the compiled pattern in a package level cache. Subsequent calls for the same
+// pattern string return the cached pattern instead of parsing it again. Patterns that fail to parse are not cached.
+func ParseCached(input string) (Pattern, error) {
+	cached, ok := cache.Load(input)
+	if ok {
+		return cached.(Pattern), nil
+	}
+
+	ptrn, err := Parse(input)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.Store(input, ptrn)
+
+	return ptrn, nil
+}
@@ -6,6 +6,7 @@
 package pattern_test
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 
@@ -136,6 +137,15 @@ func TestPattern(t *testing.T) {
 			input:       "abc123,*",
 			expectedErr: fmt.Errorf("incomplete match"),
 		},
+		{
+			pattern: "16z",
+			input:   "abc123,*=!_\r\n",
+		},
+		{
+			pattern:     "16z",
+			input:       "abc~",
+			expectedErr: fmt.Errorf("incomplete match"),
+		},
 		{
 			pattern: "16x",
 			input:   "1234567890ABCDEF",
@@ -191,6 +201,20 @@ func TestPattern(t *testing.T) {
 			input:       "0,000,00",
 			expectedErr: fmt.Errorf("incomplete match"),
 		},
+		{
+			pattern: "15d",
+			input:   "20000,",
+		},
+		{
+			pattern:     "15d",
+			input:       "20000",
+			expectedErr: fmt.Errorf("incomplete match"),
+		},
+		{
+			pattern:     "15d",
+			input:       ",00",
+			expectedErr: fmt.Errorf("incomplete match"),
+		},
 		{
 			pattern: "3!d3!d",
 			input:   "0,000,00",
@@ -407,3 +431,188 @@ func TestPattern(t *testing.T) {
 		})
 	}
 }
+
+func TestPatternParseCached(t *testing.T) {
+	t.Parallel()
+
+	first, err := pattern.ParseCached("6*35x")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := pattern.ParseCached("6*35x")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(first) != len(second) || &first[0] != &second[0] {
+		t.Error("expected the second call to return the cached pattern")
+	}
+
+	_, err = pattern.ParseCached("(/")
+	mttest.ValidateError(t, fmt.Errorf("unclosed optional expression"), err)
+}
+
+// TestRegisterCharSet changes the character sets of the package, it therefore doesn't run in parallel with other tests
+// and undoes the registration once done.
+func TestRegisterCharSet(t *testing.T) {
+	isHex := func(r rune) bool {
+		return (r >= '0' && r <= '9') || (r >= 'A' && r <= 'F') || (r >= 'a' && r <= 'f')
+	}
+
+	for _, test := range []struct {
+		name        string
+		key         string
+		fn          func(r rune) bool
+		expectedErr error
+	}{
+		{
+			name:        "BuiltIn",
+			key:         "n",
+			fn:          isHex,
+			expectedErr: fmt.Errorf(`invalid charset key "n": can not redefine built-in charset`),
+		},
+		{
+			name:        "MultipleCharacters",
+			key:         "hx",
+			fn:          isHex,
+			expectedErr: fmt.Errorf(`invalid charset key "hx": expected a single character`),
+		},
+		{
+			name:        "NotALetter",
+			key:         "!",
+			fn:          isHex,
+			expectedErr: fmt.Errorf(`invalid charset key "!": expected a letter`),
+		},
+		{
+			name:        "NilFunction",
+			key:         "q",
+			expectedErr: fmt.Errorf(`invalid charset "q": nil function`),
+		},
+	} {
+		err := pattern.RegisterCharSet(test.key, test.fn)
+		mttest.ValidateError(t, test.expectedErr, err)
+	}
+
+	err := pattern.RegisterCharSet("h", isHex)
+	mttest.ValidateError(t, nil, err)
+	t.Cleanup(func() {
+		pattern.UnregisterCharSet("h")
+	})
+
+	ptrn, err := pattern.ParseCached("4!h")
+	mttest.ValidateError(t, nil, err)
+
+	mttest.ValidateError(t, nil, ptrn.Validate("0aF9"))
+	mttest.ValidateError(t, fmt.Errorf("expected 4 characters within 'h' group, got 1"), ptrn.Validate("0G12"))
+}
+
+func TestPatternValidatePosition(t *testing.T) {
+	for _, test := range []struct {
+		name           string
+		pattern        string
+		input          string
+		expectedErr    error
+		expectedLine   int
+		expectedColumn int
+	}{
+		{
+			name:           "CharGroup",
+			pattern:        "3!a",
+			input:          "ABc",
+			expectedErr:    fmt.Errorf("line 1, column 3: expected 3 characters within 'a' group, got 2"),
+			expectedLine:   1,
+			expectedColumn: 3,
+		},
+		{
+			name:           "Literal",
+			pattern:        "3!a/3!n",
+			input:          "ABC-123",
+			expectedErr:    fmt.Errorf("line 1, column 4: expected input to have literal \"/\""),
+			expectedLine:   1,
+			expectedColumn: 4,
+		},
+		{
+			name:           "LinePattern",
+			pattern:        "3*3!n",
+			input:          "123\n456\n78A",
+			expectedErr:    fmt.Errorf("line 3, column 3: expected 3 characters within 'n' group, got 2"),
+			expectedLine:   3,
+			expectedColumn: 3,
+		},
+		{
+			name:           "LinePatternIncompleteMatch",
+			pattern:        "2*3x",
+			input:          "ABC\nABCD",
+			expectedErr:    fmt.Errorf("line 2, column 4: incomplete match"),
+			expectedLine:   2,
+			expectedColumn: 4,
+		},
+		{
+			name:           "Or",
+			pattern:        "3!n|3!a",
+			input:          "12A",
+			expectedErr:    fmt.Errorf("line 1, column 3: input invalid for or"),
+			expectedLine:   1,
+			expectedColumn: 3,
+		},
+		{
+			name:           "IncompleteMatch",
+			pattern:        "3!n",
+			input:          "1234",
+			expectedErr:    fmt.Errorf("line 1, column 4: incomplete match"),
+			expectedLine:   1,
+			expectedColumn: 4,
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ptrn, err := pattern.Parse(test.pattern)
+			if err != nil {
+				t.Fatalf("could not parse pattern: %v", err)
+			}
+
+			err = ptrn.Validate(test.input)
+			mttest.ValidateError(t, test.expectedErr, err)
+
+			var posErr pattern.PositionError
+			if !errors.As(err, &posErr) {
+				t.Fatalf("expected a PositionError, got %T", err)
+			}
+
+			if posErr.Line != test.expectedLine || posErr.Column != test.expectedColumn {
+				t.Errorf(
+					"expected position %d:%d, got %d:%d",
+					test.expectedLine,
+					test.expectedColumn,
+					posErr.Line,
+					posErr.Column,
+				)
+			}
+		})
+	}
+}
+
+const benchmarkPattern = "1!a|2!n|3!d1*1!a|2!n|3!d"
+
+func BenchmarkPatternParse(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_, err := pattern.Parse(benchmarkPattern)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPatternParseCached(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_, err := pattern.ParseCached(benchmarkPattern)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -29,7 +29,7 @@ func (p *processor) astOptionalToOptional(o ast.Optional, currLine int) Optional
 func (p *processor) astCharGroupToCharGroup(cg ast.CharGroup) CharGroup {
 	return CharGroup{
 		charSetKey:  cg.CharSetKey,
-		CharSet:     charSets[cg.CharSetKey],
+		CharSet:     lookupCharSet(cg.CharSetKey),
 		Count:       cg.CharCount,
 		CountStrict: cg.CharCountStrict,
 	}
@@ -0,0 +1,97 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package validate
+
+import (
+	"fmt"
+	"time"
+)
+
+const dateFormat = "2006-01-02"
+
+// DateField is the date held by a field of a message, for validations comparing the dates of multiple fields. Field is
+// the name of the struct field, e.g. StatementLines[1], and Label its tag.
+type DateField struct {
+	Field string
+	Label string
+	Time  time.Time
+}
+
+// day returns midnight UTC of the day of t, in its own location, so dates are compared regardless of their time.
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func dateError(field DateField, err error) ValidationError {
+	return validationErrors{
+		{
+			field: field.Field,
+			label: field.Label,
+			err:   valueError{err},
+		},
+	}
+}
+
+// NotBefore validates that the date of field is not before the date of other, e.g. that a closing balance is not dated
+// before the opening balance. Dates are compared by day. Nil is returned if either date is zero, i.e. absent.
+func NotBefore(field, other DateField) ValidationError {
+	if field.Time.IsZero() || other.Time.IsZero() {
+		return nil
+	}
+
+	if !day(field.Time).Before(day(other.Time)) {
+		return nil
+	}
+
+	return dateError(field, fmt.Errorf(
+		"date %s is before date %s of %s",
+		field.Time.Format(dateFormat),
+		other.Time.Format(dateFormat),
+		other.Field,
+	))
+}
+
+// NotAfter validates that the date of field is not after the date of other, the counterpart of NotBefore.
+func NotAfter(field, other DateField) ValidationError {
+	if field.Time.IsZero() || other.Time.IsZero() {
+		return nil
+	}
+
+	if !day(field.Time).After(day(other.Time)) {
+		return nil
+	}
+
+	return dateError(field, fmt.Errorf(
+		"date %s is after date %s of %s",
+		field.Time.Format(dateFormat),
+		other.Time.Format(dateFormat),
+		other.Field,
+	))
+}
+
+// Join combines the validation errors of multiple validations spanning fields, such as NotBefore, into one. Nil errors
+// are left out, nil is returned if all errors are nil.
+func Join(errs ...ValidationError) ValidationError {
+	joined := make(validationErrors, 0, len(errs))
+
+	for _, err := range errs {
+		switch e := err.(type) {
+		case nil:
+		case validationErrors:
+			joined = append(joined, e...)
+		case validationError:
+			joined = append(joined, e)
+		default:
+			joined = append(joined, validationError{err: e})
+		}
+	}
+
+	if len(joined) == 0 {
+		return nil
+	}
+
+	return joined
+}
@@ -5,7 +5,10 @@
 
 package validate
 
-import "strings"
+import (
+	"errors"
+	"strings"
+)
 
 type ValidationError interface {
 	Error() string
@@ -24,6 +27,39 @@ func (e valueError) Error() string {
 	return e.IndentError("")
 }
 
+// patternError is the cause of a valueError for a value that does not match the pattern of its field.
+type patternError struct {
+	err error
+}
+
+func (e patternError) Error() string {
+	return "pattern validation failed: " + e.err.Error()
+}
+
+func (e patternError) Unwrap() error {
+	return e.err
+}
+
+// isPatternFailure returns true if the validation error consists solely of pattern validation failures.
+func isPatternFailure(err ValidationError) bool {
+	switch e := err.(type) {
+	case valueError:
+		return errors.As(e.err, &patternError{})
+	case validationError:
+		return isPatternFailure(e.err)
+	case validationErrors:
+		for _, ve := range e {
+			if !isPatternFailure(ve.err) {
+				return false
+			}
+		}
+
+		return len(e) > 0
+	default:
+		return false
+	}
+}
+
 type validationError struct {
 	field string
 	label string
@@ -49,6 +85,10 @@ func (ve validationError) IndentError(indent string) string {
 	}
 }
 
+func (ve validationError) Error() string {
+	return ve.IndentError("\t")
+}
+
 type validationErrors []validationError
 
 func (ves validationErrors) IndentError(indent string) string {
@@ -63,3 +103,70 @@ func (ves validationErrors) IndentError(indent string) string {
 func (ves validationErrors) Error() string {
 	return ves.IndentError("\t")
 }
+
+// Relax splits a validation error as returned by Validator.Validate. The failures of fields labeled with one of the
+// given labels that consist solely of pattern validation failures are returned separately, one per field, all other
+// failures remain. Remaining is nil if no failures remain.
+func Relax(err ValidationError, labels []string) (remaining ValidationError, relaxed []ValidationError) {
+	ves, ok := err.(validationErrors)
+	if !ok || len(labels) == 0 {
+		return err, nil
+	}
+
+	relax := make(map[string]bool, len(labels))
+	for _, label := range labels {
+		relax[label] = true
+	}
+
+	kept := make(validationErrors, 0, len(ves))
+	for _, ve := range ves {
+		if relax[ve.label] && isPatternFailure(ve.err) {
+			relaxed = append(relaxed, ve)
+			continue
+		}
+
+		kept = append(kept, ve)
+	}
+
+	if len(kept) == 0 {
+		return nil, relaxed
+	}
+
+	return kept, relaxed
+}
+
+// Failure is the failure of a single field, or group of fields, split off a validation error by Failures. Its error
+// describes the failure of that field only.
+type Failure struct {
+	Field string
+	Label string
+	Err   error
+}
+
+// Failures splits a validation error as returned by Validator.Validate into the failures of the individual fields, in
+// the order they are held by the validation error. Nil is returned for a nil error.
+func Failures(err ValidationError) []Failure {
+	var ves validationErrors
+
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case validationErrors:
+		ves = e
+	case validationError:
+		ves = validationErrors{e}
+	default:
+		return []Failure{{Err: e}}
+	}
+
+	failures := make([]Failure, len(ves))
+	for i, ve := range ves {
+		failures[i] = Failure{
+			Field: ve.field,
+			Label: ve.label,
+			Err:   ve,
+		}
+	}
+
+	return failures
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/DennisVis/mt/internal/pattern"
+)
+
+// FieldSpec describes a field of a body, as validated by a validator created with CreateValidatorForFields.
+type FieldSpec struct {
+	Pattern    string
+	Mandatory  bool
+	Repeatable bool
+}
+
+type fieldsValidator struct {
+	tags       []string
+	items      map[string]validationItem
+	repeatable map[string]bool
+}
+
+// CreateValidatorForFields creates a validator for bodies held as map[string][]string, the values of each field keyed
+// by its tag, driven by the given specs rather than by struct tags. The validator verifies the mandatory fields are
+// present, only repeatable fields occur more than once, every value matches the pattern of its field and no fields
+// other than those specified are present. The failures are labeled with the tags of the fields.
+func CreateValidatorForFields(specs map[string]FieldSpec) (Validator, error) {
+	v := fieldsValidator{
+		tags:       make([]string, 0, len(specs)),
+		items:      make(map[string]validationItem, len(specs)),
+		repeatable: make(map[string]bool, len(specs)),
+	}
+
+	for tag, spec := range specs {
+		if tag == "" {
+			return nil, fmt.Errorf("field spec needs a tag")
+		}
+
+		ptrn, err := pattern.ParseCached(spec.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("field spec for tag %s contained invalid pattern %q: %w", tag, spec.Pattern, err)
+		}
+
+		v.tags = append(v.tags, tag)
+		v.items[tag] = validationItem{
+			label:     tag,
+			field:     tag,
+			mandatory: spec.Mandatory,
+			pattern:   ptrn,
+		}
+		v.repeatable[tag] = spec.Repeatable
+	}
+
+	sort.Strings(v.tags)
+
+	return &v, nil
+}
+
+func (v *fieldsValidator) Validate(fields interface{}) ValidationError {
+	body, ok := fields.(map[string][]string)
+	if !ok {
+		return valueError{fmt.Errorf("not a map of fields: %s", reflect.TypeOf(fields))}
+	}
+
+	errors := make(validationErrors, 0)
+
+	for _, tag := range v.tags {
+		err := v.validateField(tag, body[tag])
+		if err != nil {
+			errors = append(errors, validationError{label: tag, err: err})
+		}
+	}
+
+	unknown := make([]string, 0)
+	for tag := range body {
+		if _, ok := v.items[tag]; !ok {
+			unknown = append(unknown, tag)
+		}
+	}
+	sort.Strings(unknown)
+
+	for _, tag := range unknown {
+		errors = append(errors, validationError{
+			label: tag,
+			err:   valueError{fmt.Errorf("field %s is not in the dictionary", tag)},
+		})
+	}
+
+	if len(errors) > 0 {
+		return errors
+	}
+
+	return nil
+}
+
+func (v *fieldsValidator) validateField(tag string, values []string) ValidationError {
+	item := v.items[tag]
+
+	switch {
+	case len(values) == 0 && item.mandatory:
+		return valueError{fmt.Errorf("missing mandatory field %s", tag)}
+	case len(values) > 1 && !v.repeatable[tag]:
+		return valueError{fmt.Errorf("field %s is not repeatable, got %d occurrences", tag, len(values))}
+	case len(values) == 1:
+		return validateValue(item, reflect.ValueOf(values[0]))
+	}
+
+	errors := make(validationErrors, 0)
+
+	for i, value := range values {
+		err := validateValue(item, reflect.ValueOf(value))
+		if err != nil {
+			errors = append(errors, validationError{field: "[" + strconv.Itoa(i) + "]", err: err})
+		}
+	}
+
+	if len(errors) > 0 {
+		return errors
+	}
+
+	return nil
+}
@@ -27,6 +27,20 @@ type rawStringer interface {
 	RawString() string
 }
 
+// selfValidator can be implemented by field types that need validation beyond what a pattern can express, e.g. checks
+// spanning multiple occurrences of the same field. Validated structs can implement it as well for rules spanning
+// multiple fields, it is called once all of their fields are valid.
+type selfValidator interface {
+	ValidateMT() error
+}
+
+const (
+	// groupKindOneOf requires exactly one of the fields in a group to be present, e.g. for a mandatory optioned field.
+	groupKindOneOf = "oneof"
+	// groupKindExclusive allows at most one of the fields in a group to be present, e.g. for an optional optioned field.
+	groupKindExclusive = "exclusive"
+)
+
 type validationItem struct {
 	label     string
 	field     string
@@ -34,6 +48,8 @@ type validationItem struct {
 	dive      bool
 	pattern   pattern.Pattern
 	items     validationItems
+	group     string
+	groupKind string
 }
 
 type validationItems map[string]validationItem
@@ -59,7 +75,7 @@ func createItem(label, mandatoryStr, patternStr, fieldName string) (validationIt
 	if patternStr == "dive" {
 		i.dive = true
 	} else {
-		ptrn, err := pattern.Parse(patternStr)
+		ptrn, err := pattern.ParseCached(patternStr)
 		if err != nil {
 			return i, fmt.Errorf("mt tag for field %s contained invalid pattern %q: %w", fieldName, patternStr, err)
 		}
@@ -70,6 +86,32 @@ func createItem(label, mandatoryStr, patternStr, fieldName string) (validationIt
 	return i, nil
 }
 
+// addGroup adds the group declared by the mtgroup tag of a field, if any, to the item. The tag consists of the name of
+// the group followed by its kind, e.g. mtgroup:"60,oneof" for fields of which exactly one must be present or
+// mtgroup:"50,exclusive" for fields of which at most one may be present.
+func addGroup(i validationItem, sf reflect.StructField) (validationItem, error) {
+	groupTag, ok := sf.Tag.Lookup("mtgroup")
+	if !ok {
+		return i, nil
+	}
+
+	groupSplit := strings.Split(groupTag, ",")
+	if len(groupSplit) != 2 || groupSplit[0] == "" {
+		return i, fmt.Errorf("mtgroup tag for field %s needs a name and a kind: %s", sf.Name, groupTag)
+	}
+
+	switch groupSplit[1] {
+	case groupKindOneOf, groupKindExclusive:
+	default:
+		return i, fmt.Errorf("mtgroup tag for field %s contained unknown kind %q", sf.Name, groupSplit[1])
+	}
+
+	i.group = groupSplit[0]
+	i.groupKind = groupSplit[1]
+
+	return i, nil
+}
+
 func diveIntoStruct(rv reflect.Value) (validationItems, error) {
 	subItems := make(validationItems)
 
@@ -100,6 +142,11 @@ func diveIntoStruct(rv reflect.Value) (validationItems, error) {
 			return subItems, err
 		}
 
+		i, err = addGroup(i, sf)
+		if err != nil {
+			return subItems, err
+		}
+
 		subItems[fieldName] = i
 	}
 
@@ -150,6 +197,11 @@ func CreateValidatorForStruct(strct interface{}) (Validator, error) {
 			return nil, err
 		}
 
+		i, err = addGroup(i, sf)
+		if err != nil {
+			return nil, err
+		}
+
 		v.items[fieldName] = i
 
 		switch {
@@ -200,7 +252,8 @@ func valueToString(rv reflect.Value) string {
 		v = rv.Interface().(rawStringer).RawString()
 	case kind == reflect.Int, kind == reflect.Int8, kind == reflect.Int16, kind == reflect.Int32, kind == reflect.Int64:
 		v = strconv.FormatInt(rv.Int(), 10)
-	case kind == reflect.Uint, kind == reflect.Uint8, kind == reflect.Uint16, kind == reflect.Uint32, kind == reflect.Uint64:
+	case kind == reflect.Uint, kind == reflect.Uint8, kind == reflect.Uint16, kind == reflect.Uint32,
+		kind == reflect.Uint64:
 		v = strconv.FormatUint(rv.Uint(), 10)
 	case kind == reflect.Float32:
 		v = strings.ReplaceAll(strconv.FormatFloat(rv.Float(), 'f', 2, 32), ".", ",")
@@ -224,7 +277,7 @@ func validateValue(item validationItem, rv reflect.Value) ValidationError {
 
 	err := item.pattern.Validate(val)
 	if err != nil {
-		return valueError{fmt.Errorf("pattern validation failed: %w", err)}
+		return valueError{patternError{err}}
 	}
 
 	return nil
@@ -232,7 +285,8 @@ func validateValue(item validationItem, rv reflect.Value) ValidationError {
 
 func isUnsupportedType(rv reflect.Value) bool {
 	switch rv.Kind() {
-	case reflect.Bool, reflect.Complex64, reflect.Complex128, reflect.Chan, reflect.Func, reflect.Map, reflect.UnsafePointer:
+	case reflect.Bool, reflect.Complex64, reflect.Complex128, reflect.Chan, reflect.Func, reflect.Map,
+		reflect.UnsafePointer:
 		return true
 	}
 
@@ -251,11 +305,22 @@ func validateMember(item validationItem, name string, rv reflect.Value) Validati
 		rv = rv.Elem()
 	}
 
+	sv, ok := rv.Interface().(selfValidator)
+	if ok {
+		err := sv.ValidateMT()
+		if err != nil {
+			return valueError{err}
+		}
+	}
+
 	shouldDive := item.dive
 
 	switch {
 	case isUnsupportedType(rv):
 		return nil
+	case rv.Kind() == reflect.Struct && shouldDive && !item.mandatory && rv.IsZero():
+		// an absent optional struct has nothing to validate
+		return nil
 	case rv.Kind() == reflect.Struct && shouldDive:
 		return validateStruct(item.items, rv)
 	case rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array:
@@ -288,6 +353,62 @@ func validateSlice(item validationItem, name string, rv reflect.Value) Validatio
 	return nil
 }
 
+// validateGroups verifies the requirements of the field groups, exactly one of the fields in a oneof group must be
+// present and at most one of the fields in an exclusive group. A field counts as present when it does not hold the zero
+// value of its type.
+func validateGroups(items validationItems, rv reflect.Value) validationErrors {
+	errors := make(validationErrors, 0)
+
+	rt := rv.Type()
+
+	groupOrder := make([]string, 0)
+	members := make(map[string][]validationItem)
+	present := make(map[string]int)
+
+	for i := 0; i < rv.NumField(); i++ {
+		item, ok := items[rt.Field(i).Name]
+		if !ok || item.group == "" {
+			continue
+		}
+
+		if _, ok := members[item.group]; !ok {
+			groupOrder = append(groupOrder, item.group)
+		}
+
+		members[item.group] = append(members[item.group], item)
+		if !rv.Field(i).IsZero() {
+			present[item.group]++
+		}
+	}
+
+	for _, group := range groupOrder {
+		fields := make([]string, len(members[group]))
+		labels := make([]string, len(members[group]))
+		for i, item := range members[group] {
+			fields[i] = item.field
+			labels[i] = item.label
+		}
+
+		var err error
+		switch {
+		case members[group][0].groupKind == groupKindOneOf && present[group] != 1:
+			err = fmt.Errorf("expected exactly one of %s to be present, got %d", strings.Join(fields, ", "), present[group])
+		case members[group][0].groupKind == groupKindExclusive && present[group] > 1:
+			err = fmt.Errorf("expected at most one of %s to be present, got %d", strings.Join(fields, ", "), present[group])
+		}
+
+		if err != nil {
+			errors = append(errors, validationError{
+				field: strings.Join(fields, "/"),
+				label: strings.Join(labels, "/"),
+				err:   valueError{err},
+			})
+		}
+	}
+
+	return errors
+}
+
 func validateStruct(items validationItems, rv reflect.Value) ValidationError {
 	errors := make(validationErrors, 0)
 
@@ -312,6 +433,8 @@ func validateStruct(items validationItems, rv reflect.Value) ValidationError {
 		}
 	}
 
+	errors = append(errors, validateGroups(items, rv)...)
+
 	if len(errors) > 0 {
 		return errors
 	}
@@ -338,6 +461,17 @@ func (v *validator) Validate(strct interface{}) ValidationError {
 	}
 
 	err := validateStruct(v.items, rv)
+	if err != nil {
+		return err
+	}
 
-	return err
+	sv, ok := rv.Interface().(selfValidator)
+	if ok {
+		err := sv.ValidateMT()
+		if err != nil {
+			return valueError{err}
+		}
+	}
+
+	return nil
 }
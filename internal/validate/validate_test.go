@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/DennisVis/mt/internal/validate"
 	mttest "github.com/DennisVis/mt/testdata"
@@ -391,3 +392,522 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+type testSelfValidatingSlice []testSubStruct
+
+func (tsvs testSelfValidatingSlice) ValidateMT() error {
+	if len(tsvs) > 2 {
+		return fmt.Errorf("expected at most 2 items, got %d", len(tsvs))
+	}
+
+	return nil
+}
+
+type testSelfValidatingStruct struct {
+	Items testSelfValidatingSlice `mt:"1,O,dive"`
+}
+
+func TestValidateSelfValidator(t *testing.T) {
+	ss := testSubStruct{SubStringVal: strings.Repeat("x", 16)}
+
+	for _, test := range []struct {
+		name        string
+		input       testSelfValidatingStruct
+		expectedErr error
+	}{
+		{
+			name:  "Valid",
+			input: testSelfValidatingStruct{Items: testSelfValidatingSlice{ss, ss}},
+		},
+		{
+			name:        "Invalid",
+			input:       testSelfValidatingStruct{Items: testSelfValidatingSlice{ss, ss, ss}},
+			expectedErr: fmt.Errorf("Items|1|: expected at most 2 items, got 3"),
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			v := validate.MustCreateValidatorForStruct(testSelfValidatingStruct{})
+
+			var err error
+			verr := v.Validate(test.input)
+			if verr != nil {
+				err = verr
+			}
+
+			mttest.ValidateError(t, test.expectedErr, err)
+		})
+	}
+}
+
+type testSelfValidatingTopLevelStruct struct {
+	From string `mt:"1,O,3!a"`
+	To   string `mt:"2,O,3!a"`
+}
+
+func (s testSelfValidatingTopLevelStruct) ValidateMT() error {
+	if s.From != s.To {
+		return fmt.Errorf("expected %s to equal %s", s.From, s.To)
+	}
+
+	return nil
+}
+
+func TestValidateSelfValidatorTopLevel(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		input       testSelfValidatingTopLevelStruct
+		expectedErr error
+	}{
+		{
+			name:  "Valid",
+			input: testSelfValidatingTopLevelStruct{From: "EUR", To: "EUR"},
+		},
+		{
+			name:        "Invalid",
+			input:       testSelfValidatingTopLevelStruct{From: "EUR", To: "USD"},
+			expectedErr: fmt.Errorf("expected EUR to equal USD"),
+		},
+		{
+			name:        "InvalidField",
+			input:       testSelfValidatingTopLevelStruct{From: "EURO", To: "USD"},
+			expectedErr: fmt.Errorf("From|1|: "),
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			v := validate.MustCreateValidatorForStruct(testSelfValidatingTopLevelStruct{})
+
+			var err error
+			verr := v.Validate(test.input)
+			if verr != nil {
+				err = verr
+			}
+
+			mttest.ValidateError(t, test.expectedErr, err)
+		})
+	}
+}
+
+type testMandatorySubStruct struct {
+	Currency string `mt:"M,3!a"`
+}
+
+type testOptionalDiveStruct struct {
+	Sub testMandatorySubStruct `mt:"1,O,dive"`
+}
+
+func TestValidateOptionalStructAbsent(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		input       testOptionalDiveStruct
+		expectedErr error
+	}{
+		{
+			name:  "Absent",
+			input: testOptionalDiveStruct{},
+		},
+		{
+			name:  "PresentValid",
+			input: testOptionalDiveStruct{Sub: testMandatorySubStruct{Currency: "EUR"}},
+		},
+		{
+			name:        "PresentInvalid",
+			input:       testOptionalDiveStruct{Sub: testMandatorySubStruct{Currency: "EU"}},
+			expectedErr: fmt.Errorf("Currency: pattern validation failed"),
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			v := validate.MustCreateValidatorForStruct(testOptionalDiveStruct{})
+
+			var err error
+			verr := v.Validate(test.input)
+			if verr != nil {
+				err = verr
+			}
+
+			mttest.ValidateError(t, test.expectedErr, err)
+		})
+	}
+}
+
+type testOneOfStruct struct {
+	Final        testSubStruct `mt:"1F,O,dive" mtgroup:"1,oneof"`
+	Intermediate testSubStruct `mt:"1M,O,dive" mtgroup:"1,oneof"`
+}
+
+func TestValidateOneOfGroup(t *testing.T) {
+	ss := testSubStruct{SubStringVal: strings.Repeat("x", 16)}
+
+	for _, test := range []struct {
+		name        string
+		input       testOneOfStruct
+		expectedErr error
+	}{
+		{
+			name:  "First",
+			input: testOneOfStruct{Final: ss},
+		},
+		{
+			name:  "Second",
+			input: testOneOfStruct{Intermediate: ss},
+		},
+		{
+			name:        "None",
+			input:       testOneOfStruct{},
+			expectedErr: fmt.Errorf("Final/Intermediate|1F/1M|: expected exactly one of Final, Intermediate to be present, got 0"),
+		},
+		{
+			name:        "Both",
+			input:       testOneOfStruct{Final: ss, Intermediate: ss},
+			expectedErr: fmt.Errorf("expected exactly one of Final, Intermediate to be present, got 2"),
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			v := validate.MustCreateValidatorForStruct(testOneOfStruct{})
+
+			var err error
+			verr := v.Validate(test.input)
+			if verr != nil {
+				err = verr
+			}
+
+			mttest.ValidateError(t, test.expectedErr, err)
+		})
+	}
+}
+
+func TestCreateInvalidGroup(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		createFrom  interface{}
+		expectedErr error
+	}{
+		{
+			name: "MissingKind",
+			createFrom: struct {
+				Val string `mt:"1,O,16x" mtgroup:"1"`
+			}{},
+			expectedErr: fmt.Errorf("mtgroup tag for field Val needs a name and a kind"),
+		},
+		{
+			name: "UnknownKind",
+			createFrom: struct {
+				Val string `mt:"1,O,16x" mtgroup:"1,anyof"`
+			}{},
+			expectedErr: fmt.Errorf("mtgroup tag for field Val contained unknown kind \"anyof\""),
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := validate.CreateValidatorForStruct(test.createFrom)
+			mttest.ValidateError(t, test.expectedErr, err)
+		})
+	}
+}
+
+type testExclusiveSubStruct struct {
+	Identifier  string `mt:"O,16x" mtgroup:"1,exclusive"`
+	NameAddress string `mt:"O,16x" mtgroup:"1,exclusive"`
+}
+
+type testExclusiveStruct struct {
+	Sub testExclusiveSubStruct `mt:"1,O,dive"`
+}
+
+func TestValidateExclusiveGroup(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		input       testExclusiveStruct
+		expectedErr error
+	}{
+		{
+			name:  "None",
+			input: testExclusiveStruct{},
+		},
+		{
+			name:  "One",
+			input: testExclusiveStruct{Sub: testExclusiveSubStruct{NameAddress: "NAME"}},
+		},
+		{
+			name:        "Both",
+			input:       testExclusiveStruct{Sub: testExclusiveSubStruct{Identifier: "ID", NameAddress: "NAME"}},
+			expectedErr: fmt.Errorf("expected at most one of Identifier, NameAddress to be present, got 2"),
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			v := validate.MustCreateValidatorForStruct(testExclusiveStruct{})
+
+			var err error
+			verr := v.Validate(test.input)
+			if verr != nil {
+				err = verr
+			}
+
+			mttest.ValidateError(t, test.expectedErr, err)
+		})
+	}
+}
+
+func TestRelax(t *testing.T) {
+	for _, test := range []struct {
+		name            string
+		input           testStruct
+		labels          []string
+		expectedErr     error
+		expectedRelaxed int
+	}{
+		{
+			name:   "Valid",
+			input:  createTestStruct(func(ts *testStruct) {}),
+			labels: []string{"1"},
+		},
+		{
+			name: "NoLabels",
+			input: createTestStruct(func(ts *testStruct) {
+				ts.StringVal = "123456789012345678901"
+			}),
+			expectedErr: fmt.Errorf("incomplete match"),
+		},
+		{
+			name: "PatternFailure",
+			input: createTestStruct(func(ts *testStruct) {
+				ts.StringVal = "123456789012345678901"
+			}),
+			labels:          []string{"1"},
+			expectedRelaxed: 1,
+		},
+		{
+			name: "PatternFailureOtherLabel",
+			input: createTestStruct(func(ts *testStruct) {
+				ts.StringVal = "123456789012345678901"
+			}),
+			labels:      []string{"4"},
+			expectedErr: fmt.Errorf("incomplete match"),
+		},
+		{
+			name: "MandatoryFailure",
+			input: createTestStruct(func(ts *testStruct) {
+				ts.StringVal = ""
+			}),
+			labels:      []string{"1"},
+			expectedErr: fmt.Errorf("empty mandatory field"),
+		},
+		{
+			name: "SlicePatternFailure",
+			input: createTestStruct(func(ts *testStruct) {
+				ts.StringSliceVal = []string{"1", "123456789012345678901"}
+			}),
+			labels:          []string{"4"},
+			expectedRelaxed: 1,
+		},
+		{
+			name: "Mixed",
+			input: createTestStruct(func(ts *testStruct) {
+				ts.StringVal = "123456789012345678901"
+				ts.StringSliceVal = []string{"123456789012345678901"}
+			}),
+			labels:          []string{"4"},
+			expectedErr:     fmt.Errorf("StringVal|1|: pattern validation failed"),
+			expectedRelaxed: 1,
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			v := validate.MustCreateValidatorForStruct(testStruct{})
+
+			remaining, relaxed := validate.Relax(v.Validate(test.input), test.labels)
+
+			var err error
+			if remaining != nil {
+				err = remaining
+			}
+
+			mttest.ValidateError(t, test.expectedErr, err)
+
+			if len(relaxed) != test.expectedRelaxed {
+				t.Errorf("expected %d relaxed errors, got %d: %v", test.expectedRelaxed, len(relaxed), relaxed)
+			}
+			for _, r := range relaxed {
+				if !strings.Contains(r.Error(), "pattern validation failed") {
+					t.Errorf("expected a pattern validation failure, got: %v", r)
+				}
+			}
+		})
+	}
+}
+
+func TestDates(t *testing.T) {
+	date := func(day, hour int) time.Time {
+		return time.Date(2021, time.March, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	opening := validate.DateField{Field: "Opening", Label: "60F", Time: date(15, 0)}
+
+	for _, test := range []struct {
+		name        string
+		err         validate.ValidationError
+		expectedErr error
+	}{
+		{
+			name: "NotBeforeLater",
+			err:  validate.NotBefore(validate.DateField{Field: "Closing", Time: date(16, 0)}, opening),
+		},
+		{
+			name: "NotBeforeSameDay",
+			err: validate.NotBefore(
+				validate.DateField{Field: "Closing", Time: date(15, 0)},
+				validate.DateField{Field: "Opening", Time: date(15, 12)},
+			),
+		},
+		{
+			name: "NotBeforeAbsent",
+			err:  validate.NotBefore(validate.DateField{Field: "Closing"}, opening),
+		},
+		{
+			name:        "NotBeforeEarlier",
+			err:         validate.NotBefore(validate.DateField{Field: "Closing", Label: "62F", Time: date(14, 0)}, opening),
+			expectedErr: fmt.Errorf("Closing|62F|: date 2021-03-14 is before date 2021-03-15 of Opening"),
+		},
+		{
+			name: "NotAfterEarlier",
+			err:  validate.NotAfter(validate.DateField{Field: "Line", Time: date(14, 0)}, opening),
+		},
+		{
+			name:        "NotAfterLater",
+			err:         validate.NotAfter(validate.DateField{Field: "Line", Label: "61", Time: date(16, 0)}, opening),
+			expectedErr: fmt.Errorf("Line|61|: date 2021-03-16 is after date 2021-03-15 of Opening"),
+		},
+		{
+			name: "JoinNil",
+			err:  validate.Join(nil, nil),
+		},
+		{
+			name: "Join",
+			err: validate.Join(
+				validate.NotAfter(validate.DateField{Field: "Line", Label: "61", Time: date(16, 0)}, opening),
+				nil,
+				validate.NotBefore(validate.DateField{Field: "Closing", Label: "62F", Time: date(14, 0)}, opening),
+			),
+			expectedErr: fmt.Errorf(
+				"Line|61|: date 2021-03-16 is after date 2021-03-15 of Opening\n" +
+					"\tClosing|62F|: date 2021-03-14 is before date 2021-03-15 of Opening",
+			),
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var err error
+			if test.err != nil {
+				err = test.err
+			}
+
+			mttest.ValidateError(t, test.expectedErr, err)
+		})
+	}
+}
+
+func TestValidateFields(t *testing.T) {
+	validator, err := validate.CreateValidatorForFields(map[string]validate.FieldSpec{
+		"20": {Pattern: "16x", Mandatory: true},
+		"32": {Pattern: "3!a15d", Mandatory: true},
+		"72": {Pattern: "6*35x", Repeatable: true},
+	})
+	if err != nil {
+		t.Fatalf("expected nil error, got: %v", err)
+	}
+
+	for _, test := range []struct {
+		name        string
+		fields      interface{}
+		expectedErr error
+	}{
+		{
+			name:   "Valid",
+			fields: map[string][]string{"20": {"REFERENCE"}, "32": {"EUR100,00"}, "72": {"INFO 1", "INFO 2"}},
+		},
+		{
+			name:        "MissingMandatory",
+			fields:      map[string][]string{"20": {"REFERENCE"}},
+			expectedErr: fmt.Errorf("|32|: missing mandatory field 32"),
+		},
+		{
+			name:        "NotRepeatable",
+			fields:      map[string][]string{"20": {"REF1", "REF2"}, "32": {"EUR100,00"}},
+			expectedErr: fmt.Errorf("|20|: field 20 is not repeatable, got 2 occurrences"),
+		},
+		{
+			name:        "PatternMismatch",
+			fields:      map[string][]string{"20": {"REFERENCE"}, "32": {"100,00"}},
+			expectedErr: fmt.Errorf("|32|: pattern validation failed"),
+		},
+		{
+			name:        "RepeatedPatternMismatch",
+			fields:      map[string][]string{"20": {"REFERENCE"}, "32": {"EUR1,00"}, "72": {"OK", strings.Repeat("X", 36)}},
+			expectedErr: fmt.Errorf("|72|:\n\t\t[1]: pattern validation failed"),
+		},
+		{
+			name:        "NotInDictionary",
+			fields:      map[string][]string{"20": {"REFERENCE"}, "32": {"EUR100,00"}, "99": {"UNKNOWN"}},
+			expectedErr: fmt.Errorf("|99|: field 99 is not in the dictionary"),
+		},
+		{
+			name:        "NotAMap",
+			fields:      testStruct{},
+			expectedErr: fmt.Errorf("not a map of fields"),
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			verr := validator.Validate(test.fields)
+			if test.expectedErr == nil && verr != nil {
+				t.Fatalf("expected nil error, got: %v", verr)
+			}
+			if test.expectedErr != nil {
+				mttest.ValidateError(t, test.expectedErr, verr)
+			}
+		})
+	}
+
+	_, err = validate.CreateValidatorForFields(map[string]validate.FieldSpec{"20": {Pattern: "1**"}})
+	mttest.ValidateError(t, fmt.Errorf(`field spec for tag 20 contained invalid pattern "1**"`), err)
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// StreamJSONArray takes as input a reader and will attempt to parse all MT messages in the input and write them to the
+// given writer as a single JSON array. Every message is written as soon as it is parsed, meaning the messages are
+// never all held in memory at the same time.
+//
+// In case of any errors during parsing a custom error is returned that encapsulates the parse errors, the written
+// array will still be valid JSON containing all successfully parsed messages. If writing to the writer fails the write
+// error is returned instead.
+//
+// Example usage:
+//
+//	f, err := os.Open("/path/to/mt/file.txt")
+//	if err != nil {
+//		return fmt.Errorf("could not open file: %w", err)
+//	}
+//	defer f.Close()
+//
+//	err = StreamJSONArray(ctx, f, os.Stdout)
+//	if err != nil {
+//		// handle parse or write errors
+//	}
+func StreamJSONArray(ctx context.Context, rd io.Reader, w io.Writer, options ...option) error {
+	messagesCh, parseErrorsCh := ParseMTx(ctx, rd, options...)
+
+	parseErrors := make(Errors, 0)
+
+	wg := &sync.WaitGroup{}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		for err := range parseErrorsCh {
+			parseErrors = append(parseErrors, err)
+		}
+	}()
+
+	var writeErr error
+	write := func(bts []byte) {
+		if writeErr != nil {
+			return
+		}
+
+		_, writeErr = w.Write(bts)
+	}
+
+	write([]byte("["))
+
+	first := true
+	for msg := range messagesCh {
+		// keep draining the channel after a write error so the parsing goroutines can finish
+		if writeErr != nil {
+			continue
+		}
+
+		bts, err := json.Marshal(msg)
+		if err != nil {
+			writeErr = fmt.Errorf("could not encode message on line %d as JSON: %w", msg.Line, err)
+			continue
+		}
+
+		if !first {
+			write([]byte(","))
+		}
+		write(bts)
+
+		first = false
+	}
+
+	write([]byte("]"))
+
+	wg.Wait()
+
+	if writeErr != nil {
+		return fmt.Errorf("could not write JSON array: %w", writeErr)
+	}
+
+	if len(parseErrors) > 0 {
+		return parseErrors
+	}
+
+	return nil
+}
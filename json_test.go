@@ -0,0 +1,74 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DennisVis/mt"
+	mttest "github.com/DennisVis/mt/testdata"
+)
+
+type failingWriter struct{}
+
+func (fw failingWriter) Write(p []byte) (int, error) {
+	return 0, mttest.ErrReadInvalid
+}
+
+func TestStreamJSONArray(t *testing.T) {
+	for _, test := range []struct {
+		name          string
+		input         string
+		expectedCount int
+	}{
+		{
+			name:          "Empty",
+			input:         "",
+			expectedCount: 0,
+		},
+		{
+			name:          "SingleMessage",
+			input:         messageInput,
+			expectedCount: 1,
+		},
+		{
+			name:          "MultipleMessages",
+			input:         messageInput + "\n" + messageInput + "\n" + messageInput,
+			expectedCount: 3,
+		},
+	} {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := &bytes.Buffer{}
+
+			err := mt.StreamJSONArray(ctx, strings.NewReader(test.input), buf)
+			mttest.ValidateError(t, nil, err)
+
+			var messages []map[string]interface{}
+			err = json.Unmarshal(buf.Bytes(), &messages)
+			if err != nil {
+				t.Fatalf("expected valid JSON array, got error: %v\n%s", err, buf.String())
+			}
+			if len(messages) != test.expectedCount {
+				t.Errorf("expected %d messages, got %d", test.expectedCount, len(messages))
+			}
+		})
+	}
+
+	t.Run("WriteError", func(t *testing.T) {
+		t.Parallel()
+
+		err := mt.StreamJSONArray(ctx, strings.NewReader(messageInput), failingWriter{})
+		mttest.ValidateError(t, fmt.Errorf("could not write JSON array: %w", mttest.ErrReadInvalid), err)
+	})
+}
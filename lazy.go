@@ -0,0 +1,126 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt
+
+import (
+	"sync"
+
+	"github.com/DennisVis/mt/internal/message"
+)
+
+// lazyBody holds the verbatim body of a message parsed with the LazyBody option. Its fields are parsed on first access,
+// a single field when accessed through MTx.Field and all fields once the body is loaded. The parsed fields are kept,
+// shared by all copies of the message.
+type lazyBody struct {
+	raw                string
+	preserveWhitespace bool
+	transform          func(map[string][]string) map[string][]string
+
+	mu       sync.Mutex
+	fields   map[string][]string
+	order    []string
+	complete bool
+}
+
+func newLazyBody(raw string, cfg config) *lazyBody {
+	return &lazyBody{
+		raw:                raw,
+		preserveWhitespace: cfg.PreserveWhitespace,
+		transform:          cfg.transformBody,
+		fields:             make(map[string][]string),
+	}
+}
+
+// field returns the values of a single field, parsing only that field unless the body consists of sub blocks.
+func (lb *lazyBody) field(tag string) []string {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	if values, ok := lb.fields[tag]; ok || lb.complete {
+		return values
+	}
+
+	values, ok := message.BodyField(lb.raw, tag, lb.preserveWhitespace)
+	if !ok {
+		lb.parseAll()
+		return lb.fields[tag]
+	}
+
+	if len(values) > 0 {
+		values = lb.transform(map[string][]string{tag: values})[tag]
+	}
+	lb.fields[tag] = values
+
+	return values
+}
+
+// body returns all fields and their order, parsing the entire body on first call.
+func (lb *lazyBody) body() (map[string][]string, []string) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	if !lb.complete {
+		lb.parseAll()
+	}
+
+	return lb.fields, lb.order
+}
+
+// parseAll parses all fields of the body, replacing the fields parsed individually so far. The lock must be held.
+func (lb *lazyBody) parseAll() {
+	fields, order := message.ParseBody(lb.raw, lb.preserveWhitespace)
+
+	lb.fields = lb.transform(fields)
+	lb.order = order
+	lb.complete = true
+}
+
+// Field returns the values of the field with the given tag in the order they appeared in, nil if it is absent. For a
+// message parsed with the LazyBody option only this field is parsed from RawBody, on first access, other messages
+// simply hold it in their Body.
+func (m MTx) Field(tag string) []string {
+	if m.lazy == nil || m.Body != nil {
+		return m.Body[tag]
+	}
+
+	return m.lazy.field(tag)
+}
+
+// IsPresent returns true if the field with the given tag was present in the body of the message. For a message parsed
+// with the LazyBody option only this field is parsed from RawBody, as Field does, as PresentFields is only populated
+// once the body is loaded.
+func (m MTx) IsPresent(tag string) bool {
+	if m.lazy == nil || m.Body != nil {
+		return m.Base.IsPresent(tag)
+	}
+
+	return len(m.lazy.field(tag)) > 0
+}
+
+// Fields returns the fields of the body keyed by their tag, like Body. For a message parsed with the LazyBody option
+// the body is loaded first, see LoadBody, so unlike Body the fields can be iterated regardless of how the message was
+// parsed.
+func (m MTx) Fields() map[string][]string {
+	return m.loaded().Body
+}
+
+// LoadBody populates Body, FieldOrder and PresentFields of a message parsed with the LazyBody option by parsing all
+// fields from RawBody, so the body can be iterated as usual. It has no effect on other messages, or once loaded.
+func (m *MTx) LoadBody() {
+	if m.lazy == nil || m.Body != nil {
+		return
+	}
+
+	m.Body, m.FieldOrder = m.lazy.body()
+	m.PresentFields = presentFields(m.Body)
+}
+
+// loaded returns the message with its body loaded, see LoadBody.
+func (m MTx) loaded() MTx {
+	m.LoadBody()
+
+	return m
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt
+
+import (
+	"reflect"
+	"time"
+)
+
+// localizer is implemented by the time types holding a date and/or time without a zone, which are parsed as UTC. Their
+// time can be moved to another location, keeping the date and time as given, see the Location option.
+type localizer interface {
+	inLocation(loc *time.Location)
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// wallClockIn returns the time with the same date and time of day as t, but in the given location.
+func wallClockIn(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+func (d *Time) inLocation(loc *time.Location) {
+	if d.Set {
+		d.Time = wallClockIn(d.Time, loc)
+	}
+}
+
+func (m *Month) inLocation(loc *time.Location) {
+	if m.Set {
+		m.Time = wallClockIn(m.Time, loc)
+	}
+}
+
+func (d *Date) inLocation(loc *time.Location) {
+	if d.Set {
+		d.Time = wallClockIn(d.Time, loc)
+	}
+}
+
+func (d *DateTime) inLocation(loc *time.Location) {
+	if d.Set {
+		d.Time = wallClockIn(d.Time, loc)
+	}
+}
+
+func (d *DateOrDateTime) inLocation(loc *time.Location) {
+	if d.Set {
+		d.Time = wallClockIn(d.Time, loc)
+	}
+}
+
+func (d *DateTimeSec) inLocation(loc *time.Location) {
+	if d.Set {
+		d.Time = wallClockIn(d.Time, loc)
+	}
+}
+
+func (d *DateTimeSecCent) inLocation(loc *time.Location) {
+	if d.Set {
+		d.Time = wallClockIn(d.Time, loc)
+	}
+}
+
+func (d *DateTimeSecOptCent) inLocation(loc *time.Location) {
+	if d.Set {
+		d.Time = wallClockIn(d.Time, loc)
+	}
+}
+
+// localize moves the times of all zone-less time types within the value v points to, e.g. a typed message, to the
+// location configured by the Location option. Times with an explicit offset, e.g. DateTimeIndication, are left as is.
+func (cfg config) localize(v interface{}) {
+	if cfg.Location == nil {
+		return
+	}
+
+	localizeValue(reflect.ValueOf(v), cfg.Location)
+}
+
+func localizeValue(rv reflect.Value, loc *time.Location) {
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if !rv.IsNil() {
+			localizeValue(rv.Elem(), loc)
+		}
+	case reflect.Struct:
+		if rv.Type() == timeType || !rv.CanAddr() {
+			return
+		}
+
+		if l, ok := rv.Addr().Interface().(localizer); ok {
+			l.inLocation(loc)
+			return
+		}
+
+		for i := 0; i < rv.NumField(); i++ {
+			// unexported fields, e.g. the lazily parsed body of an MTx, hold no times
+			if rv.Type().Field(i).PkgPath != "" {
+				continue
+			}
+
+			localizeValue(rv.Field(i), loc)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			localizeValue(rv.Index(i), loc)
+		}
+	}
+}
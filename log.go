@@ -0,0 +1,64 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	// levels passed to the function set by the WithLogger option
+	logLevelInfo  = "info"
+	logLevelWarn  = "warn"
+	logLevelError = "error"
+)
+
+// log passes the formatted message to the logger set by the WithLogger option, if any.
+func (cfg config) log(ctx context.Context, level, format string, args ...interface{}) {
+	if cfg.Logger == nil {
+		return
+	}
+
+	cfg.Logger(ctx, level, fmt.Sprintf(format, args...))
+}
+
+// logError logs an error parsing carries on after, warnings at level warn and errors at level error.
+func (cfg config) logError(ctx context.Context, err Error) {
+	level := logLevelError
+	if err.IsWarning() {
+		level = logLevelWarn
+	}
+
+	cfg.log(ctx, level, "%s", err.String())
+}
+
+// logProcessed logs the outcome of processing a message, the errors encountered and whether it was discarded.
+func (cfg config) logProcessed(ctx context.Context, mtx MTx, errs Errors, ok bool) {
+	for _, err := range errs {
+		cfg.logError(ctx, err)
+	}
+
+	if !ok {
+		cfg.log(ctx, logLevelWarn, "discarded message on line %d", mtx.Line)
+	}
+}
+
+// onSkipped returns the function the underlying message parser calls with content it skips, which logs the skip and
+// hands the content to the function set by the SkippedContent option, if any.
+func (cfg config) onSkipped(ctx context.Context) func(content string, line int) {
+	if cfg.Logger == nil {
+		return cfg.SkippedContent
+	}
+
+	return func(content string, line int) {
+		cfg.log(ctx, logLevelInfo, "skipped %d bytes of content outside of a message on line %d", len(content), line)
+
+		if cfg.SkippedContent != nil {
+			cfg.SkippedContent(content, line)
+		}
+	}
+}
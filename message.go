@@ -6,10 +6,16 @@
 package mt
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
+	"unicode/utf8"
 )
 
 // ApplicationID identifies the application within which the message is being sent or received. The available options
@@ -40,6 +46,28 @@ func (aid ApplicationID) RawString() string {
 	return aid.String()
 }
 
+// Direction indicates whether a message is an input message, sent to SWIFT, or an output message, received from SWIFT.
+type Direction int
+
+const (
+	DirectionInput  Direction = iota // I
+	DirectionOutput                  // O
+)
+
+func (d Direction) String() string {
+	switch d {
+	case DirectionOutput:
+		return "O"
+	// DirectionInput
+	default:
+		return "I"
+	}
+}
+
+func (d Direction) RawString() string {
+	return d.String()
+}
+
 // ServiceID consists of two numeric characters. It identifies the type of data that is being sent or received and, in
 // doing so, whether the message which follows is one of the following: a user-to-user message, a system message, a
 // service message, for example, a session control command, such as SELECT, or a logical acknowledgment, such as
@@ -159,14 +187,84 @@ func creditDebitFromString(input string) (CreditDebit, error) {
 	}
 }
 
-// Balance represents the balance of a given account at a given date.
+// Amount represents a monetary amount as found in MT messages, e.g. 40000,00. The amount is stored exactly, as a
+// whole number of fractional units together with the number of decimals, to prevent any loss of precision floating
+// point numbers would incur for large amounts.
+type Amount struct {
+	Set      bool
+	Raw      string
+	Value    int64
+	Decimals int
+}
+
+func (a *Amount) UnmarshalMT(input string) error {
+	// example:
+	// 40000,00
+
+	commaIdx := strings.Index(input, ",")
+	if commaIdx < 1 || strings.Count(input, ",") > 1 {
+		return fmt.Errorf("amount: invalid amount: %s", input)
+	}
+
+	digits := input[:commaIdx] + input[commaIdx+1:]
+	for _, r := range digits {
+		if !unicode.IsDigit(r) {
+			return fmt.Errorf("amount: invalid amount: %s", input)
+		}
+	}
+
+	value, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return fmt.Errorf("amount: invalid amount: %s: %w", input, err)
+	}
+
+	a.Set = true
+	a.Raw = input
+	a.Value = value
+	a.Decimals = len(input) - commaIdx - 1
+
+	return nil
+}
+
+// Float64 returns the amount as a float64. Be aware that this can lose precision for very large amounts.
+func (a Amount) Float64() float64 {
+	return float64(a.Value) / math.Pow10(a.Decimals)
+}
+
+func (a Amount) RawString() string {
+	return a.Raw
+}
+
+// MarshalMT formats the amount from its value and decimals, e.g. 40000,00.
+func (a Amount) MarshalMT() (string, error) {
+	if a.Value < 0 || a.Decimals < 0 {
+		return "", fmt.Errorf("amount: can not marshal negative amount or decimals: %d, %d", a.Value, a.Decimals)
+	}
+
+	digits := strconv.FormatInt(a.Value, 10)
+	if len(digits) <= a.Decimals {
+		digits = strings.Repeat("0", a.Decimals-len(digits)+1) + digits
+	}
+
+	commaIdx := len(digits) - a.Decimals
+
+	return digits[:commaIdx] + "," + digits[commaIdx:], nil
+}
+
+func (a Amount) String() string {
+	return a.RawString()
+}
+
+// Balance represents the balance of a given account at a given date. Option is the option letter of the tag the balance
+// was found under, e.g. F for 60F or M for 62M, and is empty for fields without options, e.g. 64.
 type Balance struct {
 	Set         bool
 	Raw         string
+	Option      string
 	CreditDebit CreditDebit `mt:"M,1!a"`
 	Date        Date        `mt:"M,6!n"`
 	Currency    string      `mt:"M,3!a"`
-	Amount      float32     `mt:"M,15d"`
+	Amount      Amount      `mt:"M,15d"`
 }
 
 func (b *Balance) UnmarshalMT(input string) error {
@@ -187,36 +285,374 @@ func (b *Balance) UnmarshalMT(input string) error {
 	}
 	b.CreditDebit = creditDebit
 
-	// mandatory, 6!n
-	dateStr := input[1:7]
-	d := Date{}
-	err = d.UnmarshalMT(dateStr)
+	// mandatory, 6!n3!a15d
+	date, currency, amount, err := unmarshalDateCurrencyAmount(input[1:])
 	if err != nil {
-		return fmt.Errorf("balance: invalid date")
+		return fmt.Errorf("balance: %w", err)
 	}
-	b.Date = d
+	b.Date = date
+	b.Currency = currency
+	b.Amount = amount
 
-	// mandatory, 3!a
-	b.Currency = input[7:10]
+	b.Set = true
+	b.Raw = input
 
-	// mandatory, 15d
-	amountStr := input[10:]
-	amount, err := strconv.ParseFloat(strings.ReplaceAll(amountStr, ",", "."), 32)
+	return nil
+}
+
+// UnmarshalMTTag parses the balance and records the option letter of the tag it was found under, e.g. M for 62M.
+func (b *Balance) UnmarshalMTTag(tag, input string) error {
+	err := b.UnmarshalMT(input)
 	if err != nil {
-		return fmt.Errorf("balance: invalid amount")
+		return err
 	}
-	b.Amount = float32(amount)
 
-	b.Set = true
-	b.Raw = input
+	b.Option = strings.TrimLeft(tag, "0123456789")
 
 	return nil
 }
 
+// MTOption returns the option letter the balance is to be written under, see Option.
+func (b Balance) MTOption() string {
+	return b.Option
+}
+
 func (b Balance) RawString() string {
 	return b.Raw
 }
 
+// MarshalMT formats the balance from its fields, e.g. C031002PLN40000,00.
+func (b Balance) MarshalMT() (string, error) {
+	dateCurrencyAmount, err := marshalDateCurrencyAmount(b.Date, b.Currency, b.Amount)
+	if err != nil {
+		return "", fmt.Errorf("balance: %w", err)
+	}
+
+	return b.CreditDebit.RawString() + dateCurrencyAmount, nil
+}
+
+// Signed returns the amount of the balance as a float64, negative for a debit balance and positive for a credit
+// balance. Be aware that this can lose precision for very large amounts.
+func (b Balance) Signed() float64 {
+	if b.CreditDebit == Debit {
+		return -b.Amount.Float64()
+	}
+
+	return b.Amount.Float64()
+}
+
+var (
+	errInvalidDate   = errors.New("invalid date")
+	errInvalidAmount = errors.New("invalid amount")
+)
+
+// unmarshalCurrencyAmount parses the currency and amount, 3!a15d, shared by many fields.
+func unmarshalCurrencyAmount(input string) (string, Amount, error) {
+	if len(input) < 4 {
+		return "", Amount{}, errInvalidAmount
+	}
+
+	// mandatory, 3!a
+	currency := input[0:3]
+
+	// mandatory, 15d
+	amount := Amount{}
+	err := amount.UnmarshalMT(input[3:])
+	if err != nil {
+		return "", Amount{}, errInvalidAmount
+	}
+
+	return currency, amount, nil
+}
+
+// unmarshalDateCurrencyAmount parses the date, currency and amount, 6!n3!a15d, shared by many fields.
+func unmarshalDateCurrencyAmount(input string) (Date, string, Amount, error) {
+	if len(input) < 6 {
+		return Date{}, "", Amount{}, errInvalidDate
+	}
+
+	// mandatory, 6!n
+	date := Date{}
+	err := date.UnmarshalMT(input[0:6])
+	if err != nil {
+		return Date{}, "", Amount{}, errInvalidDate
+	}
+
+	currency, amount, err := unmarshalCurrencyAmount(input[6:])
+	if err != nil {
+		return Date{}, "", Amount{}, err
+	}
+
+	return date, currency, amount, nil
+}
+
+func marshalCurrencyAmount(currency string, amount Amount) (string, error) {
+	amountStr, err := amount.MarshalMT()
+	if err != nil {
+		return "", err
+	}
+
+	return currency + amountStr, nil
+}
+
+func marshalDateCurrencyAmount(date Date, currency string, amount Amount) (string, error) {
+	dateStr, err := date.MarshalMT()
+	if err != nil {
+		return "", err
+	}
+
+	currencyAmount, err := marshalCurrencyAmount(currency, amount)
+	if err != nil {
+		return "", err
+	}
+
+	return dateStr + currencyAmount, nil
+}
+
+// CurrencyAmount represents a currency and amount pair, as found in e.g. field 32B.
+type CurrencyAmount struct {
+	Set      bool
+	Raw      string
+	Currency string `mt:"M,3!a"`
+	Amount   Amount `mt:"M,15d"`
+}
+
+func (ca *CurrencyAmount) UnmarshalMT(input string) error {
+	// example:
+	// PLN40000,00
+
+	// min: currency plus at least 2 for amount
+	// max: currency plus max 15 for amount
+	if len(input) < 5 || len(input) > 18 {
+		return fmt.Errorf("currency amount: invalid input length: %d", len(input))
+	}
+
+	currency, amount, err := unmarshalCurrencyAmount(input)
+	if err != nil {
+		return fmt.Errorf("currency amount: %w", err)
+	}
+	ca.Currency = currency
+	ca.Amount = amount
+
+	ca.Set = true
+	ca.Raw = input
+
+	return nil
+}
+
+func (ca CurrencyAmount) RawString() string {
+	return ca.Raw
+}
+
+// MarshalMT formats the currency and amount from its fields, e.g. PLN40000,00.
+func (ca CurrencyAmount) MarshalMT() (string, error) {
+	currencyAmount, err := marshalCurrencyAmount(ca.Currency, ca.Amount)
+	if err != nil {
+		return "", fmt.Errorf("currency amount: %w", err)
+	}
+
+	return currencyAmount, nil
+}
+
+// DateCurrencyAmount represents a value date, currency and amount triple, as found in field 32A of e.g. MT900, MT910,
+// MT103 and MT202.
+type DateCurrencyAmount struct {
+	Set      bool
+	Raw      string
+	Date     Date   `mt:"M,6!n"`
+	Currency string `mt:"M,3!a"`
+	Amount   Amount `mt:"M,15d"`
+}
+
+func (dca *DateCurrencyAmount) UnmarshalMT(input string) error {
+	// example:
+	// 031002PLN40000,00
+
+	// min: all fixed length fields plus at least 2 for amount
+	// max: all fixed length fields plus max 15 for amount
+	if len(input) < 11 || len(input) > 24 {
+		return fmt.Errorf("date currency amount: invalid input length: %d", len(input))
+	}
+
+	date, currency, amount, err := unmarshalDateCurrencyAmount(input)
+	if err != nil {
+		return fmt.Errorf("date currency amount: %w", err)
+	}
+	dca.Date = date
+	dca.Currency = currency
+	dca.Amount = amount
+
+	dca.Set = true
+	dca.Raw = input
+
+	return nil
+}
+
+func (dca DateCurrencyAmount) RawString() string {
+	return dca.Raw
+}
+
+// MarshalMT formats the date, currency and amount from its fields, e.g. 031002PLN40000,00.
+func (dca DateCurrencyAmount) MarshalMT() (string, error) {
+	dateCurrencyAmount, err := marshalDateCurrencyAmount(dca.Date, dca.Currency, dca.Amount)
+	if err != nil {
+		return "", fmt.Errorf("date currency amount: %w", err)
+	}
+
+	return dateCurrencyAmount, nil
+}
+
+// NumberAndSum represents the number and sum of entries, as found in fields 90D and 90C of e.g. MT941.
+type NumberAndSum struct {
+	Set      bool
+	Raw      string
+	Number   int    `mt:"M,5n"`
+	Currency string `mt:"M,3!a"`
+	Amount   Amount `mt:"M,15d"`
+}
+
+func (ns *NumberAndSum) UnmarshalMT(input string) error {
+	// example:
+	// 12PLN40000,00
+
+	// min: at least 1 for number, currency plus at least 2 for amount
+	// max: max 5 for number, currency plus max 15 for amount
+	if len(input) < 6 || len(input) > 23 {
+		return fmt.Errorf("number and sum: invalid input length: %d", len(input))
+	}
+
+	// mandatory, 5n
+	numberLen := strings.IndexFunc(input, isNotDigit)
+	if numberLen < 1 || numberLen > 5 {
+		return fmt.Errorf("number and sum: invalid number of entries: %s", input)
+	}
+
+	number, err := strconv.Atoi(input[:numberLen])
+	if err != nil {
+		return fmt.Errorf("number and sum: invalid number of entries: %w", err)
+	}
+	ns.Number = number
+
+	// mandatory, 3!a15d
+	currency, amount, err := unmarshalCurrencyAmount(input[numberLen:])
+	if err != nil {
+		return fmt.Errorf("number and sum: %w", err)
+	}
+	ns.Currency = currency
+	ns.Amount = amount
+
+	ns.Set = true
+	ns.Raw = input
+
+	return nil
+}
+
+func (ns NumberAndSum) RawString() string {
+	return ns.Raw
+}
+
+// MarshalMT formats the number, currency and amount from its fields, e.g. 12PLN40000,00.
+func (ns NumberAndSum) MarshalMT() (string, error) {
+	if ns.Number < 0 {
+		return "", fmt.Errorf("number and sum: can not marshal negative number of entries: %d", ns.Number)
+	}
+
+	currencyAmount, err := marshalCurrencyAmount(ns.Currency, ns.Amount)
+	if err != nil {
+		return "", fmt.Errorf("number and sum: %w", err)
+	}
+
+	return strconv.Itoa(ns.Number) + currencyAmount, nil
+}
+
+// FloorLimit represents the floor limit, field 34F, for which messages are reported. The indicator is either D for
+// the debit floor limit, C for the credit floor limit or empty when the floor limit applies to both.
+type FloorLimit struct {
+	Set       bool
+	Raw       string
+	Currency  string `mt:"M,3!a"`
+	Indicator string `mt:"O,1!a"`
+	Amount    Amount `mt:"M,15d"`
+}
+
+func (fl *FloorLimit) UnmarshalMT(input string) error {
+	// example:
+	// PLN0,
+	// PLND100,00
+
+	// min: currency plus at least 2 for amount
+	// max: currency, indicator and max 15 for amount
+	if len(input) < 5 || len(input) > 19 {
+		return fmt.Errorf("floor limit: invalid input length: %d", len(input))
+	}
+
+	// optional, 1!a
+	rest := input[3:]
+	if strings.HasPrefix(rest, "D") || strings.HasPrefix(rest, "C") {
+		fl.Indicator = rest[0:1]
+		rest = rest[1:]
+	}
+
+	// mandatory, 3!a15d
+	currency, amount, err := unmarshalCurrencyAmount(input[0:3] + rest)
+	if err != nil {
+		return fmt.Errorf("floor limit: %w", err)
+	}
+	fl.Currency = currency
+	fl.Amount = amount
+
+	fl.Set = true
+	fl.Raw = input
+
+	return nil
+}
+
+func (fl FloorLimit) RawString() string {
+	return fl.Raw
+}
+
+// AppliesToDebit returns true if the floor limit applies to debit entries, either explicitly or because it has no
+// indicator.
+func (fl FloorLimit) AppliesToDebit() bool {
+	return fl.Indicator != "C"
+}
+
+// AppliesToCredit returns true if the floor limit applies to credit entries, either explicitly or because it has no
+// indicator.
+func (fl FloorLimit) AppliesToCredit() bool {
+	return fl.Indicator != "D"
+}
+
+// FloorLimits holds the floor limits of a message. Field 34F may occur at most twice, in which case the first
+// occurrence holds the debit floor limit and the second the credit floor limit.
+type FloorLimits []FloorLimit
+
+// ValidateMT verifies at most two floor limits are present and, if there are two, they have distinct indicators.
+func (fls FloorLimits) ValidateMT() error {
+	switch {
+	case len(fls) > 2:
+		return fmt.Errorf("floor limits: expected at most 2 occurrences, got %d", len(fls))
+	case len(fls) == 2 && fls[0].Indicator == fls[1].Indicator:
+		return fmt.Errorf("floor limits: expected distinct indicators, got %q twice", fls[0].Indicator)
+	}
+
+	return nil
+}
+
+// validateStrict verifies that, if there are two floor limits, the first is the debit floor limit and the second the
+// credit floor limit. A floor limit without indicator applies to both and must therefore be the only one.
+func (fls FloorLimits) validateStrict() error {
+	if len(fls) == 2 && (fls[0].Indicator != "D" || fls[1].Indicator != "C") {
+		return fmt.Errorf(
+			"floor limits: expected debit followed by credit indicator, got %q and %q",
+			fls[0].Indicator,
+			fls[1].Indicator,
+		)
+	}
+
+	return nil
+}
+
 type FundsCode int
 
 const (
@@ -244,17 +680,32 @@ func (fc FundsCode) RawString() string {
 	return fc.String()
 }
 
+const (
+	bankReferencePrefix = "//"
+	bankReferenceMaxLen = 16
+)
+
+// StatementLine represents a single transaction, field 61, within a statement. The field consists of the following
+// subfields, in order: value date, entry date, credit/debit mark, amount, transaction type identification code,
+// reference for the account owner, reference of the account servicing institution prefixed by // and supplementary
+// details on the next line.
+//
+// Information holds the information to the account owner, field 86, directly following the statement line in an MT940.
+// It's not part of field 61 itself and therefore neither decoded nor generated by UnmarshalMT and MarshalMT.
+//
+// The entry date only holds a month and day. UnmarshalMT infers its year from the value date, see EntryDateFull.
 type StatementLine struct {
 	Set                   bool
 	Raw                   string
 	Date                  Date      `mt:"M,6!n"`
 	EntryDate             Month     `mt:"O,4!n"`
 	FundsCode             FundsCode `mt:"M,2a"`
-	Amount                float64   `mt:"M,15d"`
+	Amount                Amount    `mt:"M,15d"`
 	SwiftCode             string    `mt:"M,1!a3!c"`
 	AccountOwnerReference string    `mt:"M,16x"`
-	BankReference         string    `mt:"O,//20x"`
-	Description           string    `mt:"O,34a"`
+	BankReference         string    `mt:"O,//16x"`
+	Description           string    `mt:"O,34x"`
+	Information           string    `mt:"O,6*65x"`
 }
 
 func (sl *StatementLine) UnmarshalMT(input string) error {
@@ -287,6 +738,9 @@ func (sl *StatementLine) UnmarshalMT(input string) error {
 			return fmt.Errorf("statement line: invalid entry date")
 		}
 		sl.EntryDate = month
+		if entryDate := sl.EntryDateFull(); !entryDate.IsZero() {
+			sl.EntryDate.Time = entryDate
+		}
 		line1 = line1[4:]
 	}
 
@@ -315,10 +769,11 @@ func (sl *StatementLine) UnmarshalMT(input string) error {
 
 	// mandatory, 15d
 	amountStr := line1[0:amountNrOfDigits]
-	// above we've made sure to only regard digits and commas
-	// therefore we cane safely ignore the error
-	//nolint
-	amount, _ := strconv.ParseFloat(strings.ReplaceAll(amountStr, ",", "."), 32)
+	amount := Amount{}
+	err = amount.UnmarshalMT(amountStr)
+	if err != nil {
+		return fmt.Errorf("statement line: invalid amount")
+	}
 	sl.Amount = amount
 	line1 = line1[amountNrOfDigits:]
 
@@ -326,17 +781,38 @@ func (sl *StatementLine) UnmarshalMT(input string) error {
 	sl.SwiftCode = line1[0:4]
 	line1 = line1[4:]
 
-	split := strings.Split(line1, "//")
-	if len(split) == 2 {
-		sl.AccountOwnerReference = split[0]
-		sl.BankReference = "//" + split[1]
-	} else {
+	// mandatory, 16x
+	// the account owner reference runs up to the first //, any further / or // belong to the bank reference
+	bankReferenceIdx := strings.Index(line1, bankReferencePrefix)
+	if bankReferenceIdx < 0 {
 		sl.AccountOwnerReference = line1
+		line1 = ""
+	} else {
+		sl.AccountOwnerReference = line1[0:bankReferenceIdx]
+		line1 = line1[bankReferenceIdx+len(bankReferencePrefix):]
+	}
+
+	// optional, //16x
+	if bankReferenceIdx >= 0 {
+		bankReferenceLen := len(line1)
+		if bankReferenceLen > bankReferenceMaxLen {
+			bankReferenceLen = bankReferenceMaxLen
+		}
+
+		sl.BankReference = bankReferencePrefix + line1[0:bankReferenceLen]
+		line1 = line1[bankReferenceLen:]
 	}
 
+	// optional, 34x
+	// supplementary details belong on the next line, though anything left on the first line is regarded as such too
+	details := make([]string, 0, 2)
+	if line1 != "" {
+		details = append(details, line1)
+	}
 	if len(lines) > 1 {
-		sl.Description = lines[1]
+		details = append(details, lines[1])
 	}
+	sl.Description = strings.Join(details, "\n")
 
 	sl.Set = true
 	sl.Raw = input
@@ -348,7 +824,256 @@ func (sl StatementLine) RawString() string {
 	return sl.Raw
 }
 
-// OutputReference is a reference to an output message containing both the send date and time of said message.
+// EntryDateFull returns the entry date, including the year that is not part of the field itself. The year is that of
+// the value date, unless the dates lie around the turn of the year, e.g. a value date of 31 December with an entry
+// date of 2 January, in which case the entry date falls in the next or the previous year. The entry date closest to
+// the value date is chosen. Without an entry date, or a value date, the zero time is returned.
+func (sl StatementLine) EntryDateFull() time.Time {
+	if !sl.EntryDate.Set || !sl.Date.Set {
+		return time.Time{}
+	}
+
+	valueDate := sl.Date.Time
+	_, month, day := sl.EntryDate.Time.Date()
+
+	var closest time.Time
+	for _, year := range []int{valueDate.Year(), valueDate.Year() + 1, valueDate.Year() - 1} {
+		entryDate := time.Date(year, month, day, 0, 0, 0, 0, valueDate.Location())
+		// 29 February only exists in leap years
+		if entryDate.Day() != day {
+			continue
+		}
+
+		if closest.IsZero() || absDuration(entryDate.Sub(valueDate)) < absDuration(closest.Sub(valueDate)) {
+			closest = entryDate
+		}
+	}
+
+	return closest
+}
+
+// Signed returns the amount of the statement line as a float64, negative when it decreases the balance and positive
+// when it increases it. A reversal is booked opposite to the entry it reverses, so a reversal of a credit, RC, is
+// negative and a reversal of a debit, RD, is positive. Be aware that this can lose precision for very large amounts.
+func (sl StatementLine) Signed() float64 {
+	switch sl.FundsCode {
+	case FundsCodeDebit, FundsCodeCreditReversal:
+		return -sl.Amount.Float64()
+	default:
+		return sl.Amount.Float64()
+	}
+}
+
+// MarshalMT formats the statement line from its fields, with the supplementary details, if any, on the second line.
+func (sl StatementLine) MarshalMT() (string, error) {
+	date, err := sl.Date.MarshalMT()
+	if err != nil {
+		return "", fmt.Errorf("statement line: %w", err)
+	}
+
+	entryDate := ""
+	if sl.EntryDate.Set {
+		entryDate, err = sl.EntryDate.MarshalMT()
+		if err != nil {
+			return "", fmt.Errorf("statement line: %w", err)
+		}
+	}
+
+	amount, err := sl.Amount.MarshalMT()
+	if err != nil {
+		return "", fmt.Errorf("statement line: %w", err)
+	}
+
+	line := date + entryDate + sl.FundsCode.RawString() + amount + sl.SwiftCode + sl.AccountOwnerReference +
+		sl.BankReference
+
+	if sl.Description != "" {
+		line += "\n" + sl.Description
+	}
+
+	return line, nil
+}
+
+// NarrativeItem is a single coded entry within a StructuredNarrative.
+type NarrativeItem struct {
+	Code string
+	Text string
+}
+
+// StructuredNarrative represents narrative fields that follow the /CODE/text convention, such as field 72 sender to
+// receiver information. Every line starting with a code starts a new item, lines starting with // continue the text of
+// the item before it and lines without a code result in an item with an empty code.
+type StructuredNarrative struct {
+	Set   bool
+	Raw   string
+	Items []NarrativeItem
+}
+
+func (sn *StructuredNarrative) UnmarshalMT(input string) error {
+	// example:
+	// /ACC/INSTRUCTION FOR THE
+	// //ACCOUNT WITH INSTITUTION
+	// /INS/ABNANL2A
+
+	items := make([]NarrativeItem, 0)
+
+	for _, line := range strings.Split(input, "\n") {
+		switch {
+		case strings.HasPrefix(line, "//") && len(items) > 0:
+			items[len(items)-1].Text += "\n" + line[2:]
+		case strings.HasPrefix(line, "//"):
+			items = append(items, NarrativeItem{Text: line[2:]})
+		case strings.HasPrefix(line, "/") && strings.Index(line[1:], "/") > 0:
+			codeEnd := strings.Index(line[1:], "/") + 1
+			items = append(items, NarrativeItem{Code: line[1:codeEnd], Text: line[codeEnd+1:]})
+		default:
+			items = append(items, NarrativeItem{Text: line})
+		}
+	}
+
+	sn.Set = true
+	sn.Raw = input
+	sn.Items = items
+
+	return nil
+}
+
+func (sn StructuredNarrative) RawString() string {
+	return sn.Raw
+}
+
+// envelopeContentsMaxLength is the highest number of characters the envelope contents, field 77T, may consist of.
+const envelopeContentsMaxLength = 9000
+
+// envelopeContentsPattern is the format of the envelope contents, 9000z. Unlike narrative fields it has no line
+// structure, the lines can be of any length as long as the contents as a whole don't exceed 9000 characters.
+var envelopeContentsPattern = MustCompilePattern("9000z")
+
+// EnvelopeContents represents the envelope contents of field 77T, e.g. the extended remittance information of an
+// MT103 REMIT, which can hold up to 9000 characters of the z character set. The contents are kept verbatim.
+type EnvelopeContents struct {
+	Set     bool
+	Raw     string
+	Content string
+}
+
+func (ec *EnvelopeContents) UnmarshalMT(input string) error {
+	// example:
+	// /NARR/REMITTANCE INFORMATION OF ANY LENGTH
+
+	if length := utf8.RuneCountInString(input); length > envelopeContentsMaxLength {
+		return fmt.Errorf(
+			"invalid EnvelopeContents: expected at most %d characters, got %d",
+			envelopeContentsMaxLength,
+			length,
+		)
+	}
+
+	err := envelopeContentsPattern.Validate(input)
+	if err != nil {
+		return fmt.Errorf("invalid EnvelopeContents: %w", err)
+	}
+
+	ec.Set = true
+	ec.Raw = input
+	ec.Content = input
+
+	return nil
+}
+
+func (ec EnvelopeContents) RawString() string {
+	return ec.Raw
+}
+
+// MarshalMT generates the value of the field from the contents.
+func (ec EnvelopeContents) MarshalMT() (string, error) {
+	return ec.Content, nil
+}
+
+// ScreeningCode is the outcome of sanctions screening as found in field 433 of the user header.
+// The possible values are:
+// AOK = Message automatically released after screening
+// FPO = Compliance check found a false positive and the message was released
+// NOK = Compliance check found the message not ok, it has been released nonetheless
+type ScreeningCode int
+
+const (
+	ScreeningCodeAutomaticallyReleased ScreeningCode = iota // AOK
+	ScreeningCodeFalsePositive                              // FPO
+	ScreeningCodeNotOK                                      // NOK
+)
+
+func (sc ScreeningCode) String() string {
+	switch sc {
+	case ScreeningCodeFalsePositive:
+		return "FPO"
+	case ScreeningCodeNotOK:
+		return "NOK"
+	// ScreeningCodeAutomaticallyReleased
+	default:
+		return "AOK"
+	}
+}
+
+func (sc ScreeningCode) RawString() string {
+	return sc.String()
+}
+
+func screeningCodeFromString(input string) (ScreeningCode, error) {
+	switch input {
+	case "AOK":
+		return ScreeningCodeAutomaticallyReleased, nil
+	case "FPO":
+		return ScreeningCodeFalsePositive, nil
+	case "NOK":
+		return ScreeningCodeNotOK, nil
+	default:
+		return 0, fmt.Errorf("sanctions screening: invalid code: %s", input)
+	}
+}
+
+// SanctionsScreening represents the sanctions screening information, field 433 of the user header. It consists of a
+// code between slashes optionally followed by additional information, e.g. /FPO/NAME MATCH. When parsing a message
+// the raw value is always kept, the code and additional information are only set when the value is well formed.
+type SanctionsScreening struct {
+	Set                   bool
+	Raw                   string
+	Code                  ScreeningCode
+	AdditionalInformation string
+}
+
+func (ss *SanctionsScreening) UnmarshalMT(input string) error {
+	// example:
+	// /AOK/
+	// /FPO/NAME MATCH
+
+	if len(input) < 5 || input[0] != '/' || input[4] != '/' {
+		return fmt.Errorf("sanctions screening: expected /3!a/[20x], got: %s", input)
+	}
+
+	// mandatory, 3!a
+	code, err := screeningCodeFromString(input[1:4])
+	if err != nil {
+		return err
+	}
+	ss.Code = code
+
+	// optional, 20x
+	ss.AdditionalInformation = input[5:]
+
+	ss.Set = true
+	ss.Raw = input
+
+	return nil
+}
+
+func (ss SanctionsScreening) RawString() string {
+	return ss.Raw
+}
+
+// OutputReference is a reference to an output message containing both the send date and time of said message. It
+// consists of the optional time (4!n), the date (6!n), the logical terminal address (12!c), the session number (4!n)
+// and the output sequence number (6!n).
 type OutputReference struct {
 	Set                    bool
 	Raw                    string
@@ -358,7 +1083,8 @@ type OutputReference struct {
 	DateOrDateTime         DateOrDateTime
 }
 
-// InputReference is a reference to an input message containing only the send date of said message.
+// InputReference is a reference to an input message containing only the send date of said message. It consists of the
+// date (6!n), the logical terminal address (12!c), the session number (4!n) and the input sequence number (6!n).
 type InputReference struct {
 	Set                    bool
 	Raw                    string
@@ -368,12 +1094,14 @@ type InputReference struct {
 	DateOrDateTime         DateOrDateTime
 }
 
-// Reference is a reference to an original user message.
+// Reference is a reference to an original user message. It may be followed by an additional reference assigned on
+// delivery of the message, which is optional and empty when absent.
 type Reference struct {
 	Set                   bool
 	Raw                   string
 	DateTime              DateTime
 	MessageInputReference InputReference
+	DeliveryReference     string
 }
 
 // BasicHeader is the only mandatory block; block 1. The basic header contains the general information that identifies
@@ -439,11 +1167,22 @@ type UsrHeader struct {
 	UniqueEndToEndTransactionReference string
 	PaymentReleaseInformation          string
 	SanctionsScreeningInformation      string
+	SanctionsScreening                 SanctionsScreening
 	PaymentControlsInformation         string
 	BalanceCheckpointDateTime          DateTimeSecOptCent
 	MessageInputReference              InputReference
 }
 
+// MarshalMT returns block 3 as it was parsed. A user header that was present but empty results in the empty block
+// markers, {3:}, while an absent user header results in an empty string.
+func (uh UsrHeader) MarshalMT() (string, error) {
+	if !uh.Set {
+		return "", nil
+	}
+
+	return uh.Raw, nil
+}
+
 // PossibleDuplicateEmission is added if user thinks the same message was sent previously.
 type PossibleDuplicateEmission struct {
 	Raw                   string
@@ -486,15 +1225,79 @@ type Trailers struct {
 	AdditionalTrailers        map[string]string
 }
 
+// MarshalMT generates block 5 from the trailers. The flag trailers, DLM and TNG, have no content and are written as
+// empty blocks, e.g. {TNG:}, if and only if their flag is true. The other trailers are written when they have content,
+// the additional trailers first, ordered by label, followed by the known trailers in the order of the specification.
+// Trailers that were present but empty result in the empty block markers, {5:}, while absent trailers, without any
+// content, result in an empty string.
+func (t Trailers) MarshalMT() (string, error) {
+	sb := &strings.Builder{}
+
+	writeTrailer := func(label, content string) {
+		sb.WriteString("{" + label + ":" + content + "}")
+	}
+
+	labels := make([]string, 0, len(t.AdditionalTrailers))
+	for label := range t.AdditionalTrailers {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	for _, label := range labels {
+		writeTrailer(label, t.AdditionalTrailers[label])
+	}
+	if t.Checksum != "" {
+		writeTrailer("CHK", t.Checksum)
+	}
+	if t.TestAndTrainingMessage {
+		writeTrailer("TNG", "")
+	}
+	if t.PossibleDuplicateEmission.Raw != "" {
+		writeTrailer("PDE", t.PossibleDuplicateEmission.Raw)
+	}
+	if t.DelayedMessage {
+		writeTrailer("DLM", "")
+	}
+	if t.MessageReference.Raw != "" {
+		writeTrailer("MRF", t.MessageReference.Raw)
+	}
+	if t.PossibleDuplicateMessage.Raw != "" {
+		writeTrailer("PDM", t.PossibleDuplicateMessage.Raw)
+	}
+	if t.SystemOriginatedMessage.Raw != "" {
+		writeTrailer("SYS", t.SystemOriginatedMessage.Raw)
+	}
+
+	if !t.Set && sb.Len() == 0 {
+		return "", nil
+	}
+
+	return "{5:" + sb.String() + "}", nil
+}
+
 // Base holds the basic structure all MT messages adhere to, excluding the body.
+//
+// Raw holds the message verbatim as it was found in the input, from the start of its first block up to and including
+// the end of its last block. This includes any whitespace in and in between the blocks, regardless of the
+// PreserveWhitespace option, and any part of the message the parser did not understand. RawBody holds the content of
+// the body verbatim in the same way, from right after {4: up to the -}, or the +} of a continued body, terminating it,
+// e.g. to forward the body as-is or to compute a checksum over it.
+//
+// PresentFields holds the tags of all fields that were present in the body of the message. This makes it possible to
+// distinguish an absent optional field from one that was present but empty or zero in the typed messages. FieldOrder
+// holds the tags of the fields in the body in the order they appeared in, including repeated tags. This makes it
+// possible to relate fields to each other by position, e.g. a field 86 to the field 61 before it.
 type Base struct {
 	Raw             string
+	RawBody         string
 	Line            int
 	BasicHeader     BasicHeader
 	AppHeaderInput  AppHeaderInput
 	AppHeaderOutput AppHeaderOutput
 	UsrHeader       UsrHeader
 	Trailers        Trailers
+	PresentFields   map[string]bool
+	FieldOrder      []string
 }
 
 // IsInput returns true if the message is of the input variety. If so it will contain an input type app header.
@@ -525,24 +1328,424 @@ func (b Base) Priority() Priority {
 	return b.AppHeaderOutput.MessagePriority
 }
 
-// HasUserHeader returns true if the user header of the message was set/filled.
+// IsSystemMessage returns true if the message is a system message rather than a financial message. That is the case for
+// messages sent within the general purpose or login application and for messages of category 0, i.e. of a type starting
+// with 0. System messages, e.g. a login, can lack an app header, in which case Type returns an empty string. Their body
+// consists of sub blocks, e.g. {4:{202:0001}}, rather than fields, these are made available as fields in the body.
+func (b Base) IsSystemMessage() bool {
+	return b.BasicHeader.AppID == ApplicationIDGeneral ||
+		b.BasicHeader.AppID == ApplicationIDLogin ||
+		strings.HasPrefix(b.Type(), "0")
+}
+
+// IsAcknowledgement returns true if the message is a logical acknowledgement, ACK or NAK, of a message that was input,
+// i.e. its basic header holds service identifier 21. An acknowledgement lacks an app header, the details can be
+// obtained using the Acknowledgement function of MTx.
+func (b Base) IsAcknowledgement() bool {
+	return b.BasicHeader.ServiceID == ServiceIDACKNACK
+}
+
+// IsPresent returns true if the field with the given tag was present in the body of the message.
+func (b Base) IsPresent(tag string) bool {
+	return b.PresentFields[tag]
+}
+
+// Direction returns whether the message is of the input or output variety.
+func (b Base) Direction() Direction {
+	if b.IsOutput() {
+		return DirectionOutput
+	}
+	return DirectionInput
+}
+
+// SenderReceiver returns the logical terminal addresses of the sender and receiver of the message. For input messages
+// the sender is found in the basic header and the receiver in the app header, for output messages the sender is found
+// in the message input reference of the app header and the receiver in the basic header. If the message has no app
+// header, or either address is missing, ok is false.
+func (b Base) SenderReceiver() (sender, receiver string, ok bool) {
+	switch {
+	case b.IsOutput():
+		sender = b.AppHeaderOutput.MessageInputReference.LogicalTerminalAddress
+		receiver = b.BasicHeader.LogicalTerminalAddress
+	case b.IsInput():
+		sender = b.BasicHeader.LogicalTerminalAddress
+		receiver = b.AppHeaderInput.ReceiverAddress
+	default:
+		return "", "", false
+	}
+
+	return sender, receiver, sender != "" && receiver != ""
+}
+
+// HasUserHeader returns true if the user header of the message was present, even if it was empty.
 // It is advised to use this function before accessing information in the UsrHeader struct.
 func (b Base) HasUserHeader() bool {
 	return b.UsrHeader.Set
 }
 
-// HasTrailers returns true if the trailers of the message were set/filled.
+// HasTrailers returns true if the trailers of the message were present, even if they were empty.
 // It is advised to use this function before accessing information in the Trailers struct.
 func (b Base) HasTrailers() bool {
 	return b.Trailers.Set
 }
 
+// cloneStrings returns a copy of the given slice, a nil slice remains nil.
+func cloneStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+
+	return append(make([]string, 0, len(s)), s...)
+}
+
+// clone returns a deep copy of the base, the maps and slices within are copied rather than shared.
+func (b Base) clone() Base {
+	cloned := b
+
+	if b.PresentFields != nil {
+		cloned.PresentFields = make(map[string]bool, len(b.PresentFields))
+		for tag, present := range b.PresentFields {
+			cloned.PresentFields[tag] = present
+		}
+	}
+
+	cloned.FieldOrder = cloneStrings(b.FieldOrder)
+
+	if b.Trailers.AdditionalTrailers != nil {
+		cloned.Trailers.AdditionalTrailers = make(map[string]string, len(b.Trailers.AdditionalTrailers))
+		for label, content := range b.Trailers.AdditionalTrailers {
+			cloned.Trailers.AdditionalTrailers[label] = content
+		}
+	}
+
+	return cloned
+}
+
+// writeSummaryLine writes a single indented line of a summary, omitting it altogether when the value is empty.
+func writeSummaryLine(sb *strings.Builder, label, value string) {
+	if value == "" {
+		return
+	}
+
+	sb.WriteString(fmt.Sprintf("  %-18s %s\n", label+":", value))
+}
+
+// writeSummary writes the human readable summary of the headers of the message, as used by the String functions of the
+// message types. Times are written as they were found in the message.
+func (b Base) writeSummary(sb *strings.Builder) {
+	direction := "input"
+	if b.IsOutput() {
+		direction = "output"
+	}
+
+	sb.WriteString("MT" + b.Type() + " " + direction + " message\n")
+
+	sender, receiver, _ := b.SenderReceiver()
+	writeSummaryLine(sb, "Sender", sender)
+	writeSummaryLine(sb, "Receiver", receiver)
+	writeSummaryLine(sb, "Priority", b.Priority().String())
+
+	if b.IsOutput() {
+		writeSummaryLine(sb, "Input time", b.AppHeaderOutput.InputTime.String())
+		writeSummaryLine(
+			sb,
+			"Output date/time",
+			strings.TrimSpace(b.AppHeaderOutput.OutputDate.String()+" "+b.AppHeaderOutput.OutputTime.String()),
+		)
+	}
+}
+
 // MTx represents a complete message including headers and a body. The body has not been further processes or validated.
 // The specific type of MT message this holds can be determined by the Type() function.
 //
 // If parsing into a more specifc struct is desireed the MTxToMT... functions be used.
 // After parsing into a more specific type the ValidateMT... functions can be used to validate the message.
+//
+// Continued is set when the body was split across transmissions, i.e. it ends with the continuation indicator +}
+// instead of -}. The body then only holds the fields of this part, relays can reassemble the message by appending the
+// RawBody of the subsequent parts. A warning is reported for such messages.
+//
+// A message parsed with the LazyBody option has its Body, FieldOrder and PresentFields populated only once loaded, see
+// LoadBody. Until then ranging over Body yields nothing. Field, IsPresent and Fields give access to the fields
+// regardless, loading what they need by themselves.
 type MTx struct {
 	Base
-	Body map[string][]string
+	Body      map[string][]string
+	Continued bool
+	lazy      *lazyBody
+}
+
+// MessageSummary holds the most relevant information of a message in one convenient struct, e.g. for display purposes.
+//
+// The Timestamp is the output date and time for output messages. For input messages it is left empty as the app header
+// of input messages holds no date or time.
+type MessageSummary struct {
+	Type      string
+	Sender    string
+	Receiver  string
+	Reference string
+	Priority  Priority
+	Direction Direction
+	Timestamp time.Time
+}
+
+// Summary returns a summary of the message.
+func (m MTx) Summary() MessageSummary {
+	sender, receiver, _ := m.SenderReceiver()
+
+	summary := MessageSummary{
+		Type:      m.Type(),
+		Sender:    sender,
+		Receiver:  receiver,
+		Priority:  m.Priority(),
+		Direction: m.Direction(),
+	}
+
+	if refs := m.Field("20"); len(refs) > 0 {
+		summary.Reference = refs[0]
+	}
+
+	if m.IsOutput() {
+		date := m.AppHeaderOutput.OutputDate.Time
+		clock := m.AppHeaderOutput.OutputTime.Time
+		summary.Timestamp = time.Date(
+			date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), 0, 0, date.Location(),
+		)
+	}
+
+	return summary
+}
+
+// References returns the references of the message found in the transaction reference number (20), related reference
+// (21) and unique end-to-end transaction reference (121, from the user header) fields, keyed by their tag. Fields that
+// are absent or empty are left out. For fields occurring multiple times only the first occurrence is returned.
+func (m MTx) References() map[string]string {
+	refs := make(map[string]string)
+
+	for _, tag := range []string{"20", "21"} {
+		if values := m.Field(tag); len(values) > 0 && values[0] != "" {
+			refs[tag] = values[0]
+		}
+	}
+
+	if uetr := m.UsrHeader.UniqueEndToEndTransactionReference; uetr != "" {
+		refs["121"] = uetr
+	}
+
+	return refs
+}
+
+// bodyTags returns the tags of the fields in the body in the order they appeared in. Messages constructed in code have
+// no field order, for those the tags of the fields in the body are returned in alphabetical order, once per value.
+func (m MTx) bodyTags() []string {
+	if len(m.FieldOrder) > 0 {
+		return m.FieldOrder
+	}
+
+	keys := make([]string, 0, len(m.Body))
+	for tag := range m.Body {
+		keys = append(keys, tag)
+	}
+	sort.Strings(keys)
+
+	tags := make([]string, 0, len(keys))
+	for _, tag := range keys {
+		for range m.Body[tag] {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// eachOrderedField calls fn for every field in the body, in the given order of their tags. The index is that of the
+// value among the values of its tag. Tags in the order without a corresponding value left in the body are skipped.
+func eachOrderedField(order []string, body map[string][]string, fn func(tag string, index int, value string)) {
+	fieldIdx := make(map[string]int, len(body))
+	for _, tag := range order {
+		values := body[tag]
+		if fieldIdx[tag] >= len(values) {
+			continue
+		}
+
+		fn(tag, fieldIdx[tag], values[fieldIdx[tag]])
+		fieldIdx[tag]++
+	}
+}
+
+// Clone returns a deep copy of the message. Assigning a message to another variable shares the body and the other maps
+// and slices within, so modifying either modifies both. A clone on the other hand can be modified freely, without
+// affecting the original.
+func (m MTx) Clone() MTx {
+	m = m.loaded()
+
+	cloned := MTx{Base: m.Base.clone(), Continued: m.Continued}
+
+	if m.Body != nil {
+		cloned.Body = make(map[string][]string, len(m.Body))
+		for tag, values := range m.Body {
+			cloned.Body[tag] = cloneStrings(values)
+		}
+	}
+
+	return cloned
+}
+
+// Walk visits every value of every field in the body, in the order the fields appeared in, and replaces it with the
+// value returned by fn. The index is that of the value among the values of the same tag, e.g. 1 for the second field
+// 61. This allows for uniform transformations, such as masking account numbers to create test data from production
+// messages:
+//
+//	redacted := mtx.Walk(func(tag string, index int, value string) string {
+//		if tag == "25" {
+//			return strings.Repeat("X", len(value))
+//		}
+//		return value
+//	})
+//
+// Walk operates on a copy, the message itself is left intact. The Raw message and RawBody of the copy are regenerated
+// from its fields, see MarshalMT, so they do not reveal the original values. If it can't be generated Raw is left
+// empty.
+func (m MTx) Walk(fn func(tag string, index int, value string) string) MTx {
+	walked := m.Clone()
+
+	eachOrderedField(walked.bodyTags(), walked.Body, func(tag string, index int, value string) {
+		walked.Body[tag][index] = fn(tag, index, value)
+	})
+
+	raw, err := walked.MarshalMT()
+	if err != nil {
+		raw = ""
+	}
+	walked.Raw = raw
+
+	rawBody := &strings.Builder{}
+	walked.writeBody(&countingWriter{w: rawBody})
+	walked.RawBody = rawBody.String()
+
+	return walked
+}
+
+// countingWriter keeps track of the number of bytes written to the underlying writer, and of the first error
+// encountered, after which nothing is written anymore.
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (cw *countingWriter) writeString(s string) {
+	if cw.err != nil {
+		return
+	}
+
+	n, err := io.WriteString(cw.w, s)
+	cw.n += int64(n)
+	cw.err = err
+}
+
+// WriteTo implements io.WriterTo. It writes the message to the writer in its MT format, without building the message
+// in memory first. The headers are written as they were parsed, the body is written with one field per line in the
+// original order, with lines separated by CRLF as the FIN format prescribes, and the trailers are generated from their
+// fields. The body of a system message or acknowledgement is written as sub blocks, e.g. {4:{177:2103151130}{451:0}}.
+// It returns the number of bytes written and the first error encountered.
+func (m MTx) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+
+	cw.writeString(m.BasicHeader.Raw)
+	if m.IsOutput() {
+		cw.writeString(m.AppHeaderOutput.Raw)
+	} else {
+		cw.writeString(m.AppHeaderInput.Raw)
+	}
+
+	usrHeader, err := m.UsrHeader.MarshalMT()
+	if err != nil {
+		return cw.n, fmt.Errorf("could not marshal user header: %w", err)
+	}
+	cw.writeString(usrHeader)
+
+	cw.writeString("{4:")
+	m.writeBody(cw)
+	if m.hasSubBlockBody() {
+		cw.writeString("}")
+	} else {
+		cw.writeString("-}")
+	}
+
+	trailers, err := m.Trailers.MarshalMT()
+	if err != nil {
+		return cw.n, fmt.Errorf("could not marshal trailers: %w", err)
+	}
+	cw.writeString(trailers)
+
+	return cw.n, cw.err
+}
+
+// hasSubBlockBody returns true if the body consists of sub blocks rather than fields, as is the case for system
+// messages and acknowledgements.
+func (m MTx) hasSubBlockBody() bool {
+	return m.IsSystemMessage() || m.IsAcknowledgement()
+}
+
+// writeBody writes the content of the body, one field per line in the original order, or one sub block per field for
+// a body consisting of sub blocks. Line breaks within the values are written as CRLF as well.
+func (m MTx) writeBody(cw *countingWriter) {
+	m = m.loaded()
+
+	if m.hasSubBlockBody() {
+		eachOrderedField(m.bodyTags(), m.Body, func(tag string, _ int, value string) {
+			cw.writeString("{" + tag + ":" + value + "}")
+		})
+		return
+	}
+
+	cw.writeString("\r\n")
+
+	eachOrderedField(m.bodyTags(), m.Body, func(tag string, _ int, value string) {
+		cw.writeString(":" + tag + ":" + crlfLines(value) + "\r\n")
+	})
+}
+
+// crlfLines returns the value with its line breaks, be it LF or CRLF, replaced by CRLF.
+func crlfLines(value string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(value, "\r\n", "\n"), "\n", "\r\n")
+}
+
+// MarshalMT generates the message in its MT format, see WriteTo.
+func (m MTx) MarshalMT() (string, error) {
+	sb := &strings.Builder{}
+
+	_, err := m.WriteTo(sb)
+	if err != nil {
+		return "", err
+	}
+
+	return sb.String(), nil
+}
+
+// String returns a human readable, multi-line, summary of the message, e.g. for logging or debugging purposes. Use Raw
+// to get the message in the format it was received in.
+func (m MTx) String() string {
+	m = m.loaded()
+
+	sb := &strings.Builder{}
+
+	m.writeSummary(sb)
+
+	if refs := m.Body["20"]; len(refs) > 0 {
+		writeSummaryLine(sb, "Reference", refs[0])
+	}
+
+	tags := m.FieldOrder
+	if len(tags) == 0 {
+		// messages constructed in code have no field order, fall back to the fields in the body in alphabetical order
+		for tag := range m.Body {
+			tags = append(tags, tag)
+		}
+		sort.Strings(tags)
+	}
+	writeSummaryLine(sb, "Fields", strings.Join(tags, ", "))
+
+	return strings.TrimRight(sb.String(), "\n")
 }
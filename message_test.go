@@ -7,7 +7,9 @@ package mt_test
 
 import (
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DennisVis/mt"
 	mttest "github.com/DennisVis/mt/testdata"
@@ -77,6 +79,85 @@ func TestCreditDebit(t *testing.T) {
 	}
 }
 
+func TestAmount(t *testing.T) {
+	if (mt.Amount{Raw: "1,23"}).RawString() != "1,23" {
+		t.Error("Amount raw string is not 1,23")
+	}
+
+	for _, test := range []struct {
+		name             string
+		input            string
+		expectedErr      error
+		expectedValue    int64
+		expectedDecimals int
+		expectedFloat    float64
+	}{
+		{
+			name:        "MissingComma",
+			input:       "4000000",
+			expectedErr: fmt.Errorf("amount: invalid amount: 4000000"),
+		},
+		{
+			name:        "LeadingComma",
+			input:       ",00",
+			expectedErr: fmt.Errorf("amount: invalid amount: ,00"),
+		},
+		{
+			name:        "MultipleCommas",
+			input:       "1,000,00",
+			expectedErr: fmt.Errorf("amount: invalid amount: 1,000,00"),
+		},
+		{
+			name:        "InvalidCharacter",
+			input:       "40X00,00",
+			expectedErr: fmt.Errorf("amount: invalid amount: 40X00,00"),
+		},
+		{
+			name:             "NoDecimals",
+			input:            "40000,",
+			expectedValue:    40000,
+			expectedDecimals: 0,
+			expectedFloat:    40000,
+		},
+		{
+			name:             "Decimals",
+			input:            "40000,25",
+			expectedValue:    4000025,
+			expectedDecimals: 2,
+			expectedFloat:    40000.25,
+		},
+	} {
+		test := test
+
+		t.Run("UnmarshalMT/"+test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var amount mt.Amount
+			err := amount.UnmarshalMT(test.input)
+			mttest.ValidateError(t, test.expectedErr, err)
+
+			if test.expectedErr != nil {
+				return
+			}
+			if !amount.Set {
+				t.Error("expected Set to be true")
+			}
+			if amount.Value != test.expectedValue {
+				t.Errorf("expected value %d, got %d", test.expectedValue, amount.Value)
+			}
+			if amount.Decimals != test.expectedDecimals {
+				t.Errorf("expected decimals %d, got %d", test.expectedDecimals, amount.Decimals)
+			}
+			if amount.Float64() != test.expectedFloat {
+				t.Errorf("expected float %f, got %f", test.expectedFloat, amount.Float64())
+			}
+			if amount.String() != test.input {
+				t.Errorf("expected string %s, got %s", test.input, amount.String())
+			}
+		})
+	}
+}
+
 func TestBalance(t *testing.T) {
 	if (mt.Balance{Raw: "123"}).RawString() != "123" {
 		t.Error("Balance raw string is not 123")
@@ -120,7 +201,7 @@ func TestBalance(t *testing.T) {
 					Raw: "031002",
 				},
 				Currency: "PLN",
-				Amount:   40000.00,
+				Amount:   mttest.MustParseAmount("40000,00"),
 			},
 		},
 		{
@@ -135,7 +216,7 @@ func TestBalance(t *testing.T) {
 					Raw: "031002",
 				},
 				Currency: "PLN",
-				Amount:   40000.00,
+				Amount:   mttest.MustParseAmount("40000,00"),
 			},
 		},
 	} {
@@ -152,6 +233,276 @@ func TestBalance(t *testing.T) {
 	}
 }
 
+func TestBalanceSigned(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		input    string
+		expected float64
+	}{
+		{
+			name:     "Credit",
+			input:    "C031002PLN40000,00",
+			expected: 40000,
+		},
+		{
+			name:     "Debit",
+			input:    "D031002PLN40000,50",
+			expected: -40000.5,
+		},
+		{
+			name:  "Zero",
+			input: "D031002PLN0,",
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var balance mt.Balance
+			err := balance.UnmarshalMT(test.input)
+			mttest.ValidateError(t, nil, err)
+
+			if actual := balance.Signed(); actual != test.expected {
+				t.Errorf("expected signed amount %f, got %f", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCurrencyAmount(t *testing.T) {
+	if (mt.CurrencyAmount{Raw: "123"}).RawString() != "123" {
+		t.Error("CurrencyAmount raw string is not 123")
+	}
+
+	for _, test := range []struct {
+		name        string
+		input       string
+		expectedErr error
+		expected    mt.CurrencyAmount
+	}{
+		{
+			name:        "InvalidInputLength",
+			input:       "USD",
+			expectedErr: fmt.Errorf("currency amount: invalid input length: 3"),
+		},
+		{
+			name:        "InvalidAmount",
+			input:       "USD1X,00",
+			expectedErr: fmt.Errorf("currency amount: invalid amount"),
+		},
+		{
+			name:  "Valid",
+			input: "EUR1250,5",
+			expected: mt.CurrencyAmount{
+				Set:      true,
+				Raw:      "EUR1250,5",
+				Currency: "EUR",
+				Amount:   mttest.MustParseAmount("1250,5"),
+			},
+		},
+	} {
+		test := test
+
+		t.Run("UnmarshalMT/"+test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var ca mt.CurrencyAmount
+			err := ca.UnmarshalMT(test.input)
+			mttest.ValidateError(t, test.expectedErr, err)
+
+			if ca != test.expected {
+				t.Errorf("expected %+v, got %+v", test.expected, ca)
+			}
+		})
+	}
+
+	t.Run("MarshalMT", func(t *testing.T) {
+		t.Parallel()
+
+		var ca mt.CurrencyAmount
+		err := ca.UnmarshalMT("EUR1250,50")
+		mttest.ValidateError(t, nil, err)
+
+		marshaled, err := ca.MarshalMT()
+		mttest.ValidateError(t, nil, err)
+
+		if marshaled != "EUR1250,50" {
+			t.Errorf("expected EUR1250,50, got %s", marshaled)
+		}
+	})
+}
+
+func TestDateCurrencyAmount(t *testing.T) {
+	if (mt.DateCurrencyAmount{Raw: "123"}).RawString() != "123" {
+		t.Error("DateCurrencyAmount raw string is not 123")
+	}
+
+	for _, test := range []struct {
+		name        string
+		input       string
+		expectedErr error
+		expected    mt.DateCurrencyAmount
+	}{
+		{
+			name:        "InvalidInputLength",
+			input:       "210315USD",
+			expectedErr: fmt.Errorf("date currency amount: invalid input length: 9"),
+		},
+		{
+			name:        "InvalidDate",
+			input:       "2X0315USD1,",
+			expectedErr: fmt.Errorf("date currency amount: invalid date"),
+		},
+		{
+			name:        "InvalidAmount",
+			input:       "210315USD1X,00",
+			expectedErr: fmt.Errorf("date currency amount: invalid amount"),
+		},
+		{
+			name:  "Valid",
+			input: "210315USD233530,",
+			expected: mt.DateCurrencyAmount{
+				Set:      true,
+				Raw:      "210315USD233530,",
+				Currency: "USD",
+				Amount:   mttest.MustParseAmount("233530,"),
+			},
+		},
+	} {
+		test := test
+
+		t.Run("UnmarshalMT/"+test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var dca mt.DateCurrencyAmount
+			err := dca.UnmarshalMT(test.input)
+			mttest.ValidateError(t, test.expectedErr, err)
+
+			if test.expectedErr != nil {
+				return
+			}
+
+			if dca.Set != test.expected.Set || dca.Raw != test.expected.Raw {
+				t.Errorf("expected Set/Raw %v/%s, got %v/%s", test.expected.Set, test.expected.Raw, dca.Set, dca.Raw)
+			}
+			if dca.Date.Raw != test.input[0:6] {
+				t.Errorf("expected date %s, got %s", test.input[0:6], dca.Date)
+			}
+			if dca.Currency != test.expected.Currency {
+				t.Errorf("expected currency %s, got %s", test.expected.Currency, dca.Currency)
+			}
+			if dca.Amount != test.expected.Amount {
+				t.Errorf("expected amount %s, got %s", test.expected.Amount, dca.Amount)
+			}
+		})
+	}
+
+	t.Run("MarshalMT", func(t *testing.T) {
+		t.Parallel()
+
+		var dca mt.DateCurrencyAmount
+		err := dca.UnmarshalMT("210315USD233530,")
+		mttest.ValidateError(t, nil, err)
+
+		marshaled, err := dca.MarshalMT()
+		mttest.ValidateError(t, nil, err)
+
+		if marshaled != "210315USD233530," {
+			t.Errorf("expected 210315USD233530, got %s", marshaled)
+		}
+	})
+}
+
+func TestFloorLimit(t *testing.T) {
+	if (mt.FloorLimit{Raw: "123"}).RawString() != "123" {
+		t.Error("FloorLimit raw string is not 123")
+	}
+
+	for _, test := range []struct {
+		indicator      string
+		expectedDebit  bool
+		expectedCredit bool
+	}{
+		{indicator: "", expectedDebit: true, expectedCredit: true},
+		{indicator: "D", expectedDebit: true, expectedCredit: false},
+		{indicator: "C", expectedDebit: false, expectedCredit: true},
+	} {
+		floorLimit := mt.FloorLimit{Indicator: test.indicator}
+		if floorLimit.AppliesToDebit() != test.expectedDebit {
+			t.Errorf("FloorLimit with indicator %q: expected AppliesToDebit %t", test.indicator, test.expectedDebit)
+		}
+		if floorLimit.AppliesToCredit() != test.expectedCredit {
+			t.Errorf("FloorLimit with indicator %q: expected AppliesToCredit %t", test.indicator, test.expectedCredit)
+		}
+	}
+
+	for _, test := range []struct {
+		name               string
+		input              string
+		expectedErr        error
+		expectedFloorLimit mt.FloorLimit
+	}{
+		{
+			name:        "InvalidInputLength",
+			input:       "PLN",
+			expectedErr: fmt.Errorf("floor limit: invalid input length: 3"),
+		},
+		{
+			name:        "InvalidAmount",
+			input:       "PLNX100,00",
+			expectedErr: fmt.Errorf("floor limit: invalid amount"),
+		},
+		{
+			name:  "WithoutIndicator",
+			input: "PLN0,",
+			expectedFloorLimit: mt.FloorLimit{
+				Set:      true,
+				Raw:      "PLN0,",
+				Currency: "PLN",
+				Amount:   mttest.MustParseAmount("0,"),
+			},
+		},
+		{
+			name:  "DebitIndicator",
+			input: "PLND100,00",
+			expectedFloorLimit: mt.FloorLimit{
+				Set:       true,
+				Raw:       "PLND100,00",
+				Currency:  "PLN",
+				Indicator: "D",
+				Amount:    mttest.MustParseAmount("100,00"),
+			},
+		},
+		{
+			name:  "CreditIndicator",
+			input: "PLNC100,00",
+			expectedFloorLimit: mt.FloorLimit{
+				Set:       true,
+				Raw:       "PLNC100,00",
+				Currency:  "PLN",
+				Indicator: "C",
+				Amount:    mttest.MustParseAmount("100,00"),
+			},
+		},
+	} {
+		test := test
+
+		t.Run("UnmarshalMT/"+test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var floorLimit mt.FloorLimit
+			err := floorLimit.UnmarshalMT(test.input)
+			mttest.ValidateError(t, test.expectedErr, err)
+
+			if test.expectedErr == nil && floorLimit != test.expectedFloorLimit {
+				t.Errorf("expected floor limit %+v, got %+v", test.expectedFloorLimit, floorLimit)
+			}
+		})
+	}
+}
+
 func TestFundsCode(t *testing.T) {
 	t.Parallel()
 
@@ -210,7 +561,7 @@ func TestStatementLine(t *testing.T) {
 					Raw: "1020",
 				},
 				FundsCode:             mt.FundsCodeCredit,
-				Amount:                20000.00,
+				Amount:                mttest.MustParseAmount("20000,00"),
 				SwiftCode:             "FMSC",
 				AccountOwnerReference: "NONREF",
 				BankReference:         "//8327000090031789",
@@ -232,7 +583,7 @@ func TestStatementLine(t *testing.T) {
 					Raw: "1020",
 				},
 				FundsCode:             mt.FundsCodeCreditReversal,
-				Amount:                20000.00,
+				Amount:                mttest.MustParseAmount("20000,00"),
 				SwiftCode:             "FMSC",
 				AccountOwnerReference: "NONREF",
 				BankReference:         "//8327000090031789",
@@ -254,7 +605,7 @@ func TestStatementLine(t *testing.T) {
 					Raw: "1020",
 				},
 				FundsCode:             mt.FundsCodeDebit,
-				Amount:                20000.00,
+				Amount:                mttest.MustParseAmount("20000,00"),
 				SwiftCode:             "FMSC",
 				AccountOwnerReference: "NONREF",
 				BankReference:         "//8327000090031789",
@@ -276,7 +627,85 @@ func TestStatementLine(t *testing.T) {
 					Raw: "1020",
 				},
 				FundsCode:             mt.FundsCodeDebitReversal,
-				Amount:                20000.00,
+				Amount:                mttest.MustParseAmount("20000,00"),
+				SwiftCode:             "FMSC",
+				AccountOwnerReference: "NONREF",
+				BankReference:         "//8327000090031789",
+				Description:           "Card transaction",
+			},
+		},
+		{
+			name:  "DescriptionContainingDoubleSlash",
+			input: "0310201020C20000,00FMSCNONREF//8327000090031789\nCard // transaction",
+			expectedStatementLine: mt.StatementLine{
+				Raw:                   "0310201020C20000,00FMSCNONREF//8327000090031789\nCard // transaction",
+				FundsCode:             mt.FundsCodeCredit,
+				Amount:                mttest.MustParseAmount("20000,00"),
+				SwiftCode:             "FMSC",
+				AccountOwnerReference: "NONREF",
+				BankReference:         "//8327000090031789",
+				Description:           "Card // transaction",
+			},
+		},
+		{
+			name:  "NoBankReferenceDescriptionContainingDoubleSlash",
+			input: "0310201020C20000,00FMSCNONREF\nCard // transaction",
+			expectedStatementLine: mt.StatementLine{
+				Raw:                   "0310201020C20000,00FMSCNONREF\nCard // transaction",
+				FundsCode:             mt.FundsCodeCredit,
+				Amount:                mttest.MustParseAmount("20000,00"),
+				SwiftCode:             "FMSC",
+				AccountOwnerReference: "NONREF",
+				Description:           "Card // transaction",
+			},
+		},
+		{
+			name:  "BankReferenceWithSlashSegments",
+			input: "0310201020C20000,00FTRFREF/2003/01//BPH/081203/01\nTransfer",
+			expectedStatementLine: mt.StatementLine{
+				Raw:                   "0310201020C20000,00FTRFREF/2003/01//BPH/081203/01\nTransfer",
+				FundsCode:             mt.FundsCodeCredit,
+				Amount:                mttest.MustParseAmount("20000,00"),
+				SwiftCode:             "FTRF",
+				AccountOwnerReference: "REF/2003/01",
+				BankReference:         "//BPH/081203/01",
+				Description:           "Transfer",
+			},
+		},
+		{
+			name:  "BankReferenceFollowedByDetails",
+			input: "0310201020C20000,00FTRFNONREF//8327000090031789//EXTRA",
+			expectedStatementLine: mt.StatementLine{
+				Raw:                   "0310201020C20000,00FTRFNONREF//8327000090031789//EXTRA",
+				FundsCode:             mt.FundsCodeCredit,
+				Amount:                mttest.MustParseAmount("20000,00"),
+				SwiftCode:             "FTRF",
+				AccountOwnerReference: "NONREF",
+				BankReference:         "//8327000090031789",
+				Description:           "//EXTRA",
+			},
+		},
+		{
+			name:  "ValidLargeAmount",
+			input: "0310201020C999999999999,99FMSCNONREF//8327000090031789\nCard transaction",
+			expectedStatementLine: mt.StatementLine{
+				Set: true,
+				Raw: "0310201020C999999999999,99FMSCNONREF//8327000090031789\nCard transaction",
+				Date: mt.Date{
+					Set: true,
+					Raw: "031020",
+				},
+				EntryDate: mt.Month{
+					Set: true,
+					Raw: "1020",
+				},
+				FundsCode: mt.FundsCodeCredit,
+				Amount: mt.Amount{
+					Set:      true,
+					Raw:      "999999999999,99",
+					Value:    99999999999999,
+					Decimals: 2,
+				},
 				SwiftCode:             "FMSC",
 				AccountOwnerReference: "NONREF",
 				BankReference:         "//8327000090031789",
@@ -297,6 +726,321 @@ func TestStatementLine(t *testing.T) {
 	}
 }
 
+func TestStatementLineEntryDateFull(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		input    string
+		expected time.Time
+	}{
+		{
+			name:     "SameYear",
+			input:    "0310201020C20000,00FMSCNONREF",
+			expected: time.Date(2003, time.October, 20, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "EntryDateInNextYear",
+			input:    "0312310102C20000,00FMSCNONREF",
+			expected: time.Date(2004, time.January, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "EntryDateInPreviousYear",
+			input:    "0401021231C20000,00FMSCNONREF",
+			expected: time.Date(2003, time.December, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "LeapDay",
+			input:    "0403010229C20000,00FMSCNONREF",
+			expected: time.Date(2004, time.February, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "WithoutEntryDate",
+			input: "031231C20000,00FMSCNONREF",
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var statementLine mt.StatementLine
+			err := statementLine.UnmarshalMT(test.input)
+			mttest.ValidateError(t, nil, err)
+
+			if actual := statementLine.EntryDateFull(); !actual.Equal(test.expected) {
+				t.Errorf("expected entry date %s, got %s", test.expected, actual)
+			}
+			if statementLine.EntryDate.Set && !statementLine.EntryDate.Time.Equal(test.expected) {
+				t.Errorf("expected entry date time %s, got %s", test.expected, statementLine.EntryDate.Time)
+			}
+		})
+	}
+}
+
+func TestStatementLineSigned(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		input    string
+		expected float64
+	}{
+		{
+			name:     "Credit",
+			input:    "0310201020C20000,00FMSCNONREF",
+			expected: 20000,
+		},
+		{
+			name:     "Debit",
+			input:    "0310201020D20000,00FMSCNONREF",
+			expected: -20000,
+		},
+		{
+			name:     "CreditReversal",
+			input:    "0310201020RC20000,00FMSCNONREF",
+			expected: -20000,
+		},
+		{
+			name:     "DebitReversal",
+			input:    "0310201020RD20000,00FMSCNONREF",
+			expected: 20000,
+		},
+		{
+			name:     "Decimals",
+			input:    "0310201020D1,25FMSCNONREF",
+			expected: -1.25,
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var statementLine mt.StatementLine
+			err := statementLine.UnmarshalMT(test.input)
+			mttest.ValidateError(t, nil, err)
+
+			if actual := statementLine.Signed(); actual != test.expected {
+				t.Errorf("expected signed amount %f, got %f", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestStructuredNarrative(t *testing.T) {
+	if (mt.StructuredNarrative{Raw: "123"}).RawString() != "123" {
+		t.Error("StructuredNarrative raw string is not 123")
+	}
+
+	for _, test := range []struct {
+		name          string
+		input         string
+		expectedItems []mt.NarrativeItem
+	}{
+		{
+			name:  "SingleCode",
+			input: "/ACC/INSTRUCTION",
+			expectedItems: []mt.NarrativeItem{
+				{Code: "ACC", Text: "INSTRUCTION"},
+			},
+		},
+		{
+			name:  "MultipleCodes",
+			input: "/ACC/INSTRUCTION\n/INS/ABNANL2A",
+			expectedItems: []mt.NarrativeItem{
+				{Code: "ACC", Text: "INSTRUCTION"},
+				{Code: "INS", Text: "ABNANL2A"},
+			},
+		},
+		{
+			name:  "ContinuationLines",
+			input: "/ACC/INSTRUCTION FOR THE\n//ACCOUNT WITH\n//INSTITUTION\n/INS/ABNANL2A",
+			expectedItems: []mt.NarrativeItem{
+				{Code: "ACC", Text: "INSTRUCTION FOR THE\nACCOUNT WITH\nINSTITUTION"},
+				{Code: "INS", Text: "ABNANL2A"},
+			},
+		},
+		{
+			name:  "LeadingContinuationLine",
+			input: "//ACCOUNT WITH\n/INS/ABNANL2A",
+			expectedItems: []mt.NarrativeItem{
+				{Text: "ACCOUNT WITH"},
+				{Code: "INS", Text: "ABNANL2A"},
+			},
+		},
+		{
+			name:  "LinesWithoutCode",
+			input: "FREE TEXT\n/INS/ABNANL2A\nMORE FREE TEXT\n/UNTERMINATED",
+			expectedItems: []mt.NarrativeItem{
+				{Text: "FREE TEXT"},
+				{Code: "INS", Text: "ABNANL2A"},
+				{Text: "MORE FREE TEXT"},
+				{Text: "/UNTERMINATED"},
+			},
+		},
+	} {
+		test := test
+
+		t.Run("UnmarshalMT/"+test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var narrative mt.StructuredNarrative
+			err := narrative.UnmarshalMT(test.input)
+			mttest.ValidateError(t, nil, err)
+
+			if !narrative.Set {
+				t.Error("expected Set to be true")
+			}
+			if narrative.Raw != test.input {
+				t.Errorf("expected Raw to be %q, got %q", test.input, narrative.Raw)
+			}
+			if len(narrative.Items) != len(test.expectedItems) {
+				t.Fatalf("expected %d items, got %d", len(test.expectedItems), len(narrative.Items))
+			}
+			for i, expected := range test.expectedItems {
+				if narrative.Items[i] != expected {
+					t.Errorf("item %d: expected %+v, got %+v", i, expected, narrative.Items[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEnvelopeContents(t *testing.T) {
+	// lines far longer than the 35 characters narrative fields are restricted to, adding up to close to the limit
+	large := strings.Repeat("/NARR/"+strings.Repeat("REMITTANCE=INFO;", 60)+"\r\n", 9)
+
+	for _, test := range []struct {
+		name        string
+		input       string
+		expectedErr error
+	}{
+		{
+			name:  "Large",
+			input: large,
+		},
+		{
+			name:  "ExactlyTheLimit",
+			input: strings.Repeat("A", 9000),
+		},
+		{
+			name:        "TooLarge",
+			input:       strings.Repeat("A", 9001),
+			expectedErr: fmt.Errorf("invalid EnvelopeContents: expected at most 9000 characters, got 9001"),
+		},
+		{
+			name:        "InvalidCharacter",
+			input:       "/NARR/INVOICE~1",
+			expectedErr: fmt.Errorf("invalid EnvelopeContents: line 1, column 14: incomplete match"),
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var contents mt.EnvelopeContents
+			err := contents.UnmarshalMT(test.input)
+			mttest.ValidateError(t, test.expectedErr, err)
+			if err != nil {
+				return
+			}
+
+			if !contents.Set {
+				t.Error("expected Set to be true")
+			}
+			if contents.Content != test.input || contents.RawString() != test.input {
+				t.Errorf(
+					"expected the contents to be kept verbatim, got %d of %d characters",
+					len(contents.Content),
+					len(test.input),
+				)
+			}
+
+			marshalled, _ := contents.MarshalMT()
+			if marshalled != test.input {
+				t.Errorf("expected marshalled contents to equal the input")
+			}
+		})
+	}
+}
+
+func TestScreeningCode(t *testing.T) {
+	t.Parallel()
+
+	if mt.ScreeningCodeAutomaticallyReleased.RawString() != "AOK" {
+		t.Error("ScreeningCodeAutomaticallyReleased raw string is not AOK")
+	}
+	if mt.ScreeningCodeFalsePositive.RawString() != "FPO" {
+		t.Error("ScreeningCodeFalsePositive raw string is not FPO")
+	}
+	if mt.ScreeningCodeNotOK.RawString() != "NOK" {
+		t.Error("ScreeningCodeNotOK raw string is not NOK")
+	}
+}
+
+func TestSanctionsScreening(t *testing.T) {
+	if (mt.SanctionsScreening{Raw: "123"}).RawString() != "123" {
+		t.Error("SanctionsScreening raw string is not 123")
+	}
+
+	for _, test := range []struct {
+		name        string
+		input       string
+		expectedErr error
+		expected    mt.SanctionsScreening
+	}{
+		{
+			name:  "BareCode",
+			input: "/AOK/",
+			expected: mt.SanctionsScreening{
+				Set:  true,
+				Raw:  "/AOK/",
+				Code: mt.ScreeningCodeAutomaticallyReleased,
+			},
+		},
+		{
+			name:  "CodeWithAdditionalInformation",
+			input: "/NOK/RELEASED BY COMPLIANCE",
+			expected: mt.SanctionsScreening{
+				Set:                   true,
+				Raw:                   "/NOK/RELEASED BY COMPLIANCE",
+				Code:                  mt.ScreeningCodeNotOK,
+				AdditionalInformation: "RELEASED BY COMPLIANCE",
+			},
+		},
+		{
+			name:        "MissingSlashes",
+			input:       "AOK",
+			expectedErr: fmt.Errorf("sanctions screening: expected /3!a/[20x], got: AOK"),
+		},
+		{
+			name:        "UnterminatedCode",
+			input:       "/AOKX",
+			expectedErr: fmt.Errorf("sanctions screening: expected /3!a/[20x], got: /AOKX"),
+		},
+		{
+			name:        "InvalidCode",
+			input:       "/XYZ/",
+			expectedErr: fmt.Errorf("sanctions screening: invalid code: XYZ"),
+		},
+	} {
+		test := test
+
+		t.Run("UnmarshalMT/"+test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var screening mt.SanctionsScreening
+			err := screening.UnmarshalMT(test.input)
+			mttest.ValidateError(t, test.expectedErr, err)
+
+			if test.expectedErr == nil && screening != test.expected {
+				t.Errorf("expected %+v, got %+v", test.expected, screening)
+			}
+		})
+	}
+}
+
 func TestBase(t *testing.T) {
 	t.Parallel()
 
@@ -336,3 +1080,68 @@ func TestBase(t *testing.T) {
 		t.Error("expected trl.HasTrailers to be true")
 	}
 }
+
+func TestBaseSenderReceiver(t *testing.T) {
+	for _, test := range []struct {
+		name             string
+		base             mt.Base
+		expectedSender   string
+		expectedReceiver string
+		expectedOK       bool
+	}{
+		{
+			name: "Input",
+			base: mt.Base{
+				BasicHeader:    mt.BasicHeader{LogicalTerminalAddress: "BPHKPLPKXXXX"},
+				AppHeaderInput: mt.AppHeaderInput{Set: true, ReceiverAddress: "BOFAUS6BXBAM"},
+			},
+			expectedSender:   "BPHKPLPKXXXX",
+			expectedReceiver: "BOFAUS6BXBAM",
+			expectedOK:       true,
+		},
+		{
+			name: "Output",
+			base: mt.Base{
+				BasicHeader: mt.BasicHeader{LogicalTerminalAddress: "BPHKPLPKXXXX"},
+				AppHeaderOutput: mt.AppHeaderOutput{
+					Set:                   true,
+					MessageInputReference: mt.InputReference{LogicalTerminalAddress: "SCBLZAJJXXXX"},
+				},
+			},
+			expectedSender:   "SCBLZAJJXXXX",
+			expectedReceiver: "BPHKPLPKXXXX",
+			expectedOK:       true,
+		},
+		{
+			name: "NoAppHeader",
+			base: mt.Base{
+				BasicHeader: mt.BasicHeader{LogicalTerminalAddress: "BPHKPLPKXXXX"},
+			},
+		},
+		{
+			name: "MissingReceiver",
+			base: mt.Base{
+				BasicHeader:    mt.BasicHeader{LogicalTerminalAddress: "BPHKPLPKXXXX"},
+				AppHeaderInput: mt.AppHeaderInput{Set: true},
+			},
+			expectedSender: "BPHKPLPKXXXX",
+		},
+	} {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			sender, receiver, ok := test.base.SenderReceiver()
+			if sender != test.expectedSender {
+				t.Errorf("expected sender %q, got %q", test.expectedSender, sender)
+			}
+			if receiver != test.expectedReceiver {
+				t.Errorf("expected receiver %q, got %q", test.expectedReceiver, receiver)
+			}
+			if ok != test.expectedOK {
+				t.Errorf("expected ok to be %v, got %v", test.expectedOK, ok)
+			}
+		})
+	}
+}
@@ -9,13 +9,112 @@
 package mt
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"sync"
 
 	"github.com/DennisVis/mt/internal/message"
 )
 
+// messageConfig returns the configuration for the underlying message parser, logging skipped content with ctx.
+func (cfg config) messageConfig(ctx context.Context) message.Config {
+	return message.Config{
+		StopOnError:         cfg.StopOnError,
+		PreserveWhitespace:  cfg.PreserveWhitespace,
+		MaxFieldsPerMessage: cfg.MaxFieldsPerMessage,
+		ReportSkipped:       cfg.ReportSkipped,
+		OnSkipped:           cfg.onSkipped(ctx),
+		SkipBody:            cfg.LazyBody,
+	}
+}
+
+// messageError turns an error of the underlying message parser into a parse error of the same severity.
+func messageError(err message.Error) Error {
+	if err.Warning {
+		return NewWarning(err.Err, err.Line)
+	}
+
+	return NewError(err.Err, err.Line)
+}
+
+// processMessage turns a parsed message into an MTx according to the configuration. The errors encountered are
+// returned, ok is false if the message is to be discarded.
+func processMessage(msg message.Message, cfg config) (mtx MTx, errs Errors, ok bool) {
+	mtx, errs = messageToMTx(msg)
+	if errs != nil {
+		return mtx, errs, false
+	}
+
+	cfg.localize(&mtx.Base)
+
+	if cfg.Strict && !cfg.SkipValidation {
+		for _, validate := range []func(Base) error{
+			validateAddresses,
+			validateUsrHeaderServiceCodes,
+			validateSessionSequenceNumbers,
+			validateObsolescencePeriod,
+		} {
+			err := validate(mtx.Base)
+			if err == nil {
+				continue
+			}
+
+			errs = append(errs, NewError(err, mtx.Line))
+
+			if !cfg.Lax {
+				return mtx, errs, false
+			}
+		}
+	}
+
+	if cfg.LazyBody {
+		// the fields of the body are parsed, and transformed, on first access instead
+		mtx.Body = nil
+		mtx.lazy = newLazyBody(mtx.RawBody, cfg)
+
+		return mtx, errs, true
+	}
+
+	if cfg.FINCharSet && !cfg.SkipValidation {
+		charSetErrs := validateFINCharSet(mtx.Body, mtx.FieldOrder)
+		for _, err := range charSetErrs {
+			errs = append(errs, NewError(err, mtx.Line))
+		}
+
+		if len(charSetErrs) > 0 && !cfg.Lax {
+			return mtx, errs, false
+		}
+	}
+
+	if cfg.DetectNonASCII {
+		// only reported, it's up to the caller to decide whether such messages are acceptable
+		for _, err := range detectNonASCII(mtx.Body, mtx.FieldOrder) {
+			errs = append(errs, NewError(err, mtx.Line))
+		}
+	}
+
+	if cfg.DeprecationWarnings {
+		for _, err := range detectDeprecatedFields(mtx.Type(), mtx.FieldOrder) {
+			errs = append(errs, NewWarning(err, mtx.Line))
+		}
+	}
+
+	mtx.Body = cfg.transformBody(mtx.Body)
+
+	return mtx, errs, true
+}
+
+// transformBody applies the transformations of the values of the fields in the body the configuration calls for, e.g.
+// the normalization of the decimal separator.
+func (cfg config) transformBody(body map[string][]string) map[string][]string {
+	body = normalizeDecimalSeparator(body, cfg.DecimalSeparator)
+	body = applyImpliedDecimals(body, cfg.ImpliedDecimals)
+
+	return body
+}
+
 // ParseMTx takes as input a reader and will attempt to parse all MT messages in the input and publish them to the
 // returned channel. Any messages that cannot be parsed are discarded. The errors encountered during parsing are
 // published on the returned parse error channel.
@@ -23,8 +122,37 @@ import (
 // This function returns generic MT messages, meaning the body is not parsed but simply returned as a
 // map[string][]string. Look to the specialized derivatives for messages with fully parsed bodies.
 //
+// Any text before the first block of a message is skipped, the ReportSkipped option has it reported as a warning and
+// the SkippedContent option hands it to a function for inspection. For inputs wrapped in an envelope, such as RJE, the
+// Envelope option can be used to have the envelope stripped before parsing. Gzip compressed inputs, e.g. archived
+// statement files, are detected and decompressed transparently. An input that ends within a block, e.g. because it was
+// truncated, has its last message discarded and reported as incomplete.
+//
+// The reader is consumed by parsing, it can't be parsed again. A reader that is an io.Seeker and turns out to have been
+// read to its end already, e.g. because it was parsed before, is reported as exhausted rather than parsed as an empty
+// input. Wrap the reader with NewRewindable to be able to parse the same input again.
+//
+// When the Limit option is passed parsing stops after the given number of messages, leaving the rest of the input
+// unread. The LazyBody option defers parsing the fields of the body until they are accessed, for workloads that only
+// need a few of them.
+//
+// When the Strict option is passed the logical terminal addresses in the headers are validated, as are the service type
+// identifier (111), unique end-to-end transaction reference (121) and sanctions screening information (433) in the user
+// header, the session and sequence numbers and the obsolescence period. Messages failing validation are discarded
+// unless Lax is passed, in both cases the validation error is published. The same goes for body fields containing
+// characters outside the FIN character set when the FINCharSet option is passed. SkipValidation turns off all of these.
+// Non-ASCII characters found when the DetectNonASCII option is passed are only reported, the messages are kept
+// regardless. The same goes for deprecated fields found when the DeprecationWarnings option is passed, which are
+// reported as warnings.
+//
 // Using channels here means that potentially very large inputs can be read without running out of memory. If input is
-// expected to easily fit into memory it is advised to use ParseAllMTx for convenience instead.
+// expected to easily fit into memory it is advised to use ParseAllMTx for convenience instead. The Synchronous option
+// reduces the number of goroutines involved, which pays off when parsing many small inputs concurrently.
+//
+// ParseMTx, like all parse functions, is safe for concurrent use, also when the same options are passed to several
+// calls. The configuration is built anew for every call and the validators of the typed messages are never modified
+// after they are created. Functions passed through options, such as SkippedContent and WithLogger, are called by every
+// call they are passed to and must be safe for concurrent use when shared.
 //
 // Example usage:
 //
@@ -41,9 +169,13 @@ import (
 func ParseMTx(ctx context.Context, rd io.Reader, options ...option) (chan MTx, chan Error) {
 	cfg := optionsToConfig(options)
 
-	msgs, errs := message.Parse(ctx, rd, message.Config{
-		StopOnError: cfg.StopOnError,
-	})
+	if cfg.Synchronous {
+		return parseMTxSynchronous(ctx, rd, cfg)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	msgs, errs := message.Parse(ctx, inputReader(rd, cfg), cfg.messageConfig(ctx))
 
 	wg := &sync.WaitGroup{}
 	mtxCh := make(chan MTx)
@@ -53,17 +185,27 @@ func ParseMTx(ctx context.Context, rd io.Reader, options ...option) (chan MTx, c
 	go func() {
 		defer wg.Done()
 
-		for msg := range msgs {
-			mtx, errs := messageToMTx(msg)
-			if errs != nil {
-				for _, err := range errs {
-					errCh <- err
-				}
+		emitted := 0
 
+		for msg := range msgs {
+			mtx, errs, ok := processMessage(msg, cfg)
+			cfg.logProcessed(ctx, mtx, errs, ok)
+			for _, err := range errs {
+				errCh <- err
+			}
+			if !ok {
 				continue
 			}
 
 			mtxCh <- mtx
+
+			emitted++
+			if cfg.Limit > 0 && emitted >= cfg.Limit {
+				// stop reading the input, the remaining messages are drained so the parser can finish
+				cancel()
+				for range msgs {
+				}
+			}
 		}
 	}()
 
@@ -72,12 +214,15 @@ func ParseMTx(ctx context.Context, rd io.Reader, options ...option) (chan MTx, c
 		defer wg.Done()
 
 		for err := range errs {
-			errCh <- NewError(err.Err, err.Line)
+			pe := messageError(err)
+			cfg.logError(ctx, pe)
+			errCh <- pe
 		}
 	}()
 
 	go func() {
 		wg.Wait()
+		cancel()
 		close(mtxCh)
 		close(errCh)
 	}()
@@ -85,6 +230,198 @@ func ParseMTx(ctx context.Context, rd io.Reader, options ...option) (chan MTx, c
 	return mtxCh, errCh
 }
 
+// parseMTxSynchronous behaves like ParseMTx but reads the input and turns the messages into MTx within a single
+// goroutine, which publishes both the messages and the errors in the order they are encountered.
+func parseMTxSynchronous(ctx context.Context, rd io.Reader, cfg config) (chan MTx, chan Error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	mtxCh := make(chan MTx)
+	errCh := make(chan Error)
+
+	go func() {
+		defer func() {
+			cancel()
+			close(mtxCh)
+			close(errCh)
+		}()
+
+		publish := func(mtx MTx) bool {
+			select {
+			case mtxCh <- mtx:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		parseSynchronous(ctx, rd, cfg, cfg.messageConfig(ctx), publish, publishErrFunc(ctx, errCh))
+	}()
+
+	return mtxCh, errCh
+}
+
+// publishErrFunc returns a function publishing errors on errCh. Publishing stops once the context is done, the
+// receiving end might have stopped listening, in which case the function returns false.
+func publishErrFunc(ctx context.Context, errCh chan Error) func(Error) bool {
+	return func(err Error) bool {
+		select {
+		case errCh <- err:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// parseSynchronous reads the input and turns the messages into MTx within the calling goroutine, passing every message
+// to publish and every error to publishErr. Parsing stops once either returns false or the Limit is reached.
+func parseSynchronous(
+	ctx context.Context,
+	rd io.Reader,
+	cfg config,
+	msgCfg message.Config,
+	publish func(MTx) bool,
+	publishErr func(Error) bool,
+) {
+	emitted := 0
+
+	onMessage := func(msg message.Message) bool {
+		mtx, errs, ok := processMessage(msg, cfg)
+		cfg.logProcessed(ctx, mtx, errs, ok)
+		for _, err := range errs {
+			if !publishErr(err) {
+				return false
+			}
+		}
+		if !ok {
+			return true
+		}
+
+		if !publish(mtx) {
+			return false
+		}
+
+		emitted++
+
+		return cfg.Limit <= 0 || emitted < cfg.Limit
+	}
+	onError := func(err message.Error) {
+		pe := messageError(err)
+		cfg.logError(ctx, pe)
+		publishErr(pe)
+	}
+
+	message.ParseReader(ctx, inputReader(rd, cfg), msgCfg, onMessage, onError)
+}
+
+// ParseHeaders parses all MT messages in the input like ParseMTx, but only the headers, blocks 1 to 3, and the
+// trailers, block 5, are parsed. The body is skipped as a whole rather than split into fields, only RawBody holds it,
+// verbatim, and FieldOrder and PresentFields are left empty. This makes it the fastest option for workloads that only
+// need to look at e.g. the type, sender or receiver of a message, to route it elsewhere for instance.
+//
+// The options of ParseMTx apply, except for those concerning the fields of the body, e.g. FINCharSet, which have no
+// effect. Both the messages and the errors are published from a single goroutine, in the order they are encountered,
+// as with the Synchronous option.
+func ParseHeaders(ctx context.Context, rd io.Reader, options ...option) (chan Base, chan Error) {
+	cfg := optionsToConfig(options)
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	baseCh := make(chan Base)
+	errCh := make(chan Error)
+
+	go func() {
+		defer func() {
+			cancel()
+			close(baseCh)
+			close(errCh)
+		}()
+
+		publish := func(mtx MTx) bool {
+			select {
+			case baseCh <- mtx.Base:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		msgCfg := cfg.messageConfig(ctx)
+		msgCfg.SkipBody = true
+
+		parseSynchronous(ctx, rd, cfg, msgCfg, publish, publishErrFunc(ctx, errCh))
+	}()
+
+	return baseCh, errCh
+}
+
+// ForEachMTx parses all MT messages in the input, like ParseMTx, and calls fn for every message in the order they
+// appear in. Parse errors are passed to onErr, which may be nil to ignore them. The callbacks are never called
+// concurrently.
+//
+// When fn returns an error parsing stops, the rest of the input is left unread, and the error is returned wrapped. When
+// the context is done before the end of the input is reached the error of the context is returned.
+//
+// Example usage:
+//
+//	err := ForEachMTx(ctx, f, func(mtx MTx) error {
+//		return store(mtx)
+//	}, func(err Error) {
+//		log.Printf("could not parse message: %v", err)
+//	})
+//	if err != nil {
+//		// handle the error returned by store
+//	}
+func ForEachMTx(
+	ctx context.Context,
+	rd io.Reader,
+	fn func(MTx) error,
+	onErr func(Error),
+	options ...option,
+) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	mtxCh, errCh := ParseMTx(ctx, rd, options...)
+
+	var fnErr error
+	for mtxCh != nil || errCh != nil {
+		select {
+		case mtx, ok := <-mtxCh:
+			if !ok {
+				mtxCh = nil
+				continue
+			}
+			// keep draining the channels after an error so the parsing goroutines can finish
+			if fnErr != nil {
+				continue
+			}
+
+			err := fn(mtx)
+			if err != nil {
+				fnErr = fmt.Errorf("could not process message on line %d: %w", mtx.Line, err)
+				cancel()
+			}
+		case err, ok := <-errCh:
+			if !ok {
+				errCh = nil
+				continue
+			}
+			if fnErr != nil || onErr == nil {
+				continue
+			}
+
+			onErr(err)
+		}
+	}
+
+	if fnErr != nil {
+		return fnErr
+	}
+
+	return ctx.Err()
+}
+
 // ParseAllMTx takes as input a reader and will attempt to parse all MT messages in the input and return them to the
 // caller. It's a convenience function for reading an entire input. If the input is expected to be very large, too large
 // to fit in memory, use ParseMTx instead.
@@ -143,3 +480,123 @@ func ParseAllMTx(ctx context.Context, rd io.Reader, options ...option) ([]MTx, e
 
 	return genericMessages, nil
 }
+
+// ParseOneMTx takes as input a reader holding exactly one MT message, parses it and returns it to the caller. It's a
+// convenience function for the common case of a single message, e.g. one taken from a queue or database. Parsing
+// stops after the second message, an input holding no messages or more than one results in an error.
+//
+// As with ParseAllMTx, in case of any errors during parsing a custom error is returned that encapsulates the parse
+// errors, the message is returned regardless.
+//
+// Example usage:
+//
+//	message, err := ParseOneMTx(ctx, strings.NewReader(input))
+//	if err != nil {
+//		// handle parse errors
+//	}
+//
+// 	return message, nil
+func ParseOneMTx(ctx context.Context, rd io.Reader, options ...option) (MTx, error) {
+	// one more than expected is read to be able to tell whether the input holds more than one message
+	genericMessages, err := ParseAllMTx(ctx, rd, append(options, Limit(2))...)
+
+	switch {
+	case len(genericMessages) == 0 && err != nil:
+		return MTx{}, err
+	case len(genericMessages) == 0:
+		return MTx{}, fmt.Errorf("expected exactly one message, found none")
+	case len(genericMessages) > 1:
+		return genericMessages[0], fmt.Errorf("expected exactly one message, found more")
+	}
+
+	return genericMessages[0], err
+}
+
+// ParseBytes parses all MT messages in the given input and returns them to the caller. It accepts the same options as
+// ParseAllMTx and returns the same results, but parses synchronously, without the goroutines and channels ParseMTx
+// uses to be able to process inputs of any size. For inputs that are already held in memory, small inputs in
+// particular, this makes it the faster option.
+//
+// Example usage:
+//
+//	messages, err := ParseBytes(input)
+//	if err != nil {
+//		// handle parse errors
+//	}
+//
+// 	return messages, nil
+func ParseBytes(b []byte, options ...option) ([]MTx, error) {
+	cfg := optionsToConfig(options)
+
+	if cfg.Envelope != EnvelopePlain || bytes.HasPrefix(b, gzipMagic) {
+		stripped, err := io.ReadAll(inputReader(bytes.NewReader(b), cfg))
+		if err != nil {
+			return nil, Errors{NewError(err, 1)}
+		}
+
+		b = stripped
+	}
+
+	genericMessages := make([]MTx, 0)
+	parseErrors := make(Errors, 0)
+
+	// there is no context to pass to the logger, ParseBytes doesn't take one
+	ctx := context.Background()
+
+	onMessage := func(msg message.Message) bool {
+		mtx, errs, ok := processMessage(msg, cfg)
+		cfg.logProcessed(ctx, mtx, errs, ok)
+		parseErrors = append(parseErrors, errs...)
+		if ok {
+			genericMessages = append(genericMessages, mtx)
+		}
+
+		return cfg.Limit <= 0 || len(genericMessages) < cfg.Limit
+	}
+	onError := func(err message.Error) {
+		pe := messageError(err)
+		cfg.logError(ctx, pe)
+		parseErrors = append(parseErrors, pe)
+	}
+
+	message.ParseBytes(b, cfg.messageConfig(ctx), onMessage, onError)
+
+	if len(parseErrors) > 0 {
+		return genericMessages, parseErrors
+	}
+
+	return genericMessages, nil
+}
+
+// SplitMessages splits the input into the raw text of each message, from its basic header up to and including its
+// last block, without parsing their structure. The boundaries of the messages are detected the same way the parse
+// functions detect them. This is lighter than parsing the messages, e.g. to archive or forward them, or to distribute
+// them over multiple parsers.
+//
+// In case of an error reading the input the messages split so far are returned together with the error. Gzip
+// compressed inputs are decompressed transparently, as the parse functions do.
+//
+// Example usage:
+//
+//	messages, err := SplitMessages(f)
+//	if err != nil {
+//		// handle read errors
+//	}
+//
+// 	for _, message := range messages {
+// 		// archive the raw message
+// 	}
+func SplitMessages(rd io.Reader) ([]string, error) {
+	msgs, errs := message.Split(newGzipReader(rd))
+
+	if len(errs) > 0 {
+		splitErrors := make(Errors, len(errs))
+		for i, err := range errs {
+			splitErrors[i] = NewError(err.Err, err.Line)
+		}
+
+		return msgs, splitErrors
+	}
+
+	return msgs, nil
+}
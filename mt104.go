@@ -0,0 +1,238 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+package mt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/DennisVis/mt/internal/encoding/mt"
+	"github.com/DennisVis/mt/internal/validate"
+)
+
+// MT104 represents a Direct Debit and Request for Debit Transfer Message.
+// The message consists of sequence A, general information, held by the MT104 itself, the repeating sequence B, one
+// per transaction, and the mandatory sequence C, the settlement details. The amounts of sequence C must add up to the
+// amounts of the transactions.
+// It's based on the spec here: https://www2.swift.com/knowledgecentre/publications/us1m_20210723/1.0?topic=mt104.htm
+type MT104 struct {
+	Base
+	Reference                   string              `mt:"20,M,16x"`
+	CustomerSpecifiedReference  string              `mt:"21R,O,16x"`
+	InstructionCode             string              `mt:"23E,O,4!c(/30x)"`
+	RegistrationReference       string              `mt:"21E,O,35x"`
+	RequestedExecutionDate      Date                `mt:"30,M,6!n"`
+	SendingInstitution          string              `mt:"51A,O,2*35x"`
+	InstructingParty            string              `mt:"50C,O,4!a2!a2!c(3!c)" mtgroup:"50CL,exclusive"`
+	InstructingPartyIdentifier  string              `mt:"50L,O,35x" mtgroup:"50CL,exclusive"`
+	Creditor                    string              `mt:"50A,O,2*35x" mtgroup:"50AK,exclusive"`
+	CreditorNameAddress         string              `mt:"50K,O,5*35x" mtgroup:"50AK,exclusive"`
+	CreditorsBank               string              `mt:"52A,O,2*35x" mtgroup:"52,exclusive"`
+	CreditorsBankCode           string              `mt:"52C,O,/34x" mtgroup:"52,exclusive"`
+	CreditorsBankNameAddress    string              `mt:"52D,O,5*35x" mtgroup:"52,exclusive"`
+	TransactionTypeCode         string              `mt:"26T,O,3!c"`
+	RegulatoryReporting         StructuredNarrative `mt:"77B,O,3*35x"`
+	DetailsOfCharges            string              `mt:"71A,O,3!a"`
+	SenderToReceiverInformation string              `mt:"72,O,6*35x"`
+	Transactions                []MT104Transaction
+	Settlement                  MT104Settlement
+}
+
+// MT104Transaction represents a single transaction, sequence B, of an MT104.
+type MT104Transaction struct {
+	TransactionReference       string              `mt:"21,M,16x"`
+	InstructionCode            string              `mt:"23E,O,4!c(/30x)"`
+	MandateReference           string              `mt:"21C,O,35x"`
+	DirectDebitReference       string              `mt:"21D,O,35x"`
+	RegistrationReference      string              `mt:"21E,O,35x"`
+	CurrencyAmount             CurrencyAmount      `mt:"32B,M,dive"`
+	InstructingParty           string              `mt:"50C,O,4!a2!a2!c(3!c)" mtgroup:"50CL,exclusive"`
+	InstructingPartyIdentifier string              `mt:"50L,O,35x" mtgroup:"50CL,exclusive"`
+	Creditor                   string              `mt:"50A,O,2*35x" mtgroup:"50AK,exclusive"`
+	CreditorNameAddress        string              `mt:"50K,O,5*35x" mtgroup:"50AK,exclusive"`
+	CreditorsBank              string              `mt:"52A,O,2*35x" mtgroup:"52,exclusive"`
+	CreditorsBankCode          string              `mt:"52C,O,/34x" mtgroup:"52,exclusive"`
+	CreditorsBankNameAddress   string              `mt:"52D,O,5*35x" mtgroup:"52,exclusive"`
+	DebtorsBank                string              `mt:"57A,O,2*35x" mtgroup:"57,exclusive"`
+	DebtorsBankCode            string              `mt:"57C,O,/34x" mtgroup:"57,exclusive"`
+	DebtorsBankNameAddress     string              `mt:"57D,O,5*35x" mtgroup:"57,exclusive"`
+	Debtor                     string              `mt:"59A,O,2*35x" mtgroup:"59,oneof"`
+	DebtorNameAddress          string              `mt:"59,O,5*35x" mtgroup:"59,oneof"`
+	RemittanceInformation      string              `mt:"70,O,4*35x"`
+	TransactionTypeCode        string              `mt:"26T,O,3!c"`
+	RegulatoryReporting        StructuredNarrative `mt:"77B,O,3*35x"`
+	OriginalOrderedAmount      CurrencyAmount      `mt:"33B,O,dive"`
+	DetailsOfCharges           string              `mt:"71A,O,3!a"`
+	SendersCharges             CurrencyAmount      `mt:"71F,O,dive"`
+	ReceiversCharges           CurrencyAmount      `mt:"71G,O,dive"`
+	ExchangeRate               string              `mt:"36,O,12d"`
+}
+
+// MT104Settlement represents the settlement details, sequence C, of an MT104. Set is false when the sequence is absent,
+// which fails validation as the sequence is mandatory.
+type MT104Settlement struct {
+	Set                             bool
+	SettlementAmount                CurrencyAmount `mt:"32B,M,dive"`
+	SumOfAmounts                    Amount         `mt:"19,O,17d"`
+	SumOfSendersCharges             CurrencyAmount `mt:"71F,O,dive"`
+	SumOfReceiversCharges           CurrencyAmount `mt:"71G,O,dive"`
+	SendersCorrespondent            string         `mt:"53A,O,2*35x" mtgroup:"53,exclusive"`
+	SendersCorrespondentNameAddress string         `mt:"53B,O,2*35x" mtgroup:"53,exclusive"`
+}
+
+var (
+	mt104TransactionValidator = validate.MustCreateValidatorForStruct(MT104Transaction{})
+	mt104SettlementValidator  = validate.MustCreateValidatorForStruct(MT104Settlement{})
+)
+
+// mt104SettlementOnlyTags are the tags that only occur in sequence C, their presence marks the start of the sequence.
+var mt104SettlementOnlyTags = map[string]bool{
+	"19":  true,
+	"53A": true,
+	"53B": true,
+}
+
+// splitMT104Sequences splits the body into sequence A, the sequences B and sequence C, nil if absent. A sequence B starts
+// at each field 21, sequence C starts at the second field 32B within the last sequence B or at a field only found in
+// sequence C, whichever comes first.
+func splitMT104Sequences(body map[string][]string, order []string) (*sequenceBody, []*sequenceBody, *sequenceBody) {
+	general := newSequenceBody()
+	transactions := make([]*sequenceBody, 0)
+	var settlement *sequenceBody
+
+	current := general
+
+	eachOrderedField(order, body, func(tag string, _ int, value string) {
+		switch {
+		case settlement != nil:
+		case tag == "21":
+			current = newSequenceBody()
+			transactions = append(transactions, current)
+		case len(transactions) > 0 && (mt104SettlementOnlyTags[tag] || tag == "32B" && len(current.fields["32B"]) > 0):
+			settlement = newSequenceBody()
+			current = settlement
+		}
+
+		current.add(tag, value)
+	})
+
+	return general, transactions, settlement
+}
+
+func (msg *MT104) unmarshalSequences(mtx MTx) error {
+	general, transactions, settlement := splitMT104Sequences(mtx.Body, mtx.FieldOrder)
+
+	err := mt.UnmarshalMT(general.fields, msg)
+	if err != nil {
+		return fmt.Errorf("sequence A: %w", err)
+	}
+
+	msg.Transactions = make([]MT104Transaction, len(transactions))
+	for i, transaction := range transactions {
+		err = mt.UnmarshalMT(transaction.fields, &msg.Transactions[i])
+		if err != nil {
+			return fmt.Errorf("sequence B[%d]: %w", i, err)
+		}
+	}
+
+	if settlement != nil {
+		err = mt.UnmarshalMT(settlement.fields, &msg.Settlement)
+		if err != nil {
+			return fmt.Errorf("sequence C: %w", err)
+		}
+		msg.Settlement.Set = true
+	}
+
+	return nil
+}
+
+// validateSettlementTotals verifies the settlement details add up to the transactions. The currency of all amounts must
+// be the same. When the sum of amounts (19) is present it must equal the sum of the transaction amounts, otherwise the
+// settlement amount must.
+func (msg MT104) validateSettlementTotals() error {
+	settlement := msg.Settlement.SettlementAmount
+
+	decimals := settlement.Amount.Decimals
+	if msg.Settlement.SumOfAmounts.Decimals > decimals {
+		decimals = msg.Settlement.SumOfAmounts.Decimals
+	}
+	for _, transaction := range msg.Transactions {
+		if transaction.CurrencyAmount.Amount.Decimals > decimals {
+			decimals = transaction.CurrencyAmount.Amount.Decimals
+		}
+	}
+
+	var sum int64
+	for i, transaction := range msg.Transactions {
+		if transaction.CurrencyAmount.Currency != settlement.Currency {
+			return fmt.Errorf(
+				"sequence B[%d]: currency %s differs from settlement currency %s",
+				i,
+				transaction.CurrencyAmount.Currency,
+				settlement.Currency,
+			)
+		}
+
+		sum += scaledAmountValue(transaction.CurrencyAmount.Amount, decimals)
+	}
+
+	label, total := "32B", settlement.Amount
+	if msg.Settlement.SumOfAmounts.Set {
+		label, total = "19", msg.Settlement.SumOfAmounts
+	}
+
+	if scaledAmountValue(total, decimals) != sum {
+		expected := Amount{Value: sum, Decimals: decimals}
+		expectedStr, _ := expected.MarshalMT()
+
+		return fmt.Errorf(
+			"sequence C: amount %s in field %s does not equal the sum of the transaction amounts %s",
+			total.RawString(),
+			label,
+			expectedStr,
+		)
+	}
+
+	return nil
+}
+
+func (msg MT104) validateSequences() error {
+	errs := make([]string, 0)
+
+	if len(msg.Transactions) == 0 {
+		errs = append(errs, "sequence B: expected at least one transaction")
+	}
+
+	for i, transaction := range msg.Transactions {
+		err := mt104TransactionValidator.Validate(transaction)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("sequence B[%d]:\n%s", i, err))
+		}
+	}
+
+	if !msg.Settlement.Set {
+		errs = append(errs, "sequence C: expected the settlement details")
+	} else {
+		err := mt104SettlementValidator.Validate(msg.Settlement)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("sequence C:\n%s", err))
+		}
+
+		// the totals can only be verified when the amounts are valid
+		if err == nil && len(errs) == 0 {
+			totalsErr := msg.validateSettlementTotals()
+			if totalsErr != nil {
+				errs = append(errs, totalsErr.Error())
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+
+	return nil
+}
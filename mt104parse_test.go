@@ -0,0 +1,207 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DennisVis/mt"
+	mttest "github.com/DennisVis/mt/testdata"
+)
+
+const mt104Header = `{1:F01BANKDEFFAXXX0000000000}{2:I104BANKBEBBXXXXN}{4:
+:20:REF144
+:30:210915
+:50K:/DE62888888880012345678
+CREDITOR NAME
+AMSTERDAM
+`
+
+const mt104Transactions = `:21:TRX1
+:32B:EUR1000,00
+:59:/BE62510007547061
+DEBTOR ONE
+:70:INVOICE 1
+:21:TRX2
+:32B:EUR500,50
+:57A:GEBABEBB
+:59:/BE68539007547034
+DEBTOR TWO
+`
+
+func TestParseMT104RegulatoryReporting(t *testing.T) {
+	input := mt104Header + ":77B:/ORDERRES/BE//MEILAAN 1, 9000 GENT\n//AND FURTHER\n/BENEFRES/NL\n" +
+		mt104Transactions + ":77B:/BENEFRES/DE\n:32B:EUR1500,50\n-}"
+
+	msgs, err := mt.ParseAllMT104(ctx, strings.NewReader(input))
+	mttest.ValidateError(t, nil, err)
+
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+
+	msg := msgs[0]
+
+	expected := []mt.NarrativeItem{
+		{Code: "ORDERRES", Text: "BE//MEILAAN 1, 9000 GENT\nAND FURTHER"},
+		{Code: "BENEFRES", Text: "NL"},
+	}
+	if !reflect.DeepEqual(msg.RegulatoryReporting.Items, expected) {
+		t.Errorf("RegulatoryReporting expected %+v, got %+v", expected, msg.RegulatoryReporting.Items)
+	}
+
+	if msg.Transactions[0].RegulatoryReporting.Set {
+		t.Errorf("expected the regulatory reporting of the first transaction to be absent")
+	}
+	transactionExpected := []mt.NarrativeItem{{Code: "BENEFRES", Text: "DE"}}
+	if !reflect.DeepEqual(msg.Transactions[1].RegulatoryReporting.Items, transactionExpected) {
+		t.Errorf(
+			"Transactions[1].RegulatoryReporting expected %+v, got %+v",
+			transactionExpected,
+			msg.Transactions[1].RegulatoryReporting.Items,
+		)
+	}
+}
+
+func TestParseMT104(t *testing.T) {
+	for _, test := range []struct {
+		name                 string
+		body                 string
+		expectedParseErrors  mt.Errors
+		expectedTransactions []string
+		expectedSettlement   string
+	}{
+		{
+			name:                 "Valid",
+			body:                 mt104Transactions + ":32B:EUR1500,50\n",
+			expectedTransactions: []string{"TRX1", "TRX2"},
+			expectedSettlement:   "EUR1500,50",
+		},
+		{
+			name: "MissingSettlement",
+			body: mt104Transactions,
+			expectedParseErrors: mt.Errors{
+				mt.NewError(fmt.Errorf("sequence C: expected the settlement details"), 1),
+			},
+		},
+		{
+			name:                 "ValidSumOfAmounts",
+			body:                 mt104Transactions + ":32B:EUR1510,50\n:19:1500,50\n:71F:EUR10,\n",
+			expectedTransactions: []string{"TRX1", "TRX2"},
+			expectedSettlement:   "EUR1510,50",
+		},
+		{
+			name: "SettlementAmountMismatch",
+			body: mt104Transactions + ":32B:EUR1500,00\n",
+			expectedParseErrors: mt.Errors{
+				mt.NewError(fmt.Errorf(
+					"sequence C: amount 1500,00 in field 32B does not equal the sum of the transaction amounts 1500,50",
+				), 1),
+			},
+		},
+		{
+			name: "SumOfAmountsMismatch",
+			body: mt104Transactions + ":32B:EUR1510,50\n:19:1510,50\n",
+			expectedParseErrors: mt.Errors{
+				mt.NewError(fmt.Errorf(
+					"sequence C: amount 1510,50 in field 19 does not equal the sum of the transaction amounts 1500,50",
+				), 1),
+			},
+		},
+		{
+			name: "CurrencyMismatch",
+			body: mt104Transactions + ":32B:USD1500,50\n",
+			expectedParseErrors: mt.Errors{
+				mt.NewError(fmt.Errorf("sequence B[0]: currency EUR differs from settlement currency USD"), 1),
+			},
+		},
+		{
+			name: "MissingDebtor",
+			body: ":21:TRX1\n:32B:EUR1000,00\n",
+			expectedParseErrors: mt.Errors{
+				mt.NewError(fmt.Errorf("expected exactly one of Debtor, DebtorNameAddress to be present, got 0"), 1),
+			},
+		},
+		{
+			name: "NoTransactions",
+			body: "",
+			expectedParseErrors: mt.Errors{
+				mt.NewError(fmt.Errorf("sequence B: expected at least one transaction"), 1),
+			},
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			input := mt104Header + test.body + "-}"
+
+			msgs, err := mt.ParseAllMT104(ctx, strings.NewReader(input))
+			mttest.ValidateErrors(t, test.expectedParseErrors, err)
+
+			if test.expectedParseErrors != nil {
+				if len(msgs) != 0 {
+					t.Errorf("expected invalid message to be discarded, got %d messages", len(msgs))
+				}
+				return
+			}
+
+			if len(msgs) != 1 {
+				t.Fatalf("expected 1 message, got %d", len(msgs))
+			}
+
+			msg := msgs[0]
+
+			if msg.Reference != "REF144" {
+				t.Errorf("Reference expected %q, got %q", "REF144", msg.Reference)
+			}
+			if msg.RequestedExecutionDate.RawString() != "210915" {
+				t.Errorf("RequestedExecutionDate expected %q, got %q", "210915", msg.RequestedExecutionDate.RawString())
+			}
+			if expected := "/DE62888888880012345678\nCREDITOR NAME\nAMSTERDAM"; msg.CreditorNameAddress != expected {
+				t.Errorf("CreditorNameAddress expected %q, got %q", expected, msg.CreditorNameAddress)
+			}
+
+			if len(msg.Transactions) != len(test.expectedTransactions) {
+				t.Fatalf("expected %d transactions, got %d", len(test.expectedTransactions), len(msg.Transactions))
+			}
+			for i, expected := range test.expectedTransactions {
+				if msg.Transactions[i].TransactionReference != expected {
+					t.Errorf(
+						"Transactions[%d].TransactionReference expected %q, got %q",
+						i,
+						expected,
+						msg.Transactions[i].TransactionReference,
+					)
+				}
+			}
+
+			// fields of the transactions are not mixed up with those of other sequences
+			if msg.Transactions[0].RemittanceInformation != "INVOICE 1" || msg.Transactions[1].RemittanceInformation != "" {
+				t.Errorf("RemittanceInformation not attributed to the first transaction only")
+			}
+			if msg.Transactions[1].DebtorsBank != "GEBABEBB" {
+				t.Errorf("Transactions[1].DebtorsBank expected %q, got %q", "GEBABEBB", msg.Transactions[1].DebtorsBank)
+			}
+
+			if msg.Settlement.Set != (test.expectedSettlement != "") {
+				t.Errorf("Settlement.Set expected %t, got %t", test.expectedSettlement != "", msg.Settlement.Set)
+			}
+			if msg.Settlement.SettlementAmount.RawString() != test.expectedSettlement {
+				t.Errorf(
+					"SettlementAmount expected %q, got %q",
+					test.expectedSettlement,
+					msg.Settlement.SettlementAmount.RawString(),
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+package mt
+
+// MT900 represents a Confirmation of Debit.
+// It's based on the spec here: https://www2.swift.com/knowledgecentre/publications/us9m_20210723/1.0?topic=mt900.htm
+type MT900 struct {
+	Base
+	Reference                      string             `mt:"20,M,16x"`
+	RelatedReference               string             `mt:"21,M,16x"`
+	AccountIdentification          string             `mt:"25,M,35x"`
+	DateTimeIndication             DateTimeIndication `mt:"13D,O,6!n4!n1!x4!n"`
+	ValueDateCurrencyAmount        DateCurrencyAmount `mt:"32A,M,dive"`
+	OrderingInstitution            string             `mt:"52A,O,2*35x" mtgroup:"52,exclusive"`
+	OrderingInstitutionNameAddress string             `mt:"52D,O,4*35x" mtgroup:"52,exclusive"`
+	SenderToReceiverInformation    string             `mt:"72,O,6*35x"`
+}
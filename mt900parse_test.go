@@ -0,0 +1,129 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/DennisVis/mt"
+	mttest "github.com/DennisVis/mt/testdata"
+)
+
+func validateDateCurrencyAmount(t *testing.T, name string, expected, actual mt.DateCurrencyAmount) {
+	t.Run(name, func(t *testing.T) {
+		if expected.Date.Raw != actual.Date.Raw {
+			t.Errorf("expected date %s, got %s", expected.Date, actual.Date)
+		}
+		if expected.Currency != actual.Currency {
+			t.Errorf("expected currency %s, got %s", expected.Currency, actual.Currency)
+		}
+		if expected.Amount != actual.Amount {
+			t.Errorf("expected amount %s, got %s", expected.Amount, actual.Amount)
+		}
+	})
+}
+
+func TestParseMT900(t *testing.T) {
+	for _, test := range []struct {
+		name                string
+		input               io.Reader
+		expectedParseErrors mt.Errors
+		expectedMT900       *mt.MT900
+	}{
+		{
+			name:                "InvalidInput",
+			input:               &mttest.TestReaderInvalid{},
+			expectedParseErrors: []mt.Error{mt.NewError(mttest.ErrReadInvalid, 1)},
+		},
+		{
+			name: "Valid",
+			input: strings.NewReader(`{1:F01AAAABEBBAXXX0000000000}{2:O9001130210315CHASUS33AXXX00000000002103151130N}{4:
+:20:C11126A1378
+:21:5482ABC
+:25:9-9876543
+:32A:210315USD233530,
+:52A:CHASUS33
+:72:/BNF/DIRECT DEBIT
+-}`),
+			expectedMT900: &mt.MT900{
+				Reference:             "C11126A1378",
+				RelatedReference:      "5482ABC",
+				AccountIdentification: "9-9876543",
+				ValueDateCurrencyAmount: mt.DateCurrencyAmount{
+					Date:     mt.Date{Raw: "210315"},
+					Currency: "USD",
+					Amount:   mttest.MustParseAmount("233530,"),
+				},
+				OrderingInstitution:         "CHASUS33",
+				SenderToReceiverInformation: "/BNF/DIRECT DEBIT",
+			},
+		},
+		{
+			name: "MissingValueDateCurrencyAmount",
+			input: strings.NewReader(`{1:F01AAAABEBBAXXX0000000000}{2:I900CHASUS33AXXXN}{4:
+:20:C11126A1378
+:21:5482ABC
+:25:9-9876543
+-}`),
+			expectedParseErrors: []mt.Error{
+				mt.NewError(fmt.Errorf("validation failed for MT900 message"), 1),
+			},
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			msgs, err := mt.ParseAllMT900(ctx, test.input)
+			mttest.ValidateErrors(t, test.expectedParseErrors, err)
+
+			if test.expectedMT900 == nil {
+				return
+			}
+			if len(msgs) != 1 {
+				t.Fatalf("expected 1 message, got %d", len(msgs))
+			}
+
+			expected := *test.expectedMT900
+			actual := msgs[0]
+
+			if expected.Reference != actual.Reference {
+				t.Errorf("Reference expected %v, got %v", expected.Reference, actual.Reference)
+			}
+			if expected.RelatedReference != actual.RelatedReference {
+				t.Errorf("RelatedReference expected %v, got %v", expected.RelatedReference, actual.RelatedReference)
+			}
+			if expected.AccountIdentification != actual.AccountIdentification {
+				t.Errorf(
+					"AccountIdentification expected %v, got %v",
+					expected.AccountIdentification,
+					actual.AccountIdentification,
+				)
+			}
+			validateDateCurrencyAmount(
+				t,
+				"ValueDateCurrencyAmount",
+				expected.ValueDateCurrencyAmount,
+				actual.ValueDateCurrencyAmount,
+			)
+			if expected.OrderingInstitution != actual.OrderingInstitution {
+				t.Errorf("OrderingInstitution expected %v, got %v", expected.OrderingInstitution, actual.OrderingInstitution)
+			}
+			if expected.SenderToReceiverInformation != actual.SenderToReceiverInformation {
+				t.Errorf(
+					"SenderToReceiverInformation expected %v, got %v",
+					expected.SenderToReceiverInformation,
+					actual.SenderToReceiverInformation,
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+package mt
+
+// MT910 represents a Confirmation of Credit.
+// It's based on the spec here: https://www2.swift.com/knowledgecentre/publications/us9m_20210723/1.0?topic=mt910.htm
+type MT910 struct {
+	Base
+	Reference                      string             `mt:"20,M,16x"`
+	RelatedReference               string             `mt:"21,M,16x"`
+	AccountIdentification          string             `mt:"25,M,35x"`
+	DateTimeIndication             DateTimeIndication `mt:"13D,O,6!n4!n1!x4!n"`
+	ValueDateCurrencyAmount        DateCurrencyAmount `mt:"32A,M,dive"`
+	OrderingCustomer               string             `mt:"50A,O,2*35x" mtgroup:"50,exclusive"`
+	OrderingCustomerNameAddress    string             `mt:"50K,O,5*35x" mtgroup:"50,exclusive"`
+	OrderingInstitution            string             `mt:"52A,O,2*35x" mtgroup:"52,exclusive"`
+	OrderingInstitutionNameAddress string             `mt:"52D,O,4*35x" mtgroup:"52,exclusive"`
+	Intermediary                   string             `mt:"56A,O,2*35x" mtgroup:"56,exclusive"`
+	IntermediaryNameAddress        string             `mt:"56D,O,4*35x" mtgroup:"56,exclusive"`
+	SenderToReceiverInformation    string             `mt:"72,O,6*35x"`
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/DennisVis/mt"
+	mttest "github.com/DennisVis/mt/testdata"
+)
+
+func TestParseMT910(t *testing.T) {
+	for _, test := range []struct {
+		name                string
+		input               io.Reader
+		expectedParseErrors mt.Errors
+		expectedMT910       *mt.MT910
+	}{
+		{
+			name:                "InvalidInput",
+			input:               &mttest.TestReaderInvalid{},
+			expectedParseErrors: []mt.Error{mt.NewError(mttest.ErrReadInvalid, 1)},
+		},
+		{
+			name: "Valid",
+			input: strings.NewReader(`{1:F01AAAABEBBAXXX0000000000}{2:O9101130210315CHASUS33AXXX00000000002103151130N}{4:
+:20:C11126C9224
+:21:494936/DEV
+:25:6789-0123
+:13D:2103151130+0100
+:32A:210315USD500000,00
+:50K:/123456789
+JOHN DOE
+NEW YORK
+:56A:CITIUS33
+-}`),
+			expectedMT910: &mt.MT910{
+				Reference:             "C11126C9224",
+				RelatedReference:      "494936/DEV",
+				AccountIdentification: "6789-0123",
+				DateTimeIndication:    mt.DateTimeIndication{Raw: "2103151130+0100"},
+				ValueDateCurrencyAmount: mt.DateCurrencyAmount{
+					Date:     mt.Date{Raw: "210315"},
+					Currency: "USD",
+					Amount:   mttest.MustParseAmount("500000,00"),
+				},
+				OrderingCustomerNameAddress: "/123456789\nJOHN DOE\nNEW YORK",
+				Intermediary:                "CITIUS33",
+			},
+		},
+		{
+			name: "InvalidValueDateCurrencyAmount",
+			input: strings.NewReader(`{1:F01AAAABEBBAXXX0000000000}{2:I910CHASUS33AXXXN}{4:
+:20:C11126C9224
+:21:494936/DEV
+:25:6789-0123
+:32A:210315USD500000
+-}`),
+			expectedParseErrors: []mt.Error{
+				mt.NewError(fmt.Errorf("date currency amount: invalid amount"), 1),
+			},
+		},
+		{
+			name: "BothOrderingCustomerOptions",
+			input: strings.NewReader(`{1:F01AAAABEBBAXXX0000000000}{2:I910CHASUS33AXXXN}{4:
+:20:C11126C9224
+:21:494936/DEV
+:25:6789-0123
+:32A:210315USD500000,00
+:50A:CHASUS33
+:50K:/123456789
+JOHN DOE
+-}`),
+			expectedParseErrors: []mt.Error{
+				mt.NewError(fmt.Errorf("validation failed for MT910 message"), 1),
+			},
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			msgs, err := mt.ParseAllMT910(ctx, test.input)
+			mttest.ValidateErrors(t, test.expectedParseErrors, err)
+
+			if test.expectedMT910 == nil {
+				return
+			}
+			if len(msgs) != 1 {
+				t.Fatalf("expected 1 message, got %d", len(msgs))
+			}
+
+			expected := *test.expectedMT910
+			actual := msgs[0]
+
+			if expected.Reference != actual.Reference {
+				t.Errorf("Reference expected %v, got %v", expected.Reference, actual.Reference)
+			}
+			if expected.RelatedReference != actual.RelatedReference {
+				t.Errorf("RelatedReference expected %v, got %v", expected.RelatedReference, actual.RelatedReference)
+			}
+			if expected.DateTimeIndication.Raw != actual.DateTimeIndication.Raw {
+				t.Errorf("DateTimeIndication expected %v, got %v", expected.DateTimeIndication, actual.DateTimeIndication)
+			}
+			validateDateCurrencyAmount(
+				t,
+				"ValueDateCurrencyAmount",
+				expected.ValueDateCurrencyAmount,
+				actual.ValueDateCurrencyAmount,
+			)
+			if expected.OrderingCustomerNameAddress != actual.OrderingCustomerNameAddress {
+				t.Errorf(
+					"OrderingCustomerNameAddress expected %q, got %q",
+					expected.OrderingCustomerNameAddress,
+					actual.OrderingCustomerNameAddress,
+				)
+			}
+			if expected.Intermediary != actual.Intermediary {
+				t.Errorf("Intermediary expected %v, got %v", expected.Intermediary, actual.Intermediary)
+			}
+		})
+	}
+}
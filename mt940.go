@@ -4,14 +4,366 @@
 // https://opensource.org/licenses/MIT
 package mt
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DennisVis/mt/internal/encoding/mt"
+	"github.com/DennisVis/mt/internal/validate"
+)
+
 // MT940 represents a Customer Statement Message.
+// A statement spanning multiple messages carries intermediate balances, option M, instead of the final ones, option F,
+// in fields 60a and 62a on the pages where it's continued. The option present is recorded as the Option of the balance.
+// Field 86 directly following a statement line holds information on that line and is set as its Information, only the
+// other occurrences, holding information on the statement as a whole, end up in AccountOwnerInformation.
+// StatementNumber holds field 28C split into the statement number, sequence number and page number, see the option
+// StatementPageNumber.
 // It's based on the spec here: https://www2.swift.com/knowledgecentre/publications/us9m_20210723/1.0?topic=mt940.htm
 type MT940 struct {
 	Base
 	Reference                     string          `mt:"20,M,16x"`
 	AccountIdentification         string          `mt:"25,M,2!c26!n|8!c/12!n"`
 	StatementNumberSequenceNumber string          `mt:"28C,M,5!n(/3!n)"`
-	OpeningBalance                Balance         `mt:"60F,M,dive"`
+	OpeningBalance                Balance         `mt:"60a,M,dive"`
 	StatementLines                []StatementLine `mt:"61,O,dive"`
+	ClosingBalance                Balance         `mt:"62a,M,dive"`
+	ClosingAvailableBalance       Balance         `mt:"64,O,dive"`
+	ForwardAvailableBalances      []Balance       `mt:"65,O,dive"`
 	AccountOwnerInformation       []string        `mt:"86,O,6*65x"`
+	StatementNumber               StatementNumber
+}
+
+// Clone returns a deep copy of the message, see MTx.Clone. The statement lines, balances and information are copied
+// rather than shared with the original.
+func (msg MT940) Clone() MT940 {
+	cloned := msg
+	cloned.Base = msg.Base.clone()
+
+	if lines := msg.StatementLines; lines != nil {
+		cloned.StatementLines = append(make([]StatementLine, 0, len(lines)), lines...)
+	}
+	if balances := msg.ForwardAvailableBalances; balances != nil {
+		cloned.ForwardAvailableBalances = append(make([]Balance, 0, len(balances)), balances...)
+	}
+	cloned.AccountOwnerInformation = cloneStrings(msg.AccountOwnerInformation)
+
+	return cloned
+}
+
+// accountOwnerInformationPerLine determines, based on the order of the fields in the body, which occurrences of field
+// 86 directly followed a field 61. It returns the index of those occurrences per statement line, and the indexes of the
+// remaining occurrences, which hold information on the statement as a whole.
+func (msg MT940) accountOwnerInformationPerLine() (map[int]int, []int) {
+	perLine := make(map[int]int)
+	statement := make([]int, 0)
+
+	lineIdx := -1
+	infoIdx := 0
+	prevTag := ""
+
+	for _, tag := range msg.FieldOrder {
+		switch tag {
+		case "61":
+			lineIdx++
+		case "86":
+			if prevTag == "61" && lineIdx < len(msg.StatementLines) {
+				perLine[lineIdx] = infoIdx
+			} else {
+				statement = append(statement, infoIdx)
+			}
+			infoIdx++
+		}
+
+		prevTag = tag
+	}
+
+	// messages constructed in code have no field order, all their information is regarded as statement level
+	for ; infoIdx < len(msg.AccountOwnerInformation); infoIdx++ {
+		statement = append(statement, infoIdx)
+	}
+
+	return perLine, statement
+}
+
+// acceptStatementPage splits the page number off field 28C, keeping StatementNumberSequenceNumber in line.
+func (msg *MT940) acceptStatementPage() {
+	msg.StatementNumber.acceptPage()
+	msg.StatementNumberSequenceNumber = msg.StatementNumber.Raw
+}
+
+// unmarshalSequences decodes the body of the message. Every statement line, field 61, forms a sequence together with
+// the field 86 directly following it, if any. Such an 86 holds information on the statement line and is set on it, only
+// the remaining occurrences, holding information on the statement as a whole, are kept in AccountOwnerInformation.
+func (msg *MT940) unmarshalSequences(mtx MTx) error {
+	err := mt.UnmarshalMT(mtx.Body, msg)
+	if err != nil {
+		return err
+	}
+
+	if msg.StatementNumberSequenceNumber != "" {
+		err = msg.StatementNumber.UnmarshalMT(msg.StatementNumberSequenceNumber)
+		if err != nil {
+			return err
+		}
+	}
+
+	perLine, statement := msg.accountOwnerInformationPerLine()
+
+	for lineIdx, infoIdx := range perLine {
+		msg.StatementLines[lineIdx].Information = msg.AccountOwnerInformation[infoIdx]
+	}
+
+	if len(statement) == 0 {
+		msg.AccountOwnerInformation = nil
+		return nil
+	}
+
+	statementInformation := make([]string, len(statement))
+	for i, infoIdx := range statement {
+		statementInformation[i] = msg.AccountOwnerInformation[infoIdx]
+	}
+	msg.AccountOwnerInformation = statementInformation
+
+	return nil
+}
+
+// ValidateMT verifies the opening and closing balances, fields 60a and 62a, are either final balances, option F, or
+// intermediate ones, option M.
+func (msg MT940) ValidateMT() error {
+	for _, balance := range []struct {
+		tag     string
+		balance Balance
+	}{
+		{"60a", msg.OpeningBalance},
+		{"62a", msg.ClosingBalance},
+	} {
+		option := balance.balance.Option
+		if !balance.balance.Set || option == "F" || option == "M" {
+			continue
+		}
+
+		return fmt.Errorf("invalid option %q of field %s: expected F or M", option, balance.tag)
+	}
+
+	return nil
+}
+
+// mt940FieldOrder is the order of the fields in the body of an MT940 as prescribed by the specification.
+var mt940FieldOrder = []string{"20", "25", "28C", "60F", "60M", "61", "62F", "62M", "64", "65", "86"}
+
+// MarshalMT generates the body, block 4, of the message from its fields. The fields are written in the order prescribed
+// by the specification. The information of a statement line is written as field 86 directly after its field 61, the
+// information on the statement as a whole is written at the end.
+func (msg MT940) MarshalMT() (string, error) {
+	fields, err := mt.MarshalMT(msg)
+	if err != nil {
+		return "", fmt.Errorf("could not marshal MT%s message: %w", MessageTypeMT940, err)
+	}
+
+	sb := &strings.Builder{}
+
+	writeField := func(tag, value string) {
+		sb.WriteString(":" + tag + ":" + value + "\n")
+	}
+
+	sb.WriteString("{4:\n")
+
+	for _, tag := range mt940FieldOrder {
+		for i, value := range fields[tag] {
+			writeField(tag, value)
+
+			if tag == "61" && msg.StatementLines[i].Information != "" {
+				writeField("86", msg.StatementLines[i].Information)
+			}
+		}
+	}
+
+	sb.WriteString("-}")
+
+	return sb.String(), nil
+}
+
+// String returns a human readable, multi-line, summary of the statement, e.g. for logging or debugging purposes. Use
+// MarshalMT to get the body in the format of the specification.
+func (msg MT940) String() string {
+	sb := &strings.Builder{}
+
+	msg.writeSummary(sb)
+
+	writeSummaryLine(sb, "Reference", msg.Reference)
+	writeSummaryLine(sb, "Account", msg.AccountIdentification)
+	writeSummaryLine(sb, "Statement number", msg.StatementNumberSequenceNumber)
+
+	writeBalance := func(label, tag string, balance Balance) {
+		if balance.Set {
+			writeSummaryLine(sb, label, balance.RawString()+" ("+tag+")")
+		}
+	}
+
+	writeBalance("Opening balance", "60"+msg.OpeningBalance.Option, msg.OpeningBalance)
+
+	writeSummaryLine(sb, "Statement lines", strconv.Itoa(len(msg.StatementLines)))
+
+	writeBalance("Closing balance", "62"+msg.ClosingBalance.Option, msg.ClosingBalance)
+
+	writeSummaryLine(sb, "Available balance", msg.ClosingAvailableBalance.RawString())
+
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// scaledAmountValue returns the value of the amount scaled to the given number of decimals, which must be at least the
+// number of decimals of the amount.
+func scaledAmountValue(amount Amount, decimals int) int64 {
+	value := amount.Value
+	for d := amount.Decimals; d < decimals; d++ {
+		value *= 10
+	}
+
+	return value
+}
+
+// signedBalanceValue returns the value of the balance scaled to the given number of decimals, negative for debit
+// balances.
+func signedBalanceValue(balance Balance, decimals int) int64 {
+	value := scaledAmountValue(balance.Amount, decimals)
+	if balance.CreditDebit == Debit {
+		return -value
+	}
+
+	return value
+}
+
+// ComputeClosingBalance computes the closing balance by applying the statement lines to the opening balance, option F
+// or M. Credits and debit reversals are added, debits and credit reversals are subtracted. The computed balance has
+// the currency of the opening balance and the date of the parsed closing balance, or that of the opening balance when
+// the closing balance is absent. Its amount has the largest number of decimals among the amounts involved.
+func (msg MT940) ComputeClosingBalance() (Balance, error) {
+	opening := msg.OpeningBalance
+	if !opening.Set {
+		return Balance{}, fmt.Errorf("can not compute closing balance: no opening balance")
+	}
+
+	closing := msg.ClosingBalance
+	if closing.Set && closing.Currency != opening.Currency {
+		return Balance{}, fmt.Errorf(
+			"can not compute closing balance: opening balance currency %s differs from closing balance currency %s",
+			opening.Currency,
+			closing.Currency,
+		)
+	}
+
+	decimals := opening.Amount.Decimals
+	for _, line := range msg.StatementLines {
+		if line.Amount.Decimals > decimals {
+			decimals = line.Amount.Decimals
+		}
+	}
+
+	total := signedBalanceValue(opening, decimals)
+	for _, line := range msg.StatementLines {
+		switch line.FundsCode {
+		case FundsCodeDebit, FundsCodeCreditReversal:
+			total -= scaledAmountValue(line.Amount, decimals)
+		default:
+			total += scaledAmountValue(line.Amount, decimals)
+		}
+	}
+
+	computed := Balance{
+		Set:         true,
+		CreditDebit: Credit,
+		Date:        opening.Date,
+		Currency:    opening.Currency,
+	}
+	if closing.Set {
+		computed.Date = closing.Date
+	}
+	if total < 0 {
+		computed.CreditDebit = Debit
+		total = -total
+	}
+
+	computed.Amount = Amount{
+		Set:      true,
+		Value:    total,
+		Decimals: decimals,
+	}
+
+	var err error
+	computed.Amount.Raw, err = computed.Amount.MarshalMT()
+	if err != nil {
+		return Balance{}, fmt.Errorf("can not compute closing balance: %w", err)
+	}
+	computed.Raw, err = computed.MarshalMT()
+	if err != nil {
+		return Balance{}, fmt.Errorf("can not compute closing balance: %w", err)
+	}
+
+	return computed, nil
+}
+
+// ReconcilesClosing reports whether the closing balance, option F or M, equals the balance computed by
+// ComputeClosingBalance. Amounts are compared by value, regardless of their number of decimals, and a zero balance
+// equals a zero balance with either mark. Without a closing balance, or when it can not be computed, false is returned.
+func (msg MT940) ReconcilesClosing() bool {
+	closing := msg.ClosingBalance
+	if !closing.Set {
+		return false
+	}
+
+	computed, err := msg.ComputeClosingBalance()
+	if err != nil {
+		return false
+	}
+
+	decimals := computed.Amount.Decimals
+	if closing.Amount.Decimals > decimals {
+		decimals = closing.Amount.Decimals
+	}
+
+	return signedBalanceValue(computed, decimals) == signedBalanceValue(closing, decimals)
+}
+
+// LinesBetween returns the statement lines with a value date, see StatementLine.Date, within the given range, both from
+// and to being inclusive. As value dates have no time they are compared as midnight in the location the message was
+// parsed in, UTC unless the Location option was passed, so day boundaries follow that location. Lines without a value
+// date are left out. The lines are returned in their original order.
+func (msg MT940) LinesBetween(from, to time.Time) []StatementLine {
+	lines := make([]StatementLine, 0)
+
+	for _, line := range msg.StatementLines {
+		if !line.Date.Set || line.Date.Time.Before(from) || line.Date.Time.After(to) {
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// validateStrict validates the order of the dates in the statement. The closing balance, option F or M, may not be
+// dated before the opening balance and the value dates of the statement lines must fall within those of the balances.
+func (msg MT940) validateStrict() error {
+	opening, closing := msg.OpeningBalance, msg.ClosingBalance
+
+	openingDate := validate.DateField{Field: "OpeningBalance", Label: "60" + opening.Option, Time: opening.Date.Time}
+	closingDate := validate.DateField{Field: "ClosingBalance", Label: "62" + closing.Option, Time: closing.Date.Time}
+
+	errs := []validate.ValidationError{validate.NotBefore(closingDate, openingDate)}
+
+	for i, line := range msg.StatementLines {
+		lineDate := validate.DateField{
+			Field: "StatementLines[" + strconv.Itoa(i) + "]",
+			Label: "61",
+			Time:  line.Date.Time,
+		}
+
+		errs = append(errs, validate.NotBefore(lineDate, openingDate), validate.NotAfter(lineDate, closingDate))
+	}
+
+	return validate.Join(errs...)
 }
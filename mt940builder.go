@@ -0,0 +1,150 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt
+
+import "fmt"
+
+// MT940Builder constructs MT940 messages in code, e.g. to generate test data or synthetic statements. The fields are
+// set from their values in MT format, the same values MarshalMT writes, and each value is parsed as it is set. The
+// first invalid value is remembered and returned by Build, any further calls are ignored.
+//
+// Example:
+//
+//	msg, err := NewMT940Builder().
+//		Reference("REFERENCE").
+//		Account("BPHKPLPK/320000546101").
+//		StatementNumber("00084/001").
+//		OpeningBalance("C031002PLN40000,00").
+//		AddLine("0310201020C20000,00FMSCNONREF//8327000090031789", "Card transaction").
+//		ClosingBalance("C031020PLN60000,00").
+//		Build()
+type MT940Builder struct {
+	msg MT940
+	err error
+}
+
+// NewMT940Builder creates a builder for an MT940 message without any fields set.
+func NewMT940Builder() *MT940Builder {
+	return &MT940Builder{}
+}
+
+// fail remembers the error for the field with the given tag, unless an earlier error was remembered already.
+func (b *MT940Builder) fail(tag string, err error) {
+	if b.err == nil {
+		b.err = fmt.Errorf("invalid field %s: %w", tag, err)
+	}
+}
+
+// balance parses the value of the balance field with the given tag, recording its option letter if any.
+func (b *MT940Builder) balance(tag, value string) Balance {
+	var balance Balance
+
+	err := balance.UnmarshalMTTag(tag, value)
+	if err != nil {
+		b.fail(tag, err)
+	}
+
+	return balance
+}
+
+// Reference sets the transaction reference number, field 20.
+func (b *MT940Builder) Reference(reference string) *MT940Builder {
+	b.msg.Reference = reference
+	return b
+}
+
+// Account sets the account identification, field 25.
+func (b *MT940Builder) Account(account string) *MT940Builder {
+	b.msg.AccountIdentification = account
+	return b
+}
+
+// StatementNumber sets the statement number and optional sequence number, field 28C, e.g. 00084/001.
+func (b *MT940Builder) StatementNumber(number string) *MT940Builder {
+	b.msg.StatementNumberSequenceNumber = number
+
+	err := b.msg.StatementNumber.UnmarshalMT(number)
+	if err != nil {
+		b.fail("28C", err)
+	}
+
+	return b
+}
+
+// OpeningBalance sets the opening balance, field 60F, e.g. C031002PLN40000,00. It replaces the intermediate opening
+// balance, field 60M, if set.
+func (b *MT940Builder) OpeningBalance(value string) *MT940Builder {
+	b.msg.OpeningBalance = b.balance("60F", value)
+	return b
+}
+
+// IntermediateOpeningBalance sets the intermediate opening balance, field 60M, of a statement spanning multiple
+// messages. It replaces the opening balance, field 60F, if set.
+func (b *MT940Builder) IntermediateOpeningBalance(value string) *MT940Builder {
+	b.msg.OpeningBalance = b.balance("60M", value)
+	return b
+}
+
+// AddLine adds a statement line, field 61, e.g. 0310201020C20000,00FMSCNONREF//8327000090031789. The information on
+// the line is written as the field 86 directly following it, it is left out when empty.
+func (b *MT940Builder) AddLine(value, information string) *MT940Builder {
+	var line StatementLine
+
+	err := line.UnmarshalMT(value)
+	if err != nil {
+		b.fail(fmt.Sprintf("61[%d]", len(b.msg.StatementLines)), err)
+	}
+	line.Information = information
+
+	b.msg.StatementLines = append(b.msg.StatementLines, line)
+
+	return b
+}
+
+// ClosingBalance sets the closing balance, field 62F, e.g. C031020PLN60000,00. It replaces the intermediate closing
+// balance, field 62M, if set.
+func (b *MT940Builder) ClosingBalance(value string) *MT940Builder {
+	b.msg.ClosingBalance = b.balance("62F", value)
+	return b
+}
+
+// IntermediateClosingBalance sets the intermediate closing balance, field 62M, of a statement spanning multiple
+// messages. It replaces the closing balance, field 62F, if set.
+func (b *MT940Builder) IntermediateClosingBalance(value string) *MT940Builder {
+	b.msg.ClosingBalance = b.balance("62M", value)
+	return b
+}
+
+// ClosingAvailableBalance sets the closing available balance, field 64.
+func (b *MT940Builder) ClosingAvailableBalance(value string) *MT940Builder {
+	b.msg.ClosingAvailableBalance = b.balance("64", value)
+	return b
+}
+
+// AddForwardAvailableBalance adds a forward available balance, field 65.
+func (b *MT940Builder) AddForwardAvailableBalance(value string) *MT940Builder {
+	b.msg.ForwardAvailableBalances = append(b.msg.ForwardAvailableBalances, b.balance("65", value))
+	return b
+}
+
+// AddInformation adds information on the statement as a whole, field 86, written at the end of the body.
+func (b *MT940Builder) AddInformation(information string) *MT940Builder {
+	b.msg.AccountOwnerInformation = append(b.msg.AccountOwnerInformation, information)
+	return b
+}
+
+// Build returns the message, validated the same way parsed messages are. The headers, i.e. the Base, are left empty.
+// An error is returned for the first invalid value set, or if the message fails validation, e.g. because a mandatory
+// field is missing. In the latter case the message is returned together with the error.
+func (b *MT940Builder) Build() (MT940, error) {
+	if b.err != nil {
+		return MT940{}, fmt.Errorf("could not build MT%s message: %w", MessageTypeMT940, b.err)
+	}
+
+	msg := b.msg.Clone()
+
+	return msg, validateMT940(msg, mt940Validator)
+}
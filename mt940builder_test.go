@@ -0,0 +1,102 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DennisVis/mt"
+	mttest "github.com/DennisVis/mt/testdata"
+)
+
+func TestMT940Builder(t *testing.T) {
+	complete := func() *mt.MT940Builder {
+		return mt.NewMT940Builder().
+			Reference("REFERENCE").
+			Account("BPHKPLPK/320000546101").
+			StatementNumber("00084/001").
+			OpeningBalance("C031002PLN40000,00").
+			AddLine("0310201020C20000,00FMSCNONREF//8327000090031789", "Card transaction").
+			AddLine("031020D10000,FTRFREF 25611247", "").
+			ClosingBalance("C031020PLN50000,00").
+			ClosingAvailableBalance("C031020PLN50000,00").
+			AddForwardAvailableBalance("C031021PLN50000,00").
+			AddInformation("STATEMENT")
+	}
+
+	for _, test := range []struct {
+		name        string
+		builder     *mt.MT940Builder
+		expectedErr error
+	}{
+		{
+			name:    "Complete",
+			builder: complete(),
+		},
+		{
+			name: "IntermediateBalances",
+			builder: complete().
+				IntermediateOpeningBalance("C031002PLN40000,00").
+				IntermediateClosingBalance("C031020PLN50000,00"),
+		},
+		{
+			name: "Incomplete",
+			builder: mt.NewMT940Builder().
+				Reference("REFERENCE").
+				OpeningBalance("C031002PLN40000,00"),
+			expectedErr: fmt.Errorf("validation failed for MT940 message"),
+		},
+		{
+			name:        "InvalidBalance",
+			builder:     complete().ClosingBalance("C0310"),
+			expectedErr: fmt.Errorf("could not build MT940 message: invalid field 62F: balance: invalid input length: 5"),
+		},
+		{
+			name:        "InvalidLine",
+			builder:     complete().AddLine("031020X10000,FTRFREF", ""),
+			expectedErr: fmt.Errorf("could not build MT940 message: invalid field 61[2]"),
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			msg, err := test.builder.Build()
+			mttest.ValidateError(t, test.expectedErr, err)
+
+			if test.expectedErr != nil {
+				return
+			}
+
+			body, err := msg.MarshalMT()
+			mttest.ValidateError(t, nil, err)
+
+			input := "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}" + body
+
+			reparsed, err := mt.ParseAllMT940(ctx, strings.NewReader(input))
+			mttest.ValidateError(t, nil, err)
+
+			if len(reparsed) != 1 {
+				t.Fatalf("expected 1 message after round trip, got %d", len(reparsed))
+			}
+
+			bodyAgain, err := reparsed[0].MarshalMT()
+			mttest.ValidateError(t, nil, err)
+
+			if bodyAgain != body {
+				t.Errorf("expected round trip to be stable, got:\n%s\nexpected:\n%s", bodyAgain, body)
+			}
+
+			if len(reparsed[0].StatementLines) != 2 || reparsed[0].StatementLines[0].Information != "Card transaction" {
+				t.Errorf("expected the statement lines to survive the round trip, got %v", reparsed[0].StatementLines)
+			}
+		})
+	}
+}
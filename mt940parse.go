@@ -8,74 +8,128 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
+	"sync"
 
-	"github.com/DennisVis/mt/internal/encoding/mt"
 	"github.com/DennisVis/mt/internal/validate"
 )
 
 const MessageTypeMT940 = "940"
 
+// mt940Validator validates the fields of MT940 messages, it is shared by all parses and never modified once created.
 var mt940Validator = validate.MustCreateValidatorForStruct(MT940{})
 
 func MTxToMT940(mtx MTx) (MT940, error) {
+	mt940, err := mtxToMT940(mtx)
+	if err != nil {
+		return mt940, err
+	}
+
+	return mt940, validateMT940(mt940, mt940Validator)
+}
+
+func mtxToMT940(mtx MTx) (MT940, error) {
 	mt940 := MT940{}
 
+	mtx.LoadBody()
+
 	if mtx.Type() != MessageTypeMT940 {
-		return mt940, fmt.Errorf("expected message type %s, got %s", MessageTypeMT940, mtx.Type())
+		return mt940, ErrWrongMessageType{Expected: MessageTypeMT940, Actual: mtx.Type()}
 	}
 
 	mt940.Base = mtx.Base
 
-	err := mt.UnmarshalMT(mtx.Body, &mt940)
+	err := unmarshalBody(mtx, &mt940)
 	if err != nil {
 		return mt940, fmt.Errorf("could not unmarshal MT%s message: %w", MessageTypeMT940, err)
 	}
 
-	err = mt940Validator.Validate(mt940)
-	if err != nil {
-		return mt940, fmt.Errorf("validation failed for MT%s message:\n%s", MessageTypeMT940, err)
-	}
-
 	return mt940, nil
 }
 
 func ValidateMT940(mt940 MT940) error {
-	err := mt940Validator.Validate(mt940)
+	return validateMT940(mt940, mt940Validator)
+}
+
+func validateMT940(mt940 MT940, validator validate.Validator) error {
+	_, err := validateRelaxedMT940(mt940, validator, nil)
+	return err
+}
+
+// validateRelaxedMT940 validates the message like validateMT940 does, except for the pattern validation failures of
+// the fields with the relaxed tags. Those are returned separately, to be reported as warnings.
+func validateRelaxedMT940(mt940 MT940, validator validate.Validator, relaxedTags []string) ([]error, error) {
+	err, relaxed := validate.Relax(validator.Validate(mt940), relaxedTags)
+
+	warnings := make([]error, len(relaxed))
+	for i, r := range relaxed {
+		warnings[i] = fmt.Errorf("relaxed validation failure for MT%s message:\n%w", MessageTypeMT940, r)
+	}
+
 	if err != nil {
-		return fmt.Errorf("validation failed for MT%s message:\n%w", MessageTypeMT940, err)
+		return warnings, newValidationError(MessageTypeMT940, err)
+	}
+
+	sequenceErr := validateSequences(mt940)
+	if sequenceErr != nil {
+		return warnings, fmt.Errorf("validation failed for MT%s message:\n%w", MessageTypeMT940, sequenceErr)
 	}
 
-	return nil
+	return warnings, nil
 }
 
-func parseAndValidateMT940(mtx MTx, skipValidation, lax bool) (MT940, error) {
-	mt940, err := MTxToMT940(mtx)
-	if err != nil || skipValidation {
-		return mt940, err
+func parseAndValidateMT940(mtx MTx, cfg config) (MT940, []error, error) {
+	validator, err := validatorForRelease(MessageTypeMT940, cfg.StandardsRelease, mt940Validator)
+	if err != nil {
+		return MT940{Base: mtx.Base}, nil, err
 	}
 
-	err = ValidateMT940(mt940)
-	if err != nil && !lax {
-		return mt940, err
+	mt940, err := mtxToMT940(mtx)
+	cfg.localize(&mt940)
+	cfg.acceptStatementPageNumber(&mt940)
+	if err != nil || cfg.SkipValidation {
+		return mt940, nil, err
 	}
 
-	return mt940, nil
+	warnings, err := validateRelaxedMT940(mt940, validator, cfg.RelaxedFields)
+	if err != nil || !cfg.Strict {
+		return mt940, warnings, err
+	}
+
+	err = validateStrict(mt940)
+	if err != nil {
+		return mt940, warnings, fmt.Errorf("strict validation failed for MT%s message:\n%w", MessageTypeMT940, err)
+	}
+
+	return mt940, warnings, nil
 }
 
 // ParseMT940 parses and validates MTx messages from ParseMTx into MT940 messages.
-// Invalid messages are discarded unless the option Lax is passed.
+// Invalid messages are discarded unless the option Lax is passed. The errors are tied to the messages like they are by
+// ParseAllMT940. Both channels are closed once all messages are parsed, they are to be read from concurrently.
 func ParseMT940(ctx context.Context, rd io.Reader, options ...option) (chan MT940, chan Error) {
 	cfg := optionsToConfig(options)
 
 	genericMessages, parseErrors := ParseMTx(ctx, rd, options...)
 
+	wg := &sync.WaitGroup{}
 	mt940Ch := make(chan MT940)
+	errCh := make(chan Error)
 
+	wg.Add(1)
 	go func() {
+		defer wg.Done()
+
+		index := -1
 		for mtx := range genericMessages {
-			mt940, err := parseAndValidateMT940(mtx, cfg.SkipValidation, cfg.Lax)
+			index++
+
+			mt940, warnings, err := parseAndValidateMT940(mtx, cfg)
+			for _, warning := range warnings {
+				errCh <- NewWarning(warning, mtx.Line).forMessage(index)
+			}
 			if err != nil {
-				parseErrors <- NewError(err, mtx.Line)
+				errCh <- NewError(err, mtx.Line).forMessage(index)
 
 				if !cfg.Lax {
 					continue
@@ -86,11 +140,31 @@ func ParseMT940(ctx context.Context, rd io.Reader, options ...option) (chan MT94
 		}
 	}()
 
-	return mt940Ch, parseErrors
+	// the errors of ParseMTx are forwarded, its channel is closed once it's done while validation might still report
+	// errors of the last messages
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		for err := range parseErrors {
+			errCh <- err
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(mt940Ch)
+		close(errCh)
+	}()
+
+	return mt940Ch, errCh
 }
 
 // ParseAllMT940 parses and validates MTx messages from ParseAllMTx into MT940 messages.
 // Invalid messages are discarded unless the option Lax is passed.
+//
+// The errors encountered while validating a message are tied to it by its index among the messages from ParseAllMTx,
+// see Errors.ByMessage. Validation failures are of type ValidationError, holding the failure of each field separately.
 func ParseAllMT940(ctx context.Context, rd io.Reader, options ...option) ([]MT940, error) {
 	cfg := optionsToConfig(options)
 
@@ -98,15 +172,15 @@ func ParseAllMT940(ctx context.Context, rd io.Reader, options ...option) ([]MT94
 
 	mt940s := make([]MT940, 0)
 
-	var parseErrors Errors
-	if pes != nil {
-		parseErrors = pes.(Errors)
-	}
+	parseErrors := errorToErrors(pes)
 
-	for _, mtx := range genericMessages {
-		mt940, err := parseAndValidateMT940(mtx, cfg.SkipValidation, cfg.Lax)
+	for i, mtx := range genericMessages {
+		mt940, warnings, err := parseAndValidateMT940(mtx, cfg)
+		for _, warning := range warnings {
+			parseErrors = append(parseErrors, NewWarning(warning, mtx.Line).forMessage(i))
+		}
 		if err != nil {
-			parseErrors = append(parseErrors, NewError(err, mtx.Line))
+			parseErrors = append(parseErrors, NewError(err, mtx.Line).forMessage(i))
 
 			if !cfg.Lax {
 				continue
@@ -116,5 +190,37 @@ func ParseAllMT940(ctx context.Context, rd io.Reader, options ...option) ([]MT94
 		mt940s = append(mt940s, mt940)
 	}
 
-	return mt940s, parseErrors
+	if len(parseErrors) > 0 {
+		return mt940s, parseErrors
+	}
+
+	return mt940s, nil
+}
+
+// ParseMT940String parses and validates the single MT940 message held by s, see ParseOneMTx. It is meant for the case
+// where the type of the message is known beforehand, an ErrWrongMessageType is returned if it is of another type.
+// An invalid message is returned together with the error. Warnings, e.g. those of the option RelaxFields, are returned
+// as Errors for an otherwise valid message.
+func ParseMT940String(ctx context.Context, s string, options ...option) (MT940, error) {
+	cfg := optionsToConfig(options)
+
+	mtx, err := ParseOneMTx(ctx, strings.NewReader(s), options...)
+	parseErrors := errorToErrors(err)
+	if len(parseErrors.WithoutWarnings()) > 0 {
+		return MT940{Base: mtx.Base}, err
+	}
+
+	mt940, warnings, err := parseAndValidateMT940(mtx, cfg)
+	if err != nil {
+		return mt940, err
+	}
+
+	for _, warning := range warnings {
+		parseErrors = append(parseErrors, NewWarning(warning, mtx.Line))
+	}
+	if len(parseErrors) > 0 {
+		return mt940, parseErrors
+	}
+
+	return mt940, nil
 }
@@ -7,9 +7,14 @@ package mt_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DennisVis/mt"
 	mttest "github.com/DennisVis/mt/testdata"
@@ -93,6 +98,7 @@ func TestParseMT940(t *testing.T) {
 	for _, test := range []struct {
 		name                string
 		input               io.Reader
+		lax                 bool
 		expectedParseErrors mt.Errors
 		expectedMT940s      TestMT940s
 	}{
@@ -104,6 +110,14 @@ func TestParseMT940(t *testing.T) {
 		{
 			name:  "SampleFile",
 			input: mttest.MustOpenFile("testdata/sample-file-mt940.txt"),
+			// the sample file does not fully adhere to the specification, e.g. field 86 spans more than 6 lines and a
+			// references exceed 16 characters, therefore it's parsed leniently and the validation errors are expected
+			lax: true,
+			expectedParseErrors: []mt.Error{
+				mt.NewError(fmt.Errorf("validation failed for MT940 message"), 1),
+				mt.NewError(fmt.Errorf("validation failed for MT940 message"), 28),
+				mt.NewError(fmt.Errorf("validation failed for MT940 message"), 60),
+			},
 			expectedMT940s: TestMT940s{
 				{
 					AppHeaderInput: mt.AppHeaderInput{
@@ -117,7 +131,27 @@ func TestParseMT940(t *testing.T) {
 								Raw: "031002",
 							},
 							Currency: "PLN",
-							Amount:   40000.00,
+							Amount:   mttest.MustParseAmount("40000,00"),
+						},
+						StatementLines: []mt.StatementLine{
+							{
+								FundsCode:             mt.FundsCodeCredit,
+								Amount:                mttest.MustParseAmount("20000,00"),
+								AccountOwnerReference: "NONREF",
+								Description:           "Card transaction",
+							},
+							{
+								FundsCode:             mt.FundsCodeDebit,
+								Amount:                mttest.MustParseAmount("10000,00"),
+								AccountOwnerReference: "REF 25611247",
+								Description:           "Transfer",
+							},
+							{
+								FundsCode:             mt.FundsCodeCredit,
+								Amount:                mttest.MustParseAmount("40,00"),
+								AccountOwnerReference: "NONREF",
+								Description:           "Interest credit",
+							},
 						},
 					},
 				},
@@ -130,9 +164,922 @@ func TestParseMT940(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
 
-			msgs, err := mt.ParseAllMT940(ctx, test.input)
+			msgs, err := mt.ParseAllMT940(ctx, test.input, mt.Lax(test.lax))
 			mttest.ValidateErrors(t, test.expectedParseErrors, err)
 			validateMT940s(t, test.expectedMT940s.toMT940(), msgs)
 		})
 	}
 }
+
+func TestMT940PresentFields(t *testing.T) {
+	t.Parallel()
+
+	input := strings.NewReader(`{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:
+:20:REFERENCE
+:25:BPHKPLPK/320000546101
+:28C:00084/001
+:60F:C031002PLN40000,00
+:62F:C020325PLN40000,00
+-}`)
+
+	msgs, err := mt.ParseAllMT940(ctx, input, mt.Lax(true))
+	mttest.ValidateError(t, nil, err)
+
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+
+	for _, tag := range []string{"20", "25", "28C", "60F"} {
+		if !msgs[0].IsPresent(tag) {
+			t.Errorf("expected field %s to be present", tag)
+		}
+	}
+	for _, tag := range []string{"61", "86"} {
+		if msgs[0].IsPresent(tag) {
+			t.Errorf("expected optional field %s to be absent", tag)
+		}
+	}
+	if len(msgs[0].AccountOwnerInformation) != 0 {
+		t.Errorf("expected no account owner information, got %v", msgs[0].AccountOwnerInformation)
+	}
+}
+
+func TestMT940StatementLineErrorIndex(t *testing.T) {
+	t.Parallel()
+
+	input := strings.NewReader(`{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:
+:20:REFERENCE
+:25:BPHKPLPK/320000546101
+:28C:00084/001
+:60F:C031002PLN40000,00
+:61:0310201020C20000,00FMSCNONREF//8327000090031789
+:61:0310201020D10000,00FTRFREF 25611247//8327000090031790
+:61:0X10201020C40,00FTRFNONREF//8327000090031791
+:62F:C020325PLN50040,00
+-}`)
+
+	_, err := mt.ParseAllMT940(ctx, input)
+	mttest.ValidateError(t, fmt.Errorf("decoding failed for StatementLines[2]: decoding failed: decoding failed: statement line: invalid date"), err)
+}
+
+func mt940ToRaw(t *testing.T, msg mt.MT940) string {
+	body, err := msg.MarshalMT()
+	if err != nil {
+		t.Fatalf("expected no error marshaling message, got: %v", err)
+	}
+
+	raw := msg.BasicHeader.Raw
+	if msg.IsInput() {
+		raw += msg.AppHeaderInput.Raw
+	} else {
+		raw += msg.AppHeaderOutput.Raw
+	}
+	usrHeader, err := msg.UsrHeader.MarshalMT()
+	if err != nil {
+		t.Fatalf("expected no error marshaling user header, got: %v", err)
+	}
+	trailers, err := msg.Trailers.MarshalMT()
+	if err != nil {
+		t.Fatalf("expected no error marshaling trailers, got: %v", err)
+	}
+
+	raw += usrHeader + body + trailers
+
+	return raw
+}
+
+const mt940MarshalInput = `{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:
+:20:REFERENCE
+:25:BPHKPLPK/320000546101
+:28C:00084/001
+:60F:C031002PLN40000,00
+:61:0310201020C20000,00FMSCNONREF//8327000090031789
+Card transaction
+:86:LINE 1
+:61:031020D10000,FTRFREF 25611247
+:62F:C031020PLN50000,00
+:64:C031020PLN50000,00
+:65:C031021PLN50000,00
+:65:C031022PLN50000,00
+:86:STATEMENT
+-}`
+
+func TestMT940MarshalMTRoundTrip(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		input func() io.Reader
+	}{
+		{
+			name:  "Statement",
+			input: func() io.Reader { return strings.NewReader(mt940MarshalInput) },
+		},
+		{
+			name:  "SampleFile",
+			input: func() io.Reader { return mttest.MustOpenFile("testdata/sample-file-mt940.txt") },
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			msgs, _ := mt.ParseAllMT940(ctx, test.input(), mt.Lax(true))
+			if len(msgs) == 0 {
+				t.Fatalf("expected messages to be parsed")
+			}
+
+			for i, msg := range msgs {
+				raw := mt940ToRaw(t, msg)
+
+				reparsed, _ := mt.ParseAllMT940(ctx, strings.NewReader(raw), mt.Lax(true))
+				if len(reparsed) != 1 {
+					t.Fatalf("MT940[%d]: expected 1 message after round trip, got %d", i, len(reparsed))
+				}
+
+				if rawAgain := mt940ToRaw(t, reparsed[0]); rawAgain != raw {
+					t.Errorf("MT940[%d]: expected round trip to be stable, got:\n%s\nexpected:\n%s", i, rawAgain, raw)
+				}
+
+				mttest.ValidateStringSlice(
+					t,
+					"AccountOwnerInformation",
+					msg.AccountOwnerInformation,
+					reparsed[0].AccountOwnerInformation,
+				)
+				mttest.ValidateStringSlice(t, "FieldOrder", msg.FieldOrder, reparsed[0].FieldOrder)
+			}
+		})
+	}
+}
+
+func TestMT940MarshalMTFieldOrder(t *testing.T) {
+	t.Parallel()
+
+	msgs, err := mt.ParseAllMT940(ctx, strings.NewReader(mt940MarshalInput))
+	mttest.ValidateError(t, nil, err)
+
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+
+	expected := `{4:
+:20:REFERENCE
+:25:BPHKPLPK/320000546101
+:28C:00084/001
+:60F:C031002PLN40000,00
+:61:0310201020C20000,00FMSCNONREF//8327000090031789
+Card transaction
+:86:LINE 1
+:61:031020D10000,FTRFREF 25611247
+:62F:C031020PLN50000,00
+:64:C031020PLN50000,00
+:65:C031021PLN50000,00
+:65:C031022PLN50000,00
+:86:STATEMENT
+-}`
+
+	body, err := msgs[0].MarshalMT()
+	mttest.ValidateError(t, nil, err)
+
+	if body != expected {
+		t.Errorf("expected body:\n%s\ngot:\n%s", expected, body)
+	}
+}
+
+func TestMT940IntermediateBalances(t *testing.T) {
+	const header = `{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:
+:20:REFERENCE
+:25:BPHKPLPK/320000546101
+:28C:00084/002
+`
+
+	for _, test := range []struct {
+		name        string
+		balances    string
+		expectedErr error
+	}{
+		{
+			name:     "Intermediate",
+			balances: ":60M:C031002PLN40000,00\n:62M:C031020PLN50000,00\n",
+		},
+		{
+			name:     "IntermediateOpeningFinalClosing",
+			balances: ":60M:C031002PLN40000,00\n:62F:C031020PLN50000,00\n",
+		},
+		{
+			name:        "BothOpening",
+			balances:    ":60F:C031002PLN40000,00\n:60M:C031002PLN40000,00\n:62F:C031020PLN50000,00\n",
+			expectedErr: fmt.Errorf("multiple options present for tag 60a: 60F, 60M"),
+		},
+		{
+			name:        "UnknownOption",
+			balances:    ":60F:C031002PLN40000,00\n:62X:C031020PLN50000,00\n",
+			expectedErr: fmt.Errorf(`invalid option "X" of field 62a: expected F or M`),
+		},
+		{
+			name:        "NoClosing",
+			balances:    ":60F:C031002PLN40000,00\n",
+			expectedErr: fmt.Errorf("validation failed for MT940 message"),
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			input := header + test.balances + "-}"
+
+			msgs, err := mt.ParseAllMT940(ctx, strings.NewReader(input))
+			mttest.ValidateError(t, test.expectedErr, err)
+
+			if test.expectedErr != nil {
+				return
+			}
+
+			if len(msgs) != 1 {
+				t.Fatalf("expected 1 message, got %d", len(msgs))
+			}
+
+			body, err := msgs[0].MarshalMT()
+			mttest.ValidateError(t, nil, err)
+
+			expected := "{4:\n:20:REFERENCE\n:25:BPHKPLPK/320000546101\n:28C:00084/002\n" + test.balances + "-}"
+			if body != expected {
+				t.Errorf("expected body:\n%s\ngot:\n%s", expected, body)
+			}
+		})
+	}
+}
+
+func TestMT940String(t *testing.T) {
+	t.Parallel()
+
+	input := strings.NewReader(`{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:
+:20:REFERENCE
+:25:BPHKPLPK/320000546101
+:28C:00084/002
+:60M:C031002PLN40000,00
+:61:0310201020C20000,00FMSCNONREF//8327000090031789
+:61:031020D10000,FTRFREF 25611247
+:62F:C031020PLN50000,00
+:64:C031020PLN50000,00
+-}`)
+
+	msgs, err := mt.ParseAllMT940(ctx, input)
+	mttest.ValidateError(t, nil, err)
+
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+
+	expected := `MT940 input message
+  Sender:            BPHKPLPKXXXX
+  Receiver:          BOFAUS6BXBAM
+  Priority:          N
+  Reference:         REFERENCE
+  Account:           BPHKPLPK/320000546101
+  Statement number:  00084/002
+  Opening balance:   C031002PLN40000,00 (60M)
+  Statement lines:   2
+  Closing balance:   C031020PLN50000,00 (62F)
+  Available balance: C031020PLN50000,00`
+
+	if str := msgs[0].String(); str != expected {
+		t.Errorf("expected string:\n%s\ngot:\n%s", expected, str)
+	}
+}
+
+func TestMT940ComputeClosingBalance(t *testing.T) {
+	const header = `{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:
+:20:REFERENCE
+:25:BPHKPLPK/320000546101
+:28C:00084/001
+`
+
+	for _, test := range []struct {
+		name              string
+		fields            string
+		expectedErr       error
+		expectedBalance   string
+		expectedReconcile bool
+	}{
+		{
+			name: "Reconciles",
+			fields: ":60F:C031002PLN40000,00\n" +
+				":61:0310201020C20000,50FMSCNONREF\n" +
+				":61:031020D10000,FTRFREF 25611247\n" +
+				":62F:C031020PLN50000,50\n",
+			expectedBalance:   "C031020PLN50000,50",
+			expectedReconcile: true,
+		},
+		{
+			name: "Reversals",
+			fields: ":60M:C031002PLN100,00\n" +
+				":61:0310201020RC30,FMSCNONREF\n" +
+				":61:0310201020RD5,25FMSCNONREF\n" +
+				":62M:C031020PLN75,25\n",
+			expectedBalance:   "C031020PLN75,25",
+			expectedReconcile: true,
+		},
+		{
+			name: "DebitResult",
+			fields: ":60F:C031002PLN100,00\n" +
+				":61:031020D150,FMSCNONREF\n" +
+				":62F:D031020PLN50,\n",
+			expectedBalance:   "D031020PLN50,00",
+			expectedReconcile: true,
+		},
+		{
+			name: "DebitOpening",
+			fields: ":60F:D031002PLN100,00\n" +
+				":61:031020C40,FMSCNONREF\n" +
+				":62F:D031020PLN60,00\n",
+			expectedBalance:   "D031020PLN60,00",
+			expectedReconcile: true,
+		},
+		{
+			name: "DoesNotReconcile",
+			fields: ":60F:C031002PLN40000,00\n" +
+				":61:031020D10000,FTRFREF 25611247\n" +
+				":62F:C031020PLN40000,00\n",
+			expectedBalance:   "C031020PLN30000,00",
+			expectedReconcile: false,
+		},
+		{
+			name: "CurrencyMismatch",
+			fields: ":60F:C031002PLN40000,00\n" +
+				":62F:C031020EUR40000,00\n",
+			expectedErr: fmt.Errorf("opening balance currency PLN differs from closing balance currency EUR"),
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			msgs, err := mt.ParseAllMT940(ctx, strings.NewReader(header+test.fields+"-}"))
+			mttest.ValidateError(t, nil, err)
+
+			if len(msgs) != 1 {
+				t.Fatalf("expected 1 message, got %d", len(msgs))
+			}
+
+			balance, err := msgs[0].ComputeClosingBalance()
+			mttest.ValidateError(t, test.expectedErr, err)
+
+			if balance.RawString() != test.expectedBalance {
+				t.Errorf("expected computed balance %q, got %q", test.expectedBalance, balance.RawString())
+			}
+
+			if reconciles := msgs[0].ReconcilesClosing(); reconciles != test.expectedReconcile {
+				t.Errorf("expected ReconcilesClosing to return %t, got %t", test.expectedReconcile, reconciles)
+			}
+		})
+	}
+}
+
+func TestMT940ComputeClosingBalanceNoOpening(t *testing.T) {
+	t.Parallel()
+
+	_, err := mt.MT940{}.ComputeClosingBalance()
+	mttest.ValidateError(t, fmt.Errorf("can not compute closing balance: no opening balance"), err)
+
+	if (mt.MT940{}).ReconcilesClosing() {
+		t.Errorf("expected ReconcilesClosing to return false without balances")
+	}
+}
+
+func TestMT940StatementLineInformation(t *testing.T) {
+	t.Parallel()
+
+	input := `{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:
+:20:REFERENCE
+:25:BPHKPLPK/320000546101
+:28C:00084/001
+:60F:C031002PLN40000,00
+:61:0310201020C20000,00FMSCNONREF//8327000090031789
+:86:INFORMATION FIRST LINE
+:61:0310201020D10000,00FTRFREF 25611247//8327000090031790
+:61:0310201020C40,00FMSCNONREF
+:86:INFORMATION THIRD LINE
+:86:STATEMENT INFORMATION 1
+:62F:C020325PLN50040,00
+:86:STATEMENT INFORMATION 2
+-}`
+
+	msgs, err := mt.ParseAllMT940(ctx, strings.NewReader(input))
+	mttest.ValidateError(t, nil, err)
+
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+
+	msg := msgs[0]
+
+	expectedInformation := []string{"INFORMATION FIRST LINE", "", "INFORMATION THIRD LINE"}
+	if len(msg.StatementLines) != len(expectedInformation) {
+		t.Fatalf("expected %d statement lines, got %d", len(expectedInformation), len(msg.StatementLines))
+	}
+	for i, expected := range expectedInformation {
+		if msg.StatementLines[i].Information != expected {
+			t.Errorf("StatementLines[%d].Information expected %q, got %q", i, expected, msg.StatementLines[i].Information)
+		}
+	}
+
+	expectedStatement := []string{"STATEMENT INFORMATION 1", "STATEMENT INFORMATION 2"}
+	if len(msg.AccountOwnerInformation) != len(expectedStatement) {
+		t.Fatalf(
+			"expected %d account owner information, got %d: %v",
+			len(expectedStatement),
+			len(msg.AccountOwnerInformation),
+			msg.AccountOwnerInformation,
+		)
+	}
+	mttest.ValidateStringSlice(t, "AccountOwnerInformation", expectedStatement, msg.AccountOwnerInformation)
+
+	body, err := msg.MarshalMT()
+	mttest.ValidateError(t, nil, err)
+
+	// the information of the statement lines stays in place, that of the statement as a whole moves to the end
+	expectedBody := `{4:
+:20:REFERENCE
+:25:BPHKPLPK/320000546101
+:28C:00084/001
+:60F:C031002PLN40000,00
+:61:0310201020C20000,00FMSCNONREF//8327000090031789
+:86:INFORMATION FIRST LINE
+:61:0310201020D10000,00FTRFREF 25611247//8327000090031790
+:61:0310201020C40,00FMSCNONREF
+:86:INFORMATION THIRD LINE
+:62F:C020325PLN50040,00
+:86:STATEMENT INFORMATION 1
+:86:STATEMENT INFORMATION 2
+-}`
+	if body != expectedBody {
+		t.Errorf("expected body:\n%s\ngot:\n%s", expectedBody, body)
+	}
+}
+
+func TestParseMT940String(t *testing.T) {
+	const valid = `{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:
+:20:REFERENCE
+:25:BPHKPLPK/320000546101
+:28C:00084/002
+:60F:C031002PLN40000,00
+:62F:C031020PLN40000,00
+-}`
+
+	for _, test := range []struct {
+		name              string
+		input             string
+		expectedErr       error
+		expectedReference string
+	}{
+		{
+			name:              "Valid",
+			input:             valid,
+			expectedReference: "REFERENCE",
+		},
+		{
+			name:        "Empty",
+			input:       "",
+			expectedErr: fmt.Errorf("expected exactly one message, found none"),
+		},
+		{
+			name:        "MoreThanOne",
+			input:       valid + "\n" + valid,
+			expectedErr: fmt.Errorf("expected exactly one message, found more"),
+		},
+		{
+			name:        "WrongType",
+			input:       strings.Replace(valid, "I940", "I942", 1),
+			expectedErr: mt.ErrWrongMessageType{Expected: "940", Actual: "942"},
+		},
+		{
+			name:        "Invalid",
+			input:       strings.Replace(valid, ":20:REFERENCE\n", "", 1),
+			expectedErr: fmt.Errorf("validation failed for MT940 message"),
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			msg, err := mt.ParseMT940String(ctx, test.input)
+			mttest.ValidateError(t, test.expectedErr, err)
+
+			if msg.Reference != test.expectedReference {
+				t.Errorf("expected reference %q, got %q", test.expectedReference, msg.Reference)
+			}
+		})
+	}
+}
+
+func TestMT940RelaxFields(t *testing.T) {
+	input := `{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:
+:20:REFERENCE-TOO-LONG-FOR-16X
+:25:BPHKPLPK/320000546101
+:28C:00084/002
+:60F:C031002PLN40000,00
+:62F:C031020PLN40000,00
+-}`
+
+	for _, test := range []struct {
+		name             string
+		tags             []string
+		expectedCount    int
+		expectedErrors   int
+		expectedWarnings int
+	}{
+		{
+			name:           "NotRelaxed",
+			expectedErrors: 1,
+		},
+		{
+			name:           "OtherFieldRelaxed",
+			tags:           []string{"25", "86"},
+			expectedErrors: 1,
+		},
+		{
+			name:             "Relaxed",
+			tags:             []string{"20"},
+			expectedCount:    1,
+			expectedWarnings: 1,
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			msgs, err := mt.ParseAllMT940(ctx, strings.NewReader(input), mt.RelaxFields(test.tags...))
+
+			if len(msgs) != test.expectedCount {
+				t.Fatalf("expected %d messages, got %d", test.expectedCount, len(msgs))
+			}
+
+			var errs mt.Errors
+			if err != nil && !errors.As(err, &errs) {
+				t.Fatalf("expected Errors, got: %v", err)
+			}
+
+			if n := len(errs.WithoutWarnings()); n != test.expectedErrors {
+				t.Errorf("expected %d errors, got %d: %v", test.expectedErrors, n, errs)
+			}
+			if n := len(errs.Warnings()); n != test.expectedWarnings {
+				t.Errorf("expected %d warnings, got %d: %v", test.expectedWarnings, n, errs)
+			}
+			for _, warning := range errs.Warnings() {
+				mttest.ValidateError(t, fmt.Errorf("Reference|20|: pattern validation failed"), warning)
+			}
+			if test.expectedCount > 0 && msgs[0].Reference != "REFERENCE-TOO-LONG-FOR-16X" {
+				t.Errorf("expected the relaxed field to be kept, got %q", msgs[0].Reference)
+			}
+		})
+	}
+}
+
+func TestMT940LinesBetween(t *testing.T) {
+	f, err := os.Open("testdata/sample-file-mt940.txt")
+	if err != nil {
+		t.Fatalf("could not open sample file: %v", err)
+	}
+	defer f.Close()
+
+	msgs, _ := mt.ParseAllMT940(ctx, f, mt.Lax(true))
+	if len(msgs) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(msgs))
+	}
+
+	// a line without a value date is never within range
+	msgs = append(msgs, mt.MT940{StatementLines: []mt.StatementLine{{}}})
+
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	for _, test := range []struct {
+		name          string
+		from          time.Time
+		to            time.Time
+		expectedDates []string
+	}{
+		{
+			name:          "AllDates",
+			from:          date(2003, time.October, 1),
+			to:            date(2003, time.December, 31),
+			expectedDates: []string{"031020", "031020", "031020", "031209", "031209", "031209", "031209", "031020"},
+		},
+		{
+			name:          "InclusiveBounds",
+			from:          date(2003, time.October, 20),
+			to:            date(2003, time.October, 20),
+			expectedDates: []string{"031020", "031020", "031020", "031020"},
+		},
+		{
+			name:          "LaterDate",
+			from:          date(2003, time.December, 9),
+			to:            date(2003, time.December, 31),
+			expectedDates: []string{"031209", "031209", "031209", "031209"},
+		},
+		{
+			name:          "InBetweenDates",
+			from:          date(2003, time.October, 21),
+			to:            date(2003, time.December, 8),
+			expectedDates: []string{},
+		},
+		{
+			name:          "ReversedRange",
+			from:          date(2003, time.December, 31),
+			to:            date(2003, time.October, 1),
+			expectedDates: []string{},
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			dates := make([]string, 0)
+			for _, msg := range msgs {
+				for _, line := range msg.LinesBetween(test.from, test.to) {
+					dates = append(dates, line.Date.Raw)
+				}
+			}
+
+			if !reflect.DeepEqual(dates, test.expectedDates) {
+				t.Errorf("expected lines with dates %v, got %v", test.expectedDates, dates)
+			}
+		})
+	}
+}
+
+func TestMT940Strict(t *testing.T) {
+	statement := func(opening, line, closing string) string {
+		return `{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:
+:20:REFERENCE
+:25:BPHKPLPK/320000546101
+:28C:00084/001
+:60F:C` + opening + `PLN40000,00
+:61:` + line + `C20000,00FMSCNONREF//8327000090031789
+:62F:C` + closing + `PLN60000,00
+-}`
+	}
+
+	for _, test := range []struct {
+		name        string
+		input       string
+		strict      bool
+		expectedErr error
+	}{
+		{
+			name:   "InOrder",
+			input:  statement("031019", "031020", "031021"),
+			strict: true,
+		},
+		{
+			name:   "SameDay",
+			input:  statement("031020", "031020", "031020"),
+			strict: true,
+		},
+		{
+			name:  "ClosingBeforeOpeningNotStrict",
+			input: statement("031021", "031021", "031020"),
+		},
+		{
+			name:   "ClosingBeforeOpening",
+			input:  statement("031021", "031021", "031020"),
+			strict: true,
+			expectedErr: fmt.Errorf(
+				"ClosingBalance|62F|: date 2003-10-20 is before date 2003-10-21 of OpeningBalance\n" +
+					"\tStatementLines[0]|61|: date 2003-10-21 is after date 2003-10-20 of ClosingBalance",
+			),
+		},
+		{
+			name:        "LineBeforeOpening",
+			input:       statement("031020", "031019", "031021"),
+			strict:      true,
+			expectedErr: fmt.Errorf("StatementLines[0]|61|: date 2003-10-19 is before date 2003-10-20 of OpeningBalance"),
+		},
+		{
+			name:        "LineAfterClosing",
+			input:       statement("031020", "031022", "031021"),
+			strict:      true,
+			expectedErr: fmt.Errorf("StatementLines[0]|61|: date 2003-10-22 is after date 2003-10-21 of ClosingBalance"),
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			msgs, err := mt.ParseAllMT940(ctx, strings.NewReader(test.input), mt.Strict(test.strict))
+			mttest.ValidateError(t, test.expectedErr, err)
+
+			expectedCount := 1
+			if test.expectedErr != nil {
+				expectedCount = 0
+			}
+			if len(msgs) != expectedCount {
+				t.Errorf("expected %d messages, got %d", expectedCount, len(msgs))
+			}
+		})
+	}
+}
+
+func TestMT940Clone(t *testing.T) {
+	f, err := os.Open("testdata/sample-file-mt940.txt")
+	if err != nil {
+		t.Fatalf("could not open sample file: %v", err)
+	}
+	defer f.Close()
+
+	msgs, _ := mt.ParseAllMT940(ctx, f, mt.Lax(true))
+	if len(msgs) < 1 {
+		t.Fatalf("expected at least 1 message, got %d", len(msgs))
+	}
+	original := msgs[0]
+	originalLine := original.StatementLines[0]
+	originalInformation := append([]string(nil), original.AccountOwnerInformation...)
+
+	clone := original.Clone()
+	if !reflect.DeepEqual(clone, original) {
+		t.Fatalf("expected clone to equal the original, got %+v", clone)
+	}
+
+	clone.StatementLines[0].Description = "CHANGED"
+	clone.AccountOwnerInformation = append(clone.AccountOwnerInformation[:0], "CHANGED")
+	clone.PresentFields["65"] = true
+	clone.FieldOrder[0] = "CHANGED"
+
+	if !reflect.DeepEqual(original.StatementLines[0], originalLine) {
+		t.Errorf("expected statement line of the original to be left intact, got %+v", original.StatementLines[0])
+	}
+	if !reflect.DeepEqual(original.AccountOwnerInformation, originalInformation) {
+		t.Errorf("expected information of the original to be left intact, got %v", original.AccountOwnerInformation)
+	}
+	if original.IsPresent("65") {
+		t.Errorf("expected field 65 to be absent from the original")
+	}
+	if original.FieldOrder[0] != "20" {
+		t.Errorf("expected field order of the original to be left intact, got %v", original.FieldOrder)
+	}
+}
+
+func TestParseAllMT940ErrorsByMessage(t *testing.T) {
+	t.Parallel()
+
+	const header = "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:\n"
+	const account = ":25:BPHKPLPK/320000546101\n:28C:00084/002\n:60F:C031002PLN40000,00\n"
+
+	input := header + ":20:REFERENCE TOO LONG\n" + account + ":62F:C031020PLN40000,00\n-}\n" +
+		header + ":20:REFERENCE\n" + account + ":62F:C031020PLN40000,00\n-}\n" +
+		header + ":20:REFERENCE\n" + account + "-}"
+
+	msgs, err := mt.ParseAllMT940(ctx, strings.NewReader(input), mt.Lax(true))
+	if len(msgs) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(msgs))
+	}
+
+	var errs mt.Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected parse errors, got: %v", err)
+	}
+
+	expected := map[int]mt.FieldFailure{
+		0: {Field: "Reference", Tag: "20"},
+		2: {Field: "ClosingBalance", Tag: "62a"},
+	}
+
+	byMessage := errs.ByMessage()
+	if len(byMessage) != len(expected) {
+		t.Fatalf("expected errors for %d messages, got %d: %v", len(expected), len(byMessage), byMessage)
+	}
+
+	for index, expectedFailure := range expected {
+		msgErrs := byMessage[index]
+		if len(msgErrs) != 1 {
+			t.Fatalf("expected 1 error for message %d, got %d", index, len(msgErrs))
+		}
+
+		if msgErrs[0].Line() != msgs[index].Line {
+			t.Errorf("expected error for message %d on line %d, got %d", index, msgs[index].Line, msgErrs[0].Line())
+		}
+
+		var validationErr mt.ValidationError
+		if !errors.As(msgErrs[0], &validationErr) {
+			t.Fatalf("expected error for message %d to be a ValidationError, got %T", index, msgErrs[0].Cause())
+		}
+
+		if validationErr.MessageType != mt.MessageTypeMT940 {
+			t.Errorf("expected message type %s, got %s", mt.MessageTypeMT940, validationErr.MessageType)
+		}
+
+		if len(validationErr.Failures) != 1 {
+			t.Fatalf("expected 1 failure for message %d, got %d: %v", index, len(validationErr.Failures), validationErr)
+		}
+
+		failure := validationErr.Failures[0]
+		if failure.Field != expectedFailure.Field || failure.Tag != expectedFailure.Tag {
+			t.Errorf(
+				"expected failure of field %s (%s), got %s (%s)",
+				expectedFailure.Field,
+				expectedFailure.Tag,
+				failure.Field,
+				failure.Tag,
+			)
+		}
+		if !strings.HasPrefix(failure.Error(), failure.Field+"|"+failure.Tag+"|") {
+			t.Errorf("expected failure to describe field %s, got: %s", failure.Field, failure)
+		}
+	}
+}
+
+func TestMT940DuplicateField(t *testing.T) {
+	const body = ":25:BPHKPLPK/320000546101\n:28C:00084/001\n:60F:C031002PLN40000,00\n:62F:C031020PLN40000,00\n-}"
+
+	for _, test := range []struct {
+		name   string
+		fields string
+	}{
+		{
+			name:   "Reference",
+			fields: ":20:REFERENCE\n:20:OTHER\n",
+		},
+		{
+			name:   "ClosingAvailableBalance",
+			fields: ":20:REFERENCE\n:64:C031020PLN40000,00\n:64:C031020PLN40000,00\n",
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			input := "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:\n" + test.fields + body
+
+			// duplicates are detected while decoding, so even when validation is skipped
+			for _, skipValidation := range []bool{false, true} {
+				msgs, err := mt.ParseAllMT940(ctx, strings.NewReader(input), mt.SkipValidation(skipValidation))
+				mttest.ValidateError(t, fmt.Errorf("got 2 occurrences of a non-repeatable field"), err)
+
+				if len(msgs) != 0 {
+					t.Errorf("expected the message to be discarded, got %d messages", len(msgs))
+				}
+			}
+		})
+	}
+}
+
+func TestParseMT940Channels(t *testing.T) {
+	t.Parallel()
+
+	const header = "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:\n"
+	const body = ":25:BPHKPLPK/320000546101\n:28C:00084/001\n:60F:C031002PLN40000,00\n:62F:C031020PLN40000,00\n-}"
+
+	// the last message fails validation, its error is reported after the messages from ParseMTx have all been read
+	input := header + ":20:REFERENCE\n" + body + header + ":20:REFERENCE TOO LONG\n" + body
+
+	msgCh, errCh := mt.ParseMT940(ctx, strings.NewReader(input))
+
+	msgs := make([]mt.MT940, 0)
+	errs := make([]mt.Error, 0)
+
+	for msgCh != nil || errCh != nil {
+		select {
+		case msg, ok := <-msgCh:
+			if !ok {
+				msgCh = nil
+				continue
+			}
+			msgs = append(msgs, msg)
+		case err, ok := <-errCh:
+			if !ok {
+				errCh = nil
+				continue
+			}
+			errs = append(errs, err)
+		}
+	}
+
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %d: %v", len(errs), errs)
+	}
+
+	if index, ok := errs[0].MessageIndex(); !ok || index != 1 {
+		t.Errorf("expected error for message 1, got %d (%t)", index, ok)
+	}
+}
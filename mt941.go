@@ -0,0 +1,61 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+package mt
+
+import (
+	"fmt"
+)
+
+// MT941 represents a Balance Report.
+// All balances and sums of entries are in the currency of the account, hence their currencies must be the same.
+// It's based on the spec here: https://www2.swift.com/knowledgecentre/publications/us9m_20210723/1.0?topic=mt941.htm
+type MT941 struct {
+	Base
+	Reference                     string             `mt:"20,M,16x"`
+	RelatedReference              string             `mt:"21,O,16x"`
+	AccountIdentification         string             `mt:"25,M,35x"`
+	StatementNumberSequenceNumber string             `mt:"28,M,5n(/2n)"`
+	DateTimeIndication            DateTimeIndication `mt:"13D,O,6!n4!n1!x4!n"`
+	OpeningBalance                Balance            `mt:"60F,O,dive"`
+	DebitEntries                  NumberAndSum       `mt:"90D,O,dive"`
+	CreditEntries                 NumberAndSum       `mt:"90C,O,dive"`
+	ClosingBalance                Balance            `mt:"62F,M,dive"`
+	ClosingAvailableBalance       Balance            `mt:"64,O,dive"`
+	ForwardAvailableBalances      []Balance          `mt:"65,O,dive"`
+	AccountOwnerInformation       string             `mt:"86,O,6*65x"`
+}
+
+// ValidateMT verifies the currencies of all balances and sums of entries present match the currency of the closing
+// balance, the only rule spanning multiple fields of the message.
+func (msg MT941) ValidateMT() error {
+	type fieldCurrency struct {
+		tag      string
+		set      bool
+		currency string
+	}
+
+	fields := []fieldCurrency{
+		{"60F", msg.OpeningBalance.Set, msg.OpeningBalance.Currency},
+		{"90D", msg.DebitEntries.Set, msg.DebitEntries.Currency},
+		{"90C", msg.CreditEntries.Set, msg.CreditEntries.Currency},
+		{"64", msg.ClosingAvailableBalance.Set, msg.ClosingAvailableBalance.Currency},
+	}
+	for _, balance := range msg.ForwardAvailableBalances {
+		fields = append(fields, fieldCurrency{"65", balance.Set, balance.Currency})
+	}
+
+	for _, field := range fields {
+		if field.set && field.currency != msg.ClosingBalance.Currency {
+			return fmt.Errorf(
+				"currency %s of field %s does not match currency %s of the closing balance",
+				field.currency,
+				field.tag,
+				msg.ClosingBalance.Currency,
+			)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DennisVis/mt"
+	mttest "github.com/DennisVis/mt/testdata"
+)
+
+const mt941Input = `{1:F01BPHKPLPKXXXX0000000000}{2:I941BOFAUS6BXBAMN}{4:
+:20:BALANCE
+:25:PL89106000760000321000006053
+:28:00042/01
+:13D:2109151200+0200
+:60F:C210914PLN40000,00
+%s
+:62F:C210915PLN59900,50
+:64:C210915PLN59900,50
+:65:C210916PLN59900,50
+:86:BALANCE REPORT
+-}`
+
+func TestParseMT941(t *testing.T) {
+	for _, test := range []struct {
+		name                  string
+		entries               string
+		expectedParseErrors   mt.Errors
+		expectedDebitEntries  mt.NumberAndSum
+		expectedCreditEntries mt.NumberAndSum
+	}{
+		{
+			name:    "Valid",
+			entries: ":90D:3PLN100,00\n:90C:12PLN20000,50",
+			expectedDebitEntries: mt.NumberAndSum{
+				Set:      true,
+				Number:   3,
+				Currency: "PLN",
+				Amount:   mttest.MustParseAmount("100,00"),
+			},
+			expectedCreditEntries: mt.NumberAndSum{
+				Set:      true,
+				Number:   12,
+				Currency: "PLN",
+				Amount:   mttest.MustParseAmount("20000,50"),
+			},
+		},
+		{
+			name: "ValidWithoutEntries",
+		},
+		{
+			name:    "InvalidNumber",
+			entries: ":90D:123456PLN100,00",
+			expectedParseErrors: mt.Errors{
+				mt.NewError(fmt.Errorf("number and sum: invalid number of entries: 123456PLN100,00"), 1),
+			},
+		},
+		{
+			name:    "CurrencyMismatch",
+			entries: ":90D:3PLN100,00\n:90C:12EUR20000,50",
+			expectedParseErrors: mt.Errors{
+				mt.NewError(fmt.Errorf("currency EUR of field 90C does not match currency PLN of the closing balance"), 1),
+			},
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			input := strings.NewReader(fmt.Sprintf(mt941Input, test.entries))
+
+			msgs, err := mt.ParseAllMT941(ctx, input)
+			mttest.ValidateErrors(t, test.expectedParseErrors, err)
+
+			if test.expectedParseErrors != nil {
+				return
+			}
+			if len(msgs) != 1 {
+				t.Fatalf("expected 1 message, got %d", len(msgs))
+			}
+
+			msg := msgs[0]
+			if msg.StatementNumberSequenceNumber != "00042/01" {
+				t.Errorf("expected StatementNumberSequenceNumber 00042/01, got %s", msg.StatementNumberSequenceNumber)
+			}
+			if msg.ClosingBalance.Amount != mttest.MustParseAmount("59900,50") {
+				t.Errorf("expected closing balance amount 59900,50, got %s", msg.ClosingBalance.Amount)
+			}
+			if len(msg.ForwardAvailableBalances) != 1 {
+				t.Errorf("expected 1 forward available balance, got %d", len(msg.ForwardAvailableBalances))
+			}
+			if msg.AccountOwnerInformation != "BALANCE REPORT" {
+				t.Errorf("expected AccountOwnerInformation BALANCE REPORT, got %s", msg.AccountOwnerInformation)
+			}
+
+			for _, entries := range []struct {
+				tag      string
+				expected mt.NumberAndSum
+				actual   mt.NumberAndSum
+			}{
+				{"90D", test.expectedDebitEntries, msg.DebitEntries},
+				{"90C", test.expectedCreditEntries, msg.CreditEntries},
+			} {
+				if entries.expected.Set != entries.actual.Set {
+					t.Errorf("%s: expected Set %t, got %t", entries.tag, entries.expected.Set, entries.actual.Set)
+					continue
+				}
+				if entries.expected.Number != entries.actual.Number {
+					t.Errorf("%s: expected number %d, got %d", entries.tag, entries.expected.Number, entries.actual.Number)
+				}
+				if entries.expected.Currency != entries.actual.Currency {
+					t.Errorf("%s: expected currency %s, got %s", entries.tag, entries.expected.Currency, entries.actual.Currency)
+				}
+				if entries.expected.Amount != entries.actual.Amount {
+					t.Errorf("%s: expected amount %s, got %s", entries.tag, entries.expected.Amount, entries.actual.Amount)
+				}
+			}
+		})
+	}
+}
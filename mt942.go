@@ -0,0 +1,29 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+package mt
+
+// MT942 represents an Interim Transaction Report.
+// It's based on the spec here: https://www2.swift.com/knowledgecentre/publications/us9m_20210723/1.0?topic=mt942.htm
+type MT942 struct {
+	Base
+	Reference                     string             `mt:"20,M,16x"`
+	RelatedReference              string             `mt:"21,O,16x"`
+	AccountIdentification         string             `mt:"25,M,35x"`
+	StatementNumberSequenceNumber StatementNumber    `mt:"28C,M,5n(/5n)"`
+	FloorLimits                   FloorLimits        `mt:"34F,M,dive"`
+	DateTimeIndication            DateTimeIndication `mt:"13D,M,6!n4!n1!x4!n"`
+	StatementLines                []StatementLine    `mt:"61,O,dive"`
+	AccountOwnerInformation       []string           `mt:"86,O,6*65x"`
+}
+
+// validateStrict validates the order of the floor limits, debit followed by credit.
+func (msg MT942) validateStrict() error {
+	return msg.FloorLimits.validateStrict()
+}
+
+// acceptStatementPage splits the page number off field 28C.
+func (msg *MT942) acceptStatementPage() {
+	msg.StatementNumberSequenceNumber.acceptPage()
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/DennisVis/mt"
+	mttest "github.com/DennisVis/mt/testdata"
+)
+
+const mt942FloorLimitsInput = `{1:F01BPHKPLPKXXXX0506100744}{2:I942AAASTHB1XXXXN}{4:
+:20:TECHMIX
+:25:PL89106000760000321000006053
+:28C:00001/001
+%s
+:13D:0506100744+0200
+:61:0506100610D93,17FMSCNONREF//6127001795151001
+:90D:1PLN93,17
+-}`
+
+func validateFloorLimits(t *testing.T, expected, actual mt.FloorLimits) {
+	t.Run("FloorLimits", func(t *testing.T) {
+		if len(expected) != len(actual) {
+			t.Fatalf("expected %d floor limits, got %d", len(expected), len(actual))
+		}
+
+		for i, exp := range expected {
+			act := actual[i]
+
+			if exp.Currency != act.Currency {
+				t.Errorf("FloorLimits[%d]: expected currency %s, got %s", i, exp.Currency, act.Currency)
+			}
+			if exp.Indicator != act.Indicator {
+				t.Errorf("FloorLimits[%d]: expected indicator %q, got %q", i, exp.Indicator, act.Indicator)
+			}
+			if exp.Amount != act.Amount {
+				t.Errorf("FloorLimits[%d]: expected amount %s, got %s", i, exp.Amount, act.Amount)
+			}
+		}
+	})
+}
+
+func TestParseMT942(t *testing.T) {
+	for _, test := range []struct {
+		name                string
+		input               io.Reader
+		strict              bool
+		expectedParseErrors mt.Errors
+		expectedFloorLimits mt.FloorLimits
+	}{
+		{
+			name:                "InvalidInput",
+			input:               &mttest.TestReaderInvalid{},
+			expectedParseErrors: []mt.Error{mt.NewError(mttest.ErrReadInvalid, 1)},
+		},
+		{
+			name:  "SampleFile",
+			input: mttest.MustOpenFile("testdata/sample-file-mt942.txt"),
+			expectedFloorLimits: mt.FloorLimits{
+				{Currency: "PLN", Amount: mttest.MustParseAmount("0,")},
+			},
+		},
+		{
+			name:  "DebitAndCreditFloorLimits",
+			input: strings.NewReader(fmt.Sprintf(mt942FloorLimitsInput, ":34F:PLND100,00\n:34F:PLNC250,00")),
+			expectedFloorLimits: mt.FloorLimits{
+				{Currency: "PLN", Indicator: "D", Amount: mttest.MustParseAmount("100,00")},
+				{Currency: "PLN", Indicator: "C", Amount: mttest.MustParseAmount("250,00")},
+			},
+		},
+		{
+			name:  "DuplicateIndicator",
+			input: strings.NewReader(fmt.Sprintf(mt942FloorLimitsInput, ":34F:PLND100,00\n:34F:PLND250,00")),
+			expectedParseErrors: []mt.Error{
+				mt.NewError(fmt.Errorf(`floor limits: expected distinct indicators, got "D" twice`), 1),
+			},
+		},
+		{
+			name:  "MissingIndicatorFollowedByCredit",
+			input: strings.NewReader(fmt.Sprintf(mt942FloorLimitsInput, ":34F:PLN100,00\n:34F:PLNC250,00")),
+			expectedFloorLimits: mt.FloorLimits{
+				{Currency: "PLN", Amount: mttest.MustParseAmount("100,00")},
+				{Currency: "PLN", Indicator: "C", Amount: mttest.MustParseAmount("250,00")},
+			},
+		},
+		{
+			name:   "MissingIndicatorFollowedByCreditStrict",
+			input:  strings.NewReader(fmt.Sprintf(mt942FloorLimitsInput, ":34F:PLN100,00\n:34F:PLNC250,00")),
+			strict: true,
+			expectedParseErrors: []mt.Error{
+				mt.NewError(fmt.Errorf(`floor limits: expected debit followed by credit indicator, got "" and "C"`), 1),
+			},
+		},
+		{
+			name:  "CreditFollowedByDebit",
+			input: strings.NewReader(fmt.Sprintf(mt942FloorLimitsInput, ":34F:PLNC100,00\n:34F:PLND250,00")),
+			expectedFloorLimits: mt.FloorLimits{
+				{Currency: "PLN", Indicator: "C", Amount: mttest.MustParseAmount("100,00")},
+				{Currency: "PLN", Indicator: "D", Amount: mttest.MustParseAmount("250,00")},
+			},
+		},
+		{
+			name:   "CreditFollowedByDebitStrict",
+			input:  strings.NewReader(fmt.Sprintf(mt942FloorLimitsInput, ":34F:PLNC100,00\n:34F:PLND250,00")),
+			strict: true,
+			expectedParseErrors: []mt.Error{
+				mt.NewError(fmt.Errorf(`floor limits: expected debit followed by credit indicator, got "C" and "D"`), 1),
+			},
+		},
+		{
+			name:  "TooManyFloorLimits",
+			input: strings.NewReader(fmt.Sprintf(mt942FloorLimitsInput, ":34F:PLND1,\n:34F:PLNC2,\n:34F:PLN3,")),
+			expectedParseErrors: []mt.Error{
+				mt.NewError(fmt.Errorf("floor limits: expected at most 2 occurrences, got 3"), 1),
+			},
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			msgs, err := mt.ParseAllMT942(ctx, test.input, mt.Strict(test.strict))
+			mttest.ValidateErrors(t, test.expectedParseErrors, err)
+
+			if test.expectedFloorLimits == nil {
+				return
+			}
+			if len(msgs) != 1 {
+				t.Fatalf("expected 1 message, got %d", len(msgs))
+			}
+
+			validateFloorLimits(t, test.expectedFloorLimits, msgs[0].FloorLimits)
+		})
+	}
+}
@@ -6,11 +6,18 @@
 package mt_test
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"os"
+	"reflect"
+	"runtime"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/DennisVis/mt"
 	mttest "github.com/DennisVis/mt/testdata"
@@ -218,6 +225,11 @@ func TestParseAppHeaderInput(t *testing.T) {
 			input:         strings.NewReader(`{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN2020X}`),
 			expectedError: mt.NewError(errors.New("invalid app header input block content length"), 1),
 		},
+		{
+			name:          "AppHeaderInputMessageTypeNotNumeric",
+			input:         strings.NewReader(`{1:F01BPHKPLPKXXXX0000000000}{2:I94XBOFAUS6BXBAMN}`),
+			expectedError: mt.NewError(errors.New(`invalid app header: invalid message type "94X": expected 3 digits`), 1),
+		},
 		{
 			name:          "AppHeaderInputPriorityUnknown17",
 			input:         strings.NewReader(`{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMX}`),
@@ -369,6 +381,11 @@ func TestParseAppHeaderOutput(t *testing.T) {
 			input:         strings.NewReader("{1:F01BPHKPLPKXXXX0000000000}{2:O9401157091028SCBLZAJJXXXX5712100002091028115}"),
 			expectedError: mt.NewError(fmt.Errorf("invalid app header output block content length"), 1),
 		},
+		{
+			name:          "MessageTypeNotNumeric",
+			input:         strings.NewReader("{1:F01BPHKPLPKXXXX0000000000}{2:O9 01157091028SCBLZAJJXXXX57121000020910281157N}"),
+			expectedError: mt.NewError(fmt.Errorf(`invalid app header: invalid message type "9 0": expected 3 digits`), 1),
+		},
 		{
 			name:          "InvalidInputTime",
 			input:         strings.NewReader("{1:F01BPHKPLPKXXXX0000000000}{2:O9401X57091028SCBLZAJJXXXX57121000020910281157N}"),
@@ -434,10 +451,92 @@ func TestParseAppHeaderOutput(t *testing.T) {
 }
 
 // {1:F01SCBLZAJJXXXX5712100002}{2:I940BOFAUS6BXBAMN1}
+func TestParseLogicalTerminalAddresses(t *testing.T) {
+	for _, test := range []struct {
+		name             string
+		input            string
+		strict           bool
+		lax              bool
+		skipValidation   bool
+		expectedError    error
+		expectedMessages int
+	}{
+		{
+			name:             "ValidInput",
+			input:            `{1:F01SCBLZAJJXXXX5712100002}{2:I940BOFAUS6BXBAMN}`,
+			expectedMessages: 1,
+		},
+		{
+			name:             "ValidOutput",
+			input:            `{1:F01SCBLZAJJXXXX5712100002}{2:O9401157091028SCBLZAJJXXXX57121000020910281157N}`,
+			expectedMessages: 1,
+		},
+		{
+			name:          "BasicHeaderTerminalCodeDigit",
+			strict:        true,
+			input:         `{1:F01SCBLZAJJ1XXX5712100002}{2:I940BOFAUS6BXBAMN}`,
+			expectedError: errors.New(`invalid basic header: logical terminal address "SCBLZAJJ1XXX": terminal code "1" must consist of a letter`),
+		},
+		{
+			name:          "ReceiverAddressCountryCodeDigit",
+			strict:        true,
+			input:         `{1:F01SCBLZAJJXXXX5712100002}{2:I940BOFAU56BXBAMN}`,
+			expectedError: errors.New(`invalid app header: logical terminal address "BOFAU56BXBAM": country code "U5" must consist of letters`),
+		},
+		{
+			name:          "MessageInputReferenceBankCodeLowercase",
+			strict:        true,
+			input:         `{1:F01SCBLZAJJXXXX5712100002}{2:O9401157091028scblZAJJXXXX57121000020910281157N}`,
+			expectedError: errors.New(`invalid app header: logical terminal address "scblZAJJXXXX": bank code "scbl" must consist of letters`),
+		},
+		{
+			name:             "InvalidNotStrict",
+			input:            `{1:F01SCBLZAJJ1XXX5712100002}{2:I940BOFAUS6BXBAMN}`,
+			expectedMessages: 1,
+		},
+		{
+			name:             "InvalidLax",
+			strict:           true,
+			input:            `{1:F01SCBLZAJJ1XXX5712100002}{2:I940BOFAUS6BXBAMN}`,
+			lax:              true,
+			expectedError:    errors.New("terminal code"),
+			expectedMessages: 1,
+		},
+		{
+			name:             "InvalidSkipValidation",
+			strict:           true,
+			input:            `{1:F01SCBLZAJJ1XXX5712100002}{2:I940BOFAUS6BXBAMN}`,
+			skipValidation:   true,
+			expectedMessages: 1,
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			msgs, err := mt.ParseAllMTx(
+				ctx,
+				strings.NewReader(test.input),
+				mt.Strict(test.strict),
+				mt.Lax(test.lax),
+				mt.SkipValidation(test.skipValidation),
+			)
+			mttest.ValidateError(t, test.expectedError, err)
+
+			if len(msgs) != test.expectedMessages {
+				t.Errorf("expected %d messages, got %d", test.expectedMessages, len(msgs))
+			}
+		})
+	}
+}
+
 func TestParseUsrHeader(t *testing.T) {
 	for _, test := range []struct {
 		name              string
 		input             io.Reader
+		strict            bool
 		expectedError     mt.Error
 		expectedUsrHeader mt.UsrHeader
 	}{
@@ -451,6 +550,61 @@ func TestParseUsrHeader(t *testing.T) {
 			input:         strings.NewReader(`{1:F01SCBLZAJJXXXX5712100002}{2:I940BOFAUS6BXBAMN1}{3:{106:091X28SCBLZAJJXXXX57121000020}}`),
 			expectedError: mt.NewError(fmt.Errorf("invalid message input reference"), 1),
 		},
+		{
+			name:          "InvalidSanctionsScreeningInformation",
+			strict:        true,
+			input:         strings.NewReader(`{1:F01SCBLZAJJXXXX5712100002}{2:I940BOFAUS6BXBAMN1}{3:{433:/XYZ/}}`),
+			expectedError: mt.NewError(fmt.Errorf("invalid usr header: invalid sanctions screening information"), 1),
+		},
+		{
+			name:  "SanctionsScreeningInformationTyped",
+			input: strings.NewReader(`{1:F01SCBLZAJJXXXX5712100002}{2:I940BOFAUS6BXBAMN1}{3:{433:/FPO/NAME MATCH}}`),
+			expectedUsrHeader: mt.UsrHeader{
+				Set:                           true,
+				SanctionsScreeningInformation: "/FPO/NAME MATCH",
+				SanctionsScreening: mt.SanctionsScreening{
+					Set:                   true,
+					Raw:                   "/FPO/NAME MATCH",
+					Code:                  mt.ScreeningCodeFalsePositive,
+					AdditionalInformation: "NAME MATCH",
+				},
+			},
+		},
+		{
+			name:  "MalformedSanctionsScreeningInformationNotStrict",
+			input: strings.NewReader(`{1:F01SCBLZAJJXXXX5712100002}{2:I940BOFAUS6BXBAMN1}{3:{433:/XYZ/}}`),
+			expectedUsrHeader: mt.UsrHeader{
+				Set:                           true,
+				SanctionsScreeningInformation: "/XYZ/",
+				SanctionsScreening:            mt.SanctionsScreening{Set: true, Raw: "/XYZ/"},
+			},
+		},
+		{
+			name:          "InvalidServiceTypeID",
+			strict:        true,
+			input:         strings.NewReader(`{1:F01SCBLZAJJXXXX5712100002}{2:I940BOFAUS6BXBAMN1}{3:{111:1A}}`),
+			expectedError: mt.NewError(fmt.Errorf(`invalid usr header: service type identifier "1A": expected 3 digits`), 1),
+		},
+		{
+			name:          "NonNumericServiceTypeID",
+			strict:        true,
+			input:         strings.NewReader(`{1:F01SCBLZAJJXXXX5712100002}{2:I940BOFAUS6BXBAMN1}{3:{111:A01}}`),
+			expectedError: mt.NewError(fmt.Errorf(`service type identifier "A01": must consist of digits`), 1),
+		},
+		{
+			name:          "MalformedUETR",
+			strict:        true,
+			input:         strings.NewReader(`{1:F01SCBLZAJJXXXX5712100002}{2:I940BOFAUS6BXBAMN1}{3:{121:MyUE2ETRef}}`),
+			expectedError: mt.NewError(fmt.Errorf(`unique end-to-end transaction reference "MyUE2ETRef"`), 1),
+		},
+		{
+			name: "UETRNotVersion4",
+			input: strings.NewReader(
+				`{1:F01SCBLZAJJXXXX5712100002}{2:I940BOFAUS6BXBAMN1}{3:{121:eb6305c9-1f7f-19de-aed0-16487c27b42d}}`,
+			),
+			strict:        true,
+			expectedError: mt.NewError(fmt.Errorf("expected a lower case version 4 UUID"), 1),
+		},
 		{
 			name:          "InvalidBalanceCheckpointDateTime",
 			input:         strings.NewReader(`{1:F01SCBLZAJJXXXX5712100002}{2:I940BOFAUS6BXBAMN1}{3:{423:123}}`),
@@ -494,7 +648,11 @@ func TestParseUsrHeader(t *testing.T) {
 				},
 				RelatedReference:              "MyRelatedReference",
 				SanctionsScreeningInformation: "MySanctionsScreeningInformation",
-				PaymentControlsInformation:    "MyPaymentControlsInformation",
+				SanctionsScreening: mt.SanctionsScreening{
+					Set: true,
+					Raw: "MySanctionsScreeningInformation",
+				},
+				PaymentControlsInformation: "MyPaymentControlsInformation",
 			},
 		},
 	} {
@@ -504,7 +662,7 @@ func TestParseUsrHeader(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
 
-			msgs, err := mt.ParseAllMTx(ctx, test.input)
+			msgs, err := mt.ParseAllMTx(ctx, test.input, mt.Strict(test.strict))
 			if test.expectedError.Cause() != nil {
 				mttest.ValidateErrors(t, test.expectedError, err)
 			} else if err != nil {
@@ -517,6 +675,173 @@ func TestParseUsrHeader(t *testing.T) {
 	}
 }
 
+func TestParseUsrHeaderServiceCodesLax(t *testing.T) {
+	t.Parallel()
+
+	input := `{1:F01SCBLZAJJXXXX5712100002}{2:I940BOFAUS6BXBAMN1}{3:{111:A01}{121:MyUE2ETRef}}{4:-}`
+
+	msgs, err := mt.ParseAllMTx(ctx, strings.NewReader(input), mt.Strict(true), mt.Lax(true))
+	mttest.ValidateError(t, fmt.Errorf("invalid usr header"), err)
+
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+
+	if msgs[0].UsrHeader.ServiceTypeID != "A01" {
+		t.Errorf("expected ServiceTypeID %q, got %q", "A01", msgs[0].UsrHeader.ServiceTypeID)
+	}
+	if uetr := msgs[0].UsrHeader.UniqueEndToEndTransactionReference; uetr != "MyUE2ETRef" {
+		t.Errorf("expected UniqueEndToEndTransactionReference %q, got %q", "MyUE2ETRef", uetr)
+	}
+}
+
+func TestParseOptionalBlocksPresence(t *testing.T) {
+	for _, test := range []struct {
+		name              string
+		input             string
+		expectedUsrHeader string
+		expectedTrailers  string
+	}{
+		{
+			name:  "Absent",
+			input: `{1:F01SCBLZAJJXXXX5712100002}{2:I940BOFAUS6BXBAMN}{4:-}`,
+		},
+		{
+			name:              "PresentButEmpty",
+			input:             `{1:F01SCBLZAJJXXXX5712100002}{2:I940BOFAUS6BXBAMN}{3:}{4:-}{5:}`,
+			expectedUsrHeader: "{3:}",
+			expectedTrailers:  "{5:}",
+		},
+		{
+			name:              "PresentWithSubBlocks",
+			input:             `{1:F01SCBLZAJJXXXX5712100002}{2:I940BOFAUS6BXBAMN}{3:{108:MyRef}}{4:-}{5:{CHK:123}{TNG:}}`,
+			expectedUsrHeader: "{3:{108:MyRef}}",
+			expectedTrailers:  "{5:{CHK:123}{TNG:}}",
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			msgs, err := mt.ParseAllMTx(ctx, strings.NewReader(test.input))
+			mttest.ValidateError(t, nil, err)
+
+			if len(msgs) != 1 {
+				t.Fatalf("expected 1 message, got %d", len(msgs))
+			}
+			msg := msgs[0]
+
+			if msg.HasUserHeader() != (test.expectedUsrHeader != "") {
+				t.Errorf("expected HasUserHeader to be %v", test.expectedUsrHeader != "")
+			}
+			if msg.HasTrailers() != (test.expectedTrailers != "") {
+				t.Errorf("expected HasTrailers to be %v", test.expectedTrailers != "")
+			}
+
+			usrHeader, err := msg.UsrHeader.MarshalMT()
+			mttest.ValidateError(t, nil, err)
+			if usrHeader != test.expectedUsrHeader {
+				t.Errorf("expected marshaled user header %q, got %q", test.expectedUsrHeader, usrHeader)
+			}
+
+			trailers, err := msg.Trailers.MarshalMT()
+			mttest.ValidateError(t, nil, err)
+			if trailers != test.expectedTrailers {
+				t.Errorf("expected marshaled trailers %q, got %q", test.expectedTrailers, trailers)
+			}
+		})
+	}
+}
+
+func TestTrailersMarshalMT(t *testing.T) {
+	for _, test := range []struct {
+		name             string
+		trailers         mt.Trailers
+		expectedTrailers string
+	}{
+		{
+			name:             "Absent",
+			trailers:         mt.Trailers{},
+			expectedTrailers: "",
+		},
+		{
+			name:             "Empty",
+			trailers:         mt.Trailers{Set: true},
+			expectedTrailers: "{5:}",
+		},
+		{
+			name:             "TestAndTraining",
+			trailers:         mt.Trailers{TestAndTrainingMessage: true},
+			expectedTrailers: "{5:{TNG:}}",
+		},
+		{
+			name:             "Delayed",
+			trailers:         mt.Trailers{Set: true, DelayedMessage: true},
+			expectedTrailers: "{5:{DLM:}}",
+		},
+		{
+			name: "All",
+			trailers: mt.Trailers{
+				Set:                    true,
+				Checksum:               "123456789ABC",
+				TestAndTrainingMessage: true,
+				DelayedMessage:         true,
+				MessageReference:       mt.Reference{Set: true, Raw: "2103151130210315BPHKPLPKXXXX0000000000"},
+				AdditionalTrailers:     map[string]string{"PAC": "34", "MAC": "12"},
+			},
+			expectedTrailers: "{5:{MAC:12}{PAC:34}{CHK:123456789ABC}{TNG:}{DLM:}" +
+				"{MRF:2103151130210315BPHKPLPKXXXX0000000000}}",
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			trailers, err := test.trailers.MarshalMT()
+			mttest.ValidateError(t, nil, err)
+
+			if trailers != test.expectedTrailers {
+				t.Errorf("expected marshaled trailers %q, got %q", test.expectedTrailers, trailers)
+			}
+		})
+	}
+}
+
+func TestTrailersMarshalMTRoundTrip(t *testing.T) {
+	for _, trailers := range []string{
+		"{5:{TNG:}}",
+		"{5:{DLM:}}",
+		"{5:{CHK:123456789ABC}{TNG:}{DLM:}}",
+	} {
+		// rebind to make sure we can run in parallel
+		trailers := trailers
+
+		t.Run(trailers, func(t *testing.T) {
+			t.Parallel()
+
+			input := "{1:F01SCBLZAJJXXXX5712100002}{2:I940BOFAUS6BXBAMN}{4:-}" + trailers
+
+			msgs, err := mt.ParseAllMTx(ctx, strings.NewReader(input))
+			mttest.ValidateError(t, nil, err)
+
+			if len(msgs) != 1 {
+				t.Fatalf("expected 1 message, got %d", len(msgs))
+			}
+
+			marshaled, err := msgs[0].Trailers.MarshalMT()
+			mttest.ValidateError(t, nil, err)
+
+			if marshaled != trailers {
+				t.Errorf("expected marshaled trailers %q, got %q", trailers, marshaled)
+			}
+		})
+	}
+}
+
 func TestParseTrailers(t *testing.T) {
 	for _, test := range []struct {
 		name             string
@@ -559,6 +884,35 @@ func TestParseTrailers(t *testing.T) {
 				mt.NewError(errors.New("invalid message reference date/time string"), 1),
 			},
 		},
+		{
+			name: "TrailersMRFTruncated",
+			input: strings.NewReader(`{1:F01SCBLZAJJXXXX5712100002}{2:O9401157091028SCBLZAJJXXXX57121000020910281157N}{4:-}
+			{5:{MRF:18062715}}`),
+			expectedErrors: mt.Errors{
+				mt.NewError(errors.New("invalid message reference string length: 8"), 1),
+			},
+		},
+		{
+			name: "TrailersValidMRFWithDeliveryReference",
+			input: strings.NewReader(`{1:F01SCBLZAJJXXXX5712100002}{2:O9401157091028SCBLZAJJXXXX57121000020910281157N}{4:-}
+			{5:{MRF:1806271539180626BANKFRPPAXXX2222123456DLVREF0001}}`),
+			expectedTrailers: mt.Trailers{
+				Set: true,
+				Raw: "{5:{MRF:1806271539180626BANKFRPPAXXX2222123456DLVREF0001}}",
+				MessageReference: mt.Reference{
+					Set:      true,
+					Raw:      "1806271539180626BANKFRPPAXXX2222123456DLVREF0001",
+					DateTime: mttest.MustParseDateTime("1806271539"),
+					MessageInputReference: mt.InputReference{
+						DateOrDateTime:         mttest.MustParseDateOrDateTime("180626"),
+						LogicalTerminalAddress: "BANKFRPPAXXX",
+						SessionNumber:          "2222",
+						SequenceNumber:         "123456",
+					},
+					DeliveryReference: "DLVREF0001",
+				},
+			},
+		},
 		{
 			name: "TrailersPDMInvalidTime",
 			input: strings.NewReader(`{1:F01SCBLZAJJXXXX5712100002}{2:O9401157091028SCBLZAJJXXXX57121000020910281157N}{4:-}
@@ -628,6 +982,180 @@ func TestParseTrailers(t *testing.T) {
 	}
 }
 
+func TestParseTrailerLengths(t *testing.T) {
+	const (
+		prefix = "{1:F01SCBLZAJJXXXX5712100002}{2:O9401157091028SCBLZAJJXXXX57121000020910281157N}{4:-}"
+		valid  = "1348120811BANKFRPPAXXX2222123456"
+		timed  = "12131348120811BANKFRPPAXXX2222123456"
+	)
+
+	expectedInputReference := mt.InputReference{
+		DateOrDateTime:         mttest.MustParseDateOrDateTime("120811"),
+		LogicalTerminalAddress: "BANKFRPPAXXX",
+		SessionNumber:          "2222",
+		SequenceNumber:         "123456",
+	}
+
+	for _, test := range []struct {
+		name             string
+		label            string
+		content          string
+		expectedError    error
+		expectedTrailers mt.Trailers
+	}{
+		{
+			name:          "PDE31",
+			label:         "PDE",
+			content:       valid[:31],
+			expectedError: errors.New("invalid possible duplicate emission string length: 31, expected 32"),
+		},
+		{
+			name:    "PDE32",
+			label:   "PDE",
+			content: valid,
+			expectedTrailers: mt.Trailers{
+				PossibleDuplicateEmission: mt.PossibleDuplicateEmission{
+					Raw:                   valid,
+					Time:                  mttest.MustParseTime("1348"),
+					MessageInputReference: expectedInputReference,
+				},
+			},
+		},
+		{
+			name:          "PDE33",
+			label:         "PDE",
+			content:       valid + "7",
+			expectedError: errors.New("invalid possible duplicate emission string length: 33, expected 32"),
+		},
+		{
+			name:          "PDEInvalidClockTime",
+			label:         "PDE",
+			content:       "2460" + valid[4:],
+			expectedError: errors.New("invalid possible duplicate emission time: 2460"),
+		},
+		{
+			name:          "PDM31",
+			label:         "PDM",
+			content:       valid[:31],
+			expectedError: errors.New("invalid possible duplicate message string length: 31, expected 32 or 36"),
+		},
+		{
+			name:    "PDM32",
+			label:   "PDM",
+			content: valid,
+			expectedTrailers: mt.Trailers{
+				PossibleDuplicateMessage: mt.PossibleDuplicateMessage{
+					Raw:  valid,
+					Time: mttest.MustParseTime("1348"),
+					MessageOutputReference: mt.OutputReference{
+						Raw:                    valid[4:],
+						DateOrDateTime:         mttest.MustParseDateOrDateTime("120811"),
+						LogicalTerminalAddress: "BANKFRPPAXXX",
+						SessionNumber:          "2222",
+						SequenceNumber:         "123456",
+					},
+				},
+			},
+		},
+		{
+			name:          "PDM33",
+			label:         "PDM",
+			content:       valid + "7",
+			expectedError: errors.New("invalid possible duplicate message string length: 33, expected 32 or 36"),
+		},
+		{
+			name:          "PDM35",
+			label:         "PDM",
+			content:       timed[:35],
+			expectedError: errors.New("invalid possible duplicate message string length: 35, expected 32 or 36"),
+		},
+		{
+			name:    "PDM36",
+			label:   "PDM",
+			content: timed,
+			expectedTrailers: mt.Trailers{
+				PossibleDuplicateMessage: mt.PossibleDuplicateMessage{
+					Raw:  timed,
+					Time: mttest.MustParseTime("1213"),
+					MessageOutputReference: mt.OutputReference{
+						Raw:                    timed[4:],
+						DateOrDateTime:         mttest.MustParseDateOrDateTime("1208111348"),
+						LogicalTerminalAddress: "BANKFRPPAXXX",
+						SessionNumber:          "2222",
+						SequenceNumber:         "123456",
+					},
+				},
+			},
+		},
+		{
+			name:          "PDM37",
+			label:         "PDM",
+			content:       timed + "7",
+			expectedError: errors.New("invalid possible duplicate message string length: 37, expected 32 or 36"),
+		},
+		{
+			name:          "PDMInvalidClockTime",
+			label:         "PDM",
+			content:       "1299" + valid[4:],
+			expectedError: errors.New("invalid possible duplicate message time: 1299"),
+		},
+		{
+			name:          "SYS31",
+			label:         "SYS",
+			content:       valid[:31],
+			expectedError: errors.New("invalid system originated message string length: 31, expected 32"),
+		},
+		{
+			name:    "SYS32",
+			label:   "SYS",
+			content: valid,
+			expectedTrailers: mt.Trailers{
+				SystemOriginatedMessage: mt.SystemOriginatedMessage{
+					Raw:                   valid,
+					Time:                  mttest.MustParseTime("1348"),
+					MessageInputReference: expectedInputReference,
+				},
+			},
+		},
+		{
+			name:          "SYS33",
+			label:         "SYS",
+			content:       valid + "7",
+			expectedError: errors.New("invalid system originated message string length: 33, expected 32"),
+		},
+		{
+			name:          "SYSInvalidClockTime",
+			label:         "SYS",
+			content:       "2500" + valid[4:],
+			expectedError: errors.New("invalid system originated message time: 2500"),
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			trailers := "{5:{" + test.label + ":" + test.content + "}}"
+
+			msgs, err := mt.ParseAllMTx(ctx, strings.NewReader(prefix+trailers))
+			if test.expectedError != nil {
+				mttest.ValidateErrors(t, mt.Errors{mt.NewError(test.expectedError, 1)}, err)
+				return
+			}
+			mttest.ValidateError(t, nil, err)
+
+			if len(msgs) != 1 {
+				t.Fatalf("expected 1 message, got %d", len(msgs))
+			}
+
+			test.expectedTrailers.Set = true
+			test.expectedTrailers.Raw = trailers
+			mttest.ValidateTrailers(t, test.expectedTrailers, msgs[0].Trailers)
+		})
+	}
+}
+
 func TestParseMTx(t *testing.T) {
 	for _, test := range []struct {
 		name           string
@@ -766,36 +1294,433 @@ STORY-?26300 SZT GR544 I OPORNIKI-5?2700 SZT GTX847 FAKTURA 333/
 	}
 }
 
-func BenchmarkParseMTxParallel(b *testing.B) {
-	for _, msgCount := range []int{
-		1,
-		10,
-		100,
-		1000,
-		10000,
+func TestMTxSummary(t *testing.T) {
+	for _, test := range []struct {
+		name            string
+		input           string
+		expectedSummary mt.MessageSummary
+	}{
+		{
+			name:  "InputMessage",
+			input: messageInput,
+			expectedSummary: mt.MessageSummary{
+				Type:      "940",
+				Sender:    "BPHKPLPKXXXX",
+				Receiver:  "BOFAUS6BXBAM",
+				Reference: "TELEWIZORY S.A.",
+				Priority:  mt.PriorityNormal,
+				Direction: mt.DirectionInput,
+			},
+		},
+		{
+			name: "OutputMessage",
+			input: `{1:F01BPHKPLPKXXXX5712100002}{2:O9401157091028SCBLZAJJXXXX57121000020910291203U}{4:
+:20:REFERENCE
+-}`,
+			expectedSummary: mt.MessageSummary{
+				Type:      "940",
+				Sender:    "SCBLZAJJXXXX",
+				Receiver:  "BPHKPLPKXXXX",
+				Reference: "REFERENCE",
+				Priority:  mt.PriorityUrgent,
+				Direction: mt.DirectionOutput,
+				Timestamp: time.Date(2009, time.October, 29, 12, 3, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:  "WithoutReference",
+			input: "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMS}{4:\n-}",
+			expectedSummary: mt.MessageSummary{
+				Type:      "940",
+				Sender:    "BPHKPLPKXXXX",
+				Receiver:  "BOFAUS6BXBAM",
+				Priority:  mt.PrioritySystem,
+				Direction: mt.DirectionInput,
+			},
+		},
 	} {
-		b.Run(fmt.Sprintf("MessageCount_%d", msgCount), func(b *testing.B) {
-			messages := strings.Repeat(messageInput, msgCount)
+		// rebind to make sure we can run in parallel
+		test := test
 
-			b.ResetTimer()
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
 
-			b.RunParallel(func(p *testing.PB) {
-				for p.Next() {
-					mt.ParseMTx(ctx, strings.NewReader(messages))
-				}
-			})
+			msgs, err := mt.ParseAllMTx(ctx, strings.NewReader(test.input))
+			mttest.ValidateError(t, nil, err)
+
+			if len(msgs) != 1 {
+				t.Fatalf("expected 1 message, got %d", len(msgs))
+			}
+
+			summary := msgs[0].Summary()
+			if summary != test.expectedSummary {
+				t.Errorf("expected summary %+v, got %+v", test.expectedSummary, summary)
+			}
 		})
 	}
 }
 
-func BenchmarkParseMTx(b *testing.B) {
-	for _, msgCount := range []int{
-		1,
-		10,
-		100,
-		1000,
-		10000,
-	} {
+func TestMTxString(t *testing.T) {
+	for _, test := range []struct {
+		name           string
+		input          string
+		expectedString string
+	}{
+		{
+			name: "InputMessage",
+			input: `{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:
+:20:REFERENCE
+:25:BPHKPLPK/320000546101
+-}`,
+			expectedString: `MT940 input message
+  Sender:            BPHKPLPKXXXX
+  Receiver:          BOFAUS6BXBAM
+  Priority:          N
+  Reference:         REFERENCE
+  Fields:            20, 25`,
+		},
+		{
+			name: "OutputMessage",
+			input: `{1:F01BPHKPLPKXXXX5712100002}{2:O9401157091028SCBLZAJJXXXX57121000020910291203U}{4:
+:25:BPHKPLPK/320000546101
+-}`,
+			expectedString: `MT940 output message
+  Sender:            SCBLZAJJXXXX
+  Receiver:          BPHKPLPKXXXX
+  Priority:          U
+  Input time:        1157
+  Output date/time:  091029 1203
+  Fields:            25`,
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			msgs, err := mt.ParseAllMTx(ctx, strings.NewReader(test.input))
+			mttest.ValidateError(t, nil, err)
+
+			if len(msgs) != 1 {
+				t.Fatalf("expected 1 message, got %d", len(msgs))
+			}
+
+			if str := msgs[0].String(); str != test.expectedString {
+				t.Errorf("expected string:\n%s\ngot:\n%s", test.expectedString, str)
+			}
+		})
+	}
+}
+
+func TestMTxReferences(t *testing.T) {
+	for _, test := range []struct {
+		name         string
+		input        string
+		expectedRefs map[string]string
+	}{
+		{
+			name: "AllReferences",
+			input: `{1:F01BPHKPLPKXXXX0000000000}{2:I900BOFAUS6BXBAMN}{3:{121:eb6305c9-1f7f-49de-aed0-16487c27b42d}}{4:
+:20:REFERENCE
+:21:RELATED
+:21:SECOND
+-}`,
+			expectedRefs: map[string]string{
+				"20":  "REFERENCE",
+				"21":  "RELATED",
+				"121": "eb6305c9-1f7f-49de-aed0-16487c27b42d",
+			},
+		},
+		{
+			name: "OnlyReference",
+			input: `{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:
+:20:REFERENCE
+:25:BPHKPLPK/320000546101
+-}`,
+			expectedRefs: map[string]string{
+				"20": "REFERENCE",
+			},
+		},
+		{
+			name:         "NoReferences",
+			input:        "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{3:{108:MyRef}}{4:\n:20:\n-}",
+			expectedRefs: map[string]string{},
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			msgs, err := mt.ParseAllMTx(ctx, strings.NewReader(test.input))
+			mttest.ValidateError(t, nil, err)
+
+			if len(msgs) != 1 {
+				t.Fatalf("expected 1 message, got %d", len(msgs))
+			}
+
+			refs := msgs[0].References()
+			if len(refs) != len(test.expectedRefs) {
+				t.Errorf("expected references %v, got %v", test.expectedRefs, refs)
+			}
+			for tag, expected := range test.expectedRefs {
+				if refs[tag] != expected {
+					t.Errorf("expected reference %s to be %q, got %q", tag, expected, refs[tag])
+				}
+			}
+		})
+	}
+}
+
+func TestParsePreserveWhitespace(t *testing.T) {
+	input := "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:\n" +
+		":20:REFERENCE\n" +
+		":86:FIXED WIDTH    \n" +
+		"NARRATIVE   \n" +
+		"-}"
+
+	for _, test := range []struct {
+		name          string
+		preserve      bool
+		expectedValue string
+	}{
+		{
+			name:          "Default",
+			expectedValue: "FIXED WIDTH    \nNARRATIVE",
+		},
+		{
+			name:          "Preserve",
+			preserve:      true,
+			expectedValue: "FIXED WIDTH    \nNARRATIVE   ",
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			msgs, err := mt.ParseAllMTx(ctx, strings.NewReader(input), mt.PreserveWhitespace(test.preserve))
+			mttest.ValidateError(t, nil, err)
+
+			if len(msgs) != 1 {
+				t.Fatalf("expected 1 message, got %d", len(msgs))
+			}
+
+			if value := msgs[0].Body["86"][0]; value != test.expectedValue {
+				t.Errorf("expected field 86 %q, got %q", test.expectedValue, value)
+			}
+
+			if msgs[0].Raw != input {
+				t.Errorf("expected Raw to equal the input:\n%q\ngot:\n%q", input, msgs[0].Raw)
+			}
+		})
+	}
+}
+
+func TestSplitMessages(t *testing.T) {
+	const (
+		first  = "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:\n:20:FIRST\n-}{5:{CHK:123}}"
+		second = "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{3:{108:REF}}{4:\n:20:SECOND\n:86:A:B\n-}"
+	)
+
+	for _, test := range []struct {
+		name             string
+		input            io.Reader
+		expectedErr      error
+		expectedMessages []string
+	}{
+		{
+			name:        "InvalidInput",
+			input:       &mttest.TestReaderInvalid{},
+			expectedErr: mttest.ErrReadInvalid,
+		},
+		{
+			name:             "Empty",
+			input:            strings.NewReader(""),
+			expectedMessages: []string{},
+		},
+		{
+			name:             "BackToBack",
+			input:            strings.NewReader(first + second),
+			expectedMessages: []string{first, second},
+		},
+		{
+			name:             "SurroundingText",
+			input:            strings.NewReader("HEADER\n" + first + "\r\n\r\n" + second + "\nFOOTER"),
+			expectedMessages: []string{first, second},
+		},
+		{
+			name:             "MissingTrailers",
+			input:            strings.NewReader(second + "\n" + second),
+			expectedMessages: []string{second, second},
+		},
+		{
+			name:             "IncompleteLastBlock",
+			input:            strings.NewReader(first + "\n{1:F01BPHKPLPKXXXX0000000000}{2:I940"),
+			expectedMessages: []string{first, "{1:F01BPHKPLPKXXXX0000000000}"},
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			msgs, err := mt.SplitMessages(test.input)
+			mttest.ValidateError(t, test.expectedErr, err)
+
+			if test.expectedErr != nil {
+				return
+			}
+
+			if len(msgs) != len(test.expectedMessages) {
+				t.Fatalf("expected %d messages, got %d", len(test.expectedMessages), len(msgs))
+			}
+			mttest.ValidateStringSlice(t, "Messages", test.expectedMessages, msgs)
+		})
+	}
+}
+
+func TestSplitMessagesMatchesParse(t *testing.T) {
+	t.Parallel()
+
+	msgs, err := mt.SplitMessages(mttest.MustOpenFile("testdata/sample-file-mt940.txt"))
+	mttest.ValidateError(t, nil, err)
+
+	parsed, _ := mt.ParseAllMTx(ctx, mttest.MustOpenFile("testdata/sample-file-mt940.txt"), mt.SkipValidation(true))
+	if len(msgs) != len(parsed) {
+		t.Fatalf("expected %d messages, got %d", len(parsed), len(msgs))
+	}
+
+	for i, msg := range msgs {
+		reparsed, err := mt.ParseAllMTx(ctx, strings.NewReader(msg), mt.SkipValidation(true))
+		mttest.ValidateError(t, nil, err)
+
+		if len(reparsed) != 1 || reparsed[0].Raw != parsed[i].Raw {
+			t.Errorf("message %d: expected split message to parse into the same message", i)
+		}
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	sampleFile, err := os.ReadFile("testdata/sample-file-mt940.txt")
+	if err != nil {
+		t.Fatalf("could not read sample file: %v", err)
+	}
+
+	for _, test := range []struct {
+		name     string
+		input    string
+		limit    int
+		envelope mt.EnvelopeKind
+	}{
+		{
+			name:  "SampleFile",
+			input: string(sampleFile),
+		},
+		{
+			name:  "Repeated",
+			input: strings.Repeat(messageInput, 10),
+		},
+		{
+			name:  "Limit",
+			input: strings.Repeat(messageInput, 10),
+			limit: 3,
+		},
+		{
+			name:  "InvalidAddress",
+			input: "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBA}{4:\n:20:REFERENCE\n-}",
+		},
+		{
+			name:     "RJEEnvelope",
+			input:    messageInput + "\n$\n" + messageInput,
+			envelope: mt.EnvelopeRJE,
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			rd := strings.NewReader(test.input)
+			expectedMsgs, expectedErr := mt.ParseAllMTx(ctx, rd, mt.Limit(test.limit), mt.Envelope(test.envelope))
+
+			msgs, err := mt.ParseBytes([]byte(test.input), mt.Limit(test.limit), mt.Envelope(test.envelope))
+			mttest.ValidateError(t, expectedErr, err)
+
+			if len(msgs) != len(expectedMsgs) {
+				t.Fatalf("expected %d messages, got %d", len(expectedMsgs), len(msgs))
+			}
+
+			for i := range expectedMsgs {
+				if msgs[i].Raw != expectedMsgs[i].Raw {
+					t.Errorf("MTx[%d]: expected Raw %q, got %q", i, expectedMsgs[i].Raw, msgs[i].Raw)
+				}
+				if msgs[i].Line != expectedMsgs[i].Line {
+					t.Errorf("MTx[%d]: expected Line %d, got %d", i, expectedMsgs[i].Line, msgs[i].Line)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkParseMTxParallel(b *testing.B) {
+	for _, msgCount := range []int{
+		1,
+		10,
+		100,
+		1000,
+		10000,
+	} {
+		b.Run(fmt.Sprintf("MessageCount_%d", msgCount), func(b *testing.B) {
+			messages := strings.Repeat(messageInput, msgCount)
+
+			b.ResetTimer()
+
+			b.RunParallel(func(p *testing.PB) {
+				for p.Next() {
+					mt.ParseMTx(ctx, strings.NewReader(messages))
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkParseAllMTxSynchronous(b *testing.B) {
+	for _, msgCount := range []int{
+		1,
+		10,
+		100,
+	} {
+		for _, sync := range []bool{false, true} {
+			b.Run(fmt.Sprintf("MessageCount_%d/Synchronous_%t", msgCount, sync), func(b *testing.B) {
+				messages := strings.Repeat(messageInput, msgCount)
+
+				b.ResetTimer()
+
+				for i := 0; i < b.N; i++ {
+					_, err := mt.ParseAllMTx(ctx, strings.NewReader(messages), mt.Synchronous(sync))
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkParseMTx(b *testing.B) {
+	for _, msgCount := range []int{
+		1,
+		10,
+		100,
+		1000,
+		10000,
+	} {
 		b.Run(fmt.Sprintf("MessageCount_%d", msgCount), func(b *testing.B) {
 			messages := strings.Repeat(messageInput, msgCount)
 
@@ -808,6 +1733,26 @@ func BenchmarkParseMTx(b *testing.B) {
 	}
 }
 
+func BenchmarkParseBytes(b *testing.B) {
+	for _, msgCount := range []int{
+		1,
+		10,
+		100,
+		1000,
+		10000,
+	} {
+		b.Run(fmt.Sprintf("MessageCount_%d", msgCount), func(b *testing.B) {
+			messages := []byte(strings.Repeat(messageInput, msgCount))
+
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				mt.ParseBytes(messages)
+			}
+		})
+	}
+}
+
 func BenchmarkParseAllMTxParallel(b *testing.B) {
 	for _, msgCount := range []int{
 		1,
@@ -849,3 +1794,1356 @@ func BenchmarkParseAllMTx(b *testing.B) {
 		})
 	}
 }
+
+func TestParseHeaders(t *testing.T) {
+	sample, err := os.ReadFile("testdata/sample-file-mt940.txt")
+	if err != nil {
+		t.Fatalf("could not read sample file: %v", err)
+	}
+
+	for _, test := range []struct {
+		name          string
+		input         string
+		expectedCount int
+		expectedErr   error
+	}{
+		{
+			name:          "SampleFile",
+			input:         string(sample),
+			expectedCount: 3,
+		},
+		{
+			name: "SystemMessage",
+			input: "{1:L01BANKBEBBAXXX0000000000}{4:{202:0001}{203:{NESTED:}}}{5:{CHK:123456789ABC}}" +
+				"{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:\n:20:REFERENCE\n-}",
+			expectedCount: 2,
+		},
+		{
+			name:          "TruncatedBody",
+			input:         "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:\n:20:REFERENCE\n",
+			expectedErr:   fmt.Errorf("incomplete"),
+			expectedCount: 0,
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			expected, expectedErr := mt.ParseAllMTx(ctx, strings.NewReader(test.input), mt.Lax(true))
+
+			basesCh, errsCh := mt.ParseHeaders(ctx, strings.NewReader(test.input), mt.Lax(true))
+
+			var errs mt.Errors
+			bases := make([]mt.Base, 0)
+			for basesCh != nil || errsCh != nil {
+				select {
+				case base, ok := <-basesCh:
+					if !ok {
+						basesCh = nil
+						continue
+					}
+					bases = append(bases, base)
+				case err, ok := <-errsCh:
+					if !ok {
+						errsCh = nil
+						continue
+					}
+					errs = append(errs, err)
+				}
+			}
+
+			if test.expectedErr == nil {
+				if len(errs) > 0 {
+					t.Errorf("expected no errors, got: %v", errs)
+				}
+			} else {
+				mttest.ValidateError(t, test.expectedErr, errs)
+				mttest.ValidateError(t, test.expectedErr, expectedErr)
+			}
+
+			if len(bases) != test.expectedCount || len(expected) != test.expectedCount {
+				t.Fatalf("expected %d messages, got %d and %d", test.expectedCount, len(bases), len(expected))
+			}
+
+			for i, base := range bases {
+				if len(base.FieldOrder) != 0 || len(base.PresentFields) != 0 {
+					t.Errorf("Base[%d]: expected no fields, got %v", i, base.FieldOrder)
+				}
+
+				// apart from the fields the headers are expected to be identical to those of the fully parsed messages
+				expectedBase := expected[i].Base
+				expectedBase.FieldOrder = base.FieldOrder
+				expectedBase.PresentFields = base.PresentFields
+				if !reflect.DeepEqual(base, expectedBase) {
+					t.Errorf("Base[%d]: expected %+v, got %+v", i, expectedBase, base)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkParseHeaders(b *testing.B) {
+	sample, err := os.ReadFile("testdata/sample-file-mt940.txt")
+	if err != nil {
+		b.Fatalf("could not read sample file: %v", err)
+	}
+	input := strings.Repeat(string(sample)+"\n", 100)
+
+	// drain reads all messages and errors from the channels, so the whole input is parsed
+	drain := func(msgs interface{}, errs chan mt.Error) {
+		go func() {
+			for range errs {
+			}
+		}()
+
+		switch msgs := msgs.(type) {
+		case chan mt.MTx:
+			for range msgs {
+			}
+		case chan mt.Base:
+			for range msgs {
+			}
+		}
+	}
+
+	b.Run("ParseMTx", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			drain(mt.ParseMTx(ctx, strings.NewReader(input), mt.Synchronous(true)))
+		}
+	})
+
+	b.Run("ParseHeaders", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			drain(mt.ParseHeaders(ctx, strings.NewReader(input)))
+		}
+	})
+}
+
+// TestParseMTxLimit is not run in parallel as it counts the goroutines to verify none are leaked.
+func TestParseMTxLimit(t *testing.T) {
+	goroutinesBefore := runtime.NumGoroutine()
+
+	input := &mttest.TestReaderRepeating{
+		Input: "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:\n:20:REFERENCE\n-}\n",
+	}
+
+	msgs, err := mt.ParseAllMTx(ctx, input, mt.Limit(3))
+	mttest.ValidateError(t, nil, err)
+
+	if len(msgs) != 3 {
+		t.Errorf("expected 3 messages, got %d", len(msgs))
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > goroutinesBefore && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if goroutinesAfter := runtime.NumGoroutine(); goroutinesAfter > goroutinesBefore {
+		t.Errorf("expected no leaked goroutines, got %d before and %d after", goroutinesBefore, goroutinesAfter)
+	}
+}
+
+func TestMTxRaw(t *testing.T) {
+	t.Parallel()
+
+	input := `{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{3:{108:MyRef}}{4:
+:20:REFERENCE
+:25:BPHKPLPK/320000546101
+:28C:00084/001
+:60F:C031002PLN40000,00
+:61:0310201020C20000,00FMSCNONREF//8327000090031789
+Card transaction
+:86:020?00Wyplata-(dysp/przel)
+:61:0310201020D10000,00FTRFREF 25611247//8327000090031790
+:86:020?00Wyplata-(dysp/przel)
+:62F:C020325PLN50040,00
+-}{5:{CHK:123456789ABC}{TNG:}}`
+
+	msgs, err := mt.ParseAllMTx(ctx, strings.NewReader(input))
+	mttest.ValidateError(t, nil, err)
+
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+
+	if msgs[0].Raw != input {
+		t.Errorf("expected Raw to equal the input:\n%s\ngot:\n%s", input, msgs[0].Raw)
+	}
+}
+
+func TestMTxRawVerbatim(t *testing.T) {
+	const (
+		first = "{1:F01BPHKPLPKXXXX0000000000} \t{2:I940BOFAUS6BXBAMN}\r\n{3:{108:MyRef} }{4:\r\n" +
+			":20:  REFERENCE  \r\n" +
+			":86:FIXED WIDTH    \r\n" +
+			"\tNARRATIVE   \r\n" +
+			"-}  {5:{CHK:123456789ABC}{TNG:}}"
+		second = "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:\n:20:SECOND\n\n-}"
+	)
+
+	input := "leading text\n" + first + "\n\n  " + second + "  \ntrailing text"
+
+	msgs, err := mt.ParseAllMTx(ctx, strings.NewReader(input))
+	mttest.ValidateError(t, nil, err)
+
+	if len(msgs) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(msgs))
+	}
+
+	for i, expected := range []string{first, second} {
+		start := strings.Index(input, expected)
+		if msgs[i].Raw != input[start:start+len(expected)] {
+			t.Errorf("message %d: expected Raw to equal the input:\n%q\ngot:\n%q", i, expected, msgs[i].Raw)
+		}
+	}
+}
+
+type limitedWriter struct {
+	remaining int
+}
+
+var errWriteLimitReached = errors.New("write limit reached")
+
+func (fw *limitedWriter) Write(p []byte) (int, error) {
+	if len(p) > fw.remaining {
+		n := fw.remaining
+		fw.remaining = 0
+		return n, errWriteLimitReached
+	}
+
+	fw.remaining -= len(p)
+
+	return len(p), nil
+}
+
+func TestMTxRawBody(t *testing.T) {
+	for _, test := range []struct {
+		name            string
+		input           string
+		expectedRawBody string
+	}{
+		{
+			name:            "Fields",
+			input:           "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:\n:20:REFERENCE\n:86:TIME 12:30\n-}",
+			expectedRawBody: "\n:20:REFERENCE\n:86:TIME 12:30\n",
+		},
+		{
+			name:            "Whitespace",
+			input:           "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:\r\n:20: REFERENCE \r\n-}{5:{CHK:ABCDEF}}",
+			expectedRawBody: "\r\n:20: REFERENCE \r\n",
+		},
+		{
+			name:            "SubBlocks",
+			input:           "{1:L01BPHKPLPKXXXX0000000000}{4:{177:2103151130}{451:0}}",
+			expectedRawBody: "{177:2103151130}{451:0}",
+		},
+		{
+			name:            "Empty",
+			input:           "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:-}",
+			expectedRawBody: "",
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			msgs, err := mt.ParseAllMTx(ctx, strings.NewReader(test.input))
+			mttest.ValidateError(t, nil, err)
+
+			if len(msgs) != 1 {
+				t.Fatalf("expected 1 message, got %d", len(msgs))
+			}
+
+			if msgs[0].RawBody != test.expectedRawBody {
+				t.Errorf("expected RawBody %q, got %q", test.expectedRawBody, msgs[0].RawBody)
+			}
+		})
+	}
+}
+
+func TestMTxWriteTo(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		input string
+	}{
+		{
+			name: "Input",
+			input: `{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{3:{108:MyRef}}{4:
+:20:REFERENCE
+:25:BPHKPLPK/320000546101
+:28C:00084/001
+:60F:C031002PLN40000,00
+:61:0310201020C20000,00FMSCNONREF//8327000090031789
+Card transaction
+:86:020?00Wyplata-(dysp/przel)
+:62F:C020325PLN60000,00
+:86:020?00Wyplata-(dysp/przel)
+-}{5:{CHK:123456789ABC}{TNG:}}`,
+		},
+		{
+			name: "Output",
+			input: `{1:F01BPHKPLPKXXXX0000000000}{2:O9401200031002BOFAUS6BXBAM00000000000310021200N}{4:
+:20:REFERENCE
+:25:BPHKPLPK/320000546101
+:28C:00084/001
+:60F:C031002PLN40000,00
+:62F:C031002PLN40000,00
+-}`,
+		},
+		{
+			name:  "Acknowledgement",
+			input: `{1:F21BANKBEBBAXXX0000000000}{4:{177:2103151130}{451:1}{405:H50}}`,
+		},
+		{
+			name:  "SystemMessage",
+			input: `{1:L01BPHKPLPKXXXX0000000000}{4:{177:2103151130}{451:0}}`,
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// the FIN format prescribes CRLF line breaks
+			input := strings.ReplaceAll(test.input, "\n", "\r\n")
+
+			msgs, err := mt.ParseAllMTx(ctx, strings.NewReader(input))
+			mttest.ValidateError(t, nil, err)
+
+			if len(msgs) != 1 {
+				t.Fatalf("expected 1 message, got %d", len(msgs))
+			}
+
+			sb := &strings.Builder{}
+			n, err := msgs[0].WriteTo(sb)
+			mttest.ValidateError(t, nil, err)
+
+			if n != int64(sb.Len()) {
+				t.Errorf("expected %d bytes written, got %d", sb.Len(), n)
+			}
+
+			marshaled, err := msgs[0].MarshalMT()
+			mttest.ValidateError(t, nil, err)
+
+			if sb.String() != marshaled {
+				t.Errorf("expected WriteTo to write:\n%s\ngot:\n%s", marshaled, sb.String())
+			}
+			if marshaled != input {
+				t.Errorf("expected MarshalMT to return the input:\n%q\ngot:\n%q", input, marshaled)
+			}
+
+			reparsed, err := mt.ParseAllMTx(ctx, strings.NewReader(marshaled))
+			mttest.ValidateError(t, nil, err)
+
+			if len(reparsed) != 1 {
+				t.Fatalf("expected 1 reparsed message, got %d", len(reparsed))
+			}
+
+			original := msgs[0]
+			original.Raw, original.RawBody = "", ""
+			roundTripped := reparsed[0]
+			roundTripped.Raw, roundTripped.RawBody = "", ""
+			if !reflect.DeepEqual(original, roundTripped) {
+				t.Errorf("expected the reparsed message to equal the original:\n%#v\ngot:\n%#v", original, roundTripped)
+			}
+		})
+	}
+}
+
+func TestMTxWriteToLimitedWriter(t *testing.T) {
+	t.Parallel()
+
+	msgs, err := mt.ParseAllMTx(ctx, strings.NewReader(messageInput), mt.Lax(true))
+	if len(msgs) == 0 {
+		t.Fatalf("expected messages to be parsed, got error: %v", err)
+	}
+
+	n, err := msgs[0].WriteTo(&limitedWriter{remaining: 10})
+	if !errors.Is(err, errWriteLimitReached) {
+		t.Errorf("expected write error, got %v", err)
+	}
+	if n != 10 {
+		t.Errorf("expected 10 bytes written, got %d", n)
+	}
+}
+
+func TestMTxWalk(t *testing.T) {
+	t.Parallel()
+
+	input := `{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:
+:20:REFERENCE
+:25:BPHKPLPK/320000546101
+:28C:00084/001
+:60F:C031002PLN40000,00
+:61:0310201020C20000,00FMSCNONREF//8327000090031789
+:86:020?00Wyplata-(dysp/przel)
+:61:0310201020D10000,00FTRFREF 25611247//8327000090031790
+:86:020?00Wyplata-(dysp/przel)
+:62F:C020325PLN50040,00
+-}`
+
+	msgs, err := mt.ParseAllMTx(ctx, strings.NewReader(input))
+	mttest.ValidateError(t, nil, err)
+
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+	original := msgs[0]
+
+	visited := make([]string, 0)
+
+	// redact the account number and the narratives, as is needed to turn production data into test data
+	redacted := original.Walk(func(tag string, index int, value string) string {
+		visited = append(visited, fmt.Sprintf("%s[%d]", tag, index))
+
+		switch tag {
+		case "25":
+			return value[:strings.Index(value, "/")+1] + strings.Repeat("X", len(value)-strings.Index(value, "/")-1)
+		case "86":
+			return fmt.Sprintf("REDACTED %d", index)
+		default:
+			return value
+		}
+	})
+
+	expectedVisited := []string{"20[0]", "25[0]", "28C[0]", "60F[0]", "61[0]", "86[0]", "61[1]", "86[1]", "62F[0]"}
+	if !reflect.DeepEqual(visited, expectedVisited) {
+		t.Errorf("expected visits %v, got %v", expectedVisited, visited)
+	}
+
+	if value := redacted.Body["25"][0]; value != "BPHKPLPK/XXXXXXXXXXXX" {
+		t.Errorf("expected redacted field 25, got %q", value)
+	}
+	if values := redacted.Body["86"]; !reflect.DeepEqual(values, []string{"REDACTED 0", "REDACTED 1"}) {
+		t.Errorf("expected redacted fields 86, got %v", values)
+	}
+	if strings.Contains(redacted.Raw, "320000546101") || !strings.Contains(redacted.Raw, ":86:REDACTED 1\r\n") {
+		t.Errorf("expected Raw to be redacted, got:\n%s", redacted.Raw)
+	}
+	if !strings.Contains(redacted.Raw, "{4:"+redacted.RawBody+"-}") || strings.Contains(redacted.RawBody, "320000546101") {
+		t.Errorf("expected RawBody to be redacted, got:\n%s", redacted.RawBody)
+	}
+
+	if original.Raw != input {
+		t.Errorf("expected the original Raw to be left intact, got:\n%s", original.Raw)
+	}
+	if value := original.Body["25"][0]; value != "BPHKPLPK/320000546101" {
+		t.Errorf("expected the original field 25 to be left intact, got %q", value)
+	}
+	if value := original.Body["86"][1]; value != "020?00Wyplata-(dysp/przel)" {
+		t.Errorf("expected the original field 86 to be left intact, got %q", value)
+	}
+}
+
+func TestMTxClone(t *testing.T) {
+	t.Parallel()
+
+	input := `{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:
+:20:REFERENCE
+:86:INFO 1
+:86:INFO 2
+-}{5:{XYZ:CONTENT}}`
+
+	msgs, err := mt.ParseAllMTx(ctx, strings.NewReader(input))
+	mttest.ValidateError(t, nil, err)
+
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+	original := msgs[0]
+
+	clone := original.Clone()
+	if !reflect.DeepEqual(clone, original) {
+		t.Fatalf("expected clone to equal the original, got %+v", clone)
+	}
+
+	clone.Body["20"][0] = "CHANGED"
+	clone.Body["86"] = append(clone.Body["86"][:1], "CHANGED")
+	clone.Body["21"] = []string{"ADDED"}
+	clone.PresentFields["21"] = true
+	clone.FieldOrder[0] = "21"
+	clone.Trailers.AdditionalTrailers["XYZ"] = "CHANGED"
+
+	if value := original.Body["20"][0]; value != "REFERENCE" {
+		t.Errorf("expected field 20 of the original to be left intact, got %q", value)
+	}
+	if values := original.Body["86"]; !reflect.DeepEqual(values, []string{"INFO 1", "INFO 2"}) {
+		t.Errorf("expected fields 86 of the original to be left intact, got %v", values)
+	}
+	if _, ok := original.Body["21"]; ok || original.IsPresent("21") {
+		t.Errorf("expected field 21 to be absent from the original")
+	}
+	if !reflect.DeepEqual(original.FieldOrder, []string{"20", "86", "86"}) {
+		t.Errorf("expected field order of the original to be left intact, got %v", original.FieldOrder)
+	}
+	if value := original.Trailers.AdditionalTrailers["XYZ"]; value != "CONTENT" {
+		t.Errorf("expected trailer of the original to be left intact, got %q", value)
+	}
+}
+
+func TestValidateAppHeader(t *testing.T) {
+	for _, test := range []struct {
+		name          string
+		in            mt.AppHeaderInput
+		out           mt.AppHeaderOutput
+		expectedError error
+	}{
+		{
+			name: "None",
+		},
+		{
+			name: "Input",
+			in:   mt.AppHeaderInput{Set: true, MessageType: "940"},
+		},
+		{
+			name: "Output",
+			out:  mt.AppHeaderOutput{Set: true, MessageType: "942"},
+		},
+		{
+			name:          "Both",
+			in:            mt.AppHeaderInput{Set: true, MessageType: "940"},
+			out:           mt.AppHeaderOutput{Set: true, MessageType: "940"},
+			expectedError: errors.New("both an input and an output app header are set"),
+		},
+		{
+			name:          "MessageTypeTooShort",
+			in:            mt.AppHeaderInput{Set: true, MessageType: "94"},
+			expectedError: errors.New(`invalid message type "94": expected 3 digits`),
+		},
+		{
+			name:          "MessageTypeNotNumeric",
+			out:           mt.AppHeaderOutput{Set: true, MessageType: "MT9"},
+			expectedError: errors.New(`invalid message type "MT9": expected 3 digits`),
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			mttest.ValidateError(t, test.expectedError, mt.ValidateAppHeader(test.in, test.out))
+		})
+	}
+}
+
+func TestParseTruncatedMessage(t *testing.T) {
+	t.Parallel()
+
+	sample, err := os.ReadFile("testdata/sample-file-mt940.txt")
+	if err != nil {
+		t.Fatalf("could not read sample file: %v", err)
+	}
+
+	// cut off the input within the body of the last message
+	input := string(sample)
+	input = input[:strings.LastIndex(input, ":62F:")]
+
+	full, _ := mt.ParseAllMTx(ctx, strings.NewReader(string(sample)))
+	msgs, err := mt.ParseAllMTx(ctx, strings.NewReader(input))
+	mttest.ValidateError(t, fmt.Errorf("incomplete message: block 4 is not terminated"), err)
+
+	if len(msgs) != len(full)-1 {
+		t.Errorf("expected %d messages, got %d", len(full)-1, len(msgs))
+	}
+}
+
+func TestMaxFieldsPerMessage(t *testing.T) {
+	const small = "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:\n:20:REFERENCE\n-}"
+
+	// a message with an excessive number of fields in between two small messages
+	sb := &strings.Builder{}
+	sb.WriteString(small + "\n{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:\n")
+	for i := 0; i < 1000; i++ {
+		sb.WriteString(":86:INFORMATION\n")
+	}
+	sb.WriteString("-}\n" + small)
+
+	for _, test := range []struct {
+		name          string
+		max           int
+		lazy          bool
+		expectedErr   error
+		expectedCount int
+	}{
+		{
+			name:          "Unbounded",
+			expectedCount: 3,
+		},
+		{
+			name:          "WithinLimit",
+			max:           1000,
+			expectedCount: 3,
+		},
+		{
+			name:          "ExceedsLimit",
+			max:           999,
+			expectedErr:   mt.NewError(fmt.Errorf("message exceeds the maximum of 999 fields"), 4),
+			expectedCount: 2,
+		},
+		{
+			name:          "LazyWithinLimit",
+			max:           1000,
+			lazy:          true,
+			expectedCount: 3,
+		},
+		{
+			name:          "LazyExceedsLimit",
+			max:           999,
+			lazy:          true,
+			expectedErr:   mt.NewError(fmt.Errorf("message exceeds the maximum of 999 fields"), 4),
+			expectedCount: 2,
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			msgs, err := mt.ParseAllMTx(
+				ctx,
+				strings.NewReader(sb.String()),
+				mt.MaxFieldsPerMessage(test.max),
+				mt.LazyBody(test.lazy),
+			)
+			mttest.ValidateError(t, test.expectedErr, err)
+
+			if len(msgs) != test.expectedCount {
+				t.Errorf("expected %d messages, got %d", test.expectedCount, len(msgs))
+			}
+		})
+	}
+}
+
+func TestReportSkipped(t *testing.T) {
+	const small = "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:\n:20:REFERENCE\n-}"
+
+	for _, test := range []struct {
+		name          string
+		input         string
+		report        bool
+		expectedErrs  mt.Errors
+		expectedCount int
+	}{
+		{
+			name:  "NotReported",
+			input: "this is not an MT message\nnor is this\n",
+		},
+		{
+			name:   "NonBlockText",
+			input:  "this is not an MT message\nnor is this\n",
+			report: true,
+			expectedErrs: mt.Errors{
+				mt.NewWarning(fmt.Errorf("skipped 38 bytes of content outside of a message"), 1),
+			},
+		},
+		{
+			name:   "WhitespaceOnly",
+			input:  " \r\n\t\n",
+			report: true,
+		},
+		{
+			name:          "WhitespaceBetweenMessages",
+			input:         small + "\r\n\r\n" + small + "\n",
+			report:        true,
+			expectedCount: 2,
+		},
+		{
+			name:   "TextBetweenMessages",
+			input:  small + "\n\ngarbage\n" + small,
+			report: true,
+			expectedErrs: mt.Errors{
+				mt.NewWarning(fmt.Errorf("skipped 10 bytes of content outside of a message"), 5),
+			},
+			expectedCount: 2,
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			for name, parse := range map[string]func() ([]mt.MTx, error){
+				"ParseAllMTx": func() ([]mt.MTx, error) {
+					return mt.ParseAllMTx(ctx, strings.NewReader(test.input), mt.ReportSkipped(test.report))
+				},
+				"Synchronous": func() ([]mt.MTx, error) {
+					return mt.ParseAllMTx(
+						ctx,
+						strings.NewReader(test.input),
+						mt.ReportSkipped(test.report),
+						mt.Synchronous(true),
+					)
+				},
+				"ParseBytes": func() ([]mt.MTx, error) {
+					return mt.ParseBytes([]byte(test.input), mt.ReportSkipped(test.report))
+				},
+			} {
+				msgs, err := parse()
+				mttest.ValidateErrors(t, test.expectedErrs, err)
+
+				if len(mt.ErrorToErrors(err).WithoutWarnings()) > 0 {
+					t.Errorf("%s: expected only warnings, got: %v", name, err)
+				}
+				if len(msgs) != test.expectedCount {
+					t.Errorf("%s: expected %d messages, got %d", name, test.expectedCount, len(msgs))
+				}
+			}
+		})
+	}
+}
+
+func TestSkippedContent(t *testing.T) {
+	const small = "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:\n:20:REFERENCE\n-}"
+
+	type skipped struct {
+		content string
+		line    int
+	}
+
+	for _, test := range []struct {
+		name     string
+		input    string
+		expected []skipped
+	}{
+		{
+			name:  "NothingSkipped",
+			input: small + "\r\n\r\n" + small + "\n",
+		},
+		{
+			name:  "BetweenMessages",
+			input: small + "\n\ngarbage\n" + small,
+			expected: []skipped{
+				{content: "\n\ngarbage\n", line: 5},
+			},
+		},
+		{
+			name:  "BeforeBetweenAndAfterMessages",
+			input: "header\n" + small + "junk" + small + "\ntrailer\n",
+			expected: []skipped{
+				{content: "header\n", line: 1},
+				{content: "junk", line: 4},
+				{content: "\ntrailer\n", line: 7},
+			},
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			for name, parse := range map[string]func(fn func(string, int)) ([]mt.MTx, error){
+				"ParseAllMTx": func(fn func(string, int)) ([]mt.MTx, error) {
+					return mt.ParseAllMTx(ctx, strings.NewReader(test.input), mt.SkippedContent(fn))
+				},
+				"Synchronous": func(fn func(string, int)) ([]mt.MTx, error) {
+					return mt.ParseAllMTx(ctx, strings.NewReader(test.input), mt.SkippedContent(fn), mt.Synchronous(true))
+				},
+			} {
+				var actual []skipped
+				msgs, err := parse(func(content string, line int) {
+					actual = append(actual, skipped{content: content, line: line})
+				})
+				if err != nil {
+					t.Errorf("%s: expected nil error, got: %v", name, err)
+				}
+				if len(msgs) != 2 {
+					t.Errorf("%s: expected 2 messages, got %d", name, len(msgs))
+				}
+				if !reflect.DeepEqual(actual, test.expected) {
+					t.Errorf("%s: expected skipped content %q, got %q", name, test.expected, actual)
+				}
+			}
+		})
+	}
+}
+
+type traceIDKey struct{}
+
+func TestWithLogger(t *testing.T) {
+	const small = "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:\n:20:REFERENCE\n-}"
+
+	input := small + "\n\ngarbage\n" + small + "{1:F01INVALID}{4:\n:20:REFERENCE\n-}"
+
+	type entry struct {
+		traceID string
+		level   string
+		msg     string
+	}
+
+	for _, test := range []struct {
+		name        string
+		synchronous bool
+	}{
+		{
+			name: "Async",
+		},
+		{
+			name:        "Synchronous",
+			synchronous: true,
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var mu sync.Mutex
+			var entries []entry
+
+			logger := func(ctx context.Context, level, msg string) {
+				traceID, _ := ctx.Value(traceIDKey{}).(string)
+
+				mu.Lock()
+				defer mu.Unlock()
+				entries = append(entries, entry{traceID: traceID, level: level, msg: msg})
+			}
+
+			traceCtx := context.WithValue(ctx, traceIDKey{}, "trace-1")
+			msgs, _ := mt.ParseAllMTx(
+				traceCtx,
+				strings.NewReader(input),
+				mt.WithLogger(logger),
+				mt.Synchronous(test.synchronous),
+			)
+			if len(msgs) != 2 {
+				t.Errorf("expected 2 messages, got %d", len(msgs))
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			var skipped, failed bool
+			for _, e := range entries {
+				if e.traceID != "trace-1" {
+					t.Errorf("expected the context value to be passed, got entry %+v", e)
+				}
+
+				switch {
+				case e.level == "info" && e.msg == "skipped 10 bytes of content outside of a message on line 5":
+					skipped = true
+				case e.level == "error" && strings.Contains(e.msg, "invalid basic header"):
+					failed = true
+				}
+			}
+
+			if !skipped {
+				t.Errorf("expected an info entry for the skipped content, got %+v", entries)
+			}
+			if !failed {
+				t.Errorf("expected an error entry for the invalid message, got %+v", entries)
+			}
+		})
+	}
+}
+
+func TestParseMTxSynchronous(t *testing.T) {
+	sample, err := os.ReadFile("testdata/sample-file-mt940.txt")
+	if err != nil {
+		t.Fatalf("could not read sample file: %v", err)
+	}
+	input := string(sample) + "\n{1:F01INVALID}{4:\n:20:REFERENCE\n-}"
+
+	for _, test := range []struct {
+		name  string
+		limit int
+	}{
+		{
+			name: "Default",
+		},
+		{
+			name:  "Limit",
+			limit: 1,
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			expectedMsgs, expectedErr := mt.ParseAllMTx(ctx, strings.NewReader(input), mt.Limit(test.limit))
+			msgs, err := mt.ParseAllMTx(ctx, strings.NewReader(input), mt.Limit(test.limit), mt.Synchronous(true))
+
+			if len(msgs) != len(expectedMsgs) {
+				t.Fatalf("expected %d messages, got %d", len(expectedMsgs), len(msgs))
+			}
+			for i := range msgs {
+				if msgs[i].Raw != expectedMsgs[i].Raw {
+					t.Errorf("message %d: expected Raw %s, got %s", i, expectedMsgs[i].Raw, msgs[i].Raw)
+				}
+			}
+
+			mttest.ValidateErrors(t, mt.ErrorToErrors(expectedErr), err)
+		})
+	}
+}
+
+func TestParseSystemMessages(t *testing.T) {
+	for _, test := range []struct {
+		name           string
+		input          string
+		expectedErr    error
+		expectedSystem bool
+		expectedType   string
+		expectedBody   map[string][]string
+		expectedOrder  []string
+	}{
+		{
+			name:           "Login",
+			input:          "{1:L01BANKBEBBAXXX0000000000}{4:{108:REFERENCE}}",
+			expectedSystem: true,
+			expectedBody:   map[string][]string{"108": {"REFERENCE"}},
+			expectedOrder:  []string{"108"},
+		},
+		{
+			name:           "GeneralPurpose",
+			input:          "{1:A01BANKBEBBAXXX0000000000}{2:I001BANKBEBBXXXXN}{4:\n:20:REFERENCE\n-}",
+			expectedSystem: true,
+			expectedType:   "001",
+			expectedBody:   map[string][]string{"20": {"REFERENCE"}},
+			expectedOrder:  []string{"20"},
+		},
+		{
+			name:           "CategoryZero",
+			input:          "{1:F01BANKBEBBAXXX0000000000}{2:I020BANKBEBBXXXXN}{4:{202:0001}{203:0002}{202:0003}}",
+			expectedSystem: true,
+			expectedType:   "020",
+			expectedBody:   map[string][]string{"202": {"0001", "0003"}, "203": {"0002"}},
+			expectedOrder:  []string{"202", "203", "202"},
+		},
+		{
+			name:          "Financial",
+			input:         "{1:F01BANKBEBBAXXX0000000000}{2:I940BANKBEBBXXXXN}{4:\n:20:REFERENCE\n-}",
+			expectedType:  "940",
+			expectedBody:  map[string][]string{"20": {"REFERENCE"}},
+			expectedOrder: []string{"20"},
+		},
+		{
+			name:        "FinancialWithoutAppHeader",
+			input:       "{1:F01BANKBEBBAXXX0000000000}{4:\n:20:REFERENCE\n-}",
+			expectedErr: mt.NewError(fmt.Errorf("invalid app header: invalid app header block content length: 0"), 1),
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			msgs, err := mt.ParseAllMTx(ctx, strings.NewReader(test.input))
+			mttest.ValidateError(t, test.expectedErr, err)
+
+			if test.expectedErr != nil {
+				return
+			}
+
+			if len(msgs) != 1 {
+				t.Fatalf("expected 1 message, got %d", len(msgs))
+			}
+
+			msg := msgs[0]
+			if msg.IsSystemMessage() != test.expectedSystem {
+				t.Errorf("expected IsSystemMessage to be %t, got %t", test.expectedSystem, msg.IsSystemMessage())
+			}
+			if msg.Type() != test.expectedType {
+				t.Errorf("expected type %q, got %q", test.expectedType, msg.Type())
+			}
+			if !reflect.DeepEqual(msg.Body, test.expectedBody) {
+				t.Errorf("expected body %v, got %v", test.expectedBody, msg.Body)
+			}
+			if !reflect.DeepEqual(msg.FieldOrder, test.expectedOrder) {
+				t.Errorf("expected field order %v, got %v", test.expectedOrder, msg.FieldOrder)
+			}
+			if msg.Raw != test.input {
+				t.Errorf("expected raw %q, got %q", test.input, msg.Raw)
+			}
+		})
+	}
+}
+
+func TestForEachMTx(t *testing.T) {
+	errAbort := errors.New("abort")
+
+	sample, err := os.ReadFile("testdata/sample-file-mt940.txt")
+	if err != nil {
+		t.Fatalf("could not read sample file: %v", err)
+	}
+	input := string(sample) + "\n{1:F01INVALID}{4:\n:20:REFERENCE\n-}"
+
+	for _, test := range []struct {
+		name           string
+		input          string
+		abortAfter     int
+		expectedErr    error
+		expectedCalls  int
+		expectedErrors int
+	}{
+		{
+			name:           "All",
+			input:          input,
+			expectedCalls:  3,
+			expectedErrors: 2,
+		},
+		{
+			name:          "AbortEarly",
+			input:         input,
+			abortAfter:    1,
+			expectedErr:   errAbort,
+			expectedCalls: 1,
+		},
+		{
+			name:  "Empty",
+			input: "",
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			calls, errCount := 0, 0
+
+			err := mt.ForEachMTx(ctx, strings.NewReader(test.input), func(mtx mt.MTx) error {
+				calls++
+				if calls == test.abortAfter {
+					return errAbort
+				}
+				return nil
+			}, func(err mt.Error) {
+				errCount++
+			})
+
+			if !errors.Is(err, test.expectedErr) {
+				t.Errorf("expected error %v, got %v", test.expectedErr, err)
+			}
+			if calls != test.expectedCalls {
+				t.Errorf("expected %d calls, got %d", test.expectedCalls, calls)
+			}
+			if test.expectedErr == nil && errCount != test.expectedErrors {
+				t.Errorf("expected %d errors, got %d", test.expectedErrors, errCount)
+			}
+		})
+	}
+}
+
+func TestForEachMTxCancelled(t *testing.T) {
+	t.Parallel()
+
+	cancelledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	err := mt.ForEachMTx(cancelledCtx, strings.NewReader(messageInput), func(mtx mt.MTx) error {
+		return nil
+	}, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestContinued(t *testing.T) {
+	const header = "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}"
+
+	for _, test := range []struct {
+		name              string
+		input             string
+		expectedErr       error
+		expectedContinued []bool
+		expectedRawBody   string
+	}{
+		{
+			name:              "Terminated",
+			input:             header + "{4:\n:20:REFERENCE\n:86:PART ONE\n-}",
+			expectedContinued: []bool{false},
+			expectedRawBody:   "\n:20:REFERENCE\n:86:PART ONE\n",
+		},
+		{
+			name:  "Continued",
+			input: header + "{4:\n:20:REFERENCE\n:86:PART ONE\n+}" + header + "{4:\n:20:REFERENCE\n-}",
+			expectedErr: fmt.Errorf(
+				"warning: message body is continued in a subsequent transmission: terminated by +} rather than -}",
+			),
+			expectedContinued: []bool{true, false},
+			expectedRawBody:   "\n:20:REFERENCE\n:86:PART ONE\n",
+		},
+		{
+			name:        "Truncated",
+			input:       header + "{4:\n:20:REFERENCE\n:86:PART ONE\n",
+			expectedErr: fmt.Errorf("incomplete message: block 4 is not terminated"),
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			msgs, err := mt.ParseAllMTx(ctx, strings.NewReader(test.input))
+			mttest.ValidateError(t, test.expectedErr, err)
+
+			if len(msgs) != len(test.expectedContinued) {
+				t.Fatalf("expected %d messages, got %d", len(test.expectedContinued), len(msgs))
+			}
+
+			for i, msg := range msgs {
+				if msg.Continued != test.expectedContinued[i] {
+					t.Errorf("expected message %d to have continued %t, got %t", i, test.expectedContinued[i], msg.Continued)
+				}
+			}
+
+			if len(msgs) == 0 {
+				return
+			}
+
+			if msgs[0].RawBody != test.expectedRawBody {
+				t.Errorf("expected raw body %q, got %q", test.expectedRawBody, msgs[0].RawBody)
+			}
+			if actual := msgs[0].Body["86"]; !reflect.DeepEqual(actual, []string{"PART ONE"}) {
+				t.Errorf("expected field 86 to hold PART ONE, got %q", actual)
+			}
+		})
+	}
+}
+
+func TestLazyBody(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		file string
+	}{
+		{
+			name: "MT940",
+			file: "testdata/sample-file-mt940.txt",
+		},
+		{
+			name: "MT942",
+			file: "testdata/sample-file-mt942.txt",
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			input, err := os.ReadFile(test.file)
+			if err != nil {
+				t.Fatalf("could not read sample file: %v", err)
+			}
+
+			for _, preserveWhitespace := range []bool{false, true} {
+				eager, err := mt.ParseAllMTx(
+					ctx,
+					bytes.NewReader(input),
+					mt.PreserveWhitespace(preserveWhitespace),
+					mt.StatementPageNumber(true),
+				)
+				mttest.ValidateError(t, nil, err)
+
+				lazy, err := mt.ParseAllMTx(
+					ctx,
+					bytes.NewReader(input),
+					mt.PreserveWhitespace(preserveWhitespace),
+					mt.StatementPageNumber(true),
+					mt.LazyBody(true),
+				)
+				mttest.ValidateError(t, nil, err)
+
+				if len(lazy) != len(eager) || len(lazy) == 0 {
+					t.Fatalf("expected %d messages, got %d", len(eager), len(lazy))
+				}
+
+				for i := range lazy {
+					if lazy[i].Body != nil {
+						t.Errorf("message %d: expected body not to be loaded, got %v", i, lazy[i].Body)
+					}
+
+					for tag, values := range eager[i].Body {
+						if actual := lazy[i].Field(tag); !reflect.DeepEqual(actual, values) {
+							t.Errorf("message %d: expected field %s to hold %q, got %q", i, tag, values, actual)
+						}
+					}
+					if actual := lazy[i].Field("99"); actual != nil {
+						t.Errorf("message %d: expected absent field to be nil, got %q", i, actual)
+					}
+
+					for tag := range eager[i].Body {
+						if !lazy[i].IsPresent(tag) {
+							t.Errorf("message %d: expected field %s to be present", i, tag)
+						}
+					}
+					if lazy[i].IsPresent("99") {
+						t.Errorf("message %d: expected field 99 not to be present", i)
+					}
+
+					iterated := make(map[string][]string)
+					for tag, values := range lazy[i].Fields() {
+						iterated[tag] = values
+					}
+					if !reflect.DeepEqual(iterated, eager[i].Body) {
+						t.Errorf("message %d: expected iterated fields %v, got %v", i, eager[i].Body, iterated)
+					}
+
+					// methods needing all fields load the body by themselves
+					if lazy[i].String() != eager[i].String() {
+						t.Errorf("message %d: expected string:\n%s\ngot:\n%s", i, eager[i], lazy[i])
+					}
+
+					lazy[i].LoadBody()
+
+					if !reflect.DeepEqual(lazy[i].Body, eager[i].Body) {
+						t.Errorf("message %d: expected body %v, got %v", i, eager[i].Body, lazy[i].Body)
+					}
+					if !reflect.DeepEqual(lazy[i].FieldOrder, eager[i].FieldOrder) {
+						t.Errorf("message %d: expected field order %v, got %v", i, eager[i].FieldOrder, lazy[i].FieldOrder)
+					}
+					if !reflect.DeepEqual(lazy[i].PresentFields, eager[i].PresentFields) {
+						t.Errorf(
+							"message %d: expected present fields %v, got %v", i, eager[i].PresentFields, lazy[i].PresentFields,
+						)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestLazyBodyTyped(t *testing.T) {
+	t.Parallel()
+
+	input, err := os.ReadFile("testdata/sample-file-mt940.txt")
+	if err != nil {
+		t.Fatalf("could not read sample file: %v", err)
+	}
+
+	eager, eagerErr := mt.ParseAllMT940(ctx, bytes.NewReader(input), mt.Lax(true))
+	lazy, lazyErr := mt.ParseAllMT940(ctx, bytes.NewReader(input), mt.Lax(true), mt.LazyBody(true))
+
+	if fmt.Sprint(lazyErr) != fmt.Sprint(eagerErr) {
+		t.Errorf("expected error %v, got %v", eagerErr, lazyErr)
+	}
+	if !reflect.DeepEqual(lazy, eager) {
+		t.Errorf("expected messages %v, got %v", eager, lazy)
+	}
+}
+
+func BenchmarkLazyBody(b *testing.B) {
+	input := "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:\n:20:REFERENCE\n" +
+		strings.Repeat(":61:0310201020C20000,00FMSCNONREF\n:86:TRANSACTION DETAILS\n", 500) + "-}"
+
+	for _, lazy := range []bool{false, true} {
+		b.Run(fmt.Sprintf("LazyBody_%t", lazy), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				msgs, err := mt.ParseAllMTx(ctx, strings.NewReader(input), mt.LazyBody(lazy))
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				if ref := msgs[0].Field("20"); len(ref) != 1 {
+					b.Fatalf("expected 1 reference, got %d", len(ref))
+				}
+			}
+		})
+	}
+}
+
+func TestSessionSequenceNumbers(t *testing.T) {
+	for _, test := range []struct {
+		name          string
+		basicHeader   string
+		trailers      string
+		strict        bool
+		expectedErr   error
+		expectedCount int
+	}{
+		{
+			name:          "Numeric",
+			basicHeader:   "F01BPHKPLPKXXXX1234567890",
+			strict:        true,
+			expectedCount: 1,
+		},
+		{
+			name:        "AlphaInSessionNumber",
+			basicHeader: "F01BPHKPLPKXXXX12A4567890",
+			strict:      true,
+			expectedErr: fmt.Errorf("invalid basic header: session number must consist of 4 digits, got: 12A4"),
+		},
+		{
+			name:        "AlphaInSequenceNumber",
+			basicHeader: "F01BPHKPLPKXXXX123456789O",
+			strict:      true,
+			expectedErr: fmt.Errorf("invalid basic header: sequence number must consist of 6 digits, got: 56789O"),
+		},
+		{
+			name:          "NotStrict",
+			basicHeader:   "F01BPHKPLPKXXXX12A4567890",
+			expectedCount: 1,
+		},
+		{
+			name:          "NumericReferences",
+			basicHeader:   "F01BPHKPLPKXXXX1234567890",
+			trailers:      "{5:{PDE:1348120811BANKFRPPAXXX2222123456}{PDM:12131348120811BANKFRPPAXXX2222123456}}",
+			strict:        true,
+			expectedCount: 1,
+		},
+		{
+			name:        "AlphaInOutputReferenceSessionNumber",
+			basicHeader: "F01BPHKPLPKXXXX1234567890",
+			trailers:    "{5:{PDM:1348120811BANKFRPPAXXX22X2123456}}",
+			strict:      true,
+			expectedErr: fmt.Errorf(
+				"invalid possible duplicate message output reference: session number must consist of 4 digits, got: 22X2",
+			),
+		},
+		{
+			name:        "AlphaInOutputReferenceSequenceNumber",
+			basicHeader: "F01BPHKPLPKXXXX1234567890",
+			trailers:    "{5:{PDM:12131348120811BANKFRPPAXXX222212345X}}",
+			strict:      true,
+			expectedErr: fmt.Errorf(
+				"invalid possible duplicate message output reference: sequence number must consist of 6 digits, got: 12345X",
+			),
+		},
+		{
+			name:        "SpaceInInputReferenceSequenceNumber",
+			basicHeader: "F01BPHKPLPKXXXX1234567890",
+			trailers:    "{5:{PDE:1348120811BANKFRPPAXXX2222 23456}}",
+			strict:      true,
+			expectedErr: fmt.Errorf(
+				"invalid possible duplicate emission message input reference: sequence number must consist of 6 digits",
+			),
+		},
+		{
+			name:          "ReferencesNotStrict",
+			basicHeader:   "F01BPHKPLPKXXXX1234567890",
+			trailers:      "{5:{PDM:1348120811BANKFRPPAXXX22X2123456}}",
+			expectedCount: 1,
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			input := "{1:" + test.basicHeader + "}{2:I940BOFAUS6BXBAMN}{4:\n:20:REFERENCE\n-}" + test.trailers
+
+			msgs, err := mt.ParseAllMTx(ctx, strings.NewReader(input), mt.Strict(test.strict))
+			mttest.ValidateError(t, test.expectedErr, err)
+
+			if len(msgs) != test.expectedCount {
+				t.Errorf("expected %d messages, got %d", test.expectedCount, len(msgs))
+			}
+		})
+	}
+}
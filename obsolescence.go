@@ -0,0 +1,51 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt
+
+import "fmt"
+
+const (
+	// maxObsolescenceFactorUrgent is the highest obsolescence period factor allowed for urgent messages, 15 minutes.
+	maxObsolescenceFactorUrgent = 3
+	// maxObsolescenceFactorNormal is the highest obsolescence period factor allowed for all other messages, 100
+	// minutes.
+	maxObsolescenceFactorNormal = 20
+)
+
+// maxObsolescenceFactor returns the highest obsolescence period factor the specification allows for the priority.
+func maxObsolescenceFactor(p Priority) int {
+	if p == PriorityUrgent {
+		return maxObsolescenceFactorUrgent
+	}
+
+	return maxObsolescenceFactorNormal
+}
+
+// validateObsolescencePeriod verifies the obsolescence period of an input message doesn't exceed the one defined for
+// its priority, 003 for urgent messages and 020 for other messages, in units of 5 minutes. A message without an
+// obsolescence period passes.
+func validateObsolescencePeriod(b Base) error {
+	if !b.IsInput() || b.AppHeaderInput.ObsolescencePeriodInMinutes == 0 {
+		return nil
+	}
+
+	priority := b.AppHeaderInput.MessagePriority
+	factor := b.AppHeaderInput.ObsolescencePeriodInMinutes / obsolescenceMinutesPerFactor
+	maxFactor := maxObsolescenceFactor(priority)
+
+	if factor > maxFactor {
+		return fmt.Errorf(
+			"invalid app header: obsolescence period %03d (%d minutes) exceeds %03d (%d minutes) for priority %s",
+			factor,
+			factor*obsolescenceMinutesPerFactor,
+			maxFactor,
+			maxFactor*obsolescenceMinutesPerFactor,
+			priority,
+		)
+	}
+
+	return nil
+}
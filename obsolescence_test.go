@@ -0,0 +1,89 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DennisVis/mt"
+	mttest "github.com/DennisVis/mt/testdata"
+)
+
+func TestObsolescencePeriod(t *testing.T) {
+	for _, test := range []struct {
+		name          string
+		appHeader     string
+		strict        bool
+		expectedErr   error
+		expectedCount int
+	}{
+		{
+			name:          "UrgentWithinBounds",
+			appHeader:     "I940BOFAUS6BXBAMU2003",
+			strict:        true,
+			expectedCount: 1,
+		},
+		{
+			name:      "UrgentOutOfBounds",
+			appHeader: "I940BOFAUS6BXBAMU2004",
+			strict:    true,
+			expectedErr: fmt.Errorf(
+				"invalid app header: obsolescence period 004 (20 minutes) exceeds 003 (15 minutes) for priority U",
+			),
+		},
+		{
+			name:          "NormalWithinBounds",
+			appHeader:     "I940BOFAUS6BXBAMN2020",
+			strict:        true,
+			expectedCount: 1,
+		},
+		{
+			name:      "NormalOutOfBounds",
+			appHeader: "I940BOFAUS6BXBAMN2999",
+			strict:    true,
+			expectedErr: fmt.Errorf(
+				"invalid app header: obsolescence period 999 (4995 minutes) exceeds 020 (100 minutes) for priority N",
+			),
+		},
+		{
+			name:      "NoPriorityOutOfBounds",
+			appHeader: "I940BOFAUS6BXBAM021",
+			strict:    true,
+			expectedErr: fmt.Errorf(
+				"invalid app header: obsolescence period 021 (105 minutes) exceeds 020 (100 minutes) for priority N",
+			),
+		},
+		{
+			name:          "NoObsolescencePeriod",
+			appHeader:     "I940BOFAUS6BXBAMU",
+			strict:        true,
+			expectedCount: 1,
+		},
+		{
+			name:          "NotStrict",
+			appHeader:     "I940BOFAUS6BXBAMN2999",
+			expectedCount: 1,
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			input := "{1:F01BPHKPLPKXXXX0000000000}{2:" + test.appHeader + "}{4:\n:20:REFERENCE\n-}"
+
+			msgs, err := mt.ParseAllMTx(ctx, strings.NewReader(input), mt.Strict(test.strict))
+			mttest.ValidateError(t, test.expectedErr, err)
+
+			if len(msgs) != test.expectedCount {
+				t.Errorf("expected %d messages, got %d", test.expectedCount, len(msgs))
+			}
+		})
+	}
+}
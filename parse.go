@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/DennisVis/mt/internal/message"
 )
@@ -66,6 +67,62 @@ func basicHeaderBlockToBasicHeader(block message.Block) (BasicHeader, error) {
 	return msgBscHeader, nil
 }
 
+// validateSessionSequenceNumbers verifies the session numbers (4!n) and sequence numbers (6!n) in the basic header and
+// in the message input and output references present consist of digits only. They are kept as strings, as their
+// leading zeros are significant.
+func validateSessionSequenceNumbers(b Base) error {
+	type sessionSequence struct {
+		name     string
+		set      bool
+		session  string
+		sequence string
+	}
+
+	trl := b.Trailers
+	pde := trl.PossibleDuplicateEmission.MessageInputReference
+	pdm := trl.PossibleDuplicateMessage.MessageOutputReference
+	sys := trl.SystemOriginatedMessage.MessageInputReference
+	mrf := trl.MessageReference.MessageInputReference
+	ahMIR := b.AppHeaderOutput.MessageInputReference
+	uhMIR := b.UsrHeader.MessageInputReference
+
+	numbers := []sessionSequence{
+		{"basic header", true, b.BasicHeader.SessionNumber, b.BasicHeader.SequenceNumber},
+		{"application header message input reference", ahMIR.Set, ahMIR.SessionNumber, ahMIR.SequenceNumber},
+		{"user header message input reference", uhMIR.Set, uhMIR.SessionNumber, uhMIR.SequenceNumber},
+		{"message reference message input reference", mrf.Set, mrf.SessionNumber, mrf.SequenceNumber},
+		{"possible duplicate emission message input reference", pde.Set, pde.SessionNumber, pde.SequenceNumber},
+		{"possible duplicate message output reference", pdm.Set, pdm.SessionNumber, pdm.SequenceNumber},
+		{"system originated message input reference", sys.Set, sys.SessionNumber, sys.SequenceNumber},
+	}
+
+	for _, n := range numbers {
+		if !n.set {
+			continue
+		}
+
+		if strings.IndexFunc(n.session, isNotDigit) >= 0 {
+			return fmt.Errorf(
+				"invalid %s: session number must consist of %d digits, got: %s",
+				n.name,
+				sessionNumberLength,
+				n.session,
+			)
+		}
+
+		if strings.IndexFunc(n.sequence, isNotDigit) >= 0 {
+			return fmt.Errorf(
+				"invalid %s: sequence number must consist of %d digits, got: %s",
+				n.name,
+				sequenceNumberLength,
+				n.sequence,
+			)
+		}
+	}
+
+	return nil
+}
+
 // 120811BANKFRPPAXXX2222123456
 func stringToMessageInputReferenceDate(str string) (InputReference, error) {
 	mird := InputReference{
@@ -73,7 +130,7 @@ func stringToMessageInputReferenceDate(str string) (InputReference, error) {
 		Raw: str,
 	}
 
-	if len(str) != 28 {
+	if len(str) != inputReferenceLength {
 		return mird, fmt.Errorf("invalid message input reference with date string length: %d", len(str))
 	}
 
@@ -86,20 +143,40 @@ func stringToMessageInputReferenceDate(str string) (InputReference, error) {
 	mird.DateOrDateTime = date
 
 	mird.LogicalTerminalAddress = str[6:18]
-	mird.SessionNumber = str[18:22]
-	mird.SequenceNumber = str[22:]
+	mird.SessionNumber, mird.SequenceNumber = stringToSessionSequence(str[18:])
 
 	return mird, nil
 }
 
-// 1806271539180626BANKFRPPAXXX2222123456
+const (
+	sessionNumberLength  = 4
+	sequenceNumberLength = 6
+)
+
+// stringToSessionSequence splits the session number, 4!n, and the sequence number, 6!n, that end the message input
+// and output references. Their digits are only verified when the Strict option is passed, see
+// validateSessionSequenceNumbers.
+func stringToSessionSequence(str string) (session, sequence string) {
+	return str[:sessionNumberLength], str[sessionNumberLength:]
+}
+
+// 1806271539180626BANKFRPPAXXX2222123456(REFERENCE)
 func stringToMessageReference(str string) (Reference, error) {
+	const (
+		dateTimeLength = 10
+		mirLength      = 28
+	)
+
 	mr := Reference{
 		Set: true,
 		Raw: str,
 	}
 
-	dateTimeStr := str[0:10]
+	if len(str) < dateTimeLength+mirLength {
+		return mr, fmt.Errorf("invalid message reference string length: %d", len(str))
+	}
+
+	dateTimeStr := str[0:dateTimeLength]
 	var dateTime DateTime
 	err := dateTime.UnmarshalMT(dateTimeStr)
 	if err != nil {
@@ -107,100 +184,135 @@ func stringToMessageReference(str string) (Reference, error) {
 	}
 	mr.DateTime = dateTime
 
-	mir, err := stringToMessageInputReferenceDate(str[10:])
+	mirStr := str[dateTimeLength : dateTimeLength+mirLength]
+	mir, err := stringToMessageInputReferenceDate(mirStr)
 	if err != nil {
-		return mr, fmt.Errorf("invalid message reference message input reference: %s: %w", str[10:], err)
+		return mr, fmt.Errorf("invalid message reference message input reference: %s: %w", mirStr, err)
 	}
 	mr.MessageInputReference = mir
 
+	// anything following the message input reference is the optional delivery reference
+	mr.DeliveryReference = str[dateTimeLength+mirLength:]
+
 	return mr, nil
 }
 
-// (1348)120811BANKFRPPAXXX2222123456
+const (
+	// trailerTimeLength is the length of the time, HHMM, preceding the reference in the PDE, PDM and SYS trailers.
+	trailerTimeLength = 4
+	// inputReferenceLength is the length of a message input reference with date, YYMMDD followed by the logical
+	// terminal address, the session number and the sequence number.
+	inputReferenceLength = 28
+	// outputReferenceLength is the length of a message output reference with date. A message output reference can
+	// also be preceded by a time, making it trailerTimeLength longer.
+	outputReferenceLength = 28
+)
+
+// 120811BANKFRPPAXXX2222123456 or (1348)120811BANKFRPPAXXX2222123456
 func stringToMessageOutputReference(str string) (OutputReference, error) {
 	mor := OutputReference{
 		Set: true,
 		Raw: str,
 	}
 
+	var dateTimeStr string
 	switch len(str) {
-	case 28:
-		dateTimeStr := str[0:6]
-		var dateTime DateOrDateTime
-		err := dateTime.UnmarshalMT(dateTimeStr)
-		if err != nil {
-			return mor, fmt.Errorf("invalid message output reference date/time string: %s: %w", dateTime, err)
-		}
-		mor.DateOrDateTime = dateTime
-
-		mor.LogicalTerminalAddress = str[6:18]
-		mor.SessionNumber = str[18:23]
-		mor.SequenceNumber = str[23:]
-	case 32:
-		dateTimeStr := str[4:10] + str[0:4]
-		var dateTime DateOrDateTime
-		err := dateTime.UnmarshalMT(dateTimeStr)
-		if err != nil {
-			return mor, fmt.Errorf("invalid message output reference date/time string: %s: %w", dateTime, err)
-		}
-		mor.DateOrDateTime = dateTime
+	case outputReferenceLength:
+		dateTimeStr = str[0:6]
+	case trailerTimeLength + outputReferenceLength:
+		// the time precedes the date, the date/time is formatted with the date first
+		dateTimeStr = str[trailerTimeLength:trailerTimeLength+6] + str[0:trailerTimeLength]
+		str = str[trailerTimeLength:]
+	default:
+		return mor, fmt.Errorf(
+			"invalid message output reference string length: %d, expected %d or %d",
+			len(str),
+			outputReferenceLength,
+			trailerTimeLength+outputReferenceLength,
+		)
+	}
 
-		mor.LogicalTerminalAddress = str[10:22]
-		mor.SessionNumber = str[22:27]
-		mor.SequenceNumber = str[27:]
+	var dateTime DateOrDateTime
+	err := dateTime.UnmarshalMT(dateTimeStr)
+	if err != nil {
+		return mor, fmt.Errorf("invalid message output reference date/time string: %s: %w", dateTimeStr, err)
 	}
+	mor.DateOrDateTime = dateTime
+
+	mor.LogicalTerminalAddress = str[6:18]
+	mor.SessionNumber, mor.SequenceNumber = stringToSessionSequence(str[18:])
 
 	return mor, nil
 }
 
+// stringToTrailerTime parses the time at the start of the content of the PDE, PDM and SYS trailers.
+func stringToTrailerTime(str string) (Time, error) {
+	timeStr := str[0:trailerTimeLength]
+
+	var time Time
+	err := time.UnmarshalMT(timeStr)
+	if err != nil {
+		return time, fmt.Errorf("%s: %w", timeStr, err)
+	}
+
+	return time, nil
+}
+
 // 1348120811BANKFRPPAXXX2222123456
 func stringToPossibleDuplicateEmission(str string) (PossibleDuplicateEmission, error) {
 	pde := PossibleDuplicateEmission{
 		Raw: str,
 	}
 
-	if len(str) != 32 {
-		return pde, fmt.Errorf("invalid possible duplicate emission string length: %d", len(str))
+	if len(str) != trailerTimeLength+inputReferenceLength {
+		return pde, fmt.Errorf(
+			"invalid possible duplicate emission string length: %d, expected %d",
+			len(str),
+			trailerTimeLength+inputReferenceLength,
+		)
 	}
 
-	timeStr := str[0:4]
-	var time Time
-	err := time.UnmarshalMT(timeStr)
+	time, err := stringToTrailerTime(str)
 	if err != nil {
-		return pde, fmt.Errorf("invalid possible duplicate emission time: %s: %w", timeStr, err)
+		return pde, fmt.Errorf("invalid possible duplicate emission time: %w", err)
 	}
 	pde.Time = time
 
-	mir, err := stringToMessageInputReferenceDate(str[4:])
+	mirStr := str[trailerTimeLength:]
+	mir, err := stringToMessageInputReferenceDate(mirStr)
 	if err != nil {
-		return pde, fmt.Errorf("invalid possible duplicate emission message input reference: %s: %w", str[4:], err)
+		return pde, fmt.Errorf("invalid possible duplicate emission message input reference: %s: %w", mirStr, err)
 	}
 	pde.MessageInputReference = mir
 
 	return pde, nil
 }
 
-// 1213120811BANKFRPPAXXX2222123456
+// 1213120811BANKFRPPAXXX2222123456 or 12131348120811BANKFRPPAXXX2222123456
 func stringToPossibleDuplicateMessage(str string) (PossibleDuplicateMessage, error) {
 	pdm := PossibleDuplicateMessage{
 		Raw: str,
 	}
 
-	if len(str) != 32 && len(str) != 36 {
-		return pdm, fmt.Errorf("invalid possible duplicate message string length: %d", len(str))
+	if len(str) != trailerTimeLength+outputReferenceLength && len(str) != 2*trailerTimeLength+outputReferenceLength {
+		return pdm, fmt.Errorf(
+			"invalid possible duplicate message string length: %d, expected %d or %d",
+			len(str),
+			trailerTimeLength+outputReferenceLength,
+			2*trailerTimeLength+outputReferenceLength,
+		)
 	}
 
-	timeStr := str[0:4]
-	var time Time
-	err := time.UnmarshalMT(timeStr)
+	time, err := stringToTrailerTime(str)
 	if err != nil {
-		return pdm, fmt.Errorf("invalid possible duplicate message time: %s: %w", timeStr, err)
+		return pdm, fmt.Errorf("invalid possible duplicate message time: %w", err)
 	}
 	pdm.Time = time
 
-	mor, err := stringToMessageOutputReference(str[4:])
+	morStr := str[trailerTimeLength:]
+	mor, err := stringToMessageOutputReference(morStr)
 	if err != nil {
-		return pdm, fmt.Errorf("invalid possible duplicate message message output reference: %s: %w", str[4:], err)
+		return pdm, fmt.Errorf("invalid possible duplicate message message output reference: %s: %w", morStr, err)
 	}
 	pdm.MessageOutputReference = mor
 
@@ -213,21 +325,24 @@ func stringToSystemOriginatedMessage(str string) (SystemOriginatedMessage, error
 		Raw: str,
 	}
 
-	if len(str) != 32 {
-		return som, fmt.Errorf("invalid system originated message string length: %d", len(str))
+	if len(str) != trailerTimeLength+inputReferenceLength {
+		return som, fmt.Errorf(
+			"invalid system originated message string length: %d, expected %d",
+			len(str),
+			trailerTimeLength+inputReferenceLength,
+		)
 	}
 
-	timeStr := str[0:4]
-	var time Time
-	err := time.UnmarshalMT(timeStr)
+	time, err := stringToTrailerTime(str)
 	if err != nil {
-		return som, fmt.Errorf("invalid system originated message time: %s: %w", timeStr, err)
+		return som, fmt.Errorf("invalid system originated message time: %w", err)
 	}
 	som.Time = time
 
-	mir, err := stringToMessageInputReferenceDate(str[4:])
+	mirStr := str[trailerTimeLength:]
+	mir, err := stringToMessageInputReferenceDate(mirStr)
 	if err != nil {
-		return som, fmt.Errorf("invalid system originated message message input reference: %s: %w", str[4:], err)
+		return som, fmt.Errorf("invalid system originated message message input reference: %s: %w", mirStr, err)
 	}
 	som.MessageInputReference = mir
 
@@ -517,6 +632,29 @@ func appHeaderBlockToAppHeader(block message.Block) (AppHeaderInput, AppHeaderOu
 	return appHeaderIn, appHeaderOut, errToReturn
 }
 
+// validateAppHeader verifies the consistency of the app header. At most one of the input and output variety can be set
+// and the message type of the one that is set has to consist of 3 digits.
+func validateAppHeader(in AppHeaderInput, out AppHeaderOutput) error {
+	var messageType string
+
+	switch {
+	case in.Set && out.Set:
+		return fmt.Errorf("both an input and an output app header are set")
+	case in.Set:
+		messageType = in.MessageType
+	case out.Set:
+		messageType = out.MessageType
+	default:
+		return nil
+	}
+
+	if len(messageType) != 3 || strings.IndexFunc(messageType, isNotDigit) >= 0 {
+		return fmt.Errorf("invalid message type %q: expected 3 digits", messageType)
+	}
+
+	return nil
+}
+
 // usrHeaderBlockToUsrHeader parses the user header block and returns a UsrHeader struct.
 //
 // The header block should contain one or more sub blocks. Each block will be processed, its label will decide which
@@ -526,18 +664,21 @@ func appHeaderBlockToAppHeader(block message.Block) (AppHeaderInput, AppHeaderOu
 func usrHeaderBlockToUsrHeader(block message.Block) (UsrHeader, []error) {
 	msgUsrHeader := UsrHeader{
 		Set: true,
-		Raw: "{3:" + block.Content + "}",
 	}
 	errors := make([]error, 0)
+	raw := "{3:"
 
 	for _, sb := range block.Blocks {
+		sbRaw := "{" + sb.Label + ":" + sb.Content + "}"
+		raw += sbRaw
+
 		switch sb.Label {
 		case "103":
 			msgUsrHeader.ServiceID = sb.Content
 		case "106":
 			msgInReference, err := stringToMessageInputReferenceDate(sb.Content)
 			if err != nil {
-				errors = append(errors, fmt.Errorf("invalid message input reference: %w", err))
+				errors = append(errors, withRaw(fmt.Errorf("invalid message input reference: %w", err), sbRaw))
 				continue
 			}
 
@@ -560,11 +701,11 @@ func usrHeaderBlockToUsrHeader(block message.Block) (UsrHeader, []error) {
 			var balanceCheckpointDateTime DateTimeSecOptCent
 			err := balanceCheckpointDateTime.UnmarshalMT(sb.Content)
 			if err != nil {
-				errors = append(errors, fmt.Errorf(
+				errors = append(errors, withRaw(fmt.Errorf(
 					"invalid balance checkpoint time in usr header block content: %s: %w",
 					sb.Content,
 					err,
-				))
+				), sbRaw))
 				continue
 			}
 
@@ -573,13 +714,24 @@ func usrHeaderBlockToUsrHeader(block message.Block) (UsrHeader, []error) {
 			msgUsrHeader.RelatedReference = sb.Content
 		case "433":
 			msgUsrHeader.SanctionsScreeningInformation = sb.Content
+
+			// the code and additional information are only set when they can be parsed, a malformed value is
+			// reported by validateUsrHeaderServiceCodes when Strict is passed
+			var sanctionsScreening SanctionsScreening
+			if err := sanctionsScreening.UnmarshalMT(sb.Content); err != nil {
+				sanctionsScreening = SanctionsScreening{Set: true, Raw: sb.Content}
+			}
+
+			msgUsrHeader.SanctionsScreening = sanctionsScreening
 		case "434":
 			msgUsrHeader.PaymentControlsInformation = sb.Content
 		default:
-			errors = append(errors, fmt.Errorf("invalid usr header block sub block label: %s", sb.Label))
+			errors = append(errors, withRaw(fmt.Errorf("invalid usr header block sub block label: %s", sb.Label), sbRaw))
 		}
 	}
 
+	msgUsrHeader.Raw = raw + "}"
+
 	if len(errors) > 0 {
 		return msgUsrHeader, errors
 	}
@@ -602,7 +754,8 @@ func trailersBlockToTrailers(block message.Block) (Trailers, []error) {
 	raw := "{5:"
 
 	for _, sb := range block.Blocks {
-		raw += "{" + sb.Label + ":" + sb.Content + "}"
+		sbRaw := "{" + sb.Label + ":" + sb.Content + "}"
+		raw += sbRaw
 
 		switch sb.Label {
 		case "CHK":
@@ -612,7 +765,7 @@ func trailersBlockToTrailers(block message.Block) (Trailers, []error) {
 		case "PDE":
 			pde, err := stringToPossibleDuplicateEmission(sb.Content)
 			if err != nil {
-				errors = append(errors, fmt.Errorf("invalid possible duplicate emission: %w", err))
+				errors = append(errors, withRaw(fmt.Errorf("invalid possible duplicate emission: %w", err), sbRaw))
 			}
 			msgTrailers.PossibleDuplicateEmission = pde
 		case "DLM":
@@ -620,19 +773,19 @@ func trailersBlockToTrailers(block message.Block) (Trailers, []error) {
 		case "MRF":
 			mr, err := stringToMessageReference(sb.Content)
 			if err != nil {
-				errors = append(errors, fmt.Errorf("invalid message reference: %w", err))
+				errors = append(errors, withRaw(fmt.Errorf("invalid message reference: %w", err), sbRaw))
 			}
 			msgTrailers.MessageReference = mr
 		case "PDM":
 			mor, err := stringToPossibleDuplicateMessage(sb.Content)
 			if err != nil {
-				errors = append(errors, fmt.Errorf("invalid possible duplicate message: %w", err))
+				errors = append(errors, withRaw(fmt.Errorf("invalid possible duplicate message: %w", err), sbRaw))
 			}
 			msgTrailers.PossibleDuplicateMessage = mor
 		case "SYS":
 			som, err := stringToSystemOriginatedMessage(sb.Content)
 			if err != nil {
-				errors = append(errors, fmt.Errorf("invalid system originated message: %w", err))
+				errors = append(errors, withRaw(fmt.Errorf("invalid system originated message: %w", err), sbRaw))
 			}
 			msgTrailers.SystemOriginatedMessage = som
 		default:
@@ -649,39 +802,70 @@ func trailersBlockToTrailers(block message.Block) (Trailers, []error) {
 	return msgTrailers, nil
 }
 
+// presentFields returns the tags of the fields in the body as a set.
+func presentFields(body map[string][]string) map[string]bool {
+	present := make(map[string]bool, len(body))
+	for tag := range body {
+		present[tag] = true
+	}
+
+	return present
+}
+
 func messageToMTx(msg message.Message) (MTx, Errors) {
 	mtx := MTx{}
 
 	mtx.Raw = msg.Raw
+	mtx.RawBody = msg.RawBody
+	mtx.Continued = msg.Continued
 	mtx.Body = msg.Body
 	mtx.Line = msg.Line
 
+	mtx.PresentFields = presentFields(msg.Body)
+	mtx.FieldOrder = msg.BodyOrder
+
 	errors := make(Errors, 0)
 
 	msgHeader, err := basicHeaderBlockToBasicHeader(msg.BasicHeader)
 	if err != nil {
-		errors = append(errors, NewError(fmt.Errorf("invalid basic header: %w", err), msg.Line))
+		errors = append(errors, newBlockError(fmt.Errorf("invalid basic header: %w", err), msg.Line, msgHeader.Raw))
 	}
 	mtx.BasicHeader = msgHeader
 
-	appHeaderInput, appHeaderOutput, err := appHeaderBlockToAppHeader(msg.AppHeader)
-	if err != nil {
-		errors = append(errors, NewError(fmt.Errorf("invalid app header: %w", err), msg.Line))
+	// system messages, e.g. a login, and acknowledgements can lack an app header, for financial messages it is required
+	if msg.AppHeader.Label != "" ||
+		(msgHeader.AppID == ApplicationIDFinancial && msgHeader.ServiceID != ServiceIDACKNACK) {
+		appHeaderRaw := "{2:" + msg.AppHeader.Content + "}"
+
+		appHeaderInput, appHeaderOutput, err := appHeaderBlockToAppHeader(msg.AppHeader)
+		if err != nil {
+			errors = append(errors, newBlockError(fmt.Errorf("invalid app header: %w", err), msg.Line, appHeaderRaw))
+		}
+		mtx.AppHeaderInput = appHeaderInput
+		mtx.AppHeaderOutput = appHeaderOutput
+
+		err = validateAppHeader(appHeaderInput, appHeaderOutput)
+		if err != nil {
+			errors = append(errors, newBlockError(fmt.Errorf("invalid app header: %w", err), msg.Line, appHeaderRaw))
+		}
 	}
-	mtx.AppHeaderInput = appHeaderInput
-	mtx.AppHeaderOutput = appHeaderOutput
 
-	usrHeader, errs := usrHeaderBlockToUsrHeader(msg.UsrHeader)
-	for _, err := range errs {
-		errors = append(errors, NewError(fmt.Errorf("invalid user header: %w", err), msg.Line))
+	// the optional blocks are only parsed when present, even if empty, so an absent block can be distinguished
+	if msg.UsrHeader.Label != "" {
+		usrHeader, errs := usrHeaderBlockToUsrHeader(msg.UsrHeader)
+		for _, err := range errs {
+			errors = append(errors, newBlockError(fmt.Errorf("invalid user header: %w", err), msg.Line, usrHeader.Raw))
+		}
+		mtx.UsrHeader = usrHeader
 	}
-	mtx.UsrHeader = usrHeader
 
-	trailers, errs := trailersBlockToTrailers(msg.Trailers)
-	for _, err := range errs {
-		errors = append(errors, NewError(fmt.Errorf("invalid trailers: %w", err), msg.Line))
+	if msg.Trailers.Label != "" {
+		trailers, errs := trailersBlockToTrailers(msg.Trailers)
+		for _, err := range errs {
+			errors = append(errors, newBlockError(fmt.Errorf("invalid trailers: %w", err), msg.Line, trailers.Raw))
+		}
+		mtx.Trailers = trailers
 	}
-	mtx.Trailers = trailers
 
 	if len(errors) > 0 {
 		return mtx, errors
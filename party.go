@@ -0,0 +1,231 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	bic8Length  = 8
+	bic11Length = 11
+
+	// partyDetailMaxNumber is the highest number of a line of name and address details in option F, 8 being used for
+	// additional information.
+	partyDetailMaxNumber = 8
+)
+
+// bicSlots describes the structure of a BIC: a 4!a bank code, 2!a country code and 2!c location code, optionally
+// followed by a 3!c branch code.
+var bicSlots = []struct {
+	name        string
+	start       int
+	end         int
+	valid       func(r rune) bool
+	description string
+}{
+	{"bank code", 0, 4, isUpperLetter, "letters"},
+	{"country code", 4, 6, isUpperLetter, "letters"},
+	{"location code", 6, 8, isUpperAlphaNumeric, "letters or digits"},
+	{"branch code", 8, 11, isUpperAlphaNumeric, "letters or digits"},
+}
+
+// validateBIC verifies the given BIC adheres to the 8 or 11 character BIC structure. The returned error describes which
+// part of the BIC is invalid.
+func validateBIC(bic string) error {
+	if len(bic) != bic8Length && len(bic) != bic11Length {
+		return fmt.Errorf("BIC %q: expected %d or %d characters, got %d", bic, bic8Length, bic11Length, len(bic))
+	}
+
+	for _, slot := range bicSlots {
+		if slot.end > len(bic) {
+			break
+		}
+
+		for _, r := range bic[slot.start:slot.end] {
+			if !slot.valid(r) {
+				return fmt.Errorf("BIC %q: %s %q must consist of %s", bic, slot.name, bic[slot.start:slot.end], slot.description)
+			}
+		}
+	}
+
+	return nil
+}
+
+// isValidIBAN tells whether an account identification is an IBAN, having both its form and valid check digits. The
+// check digits are verified by moving the first four characters to the end, replacing letters by numbers, A being 10,
+// and verifying the remainder of dividing the resulting number by 97 is 1.
+func isValidIBAN(account string) bool {
+	if !isIBAN(account) {
+		return false
+	}
+
+	digits := &strings.Builder{}
+	for _, r := range account[4:] + account[:4] {
+		if isUpperLetter(r) {
+			digits.WriteString(strconv.Itoa(int(r-'A') + 10))
+			continue
+		}
+
+		digits.WriteRune(r)
+	}
+
+	n, ok := new(big.Int).SetString(digits.String(), 10)
+	if !ok {
+		return false
+	}
+
+	return new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+// PartyDetail is a numbered line of the name and address details of a party in option F, e.g. 1/JOHN DOE. The number
+// identifies the kind of detail: 1 for the name, 2 for the address, 3 for the country and town, 4 for the date of
+// birth, 5 for the place of birth, 6 for the customer identification number, 7 for the national identity number and 8
+// for additional information.
+type PartyDetail struct {
+	Number int
+	Value  string
+}
+
+// Party is a party identified in one of the options of fields like the ordering customer (50) and the beneficiary
+// customer (59). Which parts are set depends on the option, the letter of the tag:
+//
+//   - A: an optional account on the first line, followed by the BIC of the party.
+//   - F: an account or a party identifier, e.g. CUST/DE/ABC/1234, on the first line, followed by the numbered lines of
+//     name and address details.
+//   - K, or no letter as for 59: an optional account on the first line, followed by the name and address of the party.
+//
+// The account is stored without its leading slash, if it is a valid IBAN it is set as the IBAN as well.
+type Party struct {
+	Set         bool
+	Raw         string
+	Option      string
+	Account     string
+	IBAN        string
+	BIC         string
+	Identifier  string
+	NameAddress []string
+	Details     []PartyDetail
+}
+
+// UnmarshalMT parses the party from a field without option letter, e.g. 59, holding an optional account and the name
+// and address of the party.
+func (p *Party) UnmarshalMT(input string) error {
+	return p.unmarshal("", input)
+}
+
+// UnmarshalMTTag parses the party according to the option letter of the tag it was found under, e.g. A for 50A.
+func (p *Party) UnmarshalMTTag(tag, input string) error {
+	option := strings.TrimLeft(tag, "0123456789")
+
+	return p.unmarshal(option, input)
+}
+
+func (p *Party) unmarshal(option, input string) error {
+	party := Party{
+		Set:    true,
+		Raw:    input,
+		Option: option,
+	}
+
+	lines := strings.Split(strings.ReplaceAll(input, "\r\n", "\n"), "\n")
+
+	// an account on the first line is marked by a leading slash, in option F the line holds a party identifier instead
+	// when it is not
+	if strings.HasPrefix(lines[0], "/") {
+		party.Account = strings.TrimPrefix(lines[0], "/")
+		if isValidIBAN(party.Account) {
+			party.IBAN = party.Account
+		}
+
+		lines = lines[1:]
+	} else if option == "F" {
+		party.Identifier = lines[0]
+		lines = lines[1:]
+	}
+
+	switch option {
+	case "A":
+		if len(lines) != 1 {
+			return fmt.Errorf("invalid Party: option A: expected a BIC on the line after the optional account")
+		}
+
+		err := validateBIC(lines[0])
+		if err != nil {
+			return fmt.Errorf("invalid Party: option A: %w", err)
+		}
+
+		party.BIC = lines[0]
+	case "F":
+		for _, line := range lines {
+			detail, err := parsePartyDetail(line)
+			if err != nil {
+				return fmt.Errorf("invalid Party: option F: %w", err)
+			}
+
+			party.Details = append(party.Details, detail)
+		}
+	case "K", "":
+		party.NameAddress = lines
+	default:
+		return fmt.Errorf("invalid Party: unsupported option %q", option)
+	}
+
+	*p = party
+
+	return nil
+}
+
+// parsePartyDetail parses a numbered line of name and address details, e.g. 1/JOHN DOE.
+func parsePartyDetail(line string) (PartyDetail, error) {
+	idx := strings.Index(line, "/")
+	if idx < 0 {
+		return PartyDetail{}, fmt.Errorf("detail %q: expected a number followed by a slash", line)
+	}
+
+	number, err := strconv.Atoi(line[:idx])
+	if err != nil || number < 1 || number > partyDetailMaxNumber {
+		return PartyDetail{}, fmt.Errorf("detail %q: expected a number from 1 to %d", line, partyDetailMaxNumber)
+	}
+
+	return PartyDetail{Number: number, Value: line[idx+1:]}, nil
+}
+
+func (p Party) RawString() string {
+	return p.Raw
+}
+
+func (p Party) String() string {
+	return p.RawString()
+}
+
+// MarshalMT generates the value of the field from the parts of the party, see Party for the format of each option.
+func (p Party) MarshalMT() (string, error) {
+	lines := make([]string, 0, 1+len(p.NameAddress)+len(p.Details))
+
+	switch {
+	case p.Account != "":
+		lines = append(lines, "/"+p.Account)
+	case p.Identifier != "":
+		lines = append(lines, p.Identifier)
+	}
+
+	switch p.Option {
+	case "A":
+		lines = append(lines, p.BIC)
+	case "F":
+		for _, detail := range p.Details {
+			lines = append(lines, strconv.Itoa(detail.Number)+"/"+detail.Value)
+		}
+	default:
+		lines = append(lines, p.NameAddress...)
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/DennisVis/mt"
+	mttest "github.com/DennisVis/mt/testdata"
+)
+
+func TestParty(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		tag         string
+		input       string
+		expectedErr error
+		expected    mt.Party
+	}{
+		{
+			name:  "OptionA",
+			tag:   "50A",
+			input: "/GB82WEST12345698765432\nABNANL2AXXX",
+			expected: mt.Party{
+				Option:  "A",
+				Account: "GB82WEST12345698765432",
+				IBAN:    "GB82WEST12345698765432",
+				BIC:     "ABNANL2AXXX",
+			},
+		},
+		{
+			name:  "OptionABICOnly",
+			tag:   "50A",
+			input: "ABNANL2A",
+			expected: mt.Party{
+				Option: "A",
+				BIC:    "ABNANL2A",
+			},
+		},
+		{
+			name:  "OptionK",
+			tag:   "50K",
+			input: "/PL61109010140000071219812874\nJOHN DOE\nMAIN STREET 1\nWARSAW",
+			expected: mt.Party{
+				Option:      "K",
+				Account:     "PL61109010140000071219812874",
+				IBAN:        "PL61109010140000071219812874",
+				NameAddress: []string{"JOHN DOE", "MAIN STREET 1", "WARSAW"},
+			},
+		},
+		{
+			name:  "OptionKAccountNotIBAN",
+			tag:   "50K",
+			input: "/123456789\nJOHN DOE",
+			expected: mt.Party{
+				Option:      "K",
+				Account:     "123456789",
+				NameAddress: []string{"JOHN DOE"},
+			},
+		},
+		{
+			name:  "OptionKInvalidIBANCheckDigits",
+			tag:   "50K",
+			input: "/GB00WEST12345698765432\nJOHN DOE",
+			expected: mt.Party{
+				Option:      "K",
+				Account:     "GB00WEST12345698765432",
+				NameAddress: []string{"JOHN DOE"},
+			},
+		},
+		{
+			name:  "OptionF",
+			tag:   "50F",
+			input: "CUST/DE/ABC/1234\n1/JOHN DOE\n2/MAIN STREET 1\n3/DE/BERLIN",
+			expected: mt.Party{
+				Option:     "F",
+				Identifier: "CUST/DE/ABC/1234",
+				Details: []mt.PartyDetail{
+					{Number: 1, Value: "JOHN DOE"},
+					{Number: 2, Value: "MAIN STREET 1"},
+					{Number: 3, Value: "DE/BERLIN"},
+				},
+			},
+		},
+		{
+			name:  "OptionFAccount",
+			tag:   "50F",
+			input: "/GB82WEST12345698765432\n1/JOHN DOE",
+			expected: mt.Party{
+				Option:  "F",
+				Account: "GB82WEST12345698765432",
+				IBAN:    "GB82WEST12345698765432",
+				Details: []mt.PartyDetail{{Number: 1, Value: "JOHN DOE"}},
+			},
+		},
+		{
+			name:  "NoOption",
+			tag:   "59",
+			input: "/123456789\nJANE DOE\nSECOND STREET 2",
+			expected: mt.Party{
+				Account:     "123456789",
+				NameAddress: []string{"JANE DOE", "SECOND STREET 2"},
+			},
+		},
+		{
+			name:        "OptionAInvalidBIC",
+			tag:         "50A",
+			input:       "/123456789\nABN1NL2A",
+			expectedErr: fmt.Errorf(`invalid Party: option A: BIC "ABN1NL2A": bank code "ABN1" must consist of letters`),
+		},
+		{
+			name:        "OptionAInvalidBICLength",
+			tag:         "50A",
+			input:       "ABNANL2AX",
+			expectedErr: fmt.Errorf(`invalid Party: option A: BIC "ABNANL2AX": expected 8 or 11 characters, got 9`),
+		},
+		{
+			name:        "OptionFInvalidDetail",
+			tag:         "50F",
+			input:       "CUST/DE/ABC/1234\n9/JOHN DOE",
+			expectedErr: fmt.Errorf(`invalid Party: option F: detail "9/JOHN DOE": expected a number from 1 to 8`),
+		},
+		{
+			name:        "UnsupportedOption",
+			tag:         "50G",
+			input:       "/123456789\nABNANL2A",
+			expectedErr: fmt.Errorf(`invalid Party: unsupported option "G"`),
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var p mt.Party
+			err := p.UnmarshalMTTag(test.tag, test.input)
+			mttest.ValidateError(t, test.expectedErr, err)
+
+			if test.expectedErr != nil {
+				if p.Set {
+					t.Errorf("expected Set to be false")
+				}
+				return
+			}
+
+			test.expected.Set = true
+			test.expected.Raw = test.input
+			if !reflect.DeepEqual(p, test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, p)
+			}
+			if p.String() != test.input {
+				t.Errorf("expected String() to return %q, got %q", test.input, p.String())
+			}
+
+			marshaled, err := p.MarshalMT()
+			if err != nil {
+				t.Fatalf("expected nil error, got: %v", err)
+			}
+			if marshaled != test.input {
+				t.Errorf("expected MarshalMT() to return %q, got %q", test.input, marshaled)
+			}
+		})
+	}
+}
+
+func TestPartyUnmarshalMT(t *testing.T) {
+	var p mt.Party
+	err := p.UnmarshalMT("JANE DOE")
+	if err != nil {
+		t.Fatalf("expected nil error, got: %v", err)
+	}
+
+	if p.Option != "" {
+		t.Errorf("expected no option, got %q", p.Option)
+	}
+	if !reflect.DeepEqual(p.NameAddress, []string{"JANE DOE"}) {
+		t.Errorf("expected NameAddress to be [JANE DOE], got %v", p.NameAddress)
+	}
+}
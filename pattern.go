@@ -0,0 +1,101 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/DennisVis/mt/internal/pattern"
+)
+
+// RegisterCharSet registers a custom character set under the given key, making it usable in the formats passed to
+// CompilePattern, e.g. 4!h after registering a hexadecimal character set under h. The key must be a single letter and
+// can not be one of the built-in keys n, a, c, x, d and z. Registering a key again replaces the character set
+// registered before. It is safe for concurrent use, patterns compiled before the registration are not affected though,
+// so character sets are best registered up front, e.g. in an init function.
+func RegisterCharSet(key string, fn func(r rune) bool) error {
+	return pattern.RegisterCharSet(key, fn)
+}
+
+// Pattern is a compiled field format, as used in the SWIFT specification and in the mt tags of the message types of
+// this library, e.g. 3!a15d or 4*35x. It is safe for concurrent use.
+type Pattern struct {
+	spec    string
+	pattern pattern.Pattern
+}
+
+// CompilePattern compiles a field format for reuse, so fields of custom message types can be validated using the same
+// grammar the library uses. The format consists of character groups, e.g. 3!a for exactly three uppercase letters,
+// literals, optional parts between parentheses, alternatives separated by a pipe and line counts, e.g. 4*35x.
+//
+// Example usage:
+//
+//	ptrn, err := CompilePattern("3!a15d")
+//	if err != nil {
+//		// handle the invalid format
+//	}
+//
+//	err = ptrn.Validate("EUR1234,56")
+func CompilePattern(spec string) (Pattern, error) {
+	ptrn, err := pattern.ParseCached(spec)
+	if err != nil {
+		return Pattern{}, fmt.Errorf("could not compile pattern %q: %w", spec, err)
+	}
+
+	return Pattern{spec: spec, pattern: ptrn}, nil
+}
+
+// MustCompilePattern is like CompilePattern but panics if the format can not be compiled. It simplifies the
+// initialization of package level patterns.
+func MustCompilePattern(spec string) Pattern {
+	ptrn, err := CompilePattern(spec)
+	if err != nil {
+		panic(err)
+	}
+
+	return ptrn
+}
+
+// Validate validates the entire value against the pattern. When the value does not match a PositionError is returned,
+// describing the line and column at which matching failed.
+func (p Pattern) Validate(value string) error {
+	if p.pattern == nil {
+		return fmt.Errorf("pattern not compiled, use CompilePattern")
+	}
+
+	err := p.pattern.Validate(value)
+
+	var posErr pattern.PositionError
+	if errors.As(err, &posErr) {
+		return PositionError{Line: posErr.Line, Column: posErr.Column, Err: posErr.Err}
+	}
+
+	return err
+}
+
+// PositionError is returned by Pattern.Validate when the value does not match the pattern. It holds the position in the
+// value where matching failed. Both line and column are 1-based, the column counts characters rather than bytes.
+type PositionError struct {
+	Line   int
+	Column int
+	Err    error
+}
+
+// Error implements the Error interface.
+func (pe PositionError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", pe.Line, pe.Column, pe.Err)
+}
+
+// Unwrap returns the underlying error.
+func (pe PositionError) Unwrap() error {
+	return pe.Err
+}
+
+// String returns the format the pattern was compiled from.
+func (p Pattern) String() string {
+	return p.spec
+}
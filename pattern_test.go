@@ -0,0 +1,127 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/DennisVis/mt"
+	mttest "github.com/DennisVis/mt/testdata"
+)
+
+func TestCompilePattern(t *testing.T) {
+	for _, test := range []struct {
+		name               string
+		spec               string
+		value              string
+		expectedCompileErr error
+		expectedErr        error
+	}{
+		{
+			name:               "InvalidSpec",
+			spec:               "(3!a",
+			expectedCompileErr: fmt.Errorf(`could not compile pattern "(3!a": could not parse pattern`),
+		},
+		{
+			name:  "Valid",
+			spec:  "3!a15d",
+			value: "EUR1234,56",
+		},
+		{
+			name:        "Invalid",
+			spec:        "3!a15d",
+			value:       "EU1234,56",
+			expectedErr: fmt.Errorf("line 1, column 3: expected 3 characters within 'a' group, got 2"),
+		},
+		{
+			name:  "ValidMultiLine",
+			spec:  "4*35x",
+			value: "LINE ONE\nLINE TWO",
+		},
+		{
+			name:        "InvalidMultiLine",
+			spec:        "2*5x",
+			value:       "LINE\nLINE TWO",
+			expectedErr: fmt.Errorf("line 2, column 6: incomplete match"),
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ptrn, err := mt.CompilePattern(test.spec)
+			mttest.ValidateError(t, test.expectedCompileErr, err)
+			if err != nil {
+				return
+			}
+
+			if ptrn.String() != test.spec {
+				t.Errorf("expected String %s, got %s", test.spec, ptrn.String())
+			}
+
+			mttest.ValidateError(t, test.expectedErr, ptrn.Validate(test.value))
+		})
+	}
+}
+
+func TestPatternPositionError(t *testing.T) {
+	t.Parallel()
+
+	ptrn := mt.MustCompilePattern("2*5x")
+
+	err := ptrn.Validate("LINE\nLINE TWO")
+
+	var posErr mt.PositionError
+	if !errors.As(err, &posErr) {
+		t.Fatalf("expected a PositionError, got %T", err)
+	}
+	if posErr.Line != 2 || posErr.Column != 6 {
+		t.Errorf("expected line 2, column 6, got line %d, column %d", posErr.Line, posErr.Column)
+	}
+	mttest.ValidateError(t, fmt.Errorf("incomplete match"), posErr.Err)
+}
+
+func TestPatternNotCompiled(t *testing.T) {
+	t.Parallel()
+
+	mttest.ValidateError(t, fmt.Errorf("pattern not compiled"), mt.Pattern{}.Validate("ABC"))
+}
+
+func TestMustCompilePattern(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected MustCompilePattern to panic on an invalid spec")
+		}
+	}()
+
+	mt.MustCompilePattern("(3!a")
+}
+
+// TestRegisterCharSet changes the character sets of the package, it therefore doesn't run in parallel with other tests
+// and undoes the registration once done.
+func TestRegisterCharSet(t *testing.T) {
+	err := mt.RegisterCharSet("n", func(r rune) bool { return true })
+	mttest.ValidateError(t, fmt.Errorf(`invalid charset key "n": can not redefine built-in charset`), err)
+
+	err = mt.RegisterCharSet("b", func(r rune) bool { return r == '0' || r == '1' })
+	mttest.ValidateError(t, nil, err)
+	t.Cleanup(func() {
+		mt.UnregisterCharSet("b")
+	})
+
+	ptrn, err := mt.CompilePattern("8!b")
+	mttest.ValidateError(t, nil, err)
+
+	mttest.ValidateError(t, nil, ptrn.Validate("01100101"))
+	expectedErr := fmt.Errorf("line 1, column 3: expected 8 characters within 'b' group, got 2")
+	mttest.ValidateError(t, expectedErr, ptrn.Validate("01201101"))
+}
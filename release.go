@@ -0,0 +1,54 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt
+
+import (
+	"fmt"
+
+	"github.com/DennisVis/mt/internal/validate"
+)
+
+// releaseValidators holds, per message type, the validators keyed by the standards release their rules were
+// introduced in. A validator applies from its release onwards, until superseded by the validator of a later release.
+// Message types without an entry are always validated against their current rules.
+var releaseValidators = map[string]map[int]validate.Validator{
+	// the spec MT940 is based on is that of SR2021
+	MessageTypeMT940: {
+		2021: mt940Validator,
+	},
+}
+
+// validatorForRelease returns the validator for the given message type applicable to the given standards release. For
+// release 0, or message types that have not been parameterized by release, the current validator is returned.
+func validatorForRelease(messageType string, release int, current validate.Validator) (validate.Validator, error) {
+	validators, ok := releaseValidators[messageType]
+	if !ok || release == 0 {
+		return current, nil
+	}
+
+	var validator validate.Validator
+	applicableRelease, earliestRelease := 0, 0
+
+	for r, v := range validators {
+		if earliestRelease == 0 || r < earliestRelease {
+			earliestRelease = r
+		}
+		if r <= release && r > applicableRelease {
+			validator, applicableRelease = v, r
+		}
+	}
+
+	if validator == nil {
+		return nil, fmt.Errorf(
+			"standards release SR%d is not supported for MT%s, the earliest supported release is SR%d",
+			release,
+			messageType,
+			earliestRelease,
+		)
+	}
+
+	return validator, nil
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DennisVis/mt"
+	mttest "github.com/DennisVis/mt/testdata"
+)
+
+func TestStandardsRelease(t *testing.T) {
+	validMT940 := strings.Replace(pagedMT940, "00084/001/02", "00084/001", 1)
+	validMT942 := fmt.Sprintf(mt942FloorLimitsInput, ":34F:PLND100,00")
+
+	for _, test := range []struct {
+		name          string
+		release       int
+		expectedErr   error
+		expectedCount int
+	}{
+		{
+			name:          "Current",
+			release:       0,
+			expectedCount: 2,
+		},
+		{
+			name:          "Earliest",
+			release:       2021,
+			expectedCount: 2,
+		},
+		{
+			name:          "Later",
+			release:       2023,
+			expectedCount: 2,
+		},
+		{
+			// only MT940 has been parameterized by release, MT942 is validated against the current rules
+			name:    "Unsupported",
+			release: 2020,
+			expectedErr: fmt.Errorf(
+				"standards release SR2020 is not supported for MT940, the earliest supported release is SR2021",
+			),
+			expectedCount: 1,
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			mt940s, err := mt.ParseAllMT940(ctx, strings.NewReader(validMT940), mt.StandardsRelease(test.release))
+			mttest.ValidateError(t, test.expectedErr, err)
+
+			mt942s, err := mt.ParseAllMT942(ctx, strings.NewReader(validMT942), mt.StandardsRelease(test.release))
+			mttest.ValidateError(t, nil, err)
+
+			if count := len(mt940s) + len(mt942s); count != test.expectedCount {
+				t.Errorf("expected %d messages, got %d", test.expectedCount, count)
+			}
+		})
+	}
+}
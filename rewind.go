@@ -0,0 +1,102 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// exhaustionGuard detects a reader that has been read to its end before it is handed to the parser, e.g. because it
+// was passed to a parse function twice. Such a reader yields no input at all, just like an empty input does, but
+// parsing it is most likely a mistake. A reader that is an io.Seeker, e.g. an *os.File, *strings.Reader or
+// *bytes.Reader, is considered exhausted when it ends right away at an offset beyond its start. Other readers can't be
+// told apart from an empty input. The error is returned by every read, as buffering readers on top might swallow it.
+type exhaustionGuard struct {
+	rd      io.Reader
+	checked bool
+	err     error
+}
+
+// Read implements the io.Reader interface.
+func (g *exhaustionGuard) Read(p []byte) (int, error) {
+	if g.err != nil {
+		return 0, g.err
+	}
+
+	n, err := g.rd.Read(p)
+	if g.checked || (n == 0 && err == nil) {
+		return n, err
+	}
+
+	g.checked = true
+
+	if n > 0 || !errors.Is(err, io.EOF) {
+		return n, err
+	}
+
+	if seeker, ok := g.rd.(io.Seeker); ok {
+		offset, seekErr := seeker.Seek(0, io.SeekCurrent)
+		if seekErr == nil && offset > 0 {
+			g.err = fmt.Errorf(
+				"reader is exhausted: it ends at offset %d before any input was read, was it parsed before?",
+				offset,
+			)
+
+			return 0, g.err
+		}
+	}
+
+	return n, err
+}
+
+// Rewindable wraps a reader, buffering everything read from it in memory so the same input can be read again from the
+// start after calling Rewind. Readers are consumed by parsing, a second parse of the same reader finds nothing left to
+// read, a Rewindable makes it possible to retry a parse, e.g. with different options, on inputs that can't be re-opened
+// or seeked, like network streams.
+//
+// Example usage:
+//
+//	rd := NewRewindable(conn)
+//
+//	messages, err := ParseAllMT940(ctx, rd)
+//	if err != nil {
+//		rd.Rewind()
+//		messages, err = ParseAllMT940(ctx, rd, Lax(true))
+//	}
+type Rewindable struct {
+	rd  io.Reader
+	buf []byte
+	pos int
+}
+
+// NewRewindable returns a Rewindable reading from rd.
+func NewRewindable(rd io.Reader) *Rewindable {
+	return &Rewindable{rd: rd}
+}
+
+// Read implements the io.Reader interface. Input read before is served from the buffer, the rest from the underlying
+// reader.
+func (r *Rewindable) Read(p []byte) (int, error) {
+	if r.pos < len(r.buf) {
+		n := copy(p, r.buf[r.pos:])
+		r.pos += n
+
+		return n, nil
+	}
+
+	n, err := r.rd.Read(p)
+	r.buf = append(r.buf, p[:n]...)
+	r.pos += n
+
+	return n, err
+}
+
+// Rewind has the next read start at the beginning of the input again.
+func (r *Rewindable) Rewind() {
+	r.pos = 0
+}
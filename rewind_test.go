@@ -0,0 +1,107 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt_test
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/DennisVis/mt"
+	mttest "github.com/DennisVis/mt/testdata"
+)
+
+func TestParseExhaustedReader(t *testing.T) {
+	const input = "{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:\n:20:REFERENCE\n-}"
+
+	for _, test := range []struct {
+		name               string
+		reader             func() io.Reader
+		expectedErr        error
+		expectedSecondErr  error
+		expectedCount      int
+		expectedSecondRead int
+	}{
+		{
+			name:              "SeekableReader",
+			reader:            func() io.Reader { return strings.NewReader(input) },
+			expectedCount:     1,
+			expectedSecondErr: fmt.Errorf("reader is exhausted: it ends at offset 70 before any input was read"),
+		},
+		{
+			name:   "EmptyInput",
+			reader: func() io.Reader { return strings.NewReader("") },
+		},
+		{
+			name:               "Rewindable",
+			reader:             func() io.Reader { return mt.NewRewindable(strings.NewReader(input)) },
+			expectedCount:      1,
+			expectedSecondRead: 1,
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			rd := test.reader()
+
+			msgs, err := mt.ParseAllMTx(ctx, rd)
+			mttest.ValidateError(t, test.expectedErr, err)
+			if len(msgs) != test.expectedCount {
+				t.Errorf("expected %d messages, got %d", test.expectedCount, len(msgs))
+			}
+
+			if rewindable, ok := rd.(*mt.Rewindable); ok {
+				rewindable.Rewind()
+			}
+
+			msgs, err = mt.ParseAllMTx(ctx, rd)
+			mttest.ValidateError(t, test.expectedSecondErr, err)
+			if len(msgs) != test.expectedSecondRead {
+				t.Errorf("expected %d messages the second time, got %d", test.expectedSecondRead, len(msgs))
+			}
+		})
+	}
+}
+
+func TestRewindable(t *testing.T) {
+	sample, err := os.ReadFile("testdata/sample-file-mt940.txt")
+	if err != nil {
+		t.Fatalf("could not read sample file: %v", err)
+	}
+
+	rd := mt.NewRewindable(strings.NewReader(string(sample)))
+
+	// read part of the input, rewind and read all of it
+	part := make([]byte, 10)
+	if _, err := io.ReadFull(rd, part); err != nil {
+		t.Fatalf("expected nil error, got: %v", err)
+	}
+
+	rd.Rewind()
+
+	all, err := io.ReadAll(rd)
+	if err != nil {
+		t.Fatalf("expected nil error, got: %v", err)
+	}
+	if string(all) != string(sample) {
+		t.Errorf("expected the whole input after rewinding, got %d of %d bytes", len(all), len(sample))
+	}
+
+	rd.Rewind()
+
+	again, err := io.ReadAll(rd)
+	if err != nil {
+		t.Fatalf("expected nil error, got: %v", err)
+	}
+	if string(again) != string(sample) {
+		t.Errorf("expected the whole input after rewinding again, got %d of %d bytes", len(again), len(sample))
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt
+
+import (
+	"github.com/DennisVis/mt/internal/encoding/mt"
+)
+
+// sequenceUnmarshaler is implemented by messages whose body consists of sequences, e.g. a repeating sequence per
+// transaction. As the same tags occur in multiple sequences the fields of such messages can not be decoded by tag
+// alone, instead the body is split into its sequences based on the order of the fields.
+type sequenceUnmarshaler interface {
+	unmarshalSequences(mtx MTx) error
+}
+
+// sequenceValidator is implemented by messages whose body consists of sequences. It validates the fields of the
+// sequences and the rules spanning multiple sequences.
+type sequenceValidator interface {
+	validateSequences() error
+}
+
+// unmarshalBody decodes the body of the message into the given pointer to a typed message, delegating to the message
+// itself for messages consisting of sequences.
+func unmarshalBody(mtx MTx, v interface{}) error {
+	if su, ok := v.(sequenceUnmarshaler); ok {
+		return su.unmarshalSequences(mtx)
+	}
+
+	return mt.UnmarshalMT(mtx.Body, v)
+}
+
+// validateSequences validates the sequences of the given typed message, if it consists of sequences.
+func validateSequences(v interface{}) error {
+	if sv, ok := v.(sequenceValidator); ok {
+		return sv.validateSequences()
+	}
+
+	return nil
+}
+
+// sequenceBody is the body of a single sequence within a message, holding its fields and their order.
+type sequenceBody struct {
+	fields map[string][]string
+	order  []string
+}
+
+func newSequenceBody() *sequenceBody {
+	return &sequenceBody{
+		fields: make(map[string][]string),
+		order:  make([]string, 0),
+	}
+}
+
+func (sb *sequenceBody) add(tag, value string) {
+	sb.fields[tag] = append(sb.fields[tag], value)
+	sb.order = append(sb.order, tag)
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt
+
+import (
+	"strings"
+)
+
+// statementPageNumberLength is the length of the page number some feeds append to the statement number and sequence
+// number in field 28C, e.g. 00084/001/02.
+const statementPageNumberLength = 2
+
+// StatementNumber represents the statement number and optional sequence number of field 28C, e.g. 00084/001. A page
+// number appended by some feeds, e.g. 00084/001/02, is only split off into Page when the option StatementPageNumber is
+// passed, otherwise it remains part of Raw and is rejected by validation.
+type StatementNumber struct {
+	Set            bool
+	Raw            string
+	Number         string
+	SequenceNumber string
+	Page           string
+}
+
+func (sn *StatementNumber) UnmarshalMT(input string) error {
+	// example:
+	// 00084/001
+
+	segments := strings.SplitN(input, "/", 3)
+
+	sn.Set = true
+	sn.Raw = input
+	sn.Number = segments[0]
+	sn.SequenceNumber = ""
+	sn.Page = ""
+
+	if len(segments) > 1 {
+		sn.SequenceNumber = segments[1]
+	}
+
+	return nil
+}
+
+func (sn StatementNumber) RawString() string {
+	return sn.Raw
+}
+
+// MarshalMT formats the statement number as it adheres to the specification, i.e. without the page number if any.
+func (sn StatementNumber) MarshalMT() (string, error) {
+	if sn.SequenceNumber == "" {
+		return sn.Number, nil
+	}
+
+	return sn.Number + "/" + sn.SequenceNumber, nil
+}
+
+// acceptPage splits the page number off the statement number, leaving a value that adheres to the specification.
+// Values without a page number, or with a third segment that is not a 2 digit number, are left alone, to be rejected
+// by validation.
+func (sn *StatementNumber) acceptPage() {
+	segments := strings.Split(sn.Raw, "/")
+	if len(segments) != 3 {
+		return
+	}
+
+	page := segments[2]
+	if len(page) != statementPageNumberLength || strings.IndexFunc(page, isNotDigit) >= 0 {
+		return
+	}
+
+	sn.Raw = sn.Raw[:len(sn.Raw)-len(page)-1]
+	sn.Page = page
+}
+
+// statementNumbered is implemented by the typed messages holding a statement number in field 28C.
+type statementNumbered interface {
+	acceptStatementPage()
+}
+
+// acceptStatementPageNumber splits the page number off the statement number of the given typed message, if it holds
+// one and the configuration allows for it, see StatementPageNumber.
+func (cfg config) acceptStatementPageNumber(v interface{}) {
+	if sn, ok := v.(statementNumbered); ok && cfg.StatementPageNumber {
+		sn.acceptStatementPage()
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DennisVis/mt"
+	mttest "github.com/DennisVis/mt/testdata"
+)
+
+const pagedMT940 = `{1:F01BPHKPLPKXXXX0000000000}{2:I940BOFAUS6BXBAMN}{4:
+:20:REFERENCE
+:25:BPHKPLPK/320000546101
+:28C:00084/001/02
+:60F:C031002PLN40000,00
+:62F:C031020PLN40000,00
+-}`
+
+func TestStatementPageNumber(t *testing.T) {
+	for _, test := range []struct {
+		name                    string
+		input                   string
+		allow                   bool
+		expectedErr             error
+		expectedStatementNumber mt.StatementNumber
+		expectedRaw28C          string
+	}{
+		{
+			name:        "Default",
+			input:       pagedMT940,
+			allow:       false,
+			expectedErr: fmt.Errorf("28C"),
+		},
+		{
+			name:  "Allowed",
+			input: pagedMT940,
+			allow: true,
+			expectedStatementNumber: mt.StatementNumber{
+				Set:            true,
+				Raw:            "00084/001",
+				Number:         "00084",
+				SequenceNumber: "001",
+				Page:           "02",
+			},
+			expectedRaw28C: ":28C:00084/001/02\n",
+		},
+		{
+			name:  "AllowedWithoutPageNumber",
+			input: strings.Replace(pagedMT940, "00084/001/02", "00084/001", 1),
+			allow: true,
+			expectedStatementNumber: mt.StatementNumber{
+				Set:            true,
+				Raw:            "00084/001",
+				Number:         "00084",
+				SequenceNumber: "001",
+			},
+			expectedRaw28C: ":28C:00084/001\n",
+		},
+		{
+			name:        "AllowedInvalidPageNumber",
+			input:       strings.Replace(pagedMT940, "00084/001/02", "00084/001/2", 1),
+			allow:       true,
+			expectedErr: fmt.Errorf("28C"),
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			msgs, err := mt.ParseAllMT940(ctx, strings.NewReader(test.input), mt.StatementPageNumber(test.allow))
+			mttest.ValidateError(t, test.expectedErr, err)
+
+			if test.expectedErr != nil {
+				return
+			}
+
+			if len(msgs) != 1 {
+				t.Fatalf("expected 1 message, got %d", len(msgs))
+			}
+
+			msg := msgs[0]
+			if msg.StatementNumber != test.expectedStatementNumber {
+				t.Errorf("StatementNumber expected %+v, got %+v", test.expectedStatementNumber, msg.StatementNumber)
+			}
+			if msg.StatementNumberSequenceNumber != test.expectedStatementNumber.Raw {
+				t.Errorf(
+					"StatementNumberSequenceNumber expected %q, got %q",
+					test.expectedStatementNumber.Raw,
+					msg.StatementNumberSequenceNumber,
+				)
+			}
+
+			if !strings.Contains(msg.Raw, test.expectedRaw28C) {
+				t.Errorf("expected Raw to contain %q, got %q", test.expectedRaw28C, msg.Raw)
+			}
+		})
+	}
+}
+
+func TestStatementNumberMarshalMT(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Number",
+			input:    "00084",
+			expected: "00084",
+		},
+		{
+			name:     "SequenceNumber",
+			input:    "00084/001",
+			expected: "00084/001",
+		},
+		{
+			name:     "PageNumber",
+			input:    "00084/001/02",
+			expected: "00084/001",
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var sn mt.StatementNumber
+			mttest.ValidateError(t, nil, sn.UnmarshalMT(test.input))
+
+			marshaled, err := sn.MarshalMT()
+			mttest.ValidateError(t, nil, err)
+
+			if marshaled != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, marshaled)
+			}
+		})
+	}
+}
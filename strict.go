@@ -0,0 +1,21 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt
+
+// strictValidator is implemented by messages with rules beyond those of their individual fields that are only enforced
+// in strict mode, see the Strict option, e.g. the consistency of dates spanning multiple fields.
+type strictValidator interface {
+	validateStrict() error
+}
+
+// validateStrict validates the rules enforced in strict mode of the given typed message, if it has any.
+func validateStrict(v interface{}) error {
+	if sv, ok := v.(strictValidator); ok {
+		return sv.validateStrict()
+	}
+
+	return nil
+}
@@ -26,6 +26,21 @@ func (tr *TestReaderInvalid) Read(p []byte) (int, error) {
 	return len(p), ErrReadInvalid
 }
 
+// TestReaderRepeating is an endless reader repeating Input over and over again.
+type TestReaderRepeating struct {
+	Input string
+	pos   int
+}
+
+func (tr *TestReaderRepeating) Read(p []byte) (int, error) {
+	for i := 0; i < len(p); i++ {
+		p[i] = tr.Input[tr.pos%len(tr.Input)]
+		tr.pos++
+	}
+
+	return len(p), nil
+}
+
 func MustParseTime(value string) mt.Time {
 	var time mt.Time
 	err := time.UnmarshalMT(value)
@@ -66,6 +81,16 @@ func MustParseDateOrDateTime(value string) mt.DateOrDateTime {
 	return time
 }
 
+func MustParseAmount(value string) mt.Amount {
+	var amount mt.Amount
+	err := amount.UnmarshalMT(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return amount
+}
+
 func MustOpenFile(path string) *os.File {
 	f, err := os.Open(path)
 	if err != nil {
@@ -282,6 +307,9 @@ func ValidateReference(t *testing.T, expected, actual mt.Reference) {
 		ValidateRaw(t, expected.Raw, actual.Raw)
 		ValidateDateTime(t, expected.DateTime, actual.DateTime)
 		ValidateInputReference(t, expected.MessageInputReference, actual.MessageInputReference)
+		if expected.DeliveryReference != actual.DeliveryReference {
+			t.Errorf("expected DeliveryReference %s, got %s", expected.DeliveryReference, actual.DeliveryReference)
+		}
 	})
 }
 
@@ -444,6 +472,13 @@ func ValidateUsrHeader(t *testing.T, expected, actual mt.UsrHeader) {
 				actual.SanctionsScreeningInformation,
 			)
 		}
+		if expected.SanctionsScreening.Set && expected.SanctionsScreening != actual.SanctionsScreening {
+			t.Errorf(
+				"SanctionsScreening expected %+v, got %+v",
+				expected.SanctionsScreening,
+				actual.SanctionsScreening,
+			)
+		}
 		if expected.PaymentControlsInformation != "" && expected.PaymentControlsInformation != actual.PaymentControlsInformation {
 			t.Errorf(
 				"PaymentControlsInformation expected %v, got %v",
@@ -490,7 +525,7 @@ func ValidateBalance(t *testing.T, name string, expected, actual mt.Balance) {
 			t.Errorf("expected currency %s, got %s", expected.Currency, actual.Currency)
 		}
 		if expected.Amount != actual.Amount {
-			t.Errorf("expected amount %f, got %f", expected.Amount, actual.Amount)
+			t.Errorf("expected amount %s, got %s", expected.Amount, actual.Amount)
 		}
 		ValidateDate(t, expected.Date, actual.Date)
 	})
@@ -503,7 +538,7 @@ func ValidateStatementLine(t *testing.T, expected, actual mt.StatementLine) {
 			t.Errorf("expected funds code %s, got %s", expected.FundsCode, actual.FundsCode)
 		}
 		if expected.Amount != actual.Amount {
-			t.Errorf("expected amount %f, got %f", expected.Amount, actual.Amount)
+			t.Errorf("expected amount %s, got %s", expected.Amount, actual.Amount)
 		}
 		if expected.SwiftCode != "" && expected.SwiftCode != actual.SwiftCode {
 			t.Errorf("expected SWIFT code %s, got %s", expected.SwiftCode, actual.SwiftCode)
@@ -517,6 +552,9 @@ func ValidateStatementLine(t *testing.T, expected, actual mt.StatementLine) {
 		if expected.Description != "" && expected.Description != actual.Description {
 			t.Errorf("expected description %s, got %s", expected.Description, actual.Description)
 		}
+		if expected.Information != "" && expected.Information != actual.Information {
+			t.Errorf("expected information %s, got %s", expected.Information, actual.Information)
+		}
 		ValidateDate(t, expected.Date, actual.Date)
 		ValidateMonth(t, "EntryDate", expected.EntryDate, actual.EntryDate)
 	})
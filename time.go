@@ -74,6 +74,10 @@ func (m Month) String() string {
 	return m.RawString()
 }
 
+func (m Month) MarshalMT() (string, error) {
+	return m.Time.Format(TimeFormatMonth), nil
+}
+
 type Date struct {
 	Set  bool
 	Raw  string
@@ -101,6 +105,10 @@ func (d Date) String() string {
 	return d.RawString()
 }
 
+func (d Date) MarshalMT() (string, error) {
+	return d.Time.Format(TimeFormatDate), nil
+}
+
 type DateTime struct {
 	Set  bool
 	Raw  string
@@ -134,20 +142,31 @@ type DateOrDateTime struct {
 	Time time.Time
 }
 
+// UnmarshalMT parses either a date, YYMMDD, or a date and time, YYMMDDHHMM, based on the length of the input. Inputs
+// of any other length are rejected.
 func (d *DateOrDateTime) UnmarshalMT(input string) error {
 	var t time.Time
 	var err error
 
-	if len(input) == 10 {
+	switch len(input) {
+	case len(TimeFormatDateTime):
 		t, err = time.Parse(TimeFormatDateTime, input)
 		if err != nil {
 			return fmt.Errorf("invalid DateOrDateTime date/time: %w", err)
 		}
-	} else {
+	case len(TimeFormatDate):
 		t, err = time.Parse(TimeFormatDate, input)
 		if err != nil {
 			return fmt.Errorf("invalid DateOrDateTime date: %w", err)
 		}
+	default:
+		return fmt.Errorf(
+			"invalid DateOrDateTime: expected %d characters for a date or %d for a date/time, got %d: %s",
+			len(TimeFormatDate),
+			len(TimeFormatDateTime),
+			len(input),
+			input,
+		)
 	}
 
 	d.Set = true
@@ -284,3 +303,61 @@ func (d DateTimeOffset) RawString() string {
 func (d DateTimeOffset) String() string {
 	return d.RawString()
 }
+
+// DateTimeIndication is the date and time of field 13D, YYMMDDHHMM, followed by the offset to UTC of the local time it
+// was given in, a sign followed by HHMM. The time it holds is in a zone with that offset.
+type DateTimeIndication struct {
+	Set  bool
+	Raw  string
+	Time time.Time
+}
+
+// UnmarshalMT parses the date, time and offset of field 13D. Inputs that are not exactly 15 characters long, or that
+// lack a + or - sign in between the time and the offset, are rejected.
+func (d *DateTimeIndication) UnmarshalMT(input string) error {
+	if len(input) != len(TimeFormatDateTimeOffset) {
+		return fmt.Errorf(
+			"invalid DateTimeIndication: expected %d characters, got %d: %s",
+			len(TimeFormatDateTimeOffset),
+			len(input),
+			input,
+		)
+	}
+
+	sign := input[len(TimeFormatDateTime)]
+	if sign != '+' && sign != '-' {
+		return fmt.Errorf("invalid DateTimeIndication: expected sign + or - for the offset, got %q", sign)
+	}
+
+	t, err := time.Parse(TimeFormatDateTimeOffset, input)
+	if err != nil {
+		return fmt.Errorf("invalid DateTimeIndication: %w", err)
+	}
+
+	d.Set = true
+	d.Raw = input
+	d.Time = t
+
+	return nil
+}
+
+func (d DateTimeIndication) RawString() string {
+	return d.Raw
+}
+
+func (d DateTimeIndication) String() string {
+	return d.RawString()
+}
+
+func (d DateTimeIndication) MarshalMT() (string, error) {
+	return d.Time.Format(TimeFormatDateTimeOffset), nil
+}
+
+// absDuration returns the absolute value of the duration d.
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+
+	return d
+}
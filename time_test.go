@@ -6,10 +6,13 @@
 package mt_test
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/DennisVis/mt"
+	mttest "github.com/DennisVis/mt/testdata"
 )
 
 func TestTime(t *testing.T) {
@@ -164,6 +167,71 @@ func TestDateTime(t *testing.T) {
 	}
 }
 
+func TestDateOrDateTime(t *testing.T) {
+	for _, test := range []struct {
+		name         string
+		input        string
+		expectedErr  error
+		expectedTime time.Time
+	}{
+		{
+			name:         "Date",
+			input:        "080102",
+			expectedTime: time.Date(2008, time.January, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:         "DateTime",
+			input:        "0801021504",
+			expectedTime: time.Date(2008, time.January, 2, 15, 4, 0, 0, time.UTC),
+		},
+		{
+			name:        "InvalidDate",
+			input:       "08X102",
+			expectedErr: fmt.Errorf("invalid DateOrDateTime date"),
+		},
+		{
+			name:        "InvalidDateTime",
+			input:       "08X1021504",
+			expectedErr: fmt.Errorf("invalid DateOrDateTime date/time"),
+		},
+		{
+			name:  "InvalidLength",
+			input: "08010215",
+			expectedErr: fmt.Errorf(
+				"invalid DateOrDateTime: expected 6 characters for a date or 10 for a date/time, got 8: 08010215",
+			),
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var d mt.DateOrDateTime
+			err := d.UnmarshalMT(test.input)
+			mttest.ValidateError(t, test.expectedErr, err)
+
+			if test.expectedErr != nil {
+				if d.Set {
+					t.Errorf("expected Set to be false")
+				}
+				return
+			}
+
+			if !d.Set {
+				t.Errorf("expected Set to be true")
+			}
+			if d.RawString() != test.input {
+				t.Errorf("expected RawString() to return %s, got %s", test.input, d.RawString())
+			}
+			if !d.Time.Equal(test.expectedTime) {
+				t.Errorf("expected Time to be %s, got %s", test.expectedTime, d.Time)
+			}
+		})
+	}
+}
+
 func TestDateTimeSec(t *testing.T) {
 	var d mt.DateTimeSec
 	err := d.UnmarshalMT("080102150405")
@@ -399,3 +467,141 @@ func TestDateTimeOffset(t *testing.T) {
 		t.Errorf("expected error")
 	}
 }
+
+func TestDateTimeIndication(t *testing.T) {
+	for _, test := range []struct {
+		name           string
+		input          string
+		expectedErr    error
+		expectedTime   time.Time
+		expectedOffset int
+	}{
+		{
+			name:           "PositiveOffset",
+			input:          "2103151130+0100",
+			expectedTime:   time.Date(2021, time.March, 15, 10, 30, 0, 0, time.UTC),
+			expectedOffset: 60 * 60,
+		},
+		{
+			name:           "NegativeOffset",
+			input:          "2103151130-0530",
+			expectedTime:   time.Date(2021, time.March, 15, 17, 0, 0, 0, time.UTC),
+			expectedOffset: -(5*60 + 30) * 60,
+		},
+		{
+			name:        "InvalidSign",
+			input:       "2103151130=0100",
+			expectedErr: fmt.Errorf("invalid DateTimeIndication: expected sign + or - for the offset, got '='"),
+		},
+		{
+			name:        "InvalidDate",
+			input:       "2113151130+0100",
+			expectedErr: fmt.Errorf("invalid DateTimeIndication: parsing time"),
+		},
+		{
+			name:        "InvalidLength",
+			input:       "2103151130+01",
+			expectedErr: fmt.Errorf("invalid DateTimeIndication: expected 15 characters, got 13: 2103151130+01"),
+		},
+	} {
+		// rebind to make sure we can run in parallel
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var d mt.DateTimeIndication
+			err := d.UnmarshalMT(test.input)
+			mttest.ValidateError(t, test.expectedErr, err)
+
+			if test.expectedErr != nil {
+				if d.Set {
+					t.Errorf("expected Set to be false")
+				}
+				return
+			}
+
+			if !d.Set {
+				t.Errorf("expected Set to be true")
+			}
+			if d.RawString() != test.input {
+				t.Errorf("expected RawString() to return %s, got %s", test.input, d.RawString())
+			}
+			if !d.Time.Equal(test.expectedTime) {
+				t.Errorf("expected Time to be %s, got %s", test.expectedTime, d.Time)
+			}
+			if _, offset := d.Time.Zone(); offset != test.expectedOffset {
+				t.Errorf("expected zone offset to be %d, got %d", test.expectedOffset, offset)
+			}
+
+			marshaled, err := d.MarshalMT()
+			if err != nil {
+				t.Fatalf("expected nil error, got: %v", err)
+			}
+			if marshaled != test.input {
+				t.Errorf("expected MarshalMT() to return %s, got %s", test.input, marshaled)
+			}
+		})
+	}
+}
+
+func TestLocation(t *testing.T) {
+	input := `{1:F01BPHKPLPKXXXX0000000000}{2:O9421157091028SCBLZAJJXXXX57121000020910281157N}{4:
+:20:TECHMIX
+:25:PL89106000760000321000006053
+:28C:00001/001
+:34F:PLN0,
+:13D:0506100744+0200
+:61:0506100610D93,17FMSCNONREF//6127001795151001
+:90D:1PLN93,17
+-}`
+
+	ist := time.FixedZone("IST", 5*60*60+30*60)
+
+	parse := func(loc *time.Location) mt.MT942 {
+		msgs, err := mt.ParseAllMT942(ctx, strings.NewReader(input), mt.Location(loc))
+		if err != nil {
+			t.Fatalf("expected nil error, got: %v", err)
+		}
+		if len(msgs) != 1 {
+			t.Fatalf("expected 1 message, got %d", len(msgs))
+		}
+
+		return msgs[0]
+	}
+
+	inUTC := parse(time.UTC)
+	inIST := parse(ist)
+
+	for name, times := range map[string][2]time.Time{
+		"OutputDate": {inUTC.AppHeaderOutput.OutputDate.Time, inIST.AppHeaderOutput.OutputDate.Time},
+		"OutputTime": {inUTC.AppHeaderOutput.OutputTime.Time, inIST.AppHeaderOutput.OutputTime.Time},
+		"ValueDate":  {inUTC.StatementLines[0].Date.Time, inIST.StatementLines[0].Date.Time},
+		"EntryDate":  {inUTC.StatementLines[0].EntryDate.Time, inIST.StatementLines[0].EntryDate.Time},
+	} {
+		utc, local := times[0], times[1]
+
+		if local.Location() != ist {
+			t.Errorf("%s: expected location IST, got %s", name, local.Location())
+		}
+		if local.Format(time.RFC3339) != utc.Format("2006-01-02T15:04:05")+"+05:30" {
+			t.Errorf("%s: expected the date and time to be kept, got %s for %s", name, local, utc)
+		}
+		if d := utc.Sub(local); d != 5*time.Hour+30*time.Minute {
+			t.Errorf("%s: expected the local time to be 5:30 hours before UTC, got %s", name, d)
+		}
+	}
+
+	// times with an explicit offset keep it
+	utcIndication := inUTC.DateTimeIndication.Time
+	istIndication := inIST.DateTimeIndication.Time
+	if !istIndication.Equal(utcIndication) || istIndication.Format(time.RFC3339) != "2005-06-10T07:44:00+02:00" {
+		t.Errorf("expected DateTimeIndication to keep its offset, got %s and %s", utcIndication, istIndication)
+	}
+
+	// without the option times remain in UTC
+	msgs, _ := mt.ParseAllMT942(ctx, strings.NewReader(input))
+	if len(msgs) != 1 || !msgs[0].AppHeaderOutput.OutputDate.Time.Equal(inUTC.AppHeaderOutput.OutputDate.Time) {
+		t.Errorf("expected the default location to be UTC")
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2021 Dennis Vis
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mt
+
+import (
+	"fmt"
+	"regexp"
+	"unicode"
+)
+
+const serviceTypeIDLength = 3
+
+// uetrPattern matches a unique end-to-end transaction reference, a version 4 UUID in lower case as prescribed for
+// field 121, e.g. eb6305c9-1f7f-49de-aed0-16487c27b42d.
+var uetrPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+// validateServiceTypeID verifies the service type identifier, field 111, consists of exactly 3 digits.
+func validateServiceTypeID(id string) error {
+	if len(id) != serviceTypeIDLength {
+		return fmt.Errorf(
+			"service type identifier %q: expected %d digits, got %d characters",
+			id,
+			serviceTypeIDLength,
+			len(id),
+		)
+	}
+
+	for _, r := range id {
+		if !unicode.IsDigit(r) {
+			return fmt.Errorf("service type identifier %q: must consist of digits", id)
+		}
+	}
+
+	return nil
+}
+
+// validateUETR verifies the unique end-to-end transaction reference, field 121, is a well-formed version 4 UUID.
+func validateUETR(uetr string) error {
+	if !uetrPattern.MatchString(uetr) {
+		return fmt.Errorf("unique end-to-end transaction reference %q: expected a lower case version 4 UUID", uetr)
+	}
+
+	return nil
+}
+
+// validateUsrHeaderServiceCodes verifies the service type identifier, unique end-to-end transaction reference and
+// sanctions screening information in the user header, if present. The raw values are kept on the header regardless,
+// for lenient callers.
+func validateUsrHeaderServiceCodes(b Base) error {
+	uh := b.UsrHeader
+
+	if uh.ServiceTypeID != "" {
+		err := validateServiceTypeID(uh.ServiceTypeID)
+		if err != nil {
+			return fmt.Errorf("invalid usr header: %w", err)
+		}
+	}
+
+	if uh.UniqueEndToEndTransactionReference != "" {
+		err := validateUETR(uh.UniqueEndToEndTransactionReference)
+		if err != nil {
+			return fmt.Errorf("invalid usr header: %w", err)
+		}
+	}
+
+	if uh.SanctionsScreeningInformation != "" {
+		var sanctionsScreening SanctionsScreening
+		err := sanctionsScreening.UnmarshalMT(uh.SanctionsScreeningInformation)
+		if err != nil {
+			return fmt.Errorf("invalid usr header: invalid sanctions screening information: %w", err)
+		}
+	}
+
+	return nil
+}